@@ -0,0 +1,111 @@
+// Command todoconv converts todo exports between json, ndjson, todo.txt and
+// Markdown checklists, reading a file or stdin and writing stdout:
+//
+//	todoconv -from json -to todotxt todos.json > todo.txt
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"testcode/test3/internal/adapters/plaintext"
+	"testcode/test3/internal/domain/entity"
+)
+
+func readTodos(format string, r io.Reader) ([]entity.Todo, error) {
+	var rows []entity.TodoImportRow
+	var err error
+	switch format {
+	case "json":
+		var todos []entity.Todo
+		err = json.NewDecoder(r).Decode(&todos)
+		return todos, err
+	case "ndjson":
+		var todos []entity.Todo
+		dec := json.NewDecoder(r)
+		for dec.More() {
+			var t entity.Todo
+			if err = dec.Decode(&t); err != nil {
+				return nil, fmt.Errorf("todo %d: %w", len(todos)+1, err)
+			}
+			todos = append(todos, t)
+		}
+		return todos, nil
+	case "todotxt":
+		rows, err = plaintext.ReadTodoTxt(r, 0)
+	case "markdown":
+		rows, err = plaintext.ReadMarkdown(r, 0)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	todos := make([]entity.Todo, 0, len(rows))
+	for _, row := range rows {
+		if row.Error != "" {
+			return nil, fmt.Errorf("row %d: %s", row.Row, row.Error)
+		}
+		todos = append(todos, row.Todo)
+	}
+	return todos, nil
+}
+
+func writeTodos(format string, w io.Writer, todos []entity.Todo) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if todos == nil {
+			todos = []entity.Todo{}
+		}
+		return enc.Encode(todos)
+	case "ndjson":
+		enc := json.NewEncoder(w)
+		for _, t := range todos {
+			if err := enc.Encode(t); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "todotxt":
+		return plaintext.WriteTodoTxt(w, todos)
+	case "markdown":
+		return plaintext.WriteMarkdown(w, todos)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+func main() {
+	from := flag.String("from", "json", "input format: json, ndjson, todotxt or markdown")
+	to := flag.String("to", "todotxt", "output format: json, ndjson, todotxt or markdown")
+	flag.Parse()
+
+	in := io.Reader(os.Stdin)
+	if flag.NArg() > 0 {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			log.Fatalf("todoconv: %s", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	todos, err := readTodos(*from, bufio.NewReader(in))
+	if err != nil {
+		log.Fatalf("todoconv: %s", err)
+	}
+	out := bufio.NewWriter(os.Stdout)
+	if err = writeTodos(*to, out, todos); err == nil {
+		err = out.Flush()
+	}
+	if err != nil {
+		log.Fatalf("todoconv: %s", err)
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
@@ -9,10 +10,15 @@ import (
 type (
 	// Config -.
 	Config struct {
-		App   `yaml:"app"`
-		HTTP  `yaml:"http"`
-		Log   `yaml:"logger"`
-		MySql `yaml:"mysql"`
+		App         `yaml:"app"`
+		HTTP        `yaml:"http"`
+		GRPC        `yaml:"grpc"`
+		Log         `yaml:"logger"`
+		MySql       `yaml:"mysql"`
+		Blob        `yaml:"blob"`
+		Search      `yaml:"search"`
+		Trash       `yaml:"trash"`
+		Idempotency `yaml:"idempotency"`
 	}
 
 	// App -.
@@ -26,6 +32,11 @@ type (
 		Port string `env-required:"true" yaml:"port" env:"HTTP_PORT"`
 	}
 
+	// GRPC -.
+	GRPC struct {
+		Port string `env-required:"true" yaml:"port" env:"GRPC_PORT"`
+	}
+
 	// Log -.
 	Log struct {
 		Level string `env-required:"true" yaml:"log_level" env:"LOG_LEVEL"`
@@ -36,6 +47,37 @@ type (
 		PoolMax int    `env-required:"true" yaml:"pool_max" env:"MYSQL_POOL_MAX"`
 		URL     string `env-required:"true"                 env:"MYSQL_URL"`
 	}
+
+	// Search -.
+	Search struct {
+		Driver string `env-required:"true" yaml:"driver" env:"SEARCH_DRIVER"`
+	}
+
+	// Trash -.
+	Trash struct {
+		Retention     time.Duration `env-required:"true" yaml:"retention"      env:"TRASH_RETENTION"`
+		PurgeInterval time.Duration `env-required:"true" yaml:"purge_interval" env:"TRASH_PURGE_INTERVAL"`
+	}
+
+	// Idempotency -.
+	Idempotency struct {
+		Driver        string        `env-required:"true" yaml:"driver"         env:"IDEMPOTENCY_DRIVER"`
+		TTL           time.Duration `env-required:"true" yaml:"ttl"            env:"IDEMPOTENCY_TTL"`
+		PurgeInterval time.Duration `env-required:"true" yaml:"purge_interval" env:"IDEMPOTENCY_PURGE_INTERVAL"`
+	}
+
+	// Blob -.
+	Blob struct {
+		Driver       string   `env-required:"true" yaml:"driver"        env:"BLOB_DRIVER"`
+		Path         string   `                    yaml:"path"          env:"BLOB_PATH"`
+		MaxSize      int64    `env-required:"true" yaml:"max_size"      env:"BLOB_MAX_SIZE"`
+		AllowedTypes []string `env-required:"true" yaml:"allowed_types" env:"BLOB_ALLOWED_TYPES"`
+		S3Endpoint   string   `                    yaml:"s3_endpoint"   env:"BLOB_S3_ENDPOINT"`
+		S3Region     string   `                    yaml:"s3_region"     env:"BLOB_S3_REGION"`
+		S3Bucket     string   `                    yaml:"s3_bucket"     env:"BLOB_S3_BUCKET"`
+		S3AccessKey  string   `                                         env:"BLOB_S3_ACCESS_KEY"`
+		S3SecretKey  string   `                                         env:"BLOB_S3_SECRET_KEY"`
+	}
 )
 
 // NewConfig returns app config.
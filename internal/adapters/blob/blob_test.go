@@ -0,0 +1,161 @@
+package blob_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"testcode/test3/internal/adapters/blob/local"
+	"testcode/test3/internal/adapters/blob/s3"
+	"testcode/test3/internal/domain/usecase"
+)
+
+// fakeS3 serves path-style object requests of one bucket from memory. It
+// checks the requests are signed but not the signature itself.
+type fakeS3 struct {
+	t       *testing.T
+	bucket  string
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (r *fakeS3) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	auth := req.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=key/") || !strings.Contains(auth, "/test-region/s3/aws4_request") ||
+		req.Header.Get("X-Amz-Date") == "" || req.Header.Get("X-Amz-Content-Sha256") == "" {
+		r.t.Errorf("%s %s: unsigned request, Authorization=%q", req.Method, req.URL.Path, auth)
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+		return
+	}
+	prefix := "/" + r.bucket + "/"
+	if !strings.HasPrefix(req.URL.Path, prefix) {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+	key := strings.TrimPrefix(req.URL.Path, prefix)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch req.Method {
+	case http.MethodPut:
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if int64(len(body)) != req.ContentLength {
+			r.t.Errorf("PUT %s: %d bytes, Content-Length %d", key, len(body), req.ContentLength)
+		}
+		r.objects[key] = body
+		r.types[key] = req.Header.Get("Content-Type")
+	case http.MethodGet:
+		body, ok := r.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", r.types[key])
+		// ServeContent answers Range with 206
+		http.ServeContent(w, req, key, time.Time{}, bytes.NewReader(body))
+	case http.MethodDelete:
+		delete(r.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "MethodNotAllowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func TestBlobStorage(t *testing.T) {
+	fake := &fakeS3{t: t, bucket: "attachments", objects: map[string][]byte{}, types: map[string]string{}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	s3Storage, err := s3.NewS3BlobStorage(srv.URL, "test-region", "attachments", "key", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	localStorage, err := local.NewLocalBlobStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, storage := range map[string]usecase.BlobStorage{"local": localStorage, "s3": s3Storage} {
+		t.Run(name, func(t *testing.T) {
+			testBlobStorage(t, storage)
+		})
+	}
+
+	if len(fake.objects) != 0 {
+		t.Errorf("s3: objects left %v", fake.objects)
+	}
+}
+
+func testBlobStorage(t *testing.T, storage usecase.BlobStorage) {
+	ctx := context.Background()
+	const key = "ab12cd34"
+	content := []byte("0123456789abcdef")
+
+	if err := storage.Put(ctx, key, bytes.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		offset int64
+		length int64
+		want   string
+	}{
+		{"whole", 0, -1, "0123456789abcdef"},
+		{"range", 4, 6, "456789"},
+		{"from offset", 10, -1, "abcdef"},
+		{"first byte", 0, 1, "0"},
+		{"last byte", 15, 1, "f"},
+	}
+	for _, tt := range tests {
+		body, err := storage.Get(ctx, key, tt.offset, tt.length)
+		if err != nil {
+			t.Fatalf("Get %s: %v", tt.name, err)
+		}
+		got, err := ioutil.ReadAll(body)
+		body.Close()
+		if err != nil {
+			t.Fatalf("Get %s: %v", tt.name, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("Get %s: %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// a second Put replaces the blob
+	if err := storage.Put(ctx, key, strings.NewReader("new"), 3, "text/plain"); err != nil {
+		t.Fatalf("Put again: %v", err)
+	}
+	body, err := storage.Get(ctx, key, 0, -1)
+	if err != nil {
+		t.Fatalf("Get replaced: %v", err)
+	}
+	got, _ := ioutil.ReadAll(body)
+	body.Close()
+	if string(got) != "new" {
+		t.Errorf("Get replaced: %q, want %q", got, "new")
+	}
+
+	if err := storage.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if body, err := storage.Get(ctx, key, 0, -1); err == nil {
+		body.Close()
+		t.Errorf("Get after Delete: no error")
+	}
+	// deleting a missing blob is not an error, purges may run twice
+	if err := storage.Delete(ctx, key); err != nil {
+		t.Errorf("Delete missing: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+type localBlobStorage struct {
+	root string
+}
+
+func NewLocalBlobStorage(root string) (*localBlobStorage, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("LocalBlobStorage - New - os.MkdirAll: %w", err)
+	}
+	return &localBlobStorage{root}, nil
+}
+
+// path spreads blobs over subdirectories by the key prefix
+func (r *localBlobStorage) path(key string) string {
+	if len(key) < 2 {
+		return filepath.Join(r.root, key)
+	}
+	return filepath.Join(r.root, key[:2], key)
+}
+
+func (r *localBlobStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	path := r.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("LocalBlobStorage - Put - os.MkdirAll: %w", err)
+	}
+
+	// write to temp file first so readers never see a partial blob
+	f, err := ioutil.TempFile(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("LocalBlobStorage - Put - ioutil.TempFile: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err = io.Copy(f, body); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("LocalBlobStorage - Put - io.Copy: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("LocalBlobStorage - Put - f.Close: %w", err)
+	}
+	if err = os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("LocalBlobStorage - Put - os.Rename: %w", err)
+	}
+	return nil
+}
+
+type limitedFile struct {
+	io.Reader
+	f *os.File
+}
+
+func (r *limitedFile) Close() error {
+	return r.f.Close()
+}
+
+func (r *localBlobStorage) Get(ctx context.Context, key string, offset int64, length int64) (io.ReadCloser, error) {
+	f, err := os.Open(r.path(key))
+	if err != nil {
+		return nil, fmt.Errorf("LocalBlobStorage - Get - os.Open: %w", err)
+	}
+	if _, err = f.Seek(offset, io.SeekStart); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("LocalBlobStorage - Get - f.Seek: %w", err)
+	}
+	if length < 0 {
+		return f, nil
+	}
+	return &limitedFile{io.LimitReader(f, length), f}, nil
+}
+
+func (r *localBlobStorage) Delete(ctx context.Context, key string) error {
+	err := os.Remove(r.path(key))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("LocalBlobStorage - Delete - os.Remove: %w", err)
+	}
+	return nil
+}
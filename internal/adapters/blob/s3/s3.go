@@ -0,0 +1,163 @@
+// Package s3 stores blobs in an S3 compatible object storage (AWS S3, MinIO,
+// etc.) using path-style requests signed with AWS Signature Version 4.
+package s3
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	_unsignedPayload = "UNSIGNED-PAYLOAD"
+	_timeFormat      = "20060102T150405Z"
+	_dateFormat      = "20060102"
+)
+
+type s3BlobStorage struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	client    *http.Client
+}
+
+func NewS3BlobStorage(endpoint, region, bucket, accessKey, secretKey string) (*s3BlobStorage, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("S3BlobStorage - New - url.Parse: %w", err)
+	}
+	return &s3BlobStorage{
+		endpoint:  u,
+		region:    region,
+		bucket:    bucket,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		client:    &http.Client{},
+	}, nil
+}
+
+func (r *s3BlobStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	req, err := r.newRequest(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return fmt.Errorf("S3BlobStorage - Put - r.newRequest: %w", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+	r.sign(req)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("S3BlobStorage - Put - r.client.Do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("S3BlobStorage - Put: %w", responseError(resp))
+	}
+	return nil
+}
+
+func (r *s3BlobStorage) Get(ctx context.Context, key string, offset int64, length int64) (io.ReadCloser, error) {
+	req, err := r.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, fmt.Errorf("S3BlobStorage - Get - r.newRequest: %w", err)
+	}
+	if length >= 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	} else if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	r.sign(req)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("S3BlobStorage - Get - r.client.Do: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("S3BlobStorage - Get: %w", responseError(resp))
+	}
+	return resp.Body, nil
+}
+
+func (r *s3BlobStorage) Delete(ctx context.Context, key string) error {
+	req, err := r.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return fmt.Errorf("S3BlobStorage - Delete - r.newRequest: %w", err)
+	}
+	r.sign(req)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("S3BlobStorage - Delete - r.client.Do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("S3BlobStorage - Delete: %w", responseError(resp))
+	}
+	return nil
+}
+
+func (r *s3BlobStorage) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	u := *r.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + r.bucket + "/" + key
+	return http.NewRequestWithContext(ctx, method, u.String(), body)
+}
+
+// sign adds AWS Signature Version 4 headers, the payload is left unsigned
+// so bodies can be streamed.
+func (r *s3BlobStorage) sign(req *http.Request) {
+	now := time.Now().UTC()
+	amzDate := now.Format(_timeFormat)
+	date := now.Format(_dateFormat)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", _unsignedPayload)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + _unsignedPayload + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		_unsignedPayload,
+	}, "\n")
+
+	scope := date + "/" + r.region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+r.secretKey), date)
+	key = hmacSHA256(key, r.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		r.accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func responseError(resp *http.Response) error {
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("unexpected status %s: %s", resp.Status, body)
+}
@@ -0,0 +1,74 @@
+// Package idempotency keeps idempotency records in memory, they are lost on
+// restart and not shared between instances.
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"testcode/test3/internal/domain/entity"
+)
+
+type idempotencyStorage struct {
+	mu sync.Mutex
+	m  map[string]entity.IdempotencyRecord
+}
+
+func NewIdempotencyStorage() *idempotencyStorage {
+	return &idempotencyStorage{m: make(map[string]entity.IdempotencyRecord)}
+}
+
+func recordKey(ctx context.Context, accountID uint, key string) string {
+	return fmt.Sprintf("%d/%d/%s", entity.TenantFromContext(ctx), accountID, key)
+}
+
+func (r *idempotencyStorage) Reserve(ctx context.Context, dto entity.IdempotencyRecord) (*entity.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := recordKey(ctx, dto.AccountId, dto.Key)
+	if v, ok := r.m[k]; ok && v.ExpiresAt.After(time.Now()) {
+		return &v, nil
+	}
+	r.m[k] = dto
+	return nil, nil
+}
+
+func (r *idempotencyStorage) Complete(ctx context.Context, dto entity.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	k := recordKey(ctx, dto.AccountId, dto.Key)
+	v, ok := r.m[k]
+	if !ok {
+		return nil
+	}
+	v.Completed = true
+	v.StatusCode = dto.StatusCode
+	v.ContentType = dto.ContentType
+	v.Body = dto.Body
+	r.m[k] = v
+	return nil
+}
+
+func (r *idempotencyStorage) Release(ctx context.Context, accountID uint, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.m, recordKey(ctx, accountID, key))
+	return nil
+}
+
+func (r *idempotencyStorage) Purge(_ context.Context, before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k, v := range r.m {
+		if v.ExpiresAt.Before(before) {
+			delete(r.m, k)
+		}
+	}
+	return nil
+}
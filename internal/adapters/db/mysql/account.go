@@ -3,6 +3,7 @@ package mysql
 import (
 	"context"
 	"fmt"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"testcode/test3/internal/domain/entity"
@@ -19,11 +20,32 @@ func NewAccountStorage(db *mysql.Mysql) *accountStorage {
 	}
 }
 
+// Create inserts the account and binds it to the tenant from context with
+// dto.AccountType as its org role. It should be called within a transaction.
 func (r *accountStorage) Create(ctx context.Context, dto entity.Account) error {
 	sql, args, err := r.db.Builder.
 		Insert("account").
 		Columns("name, password, account_type").
-		Values(dto.Name, dto.Password, dto.AccountType).
+		Values(dto.Name, dto.Password, entity.AccountTypeUser).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("AccountStorage - Create - r.Builder: %w", err)
+	}
+
+	res, err := r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("AccountStorage - Create - r.Exec: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("AccountStorage - Create - res.LastInsertId: %w", err)
+	}
+
+	sql, args, err = r.db.Builder.
+		Insert("org_account").
+		Columns("org_id, account_id, role").
+		Values(entity.TenantFromContext(ctx), id, dto.AccountType).
 		ToSql()
 	if err != nil {
 		return fmt.Errorf("AccountStorage - Create - r.Builder: %w", err)
@@ -37,11 +59,14 @@ func (r *accountStorage) Create(ctx context.Context, dto entity.Account) error {
 	return nil
 }
 
+// Get returns the account with its role in the tenant from context.
 func (r *accountStorage) Get(ctx context.Context, accountID uint) (*entity.Account, error) {
 	sql, args, err := r.db.Builder.
-		Select("id, name, password, account_type").
-		From("account").
-		Where(sq.Eq{"id": accountID}).
+		Select("a.id, o.org_id, a.name, a.password, o.role").
+		From("account a").
+		Join("org_account o ON o.account_id = a.id").
+		Where(sq.Eq{"a.id": accountID, "o.deleted_at": nil}).
+		Where(tenantEq(ctx, "o.org_id")).
 		ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("AccountStorage - Get - r.Builder: %w", err)
@@ -55,7 +80,7 @@ func (r *accountStorage) Get(ctx context.Context, accountID uint) (*entity.Accou
 
 	if rows.Next() {
 		e := entity.Account{}
-		err = rows.Scan(&e.Id, &e.Name, &e.Password, &e.AccountType)
+		err = rows.Scan(&e.Id, &e.TenantId, &e.Name, &e.Password, &e.AccountType)
 		if err != nil {
 			return nil, fmt.Errorf("AccountStorage - Get - rows.Scan: %w", err)
 		}
@@ -64,6 +89,43 @@ func (r *accountStorage) Get(ctx context.Context, accountID uint) (*entity.Accou
 	return nil, nil
 }
 
+// GetByIds returns the accounts of the tenant from context among the ids,
+// in no particular order, missing ones are left out.
+func (r *accountStorage) GetByIds(ctx context.Context, accountIDs []uint) ([]entity.Account, error) {
+	if len(accountIDs) == 0 {
+		return nil, nil
+	}
+	sql, args, err := r.db.Builder.
+		Select("a.id, o.org_id, a.name, o.role").
+		From("account a").
+		Join("org_account o ON o.account_id = a.id").
+		Where(sq.Eq{"a.id": accountIDs, "o.deleted_at": nil}).
+		Where(tenantEq(ctx, "o.org_id")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("AccountStorage - GetByIds - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("AccountStorage - GetByIds - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	entities := make([]entity.Account, 0, len(accountIDs))
+	for rows.Next() {
+		e := entity.Account{}
+		err = rows.Scan(&e.Id, &e.TenantId, &e.Name, &e.AccountType)
+		if err != nil {
+			return nil, fmt.Errorf("AccountStorage - GetByIds - rows.Scan: %w", err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
+
+// GetByName looks the account up across all tenants, it is used for login
+// before the tenant is known and returns the global account type.
 func (r *accountStorage) GetByName(ctx context.Context, name string) (*entity.Account, error) {
 	sql, args, err := r.db.Builder.
 		Select("id, name, password, account_type").
@@ -93,8 +155,11 @@ func (r *accountStorage) GetByName(ctx context.Context, name string) (*entity.Ac
 
 func (r *accountStorage) GetAll(ctx context.Context) ([]entity.Account, error) {
 	sql, args, err := r.db.Builder.
-		Select("id, name, password, account_type").
-		From("account").
+		Select("a.id, o.org_id, a.name, o.role").
+		From("account a").
+		Join("org_account o ON o.account_id = a.id").
+		Where(sq.Eq{"o.deleted_at": nil}).
+		Where(tenantEq(ctx, "o.org_id")).
 		ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("AccountStorage - GetAll - r.Builder: %w", err)
@@ -109,7 +174,7 @@ func (r *accountStorage) GetAll(ctx context.Context) ([]entity.Account, error) {
 	entities := make([]entity.Account, 0, _defaultEntityCap)
 	for rows.Next() {
 		e := entity.Account{}
-		err = rows.Scan(&e.Id, &e.Name, &e.Password, &e.AccountType)
+		err = rows.Scan(&e.Id, &e.TenantId, &e.Name, &e.AccountType)
 		if err != nil {
 			return nil, fmt.Errorf("AccountStorage - GetAll - rows.Scan: %w", err)
 		}
@@ -118,10 +183,14 @@ func (r *accountStorage) GetAll(ctx context.Context) ([]entity.Account, error) {
 	return entities, nil
 }
 
+// Delete moves the account of the tenant from context to the trash. The
+// account itself goes away with Purge once it belongs to no org.
 func (r *accountStorage) Delete(ctx context.Context, accountID uint) error {
 	sql, args, err := r.db.Builder.
-		Delete("account").
-		Where(sq.Eq{"id": accountID}).
+		Update("org_account").
+		Set("deleted_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"account_id": accountID, "deleted_at": nil}).
+		Where(tenantEq(ctx, "org_id")).
 		ToSql()
 	if err != nil {
 		return fmt.Errorf("AccountStorage - Delete - r.Builder: %w", err)
@@ -133,3 +202,103 @@ func (r *accountStorage) Delete(ctx context.Context, accountID uint) error {
 	}
 	return nil
 }
+
+// GetAllDeleted returns accounts in the trash of the tenant from context.
+func (r *accountStorage) GetAllDeleted(ctx context.Context) ([]entity.Account, error) {
+	sql, args, err := r.db.Builder.
+		Select("a.id, o.org_id, a.name, o.role, o.deleted_at").
+		From("account a").
+		Join("org_account o ON o.account_id = a.id").
+		Where(sq.NotEq{"o.deleted_at": nil}).
+		Where(tenantEq(ctx, "o.org_id")).
+		OrderBy("o.deleted_at DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("AccountStorage - GetAllDeleted - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("AccountStorage - GetAllDeleted - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	entities := make([]entity.Account, 0, _defaultEntityCap)
+	for rows.Next() {
+		e := entity.Account{}
+		err = rows.Scan(&e.Id, &e.TenantId, &e.Name, &e.AccountType, &e.DeletedAt)
+		if err != nil {
+			return nil, fmt.Errorf("AccountStorage - GetAllDeleted - rows.Scan: %w", err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
+
+// Undelete takes the account of the tenant from context out of the trash and
+// reports whether it was there.
+func (r *accountStorage) Undelete(ctx context.Context, accountID uint) (bool, error) {
+	sql, args, err := r.db.Builder.
+		Update("org_account").
+		Set("deleted_at", nil).
+		Where(sq.Eq{"account_id": accountID}).
+		Where(sq.NotEq{"deleted_at": nil}).
+		Where(tenantEq(ctx, "org_id")).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("AccountStorage - Undelete - r.Builder: %w", err)
+	}
+
+	res, err := r.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("AccountStorage - Undelete - r.Exec: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("AccountStorage - Undelete - res.RowsAffected: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Purge drops org memberships deleted before the time in every tenant, then
+// accounts left without org unless something they authored still refers to
+// them. It should be called within a transaction.
+func (r *accountStorage) Purge(ctx context.Context, before time.Time) (int64, error) {
+	sql, args, err := r.db.Builder.
+		Delete("org_account").
+		Where(sq.Lt{"deleted_at": before}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("AccountStorage - Purge - r.Builder: %w", err)
+	}
+
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("AccountStorage - Purge - r.Exec: %w", err)
+	}
+
+	sql, args, err = r.db.Builder.
+		Delete("account").
+		Where(sq.Expr("NOT EXISTS (SELECT 1 FROM org_account o WHERE o.account_id = account.id)")).
+		Where(sq.Expr("NOT EXISTS (SELECT 1 FROM todo t WHERE t.owner_id = account.id)")).
+		Where(sq.Expr("NOT EXISTS (SELECT 1 FROM project p WHERE p.owner_id = account.id)")).
+		Where(sq.Expr("NOT EXISTS (SELECT 1 FROM comment c WHERE c.author_id = account.id)")).
+		Where(sq.Expr("NOT EXISTS (SELECT 1 FROM attachment a WHERE a.uploader_id = account.id)")).
+		Where(sq.Expr("NOT EXISTS (SELECT 1 FROM todo_revision v WHERE v.actor_id = account.id)")).
+		Where(sq.NotEq{"account_type": entity.AccountTypeSuperAdmin}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("AccountStorage - Purge - r.Builder: %w", err)
+	}
+
+	res, err := r.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("AccountStorage - Purge - r.Exec: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("AccountStorage - Purge - res.RowsAffected: %w", err)
+	}
+	return n, nil
+}
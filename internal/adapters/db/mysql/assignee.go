@@ -0,0 +1,85 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/mysql"
+)
+
+type assigneeStorage struct {
+	baseStorage
+}
+
+func NewAssigneeStorage(db *mysql.Mysql) *assigneeStorage {
+	return &assigneeStorage{
+		baseStorage{db},
+	}
+}
+
+func (r *assigneeStorage) Add(ctx context.Context, todoID uint, accountID uint) error {
+	sql, args, err := r.db.Builder.
+		Insert("todo_assignee").
+		Options("IGNORE").
+		Columns("tenant_id, todo_id, account_id").
+		Values(entity.TenantFromContext(ctx), todoID, accountID).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("AssigneeStorage - Add - r.Builder: %w", err)
+	}
+
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("AssigneeStorage - Add - r.Exec: %w", err)
+	}
+
+	return nil
+}
+
+func (r *assigneeStorage) Remove(ctx context.Context, todoID uint, accountID uint) error {
+	sql, args, err := r.db.Builder.
+		Delete("todo_assignee").
+		Where(sq.Eq{"todo_id": todoID, "account_id": accountID}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("AssigneeStorage - Remove - r.Builder: %w", err)
+	}
+
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("AssigneeStorage - Remove - r.Exec: %w", err)
+	}
+	return nil
+}
+
+func (r *assigneeStorage) GetByTodo(ctx context.Context, todoID uint) ([]uint, error) {
+	sql, args, err := r.db.Builder.
+		Select("account_id").
+		From("todo_assignee").
+		Where(sq.Eq{"todo_id": todoID}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("AssigneeStorage - GetByTodo - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("AssigneeStorage - GetByTodo - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]uint, 0, _defaultEntityCap)
+	for rows.Next() {
+		var id uint
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("AssigneeStorage - GetByTodo - rows.Scan: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,158 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/mysql"
+)
+
+type attachmentStorage struct {
+	baseStorage
+}
+
+func NewAttachmentStorage(db *mysql.Mysql) *attachmentStorage {
+	return &attachmentStorage{
+		baseStorage{db},
+	}
+}
+
+func (r *attachmentStorage) Create(ctx context.Context, dto entity.Attachment) (uint, error) {
+	sql, args, err := r.db.Builder.
+		Insert("attachment").
+		Columns("tenant_id, todo_id, uploader_id, name, content_type, size, blob_key").
+		Values(entity.TenantFromContext(ctx), dto.TodoId, dto.UploaderId, dto.Name, dto.ContentType, dto.Size, dto.BlobKey).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("AttachmentStorage - Create - r.Builder: %w", err)
+	}
+
+	res, err := r.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("AttachmentStorage - Create - r.Exec: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("AttachmentStorage - Create - res.LastInsertId: %w", err)
+	}
+
+	return uint(id), nil
+}
+
+func (r *attachmentStorage) Get(ctx context.Context, attachmentID uint) (*entity.Attachment, error) {
+	sql, args, err := r.db.Builder.
+		Select("id, todo_id, uploader_id, name, content_type, size, blob_key, created_at").
+		From("attachment").
+		Where(sq.Eq{"id": attachmentID}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("AttachmentStorage - Get - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("AttachmentStorage - Get - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		e := entity.Attachment{}
+		err = rows.Scan(&e.Id, &e.TodoId, &e.UploaderId, &e.Name, &e.ContentType, &e.Size, &e.BlobKey, &e.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("AttachmentStorage - Get - rows.Scan: %w", err)
+		}
+		return &e, nil
+	}
+	return nil, nil
+}
+
+func (r *attachmentStorage) GetAllByTodo(ctx context.Context, todoID uint) ([]entity.Attachment, error) {
+	return r.getAll(ctx, "GetAllByTodo", sq.Eq{"a.todo_id": todoID})
+}
+
+func (r *attachmentStorage) GetAllByProject(ctx context.Context, projectID uint) ([]entity.Attachment, error) {
+	return r.getAll(ctx, "GetAllByProject", sq.Eq{"t.project_id": projectID})
+}
+
+func (r *attachmentStorage) getAll(ctx context.Context, method string, pred sq.Eq) ([]entity.Attachment, error) {
+	sql, args, err := r.db.Builder.
+		Select("a.id, a.todo_id, a.uploader_id, a.name, a.content_type, a.size, a.blob_key, a.created_at").
+		From("attachment a").
+		Join("todo t ON t.id = a.todo_id").
+		Where(pred).
+		Where(tenantEq(ctx, "a.tenant_id")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("AttachmentStorage - %s - r.Builder: %w", method, err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("AttachmentStorage - %s - r.Query: %w", method, err)
+	}
+	defer rows.Close()
+
+	entities := make([]entity.Attachment, 0, _defaultEntityCap)
+	for rows.Next() {
+		e := entity.Attachment{}
+		err = rows.Scan(&e.Id, &e.TodoId, &e.UploaderId, &e.Name, &e.ContentType, &e.Size, &e.BlobKey, &e.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("AttachmentStorage - %s - rows.Scan: %w", method, err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
+
+func (r *attachmentStorage) Delete(ctx context.Context, attachmentID uint) error {
+	sql, args, err := r.db.Builder.
+		Delete("attachment").
+		Where(sq.Eq{"id": attachmentID}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("AttachmentStorage - Delete - r.Builder: %w", err)
+	}
+
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("AttachmentStorage - Delete - r.Exec: %w", err)
+	}
+	return nil
+}
+
+// GetAllPurgeable returns attachments of todos deleted before the time in
+// every tenant, their blobs go away once Purge drops the todos.
+func (r *attachmentStorage) GetAllPurgeable(ctx context.Context, before time.Time) ([]entity.Attachment, error) {
+	sql, args, err := r.db.Builder.
+		Select("a.id, a.todo_id, a.uploader_id, a.name, a.content_type, a.size, a.blob_key, a.created_at").
+		From("attachment a").
+		Join("todo t ON t.id = a.todo_id").
+		Where(sq.Lt{"t.deleted_at": before}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("AttachmentStorage - GetAllPurgeable - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("AttachmentStorage - GetAllPurgeable - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	entities := make([]entity.Attachment, 0, _defaultEntityCap)
+	for rows.Next() {
+		e := entity.Attachment{}
+		err = rows.Scan(&e.Id, &e.TodoId, &e.UploaderId, &e.Name, &e.ContentType, &e.Size, &e.BlobKey, &e.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("AttachmentStorage - GetAllPurgeable - rows.Scan: %w", err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
@@ -0,0 +1,149 @@
+package mysql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/mysql"
+)
+
+// auditStorage is append-only, there is neither update nor delete.
+type auditStorage struct {
+	baseStorage
+}
+
+func NewAuditStorage(db *mysql.Mysql) *auditStorage {
+	return &auditStorage{
+		baseStorage{db},
+	}
+}
+
+func (r *auditStorage) Append(ctx context.Context, dto entity.AuditEntry) error {
+	summary, err := json.Marshal(dto.Summary)
+	if err != nil {
+		return fmt.Errorf("AuditStorage - Append - json.Marshal: %w", err)
+	}
+
+	sql, args, err := r.db.Builder.
+		Insert("audit_log").
+		Columns("tenant_id, actor_id, action, target_type, target_id, request_id, client_ip, summary").
+		Values(entity.TenantFromContext(ctx), nullableID(dto.ActorId), dto.Action, dto.TargetType, nullableID(dto.TargetId),
+			dto.RequestId, dto.ClientIp, summary).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("AuditStorage - Append - r.Builder: %w", err)
+	}
+
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("AuditStorage - Append - r.Exec: %w", err)
+	}
+	return nil
+}
+
+func auditWhere(ctx context.Context, f entity.AuditFilter) sq.And {
+	pred := sq.And{tenantEq(ctx, "tenant_id")}
+	if f.ActorId != 0 {
+		pred = append(pred, sq.Eq{"actor_id": f.ActorId})
+	}
+	if f.Action != "" {
+		pred = append(pred, sq.Eq{"action": f.Action})
+	}
+	if f.TargetType != "" {
+		pred = append(pred, sq.Eq{"target_type": f.TargetType})
+	}
+	if f.TargetId != 0 {
+		pred = append(pred, sq.Eq{"target_id": f.TargetId})
+	}
+	if !f.From.IsZero() {
+		pred = append(pred, sq.GtOrEq{"created_at": f.From})
+	}
+	if !f.To.IsZero() {
+		pred = append(pred, sq.Lt{"created_at": f.To})
+	}
+	return pred
+}
+
+func (r *auditStorage) Count(ctx context.Context, f entity.AuditFilter) (uint, error) {
+	sql, args, err := r.db.Builder.
+		Select("COUNT(*)").
+		From("audit_log").
+		Where(auditWhere(ctx, f)).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("AuditStorage - Count - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("AuditStorage - Count - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var total uint
+	if rows.Next() {
+		if err = rows.Scan(&total); err != nil {
+			return 0, fmt.Errorf("AuditStorage - Count - rows.Scan: %w", err)
+		}
+	}
+	return total, nil
+}
+
+// GetPage returns entries matching the filter, newest first.
+func (r *auditStorage) GetPage(ctx context.Context, f entity.AuditFilter, offset uint64, limit uint64) ([]entity.AuditEntry, error) {
+	entities := make([]entity.AuditEntry, 0, limit)
+	err := r.each(ctx, "GetPage", f, offset, limit, func(e entity.AuditEntry) error {
+		entities = append(entities, e)
+		return nil
+	})
+	return entities, err
+}
+
+// Each streams every entry matching the filter, newest first, stopping at
+// the first error fn returns.
+func (r *auditStorage) Each(ctx context.Context, f entity.AuditFilter, fn func(entity.AuditEntry) error) error {
+	return r.each(ctx, "Each", f, 0, 0, fn)
+}
+
+func (r *auditStorage) each(ctx context.Context, method string, f entity.AuditFilter, offset uint64, limit uint64,
+	fn func(entity.AuditEntry) error) error {
+	builder := r.db.Builder.
+		Select("id, COALESCE(actor_id, 0), action, target_type, COALESCE(target_id, 0), request_id, client_ip, summary, created_at").
+		From("audit_log").
+		Where(auditWhere(ctx, f)).
+		OrderBy("id DESC")
+	if limit > 0 {
+		builder = builder.Offset(offset).Limit(limit)
+	}
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("AuditStorage - %s - r.Builder: %w", method, err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("AuditStorage - %s - r.Query: %w", method, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			e       entity.AuditEntry
+			summary []byte
+		)
+		err = rows.Scan(&e.Id, &e.ActorId, &e.Action, &e.TargetType, &e.TargetId, &e.RequestId, &e.ClientIp, &summary, &e.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("AuditStorage - %s - rows.Scan: %w", method, err)
+		}
+		if err = json.Unmarshal(summary, &e.Summary); err != nil {
+			return fmt.Errorf("AuditStorage - %s - json.Unmarshal: %w", method, err)
+		}
+		if err = fn(e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
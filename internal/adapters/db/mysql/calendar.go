@@ -0,0 +1,84 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/mysql"
+)
+
+type calendarFeedStorage struct {
+	baseStorage
+}
+
+func NewCalendarFeedStorage(db *mysql.Mysql) *calendarFeedStorage {
+	return &calendarFeedStorage{
+		baseStorage{db},
+	}
+}
+
+// Set stores the token hash of the account, replacing the previous one.
+func (r *calendarFeedStorage) Set(ctx context.Context, accountID uint, tokenHash string) error {
+	sql, args, err := r.db.Builder.
+		Insert("calendar_feed").
+		Columns("tenant_id, account_id, token_hash").
+		Values(entity.TenantFromContext(ctx), accountID, tokenHash).
+		Suffix("ON DUPLICATE KEY UPDATE token_hash = VALUES(token_hash), created_at = CURRENT_TIMESTAMP").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("CalendarFeedStorage - Set - r.Builder: %w", err)
+	}
+
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("CalendarFeedStorage - Set - r.Exec: %w", err)
+	}
+	return nil
+}
+
+func (r *calendarFeedStorage) Delete(ctx context.Context, accountID uint) error {
+	sql, args, err := r.db.Builder.
+		Delete("calendar_feed").
+		Where(sq.Eq{"account_id": accountID}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("CalendarFeedStorage - Delete - r.Builder: %w", err)
+	}
+
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("CalendarFeedStorage - Delete - r.Exec: %w", err)
+	}
+	return nil
+}
+
+// GetByToken looks the feed up in every tenant, the token is all the caller
+// has.
+func (r *calendarFeedStorage) GetByToken(ctx context.Context, tokenHash string) (*entity.CalendarFeed, error) {
+	sql, args, err := r.db.Builder.
+		Select("tenant_id, account_id").
+		From("calendar_feed").
+		Where(sq.Eq{"token_hash": tokenHash}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("CalendarFeedStorage - GetByToken - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("CalendarFeedStorage - GetByToken - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		e := entity.CalendarFeed{}
+		if err = rows.Scan(&e.TenantId, &e.AccountId); err != nil {
+			return nil, fmt.Errorf("CalendarFeedStorage - GetByToken - rows.Scan: %w", err)
+		}
+		return &e, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,169 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/mysql"
+)
+
+type commentStorage struct {
+	baseStorage
+}
+
+func NewCommentStorage(db *mysql.Mysql) *commentStorage {
+	return &commentStorage{
+		baseStorage{db},
+	}
+}
+
+func (r *commentStorage) Create(ctx context.Context, dto entity.Comment) (uint, error) {
+	sql, args, err := r.db.Builder.
+		Insert("comment").
+		Columns("tenant_id, todo_id, author_id, body").
+		Values(entity.TenantFromContext(ctx), dto.TodoId, dto.AuthorId, dto.Body).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("CommentStorage - Create - r.Builder: %w", err)
+	}
+
+	res, err := r.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("CommentStorage - Create - r.Exec: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("CommentStorage - Create - res.LastInsertId: %w", err)
+	}
+
+	return uint(id), nil
+}
+
+func (r *commentStorage) Get(ctx context.Context, commentID uint) (*entity.Comment, error) {
+	sql, args, err := r.db.Builder.
+		Select("id, todo_id, author_id, body, created_at, edited_at").
+		From("comment").
+		Where(sq.Eq{"id": commentID, "deleted_at": nil}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("CommentStorage - Get - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("CommentStorage - Get - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		e := entity.Comment{}
+		err = rows.Scan(&e.Id, &e.TodoId, &e.AuthorId, &e.Body, &e.CreatedAt, &e.EditedAt)
+		if err != nil {
+			return nil, fmt.Errorf("CommentStorage - Get - rows.Scan: %w", err)
+		}
+		return &e, nil
+	}
+	return nil, nil
+}
+
+// GetAllByTodo returns a page of not deleted comments, oldest first.
+func (r *commentStorage) GetAllByTodo(ctx context.Context, todoID uint, offset uint64, limit uint64) ([]entity.Comment, error) {
+	sql, args, err := r.db.Builder.
+		Select("id, todo_id, author_id, body, created_at, edited_at").
+		From("comment").
+		Where(sq.Eq{"todo_id": todoID, "deleted_at": nil}).
+		Where(tenantEq(ctx, "tenant_id")).
+		OrderBy("created_at, id").
+		Offset(offset).
+		Limit(limit).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("CommentStorage - GetAllByTodo - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("CommentStorage - GetAllByTodo - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	entities := make([]entity.Comment, 0, limit)
+	for rows.Next() {
+		e := entity.Comment{}
+		err = rows.Scan(&e.Id, &e.TodoId, &e.AuthorId, &e.Body, &e.CreatedAt, &e.EditedAt)
+		if err != nil {
+			return nil, fmt.Errorf("CommentStorage - GetAllByTodo - rows.Scan: %w", err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
+
+func (r *commentStorage) CountByTodo(ctx context.Context, todoID uint) (uint, error) {
+	sql, args, err := r.db.Builder.
+		Select("COUNT(*)").
+		From("comment").
+		Where(sq.Eq{"todo_id": todoID, "deleted_at": nil}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("CommentStorage - CountByTodo - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("CommentStorage - CountByTodo - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var count uint
+	if rows.Next() {
+		err = rows.Scan(&count)
+		if err != nil {
+			return 0, fmt.Errorf("CommentStorage - CountByTodo - rows.Scan: %w", err)
+		}
+	}
+	return count, nil
+}
+
+func (r *commentStorage) Update(ctx context.Context, commentID uint, body string) error {
+	sql, args, err := r.db.Builder.
+		Update("comment").
+		Set("body", body).
+		Set("edited_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": commentID, "deleted_at": nil}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("CommentStorage - Update - r.Builder: %w", err)
+	}
+
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("CommentStorage - Update - r.Exec: %w", err)
+	}
+	return nil
+}
+
+// Delete marks the comment as deleted, the row is kept.
+func (r *commentStorage) Delete(ctx context.Context, commentID uint) error {
+	sql, args, err := r.db.Builder.
+		Update("comment").
+		Set("deleted_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": commentID, "deleted_at": nil}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("CommentStorage - Delete - r.Builder: %w", err)
+	}
+
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("CommentStorage - Delete - r.Exec: %w", err)
+	}
+	return nil
+}
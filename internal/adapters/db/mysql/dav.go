@@ -0,0 +1,93 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/mysql"
+)
+
+type davResourceStorage struct {
+	baseStorage
+}
+
+func NewDavResourceStorage(db *mysql.Mysql) *davResourceStorage {
+	return &davResourceStorage{
+		baseStorage{db},
+	}
+}
+
+// Set maps the resource name to the todo, false when the name is taken. A
+// name is never moved to another todo, so one client cannot take over the
+// resource of another.
+func (r *davResourceStorage) Set(ctx context.Context, dto entity.DavResource) (bool, error) {
+	sql, args, err := r.db.Builder.
+		Insert("dav_resource").
+		Options("IGNORE").
+		Columns("tenant_id, name, todo_id, uid").
+		Values(entity.TenantFromContext(ctx), dto.Name, dto.TodoId, dto.Uid).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("DavResourceStorage - Set - r.Builder: %w", err)
+	}
+
+	res, err := r.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("DavResourceStorage - Set - r.Exec: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("DavResourceStorage - Set - res.RowsAffected: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *davResourceStorage) GetByName(ctx context.Context, name string) (*entity.DavResource, error) {
+	ret, err := r.getAll(ctx, "GetByName", sq.Eq{"name": name})
+	if err != nil || len(ret) == 0 {
+		return nil, err
+	}
+	return &ret[0], nil
+}
+
+func (r *davResourceStorage) GetByTodo(ctx context.Context, todoID uint) (*entity.DavResource, error) {
+	ret, err := r.getAll(ctx, "GetByTodo", sq.Eq{"todo_id": todoID})
+	if err != nil || len(ret) == 0 {
+		return nil, err
+	}
+	return &ret[0], nil
+}
+
+func (r *davResourceStorage) GetAll(ctx context.Context) ([]entity.DavResource, error) {
+	return r.getAll(ctx, "GetAll", sq.Eq{})
+}
+
+func (r *davResourceStorage) getAll(ctx context.Context, method string, pred sq.Eq) ([]entity.DavResource, error) {
+	sql, args, err := r.db.Builder.
+		Select("todo_id, name, uid").
+		From("dav_resource").
+		Where(pred).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("DavResourceStorage - %s - r.Builder: %w", method, err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("DavResourceStorage - %s - r.Query: %w", method, err)
+	}
+	defer rows.Close()
+
+	entities := make([]entity.DavResource, 0, _defaultEntityCap)
+	for rows.Next() {
+		e := entity.DavResource{}
+		if err = rows.Scan(&e.TodoId, &e.Name, &e.Uid); err != nil {
+			return nil, fmt.Errorf("DavResourceStorage - %s - rows.Scan: %w", method, err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
@@ -0,0 +1,28 @@
+package mysql
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"testcode/test3/internal/domain/entity"
+)
+
+func TestDavResourceSetKeepsTakenNames(t *testing.T) {
+	rec, db := newRecorder()
+	// the recorder affects no rows, as when the name is taken
+	ok, err := NewDavResourceStorage(db).Set(entity.WithTenant(context.Background(), 1),
+		entity.DavResource{Name: "a.ics", TodoId: 3, Uid: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Errorf("Set: true, want false when no row is inserted")
+	}
+
+	queries := rec.take()
+	if len(queries) != 1 || !strings.HasPrefix(queries[0].sql, "INSERT IGNORE INTO dav_resource ") ||
+		strings.Contains(queries[0].sql, "UPDATE") {
+		t.Errorf("queries %v, want one insert leaving existing rows alone", queries)
+	}
+}
@@ -0,0 +1,167 @@
+package mysql
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/filter"
+)
+
+// _filterMe and _filterNone are the values of account and project fields
+// meaning the viewer and no value at all.
+const (
+	_filterMe   = "me"
+	_filterNone = "none"
+)
+
+var _filterStatus = map[string]entity.TodoStatus{
+	"open": entity.TodoStatusDefault,
+	"done": entity.TodoStatusDone,
+}
+
+// todoFilter compiles the filter AST into conditions on todo aliased as t and
+// its project as p.
+func todoFilter(n filter.Node, viewer entity.Account) (sq.Sqlizer, error) {
+	switch n := n.(type) {
+	case filter.And:
+		ret := make(sq.And, 0, len(n.Nodes))
+		for _, sub := range n.Nodes {
+			cond, err := todoFilter(sub, viewer)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, cond)
+		}
+		return ret, nil
+	case filter.Or:
+		ret := make(sq.Or, 0, len(n.Nodes))
+		for _, sub := range n.Nodes {
+			cond, err := todoFilter(sub, viewer)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, cond)
+		}
+		return ret, nil
+	case filter.Not:
+		cond, err := todoFilter(n.Node, viewer)
+		if err != nil {
+			return nil, err
+		}
+		return not(cond)
+	case filter.Text:
+		pattern := "%" + escapeLike(n.Value) + "%"
+		return sq.Or{sq.Like{"t.name": pattern}, sq.Like{"t.`desc`": pattern}}, nil
+	case filter.Cmp:
+		cond, err := todoCmp(n, viewer)
+		if err != nil || n.Op != filter.OpNe {
+			return cond, err
+		}
+		return not(cond)
+	}
+	return nil, fmt.Errorf("unexpected filter node %T", n)
+}
+
+// todoCmp compiles the comparison, != is compiled as : and negated by caller.
+func todoCmp(n filter.Cmp, viewer entity.Account) (sq.Sqlizer, error) {
+	switch n.Field {
+	case "id":
+		id, err := strconv.ParseUint(n.Value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("filter id: %w", err)
+		}
+		switch n.Op {
+		case filter.OpLt:
+			return sq.Lt{"t.id": id}, nil
+		case filter.OpLte:
+			return sq.LtOrEq{"t.id": id}, nil
+		case filter.OpGt:
+			return sq.Gt{"t.id": id}, nil
+		case filter.OpGte:
+			return sq.GtOrEq{"t.id": id}, nil
+		}
+		return sq.Eq{"t.id": id}, nil
+	case "status":
+		status, ok := _filterStatus[n.Value]
+		if !ok {
+			return nil, fmt.Errorf("filter status: unknown value %q", n.Value)
+		}
+		return sq.Eq{"t.status": status}, nil
+	case "owner":
+		if n.Value == _filterMe {
+			return sq.Eq{"t.owner_id": viewer.Id}, nil
+		}
+		return sq.Expr("t.owner_id IN (SELECT id FROM account WHERE name = ?)", n.Value), nil
+	case "assignee":
+		switch n.Value {
+		case _filterNone:
+			return sq.Expr("NOT EXISTS (SELECT 1 FROM todo_assignee a WHERE a.tenant_id = t.tenant_id AND a.todo_id = t.id)"), nil
+		case _filterMe:
+			return sq.Expr("EXISTS (SELECT 1 FROM todo_assignee a WHERE a.tenant_id = t.tenant_id AND a.todo_id = t.id AND a.account_id = ?)",
+				viewer.Id), nil
+		}
+		return sq.Expr("EXISTS (SELECT 1 FROM todo_assignee a JOIN account ac ON ac.id = a.account_id "+
+			"WHERE a.tenant_id = t.tenant_id AND a.todo_id = t.id AND ac.name = ?)", n.Value), nil
+	case "project":
+		if n.Value == _filterNone {
+			return sq.Eq{"t.project_id": nil}, nil
+		}
+		return sq.Eq{"p.name": n.Value}, nil
+	case "label":
+		if n.Value == _filterNone {
+			return sq.Eq{"t.labels": ""}, nil
+		}
+		return sq.Expr("FIND_IN_SET(?, t.labels) > 0", n.Value), nil
+	case "due":
+		return dueCmp(n)
+	}
+	return nil, fmt.Errorf("filter: unsupported field %q", n.Field)
+}
+
+// dueCmp compares the due time to the whole day of the value, due:none
+// matches todos without due date.
+func dueCmp(n filter.Cmp) (sq.Sqlizer, error) {
+	if n.Value == filter.None {
+		return sq.Eq{"t.due": nil}, nil
+	}
+	day, err := time.Parse(filter.DateLayout, n.Value)
+	if err != nil {
+		return nil, fmt.Errorf("filter due: %w", err)
+	}
+	next := day.AddDate(0, 0, 1)
+	switch n.Op {
+	case filter.OpLt:
+		return sq.Lt{"t.due": day}, nil
+	case filter.OpLte:
+		return sq.Lt{"t.due": next}, nil
+	case filter.OpGt:
+		return sq.GtOrEq{"t.due": next}, nil
+	case filter.OpGte:
+		return sq.GtOrEq{"t.due": day}, nil
+	}
+	return sq.And{sq.GtOrEq{"t.due": day}, sq.Lt{"t.due": next}}, nil
+}
+
+func not(cond sq.Sqlizer) (sq.Sqlizer, error) {
+	sql, args, err := cond.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	// NULL columns make the inner condition unknown, COALESCE turns it false
+	return sq.Expr("NOT COALESCE(("+sql+"), FALSE)", args...), nil
+}
+
+// escapeLike escapes LIKE wildcards, backslash is the MySQL default escape.
+func escapeLike(s string) string {
+	ret := make([]rune, 0, len(s))
+	for _, r := range s {
+		if r == '%' || r == '_' || r == '\\' {
+			ret = append(ret, '\\')
+		}
+		ret = append(ret, r)
+	}
+	return string(ret)
+}
@@ -0,0 +1,120 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/mysql"
+)
+
+type savedFilterStorage struct {
+	baseStorage
+}
+
+func NewSavedFilterStorage(db *mysql.Mysql) *savedFilterStorage {
+	return &savedFilterStorage{
+		baseStorage{db},
+	}
+}
+
+// Create saves the filter, saving under an existing name replaces its query.
+func (r *savedFilterStorage) Create(ctx context.Context, dto entity.SavedFilter) (uint, error) {
+	sql, args, err := r.db.Builder.
+		Insert("saved_filter").
+		Columns("tenant_id, account_id, name, query").
+		Values(entity.TenantFromContext(ctx), dto.AccountId, dto.Name, dto.Query).
+		Suffix("ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id), query = VALUES(query)").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("SavedFilterStorage - Create - r.Builder: %w", err)
+	}
+
+	res, err := r.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("SavedFilterStorage - Create - r.Exec: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("SavedFilterStorage - Create - res.LastInsertId: %w", err)
+	}
+
+	return uint(id), nil
+}
+
+func (r *savedFilterStorage) Get(ctx context.Context, filterID uint) (*entity.SavedFilter, error) {
+	sql, args, err := r.db.Builder.
+		Select("id, account_id, name, query, created_at").
+		From("saved_filter").
+		Where(sq.Eq{"id": filterID}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("SavedFilterStorage - Get - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("SavedFilterStorage - Get - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		e := entity.SavedFilter{}
+		err = rows.Scan(&e.Id, &e.AccountId, &e.Name, &e.Query, &e.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("SavedFilterStorage - Get - rows.Scan: %w", err)
+		}
+		return &e, nil
+	}
+	return nil, nil
+}
+
+func (r *savedFilterStorage) GetAllByAccount(ctx context.Context, accountID uint) ([]entity.SavedFilter, error) {
+	sql, args, err := r.db.Builder.
+		Select("id, account_id, name, query, created_at").
+		From("saved_filter").
+		Where(sq.Eq{"account_id": accountID}).
+		Where(tenantEq(ctx, "tenant_id")).
+		OrderBy("name").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("SavedFilterStorage - GetAllByAccount - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("SavedFilterStorage - GetAllByAccount - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	entities := make([]entity.SavedFilter, 0, _defaultEntityCap)
+	for rows.Next() {
+		e := entity.SavedFilter{}
+		err = rows.Scan(&e.Id, &e.AccountId, &e.Name, &e.Query, &e.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("SavedFilterStorage - GetAllByAccount - rows.Scan: %w", err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
+
+func (r *savedFilterStorage) Delete(ctx context.Context, filterID uint) error {
+	sql, args, err := r.db.Builder.
+		Delete("saved_filter").
+		Where(sq.Eq{"id": filterID}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("SavedFilterStorage - Delete - r.Builder: %w", err)
+	}
+
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("SavedFilterStorage - Delete - r.Exec: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package mysql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+	"testcode/test3/pkg/filter"
+)
+
+func TestTodoFilter(t *testing.T) {
+	viewer := entity.Account{Id: 7}
+	day := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	next := day.AddDate(0, 0, 1)
+
+	tests := []struct {
+		query string
+		sql   string
+		args  []interface{}
+	}{
+		{
+			`status:open owner:alice label:urgent due<2026-11-01 "login bug"`,
+			"(t.status = ? AND t.owner_id IN (SELECT id FROM account WHERE name = ?) AND FIND_IN_SET(?, t.labels) > 0 AND " +
+				"t.due < ? AND (t.name LIKE ? OR t.`desc` LIKE ?))",
+			[]interface{}{entity.TodoStatusDefault, "alice", "urgent", day, "%login bug%", "%login bug%"},
+		},
+		{"due<=2026-11-01", "t.due < ?", []interface{}{next}},
+		{"due>2026-11-01", "t.due >= ?", []interface{}{next}},
+		{"due>=2026-11-01", "t.due >= ?", []interface{}{day}},
+		{"due:2026-11-01", "(t.due >= ? AND t.due < ?)", []interface{}{day, next}},
+		{"due:none", "t.due IS NULL", nil},
+		{"due!=none", "NOT COALESCE((t.due IS NULL), FALSE)", nil},
+		{"label:none", "t.labels = ?", []interface{}{""}},
+		{"-label:urgent", "NOT COALESCE((FIND_IN_SET(?, t.labels) > 0), FALSE)", []interface{}{"urgent"}},
+	}
+	for _, tt := range tests {
+		f, err := filter.Parse(tt.query, usecase.TodoFilterFields)
+		if err != nil {
+			t.Errorf("%s: %v", tt.query, err)
+			continue
+		}
+		cond, err := todoFilter(f, viewer)
+		if err != nil {
+			t.Errorf("%s: %v", tt.query, err)
+			continue
+		}
+		sql, args, err := cond.ToSql()
+		if err != nil {
+			t.Errorf("%s: %v", tt.query, err)
+			continue
+		}
+		if sql != tt.sql {
+			t.Errorf("%s: sql %s, want %s", tt.query, sql, tt.sql)
+		}
+		if len(args) != 0 || len(tt.args) != 0 {
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("%s: args %v, want %v", tt.query, args, tt.args)
+			}
+		}
+	}
+
+	for _, query := range []string{"due<none", "due:tomorrow", "due>2026-13-01"} {
+		if _, err := filter.Parse(query, usecase.TodoFilterFields); err == nil || !strings.Contains(err.Error(), "filter:") {
+			t.Errorf("%s: error %v, want a filter error", query, err)
+		}
+	}
+}
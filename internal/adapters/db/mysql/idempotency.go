@@ -0,0 +1,144 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/mysql"
+)
+
+type idempotencyStorage struct {
+	baseStorage
+}
+
+func NewIdempotencyStorage(db *mysql.Mysql) *idempotencyStorage {
+	return &idempotencyStorage{
+		baseStorage{db},
+	}
+}
+
+// Reserve stores the record unless the account has an unexpired one for the
+// key, which is returned instead.
+func (r *idempotencyStorage) Reserve(ctx context.Context, dto entity.IdempotencyRecord) (*entity.IdempotencyRecord, error) {
+	pred := sq.Eq{"account_id": dto.AccountId, "idem_key": dto.Key}
+
+	sql, args, err := r.db.Builder.
+		Delete("idempotency_key").
+		Where(pred).
+		Where(tenantEq(ctx, "tenant_id")).
+		Where(sq.Lt{"expires_at": time.Now()}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("IdempotencyStorage - Reserve - r.Builder: %w", err)
+	}
+	if _, err = r.Exec(ctx, sql, args...); err != nil {
+		return nil, fmt.Errorf("IdempotencyStorage - Reserve - r.Exec: %w", err)
+	}
+
+	sql, args, err = r.db.Builder.
+		Insert("idempotency_key").
+		Options("IGNORE").
+		Columns("tenant_id, account_id, idem_key, request_hash, expires_at").
+		Values(entity.TenantFromContext(ctx), dto.AccountId, dto.Key, dto.RequestHash, dto.ExpiresAt).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("IdempotencyStorage - Reserve - r.Builder: %w", err)
+	}
+	res, err := r.Exec(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("IdempotencyStorage - Reserve - r.Exec: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("IdempotencyStorage - Reserve - res.RowsAffected: %w", err)
+	}
+	if n > 0 {
+		return nil, nil
+	}
+
+	sql, args, err = r.db.Builder.
+		Select("account_id, idem_key, request_hash, completed, status_code, content_type, body, expires_at").
+		From("idempotency_key").
+		Where(pred).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("IdempotencyStorage - Reserve - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("IdempotencyStorage - Reserve - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		e := entity.IdempotencyRecord{}
+		err = rows.Scan(&e.AccountId, &e.Key, &e.RequestHash, &e.Completed, &e.StatusCode, &e.ContentType, &e.Body, &e.ExpiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("IdempotencyStorage - Reserve - rows.Scan: %w", err)
+		}
+		return &e, nil
+	}
+	return nil, fmt.Errorf("IdempotencyStorage - Reserve: record of key %q vanished", dto.Key)
+}
+
+// Complete stores the response of the reserved record.
+func (r *idempotencyStorage) Complete(ctx context.Context, dto entity.IdempotencyRecord) error {
+	sql, args, err := r.db.Builder.
+		Update("idempotency_key").
+		Set("completed", true).
+		Set("status_code", dto.StatusCode).
+		Set("content_type", dto.ContentType).
+		Set("body", dto.Body).
+		Where(sq.Eq{"account_id": dto.AccountId, "idem_key": dto.Key}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("IdempotencyStorage - Complete - r.Builder: %w", err)
+	}
+
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("IdempotencyStorage - Complete - r.Exec: %w", err)
+	}
+	return nil
+}
+
+// Release drops the reservation so the key may be used again.
+func (r *idempotencyStorage) Release(ctx context.Context, accountID uint, key string) error {
+	sql, args, err := r.db.Builder.
+		Delete("idempotency_key").
+		Where(sq.Eq{"account_id": accountID, "idem_key": key}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("IdempotencyStorage - Release - r.Builder: %w", err)
+	}
+
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("IdempotencyStorage - Release - r.Exec: %w", err)
+	}
+	return nil
+}
+
+// Purge drops records expired before the time in every tenant.
+func (r *idempotencyStorage) Purge(ctx context.Context, before time.Time) error {
+	sql, args, err := r.db.Builder.
+		Delete("idempotency_key").
+		Where(sq.Lt{"expires_at": before}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("IdempotencyStorage - Purge - r.Builder: %w", err)
+	}
+
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("IdempotencyStorage - Purge - r.Exec: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/mysql"
+)
+
+type memberStorage struct {
+	baseStorage
+}
+
+func NewMemberStorage(db *mysql.Mysql) *memberStorage {
+	return &memberStorage{
+		baseStorage{db},
+	}
+}
+
+func (r *memberStorage) Create(ctx context.Context, dto entity.Member) (uint, error) {
+	sql, args, err := r.db.Builder.
+		Insert("member").
+		Columns("tenant_id, resource_type, resource_id, account_id, role, invited_by, accepted").
+		Values(entity.TenantFromContext(ctx), dto.ResourceType, dto.ResourceId, dto.AccountId, dto.Role, dto.InvitedBy, dto.Accepted).
+		Suffix("ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id), role = VALUES(role), invited_by = VALUES(invited_by)").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("MemberStorage - Create - r.Builder: %w", err)
+	}
+
+	res, err := r.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("MemberStorage - Create - r.Exec: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("MemberStorage - Create - res.LastInsertId: %w", err)
+	}
+
+	return uint(id), nil
+}
+
+func (r *memberStorage) Get(ctx context.Context, memberID uint) (*entity.Member, error) {
+	sql, args, err := r.db.Builder.
+		Select("id, resource_type, resource_id, account_id, role, invited_by, accepted").
+		From("member").
+		Where(sq.Eq{"id": memberID}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("MemberStorage - Get - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("MemberStorage - Get - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		e := entity.Member{}
+		err = rows.Scan(&e.Id, &e.ResourceType, &e.ResourceId, &e.AccountId, &e.Role, &e.InvitedBy, &e.Accepted)
+		if err != nil {
+			return nil, fmt.Errorf("MemberStorage - Get - rows.Scan: %w", err)
+		}
+		return &e, nil
+	}
+	return nil, nil
+}
+
+func (r *memberStorage) GetAllByResource(ctx context.Context, resourceType entity.MemberResource, resourceID uint) ([]entity.Member, error) {
+	return r.getAll(ctx, "GetAllByResource", sq.Eq{"resource_type": resourceType, "resource_id": resourceID})
+}
+
+func (r *memberStorage) GetAllByAccount(ctx context.Context, accountID uint) ([]entity.Member, error) {
+	return r.getAll(ctx, "GetAllByAccount", sq.Eq{"account_id": accountID})
+}
+
+func (r *memberStorage) getAll(ctx context.Context, method string, pred sq.Eq) ([]entity.Member, error) {
+	sql, args, err := r.db.Builder.
+		Select("id, resource_type, resource_id, account_id, role, invited_by, accepted").
+		From("member").
+		Where(pred).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("MemberStorage - %s - r.Builder: %w", method, err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("MemberStorage - %s - r.Query: %w", method, err)
+	}
+	defer rows.Close()
+
+	entities := make([]entity.Member, 0, _defaultEntityCap)
+	for rows.Next() {
+		e := entity.Member{}
+		err = rows.Scan(&e.Id, &e.ResourceType, &e.ResourceId, &e.AccountId, &e.Role, &e.InvitedBy, &e.Accepted)
+		if err != nil {
+			return nil, fmt.Errorf("MemberStorage - %s - rows.Scan: %w", method, err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
+
+// GetRole returns the highest accepted role the account has on the todo,
+// either directly or through the project the todo belongs to.
+func (r *memberStorage) GetRole(ctx context.Context, accountID uint, todoID uint, projectID uint) (entity.MemberRole, error) {
+	sql, args, err := r.db.Builder.
+		Select("COALESCE(MAX(role), 0)").
+		From("member").
+		Where(sq.Eq{"account_id": accountID, "accepted": true}).
+		Where(tenantEq(ctx, "tenant_id")).
+		Where(sq.Or{
+			sq.Eq{"resource_type": entity.MemberResourceTodo, "resource_id": todoID},
+			sq.Eq{"resource_type": entity.MemberResourceProject, "resource_id": projectID},
+		}).
+		ToSql()
+	if err != nil {
+		return entity.MemberRoleNone, fmt.Errorf("MemberStorage - GetRole - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return entity.MemberRoleNone, fmt.Errorf("MemberStorage - GetRole - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	role := entity.MemberRoleNone
+	if rows.Next() {
+		err = rows.Scan(&role)
+		if err != nil {
+			return entity.MemberRoleNone, fmt.Errorf("MemberStorage - GetRole - rows.Scan: %w", err)
+		}
+	}
+	return role, nil
+}
+
+func (r *memberStorage) Accept(ctx context.Context, memberID uint) error {
+	sql, args, err := r.db.Builder.
+		Update("member").
+		Set("accepted", true).
+		Where(sq.Eq{"id": memberID}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("MemberStorage - Accept - r.Builder: %w", err)
+	}
+
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("MemberStorage - Accept - r.Exec: %w", err)
+	}
+	return nil
+}
+
+func (r *memberStorage) Delete(ctx context.Context, memberID uint) error {
+	sql, args, err := r.db.Builder.
+		Delete("member").
+		Where(sq.Eq{"id": memberID}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("MemberStorage - Delete - r.Builder: %w", err)
+	}
+
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("MemberStorage - Delete - r.Exec: %w", err)
+	}
+	return nil
+}
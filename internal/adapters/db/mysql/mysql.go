@@ -5,7 +5,11 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	sq "github.com/Masterminds/squirrel"
+	"testcode/test3/internal/domain/entity"
 	"testcode/test3/pkg/logger"
 	"testcode/test3/pkg/mysql"
 )
@@ -30,19 +34,77 @@ func NewTransactor(log *logger.Logger, db *mysql.Mysql) *transactor {
 
 type txKey struct{}
 
+// txState is the transaction in context with the functions to run once it
+// commits.
+type txState struct {
+	tx          *sql.Tx
+	afterCommit []func()
+}
+
 // injectTx injects transaction to context
 func injectTx(ctx context.Context, tx *sql.Tx) context.Context {
-	return context.WithValue(ctx, txKey{}, tx)
+	return context.WithValue(ctx, txKey{}, &txState{tx: tx})
 }
 
 // extractTx extracts transaction from context
 func extractTx(ctx context.Context) *sql.Tx {
-	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
-		return tx
+	if s, ok := ctx.Value(txKey{}).(*txState); ok {
+		return s.tx
+	}
+	return nil
+}
+
+// tenantEq scopes query to the tenant from context. Context without tenant
+// yields zero, which matches no rows.
+func tenantEq(ctx context.Context, column string) sq.Eq {
+	return sq.Eq{column: entity.TenantFromContext(ctx)}
+}
+
+// nullableTime maps nil and the zero time to NULL
+func nullableTime(t *time.Time) interface{} {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return *t
+}
+
+// joinLabels encodes labels for the labels column, a comma separated list
+// FIND_IN_SET can search.
+func joinLabels(labels []string) string {
+	return strings.Join(labels, ",")
+}
+
+// labelsColumn scans the labels column, empty yields no labels.
+type labelsColumn struct {
+	dst *[]string
+}
+
+func (c labelsColumn) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return fmt.Errorf("labels: unexpected %T", src)
+	}
+	*c.dst = nil
+	if s != "" {
+		*c.dst = strings.Split(s, ",")
 	}
 	return nil
 }
 
+// nullableID maps zero id to NULL for optional foreign keys
+func nullableID(id uint) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
 func (r *baseStorage) Exec(ctx context.Context, sql string, args ...interface{}) (sql.Result, error) {
 	tx := extractTx(ctx)
 	if tx != nil {
@@ -61,8 +123,13 @@ func (r *baseStorage) Query(ctx context.Context, sql string, args ...interface{}
 
 // WithinTransaction runs function within transaction
 //
-// The transaction commits when function were finished without error
+// The transaction commits when function were finished without error. Nested
+// calls join the transaction from context, so the outermost call decides.
 func (r *transactor) WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) (err error) {
+	if extractTx(ctx) != nil {
+		return tFunc(ctx)
+	}
+
 	// begin transaction
 	tx, err := r.db.Pool.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
 	if err != nil {
@@ -79,7 +146,8 @@ func (r *transactor) WithinTransaction(ctx context.Context, tFunc func(ctx conte
 	}()
 
 	// run callback
-	err = tFunc(injectTx(ctx, tx))
+	txCtx := injectTx(ctx, tx)
+	err = tFunc(txCtx)
 	if err != nil {
 		// if error, rollback
 		_ = tx.Rollback()
@@ -88,5 +156,21 @@ func (r *transactor) WithinTransaction(ctx context.Context, tFunc func(ctx conte
 	}
 
 	// if no error, commit
-	return tx.Commit()
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	for _, fn := range txCtx.Value(txKey{}).(*txState).afterCommit {
+		fn()
+	}
+	return nil
+}
+
+// AfterCommit runs fn once the transaction from context commits, right away
+// when there is none. A rolled back transaction drops fn.
+func (r *transactor) AfterCommit(ctx context.Context, fn func()) {
+	if s, ok := ctx.Value(txKey{}).(*txState); ok {
+		s.afterCommit = append(s.afterCommit, fn)
+		return
+	}
+	fn()
 }
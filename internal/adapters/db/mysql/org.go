@@ -0,0 +1,191 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/mysql"
+)
+
+// orgStorage manages tenants themselves, so unlike other storages its
+// queries are not scoped by the tenant from context.
+type orgStorage struct {
+	baseStorage
+}
+
+func NewOrgStorage(db *mysql.Mysql) *orgStorage {
+	return &orgStorage{
+		baseStorage{db},
+	}
+}
+
+func (r *orgStorage) Create(ctx context.Context, dto entity.Org) (uint, error) {
+	sql, args, err := r.db.Builder.
+		Insert("org").
+		Columns("name").
+		Values(dto.Name).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("OrgStorage - Create - r.Builder: %w", err)
+	}
+
+	res, err := r.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("OrgStorage - Create - r.Exec: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("OrgStorage - Create - res.LastInsertId: %w", err)
+	}
+
+	return uint(id), nil
+}
+
+func (r *orgStorage) Get(ctx context.Context, orgID uint) (*entity.Org, error) {
+	sql, args, err := r.db.Builder.
+		Select("id, name").
+		From("org").
+		Where(sq.Eq{"id": orgID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("OrgStorage - Get - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("OrgStorage - Get - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		e := entity.Org{}
+		err = rows.Scan(&e.Id, &e.Name)
+		if err != nil {
+			return nil, fmt.Errorf("OrgStorage - Get - rows.Scan: %w", err)
+		}
+		return &e, nil
+	}
+	return nil, nil
+}
+
+func (r *orgStorage) GetAll(ctx context.Context) ([]entity.Org, error) {
+	sql, _, err := r.db.Builder.
+		Select("id, name").
+		From("org").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("OrgStorage - GetAll - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql)
+	if err != nil {
+		return nil, fmt.Errorf("OrgStorage - GetAll - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	entities := make([]entity.Org, 0, _defaultEntityCap)
+	for rows.Next() {
+		e := entity.Org{}
+		err = rows.Scan(&e.Id, &e.Name)
+		if err != nil {
+			return nil, fmt.Errorf("OrgStorage - GetAll - rows.Scan: %w", err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
+
+func (r *orgStorage) GetAccountOrgs(ctx context.Context, accountID uint) ([]entity.OrgAccount, error) {
+	sql, args, err := r.db.Builder.
+		Select("org_id, account_id, role").
+		From("org_account").
+		Where(sq.Eq{"account_id": accountID, "deleted_at": nil}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("OrgStorage - GetAccountOrgs - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("OrgStorage - GetAccountOrgs - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	entities := make([]entity.OrgAccount, 0, _defaultEntityCap)
+	for rows.Next() {
+		e := entity.OrgAccount{}
+		err = rows.Scan(&e.OrgId, &e.AccountId, &e.Role)
+		if err != nil {
+			return nil, fmt.Errorf("OrgStorage - GetAccountOrgs - rows.Scan: %w", err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
+
+func (r *orgStorage) GetOrgAccounts(ctx context.Context, orgID uint) ([]entity.OrgAccount, error) {
+	sql, args, err := r.db.Builder.
+		Select("org_id, account_id, role").
+		From("org_account").
+		Where(sq.Eq{"org_id": orgID, "deleted_at": nil}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("OrgStorage - GetOrgAccounts - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("OrgStorage - GetOrgAccounts - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	entities := make([]entity.OrgAccount, 0, _defaultEntityCap)
+	for rows.Next() {
+		e := entity.OrgAccount{}
+		err = rows.Scan(&e.OrgId, &e.AccountId, &e.Role)
+		if err != nil {
+			return nil, fmt.Errorf("OrgStorage - GetOrgAccounts - rows.Scan: %w", err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
+
+func (r *orgStorage) AddAccount(ctx context.Context, dto entity.OrgAccount) error {
+	sql, args, err := r.db.Builder.
+		Insert("org_account").
+		Columns("org_id, account_id, role").
+		Values(dto.OrgId, dto.AccountId, dto.Role).
+		Suffix("ON DUPLICATE KEY UPDATE role = VALUES(role), deleted_at = NULL").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("OrgStorage - AddAccount - r.Builder: %w", err)
+	}
+
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("OrgStorage - AddAccount - r.Exec: %w", err)
+	}
+	return nil
+}
+
+// RemoveAccount moves the membership to the trash, like accountStorage.Delete.
+func (r *orgStorage) RemoveAccount(ctx context.Context, orgID uint, accountID uint) error {
+	sql, args, err := r.db.Builder.
+		Update("org_account").
+		Set("deleted_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"org_id": orgID, "account_id": accountID, "deleted_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("OrgStorage - RemoveAccount - r.Builder: %w", err)
+	}
+
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("OrgStorage - RemoveAccount - r.Exec: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/mysql"
+)
+
+type projectStorage struct {
+	baseStorage
+}
+
+func NewProjectStorage(db *mysql.Mysql) *projectStorage {
+	return &projectStorage{
+		baseStorage{db},
+	}
+}
+
+func (r *projectStorage) Create(ctx context.Context, dto entity.Project) (uint, error) {
+	sql, args, err := r.db.Builder.
+		Insert("project").
+		Columns("tenant_id, owner_id, name, `desc`, color").
+		Values(entity.TenantFromContext(ctx), dto.OwnerId, dto.Name, dto.Desc, dto.Color).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("ProjectStorage - Create - r.Builder: %w", err)
+	}
+
+	res, err := r.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("ProjectStorage - Create - r.Exec: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("ProjectStorage - Create - res.LastInsertId: %w", err)
+	}
+
+	return uint(id), nil
+}
+
+func (r *projectStorage) Get(ctx context.Context, projectID uint) (*entity.Project, error) {
+	sql, args, err := r.db.Builder.
+		Select("id, owner_id, name, `desc`, color, archived").
+		From("project").
+		Where(sq.Eq{"id": projectID}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ProjectStorage - Get - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ProjectStorage - Get - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		e := entity.Project{}
+		err = rows.Scan(&e.Id, &e.OwnerId, &e.Name, &e.Desc, &e.Color, &e.Archived)
+		if err != nil {
+			return nil, fmt.Errorf("ProjectStorage - Get - rows.Scan: %w", err)
+		}
+		return &e, nil
+	}
+	return nil, nil
+}
+
+func (r *projectStorage) GetAll(ctx context.Context) ([]entity.Project, error) {
+	sql, args, err := r.db.Builder.
+		Select("id, owner_id, name, `desc`, color, archived").
+		From("project").
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ProjectStorage - GetAll - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ProjectStorage - GetAll - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	entities := make([]entity.Project, 0, _defaultEntityCap)
+	for rows.Next() {
+		e := entity.Project{}
+		err = rows.Scan(&e.Id, &e.OwnerId, &e.Name, &e.Desc, &e.Color, &e.Archived)
+		if err != nil {
+			return nil, fmt.Errorf("ProjectStorage - GetAll - rows.Scan: %w", err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
+
+// GetAllVisible returns projects the account owns or is an accepted member
+// of.
+func (r *projectStorage) GetAllVisible(ctx context.Context, accountID uint) ([]entity.Project, error) {
+	sql, args, err := r.db.Builder.
+		Select("p.id, p.owner_id, p.name, p.`desc`, p.color, p.archived").
+		From("project p").
+		Where(tenantEq(ctx, "p.tenant_id")).
+		Where(sq.Or{
+			sq.Eq{"p.owner_id": accountID},
+			sq.Expr("EXISTS (SELECT 1 FROM member m WHERE m.tenant_id = p.tenant_id AND m.account_id = ? AND m.accepted AND "+
+				"m.resource_type = ? AND m.resource_id = p.id)", accountID, entity.MemberResourceProject),
+		}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ProjectStorage - GetAllVisible - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ProjectStorage - GetAllVisible - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	entities := make([]entity.Project, 0, _defaultEntityCap)
+	for rows.Next() {
+		e := entity.Project{}
+		err = rows.Scan(&e.Id, &e.OwnerId, &e.Name, &e.Desc, &e.Color, &e.Archived)
+		if err != nil {
+			return nil, fmt.Errorf("ProjectStorage - GetAllVisible - rows.Scan: %w", err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
+
+func (r *projectStorage) Update(ctx context.Context, dto entity.Project) error {
+	builder := r.db.Builder.Update("project")
+	if dto.Name != "" {
+		builder = builder.Set("name", dto.Name)
+	}
+	if dto.Desc != "" {
+		builder = builder.Set("`desc`", dto.Desc)
+	}
+	if dto.Color != "" {
+		builder = builder.Set("color", dto.Color)
+	}
+	sql, args, err := builder.
+		Where(sq.Eq{"id": dto.Id}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ProjectStorage - Update - r.Builder: %w", err)
+	}
+
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("ProjectStorage - Update - r.Exec: %w", err)
+	}
+
+	return nil
+}
+
+// SetArchived toggles the archived flag. Todos of an archived project are
+// hidden from the global todo listing, see todoStorage.GetAll.
+func (r *projectStorage) SetArchived(ctx context.Context, projectID uint, archived bool) error {
+	sql, args, err := r.db.Builder.
+		Update("project").
+		Set("archived", archived).
+		Where(sq.Eq{"id": projectID}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ProjectStorage - SetArchived - r.Builder: %w", err)
+	}
+
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("ProjectStorage - SetArchived - r.Exec: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the project and moves its todos to the trash. They are
+// detached from the project, its foreign key would drop them with it. It
+// should be called within a transaction so both statements are applied
+// atomically.
+func (r *projectStorage) Delete(ctx context.Context, projectID uint) error {
+	sql, args, err := r.db.Builder.
+		Update("todo").
+		Set("project_id", nil).
+		Set("deleted_at", sq.Expr("COALESCE(deleted_at, NOW())")).
+		Where(sq.Eq{"project_id": projectID}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ProjectStorage - Delete - r.Builder: %w", err)
+	}
+
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("ProjectStorage - Delete - r.Exec: %w", err)
+	}
+
+	sql, args, err = r.db.Builder.
+		Delete("project").
+		Where(sq.Eq{"id": projectID}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ProjectStorage - Delete - r.Builder: %w", err)
+	}
+
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("ProjectStorage - Delete - r.Exec: %w", err)
+	}
+	return nil
+}
+
+func (r *projectStorage) GetAllByOwner(ctx context.Context, ownerID uint) ([]entity.Project, error) {
+	sql, args, err := r.db.Builder.
+		Select("id, owner_id, name, `desc`, color, archived").
+		From("project").
+		Where(sq.Eq{"owner_id": ownerID}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ProjectStorage - GetAllByOwner - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ProjectStorage - GetAllByOwner - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	entities := make([]entity.Project, 0, _defaultEntityCap)
+	for rows.Next() {
+		e := entity.Project{}
+		err = rows.Scan(&e.Id, &e.OwnerId, &e.Name, &e.Desc, &e.Color, &e.Archived)
+		if err != nil {
+			return nil, fmt.Errorf("ProjectStorage - GetAllByOwner - rows.Scan: %w", err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
+
+// SetOwner hands every project of the owner to another account.
+func (r *projectStorage) SetOwner(ctx context.Context, ownerID uint, newOwnerID uint) error {
+	sql, args, err := r.db.Builder.
+		Update("project").
+		Set("owner_id", newOwnerID).
+		Where(sq.Eq{"owner_id": ownerID}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ProjectStorage - SetOwner - r.Builder: %w", err)
+	}
+
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("ProjectStorage - SetOwner - r.Exec: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package mysql
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"testcode/test3/internal/domain/entity"
+)
+
+func TestProjectDeleteTrashesTodos(t *testing.T) {
+	rec, db := newRecorder()
+	if err := NewProjectStorage(db).Delete(entity.WithTenant(context.Background(), 1), 3); err != nil {
+		t.Fatal(err)
+	}
+
+	queries := rec.take()
+	if len(queries) != 2 {
+		t.Fatalf("queries %v, want the todos updated and the project deleted", queries)
+	}
+	todos := queries[0].sql
+	if !strings.HasPrefix(todos, "UPDATE todo ") || !strings.Contains(todos, "project_id = ?") ||
+		!strings.Contains(todos, "deleted_at = COALESCE(deleted_at, NOW())") {
+		t.Errorf("todos: %s, want them detached and moved to the trash", todos)
+	}
+	if queries[0].args[0] != nil {
+		t.Errorf("todos: project_id set to %v, want NULL", queries[0].args[0])
+	}
+	if !strings.HasPrefix(queries[1].sql, "DELETE FROM project ") {
+		t.Errorf("project: %s", queries[1].sql)
+	}
+}
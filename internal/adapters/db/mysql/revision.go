@@ -0,0 +1,100 @@
+package mysql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/mysql"
+)
+
+type revisionStorage struct {
+	baseStorage
+}
+
+func NewRevisionStorage(db *mysql.Mysql) *revisionStorage {
+	return &revisionStorage{
+		baseStorage{db},
+	}
+}
+
+func (r *revisionStorage) Create(ctx context.Context, dto entity.TodoRevision) (uint, error) {
+	changes, err := json.Marshal(dto.Changes)
+	if err != nil {
+		return 0, fmt.Errorf("RevisionStorage - Create - json.Marshal: %w", err)
+	}
+
+	sql, args, err := r.db.Builder.
+		Insert("todo_revision").
+		Columns("tenant_id, todo_id, actor_id, name, `desc`, status, due, labels, priority, rrule, project_id, restored_from, changes").
+		Values(entity.TenantFromContext(ctx), dto.TodoId, dto.ActorId, dto.Name, dto.Desc, dto.Status,
+			nullableTime(dto.Due), joinLabels(dto.Labels), dto.Priority, dto.Recurrence, nullableID(dto.ProjectId), nullableID(dto.RestoredFrom), changes).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("RevisionStorage - Create - r.Builder: %w", err)
+	}
+
+	res, err := r.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("RevisionStorage - Create - r.Exec: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("RevisionStorage - Create - res.LastInsertId: %w", err)
+	}
+
+	return uint(id), nil
+}
+
+func (r *revisionStorage) Get(ctx context.Context, revisionID uint) (*entity.TodoRevision, error) {
+	ret, err := r.getAll(ctx, "Get", sq.Eq{"id": revisionID})
+	if err != nil || len(ret) == 0 {
+		return nil, err
+	}
+	return &ret[0], nil
+}
+
+// GetAllByTodo returns revisions of the todo, newest first.
+func (r *revisionStorage) GetAllByTodo(ctx context.Context, todoID uint) ([]entity.TodoRevision, error) {
+	return r.getAll(ctx, "GetAllByTodo", sq.Eq{"todo_id": todoID})
+}
+
+func (r *revisionStorage) getAll(ctx context.Context, method string, pred sq.Eq) ([]entity.TodoRevision, error) {
+	sql, args, err := r.db.Builder.
+		Select("id, todo_id, actor_id, name, `desc`, status, due, labels, priority, rrule, COALESCE(project_id, 0), COALESCE(restored_from, 0), changes, created_at").
+		From("todo_revision").
+		Where(pred).
+		Where(tenantEq(ctx, "tenant_id")).
+		OrderBy("id DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("RevisionStorage - %s - r.Builder: %w", method, err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("RevisionStorage - %s - r.Query: %w", method, err)
+	}
+	defer rows.Close()
+
+	entities := make([]entity.TodoRevision, 0, _defaultEntityCap)
+	for rows.Next() {
+		var (
+			e       entity.TodoRevision
+			changes []byte
+		)
+		err = rows.Scan(&e.Id, &e.TodoId, &e.ActorId, &e.Name, &e.Desc, &e.Status, &e.Due, labelsColumn{&e.Labels}, &e.Priority, &e.Recurrence, &e.ProjectId, &e.RestoredFrom,
+			&changes, &e.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("RevisionStorage - %s - rows.Scan: %w", method, err)
+		}
+		if err = json.Unmarshal(changes, &e.Changes); err != nil {
+			return nil, fmt.Errorf("RevisionStorage - %s - json.Unmarshal: %w", method, err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
@@ -0,0 +1,191 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+	"testcode/test3/pkg/filter"
+	"testcode/test3/pkg/mysql"
+	"testcode/test3/pkg/search"
+)
+
+// recorder is a database/sql connector that keeps the statements run through
+// it and answers every query with no rows.
+type recorder struct {
+	mu      sync.Mutex
+	queries []recordedQuery
+}
+
+type recordedQuery struct {
+	sql  string
+	args []driver.Value
+}
+
+func (r *recorder) Connect(context.Context) (driver.Conn, error) { return recorderConn{r}, nil }
+func (r *recorder) Driver() driver.Driver                        { return nil }
+
+func (r *recorder) record(query string, args []driver.NamedValue) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	q := recordedQuery{sql: query}
+	for _, a := range args {
+		q.args = append(q.args, a.Value)
+	}
+	r.queries = append(r.queries, q)
+}
+
+func (r *recorder) take() []recordedQuery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ret := r.queries
+	r.queries = nil
+	return ret
+}
+
+type recorderConn struct{ r *recorder }
+
+func (c recorderConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c recorderConn) Close() error                        { return nil }
+func (c recorderConn) Begin() (driver.Tx, error)           { return recorderTx{}, nil }
+
+func (c recorderConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.r.record(query, args)
+	return recorderRows{}, nil
+}
+
+func (c recorderConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.r.record(query, args)
+	return driver.RowsAffected(0), nil
+}
+
+type recorderTx struct{}
+
+func (recorderTx) Commit() error   { return nil }
+func (recorderTx) Rollback() error { return nil }
+
+type recorderRows struct{}
+
+func (recorderRows) Columns() []string              { return nil }
+func (recorderRows) Close() error                   { return nil }
+func (recorderRows) Next(dest []driver.Value) error { return io.EOF }
+
+func newRecorder() (*recorder, *mysql.Mysql) {
+	r := &recorder{}
+	return r, &mysql.Mysql{
+		Builder: sq.StatementBuilder.PlaceholderFormat(sq.Question),
+		Pool:    sql.OpenDB(r),
+	}
+}
+
+// tenantArg returns the value bound to "column = ?" in the query.
+func tenantArg(q recordedQuery, column string) (driver.Value, bool) {
+	loc := regexp.MustCompile(`(^|[^.\w])` + regexp.QuoteMeta(column) + ` = \?`).FindStringIndex(q.sql)
+	if loc == nil {
+		return nil, false
+	}
+	n := strings.Count(q.sql[:loc[1]], "?") - 1
+	if n >= len(q.args) {
+		return nil, false
+	}
+	return q.args[n], true
+}
+
+func TestStorageTenantIsolation(t *testing.T) {
+	rec, db := newRecorder()
+	todos := NewTodoStorage(db)
+	accounts := NewAccountStorage(db)
+	projects := NewProjectStorage(db)
+	comments := NewCommentStorage(db)
+	attachments := NewAttachmentStorage(db)
+	members := NewMemberStorage(db)
+	revisions := NewRevisionStorage(db)
+	audit := NewAuditStorage(db)
+	filters := NewSavedFilterStorage(db)
+	assignees := NewAssigneeStorage(db)
+	davResources := NewDavResourceStorage(db)
+
+	viewer := entity.Account{Id: 7, AccountType: entity.AccountTypeUser}
+	f, err := filter.Parse(`status:open owner:alice label:urgent due<2026-11-01 "login bug"`, usecase.TodoFilterFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		column string
+		call   func(ctx context.Context) error
+	}{
+		{"todo Get", "tenant_id", func(ctx context.Context) error { _, err := todos.Get(ctx, 1); return err }},
+		{"todo GetAll", "t.tenant_id", func(ctx context.Context) error { _, err := todos.GetAll(ctx); return err }},
+		{"todo GetAllVisible", "t.tenant_id", func(ctx context.Context) error { _, err := todos.GetAllVisible(ctx, viewer.Id); return err }},
+		{"todo GetAllByAssignee", "t.tenant_id", func(ctx context.Context) error { _, err := todos.GetAllByAssignee(ctx, viewer.Id); return err }},
+		{"todo GetAllByProject", "tenant_id", func(ctx context.Context) error { _, err := todos.GetAllByProject(ctx, 1); return err }},
+		{"todo GetDeleted", "tenant_id", func(ctx context.Context) error { _, err := todos.GetDeleted(ctx, 1); return err }},
+		{"todo GetAllDeleted", "tenant_id", func(ctx context.Context) error { _, err := todos.GetAllDeleted(ctx, viewer.Id); return err }},
+		{"todo Search", "t.tenant_id", func(ctx context.Context) error {
+			_, err := todos.Search(ctx, search.Parse("login bug"), viewer, 10)
+			return err
+		}},
+		{"todo GetAllByFilter", "t.tenant_id", func(ctx context.Context) error { _, err := todos.GetAllByFilter(ctx, f, viewer); return err }},
+		{"account Get", "o.org_id", func(ctx context.Context) error { _, err := accounts.Get(ctx, 1); return err }},
+		{"account GetByIds", "o.org_id", func(ctx context.Context) error { _, err := accounts.GetByIds(ctx, []uint{1, 2}); return err }},
+		{"account GetAll", "o.org_id", func(ctx context.Context) error { _, err := accounts.GetAll(ctx); return err }},
+		{"account GetAllDeleted", "o.org_id", func(ctx context.Context) error { _, err := accounts.GetAllDeleted(ctx); return err }},
+		{"project Get", "tenant_id", func(ctx context.Context) error { _, err := projects.Get(ctx, 1); return err }},
+		{"project GetAll", "tenant_id", func(ctx context.Context) error { _, err := projects.GetAll(ctx); return err }},
+		{"project Delete", "tenant_id", func(ctx context.Context) error { return projects.Delete(ctx, 1) }},
+		{"project GetAllVisible", "p.tenant_id", func(ctx context.Context) error { _, err := projects.GetAllVisible(ctx, viewer.Id); return err }},
+		{"comment GetAllByTodo", "tenant_id", func(ctx context.Context) error { _, err := comments.GetAllByTodo(ctx, 1, 0, 10); return err }},
+		{"attachment GetAllByTodo", "a.tenant_id", func(ctx context.Context) error { _, err := attachments.GetAllByTodo(ctx, 1); return err }},
+		{"member GetAllByAccount", "tenant_id", func(ctx context.Context) error { _, err := members.GetAllByAccount(ctx, viewer.Id); return err }},
+		{"member GetRole", "tenant_id", func(ctx context.Context) error { _, err := members.GetRole(ctx, viewer.Id, 1, 1); return err }},
+		{"revision GetAllByTodo", "tenant_id", func(ctx context.Context) error { _, err := revisions.GetAllByTodo(ctx, 1); return err }},
+		{"audit GetPage", "tenant_id", func(ctx context.Context) error {
+			_, err := audit.GetPage(ctx, entity.AuditFilter{}, 0, 10)
+			return err
+		}},
+		{"audit Count", "tenant_id", func(ctx context.Context) error { _, err := audit.Count(ctx, entity.AuditFilter{}); return err }},
+		{"saved filter GetAllByAccount", "tenant_id", func(ctx context.Context) error { _, err := filters.GetAllByAccount(ctx, viewer.Id); return err }},
+		{"assignee GetByTodo", "tenant_id", func(ctx context.Context) error { _, err := assignees.GetByTodo(ctx, 1); return err }},
+		{"dav resource GetByName", "tenant_id", func(ctx context.Context) error { _, err := davResources.GetByName(ctx, "a.ics"); return err }},
+		{"dav resource GetByTodo", "tenant_id", func(ctx context.Context) error { _, err := davResources.GetByTodo(ctx, 1); return err }},
+		{"dav resource GetAll", "tenant_id", func(ctx context.Context) error { _, err := davResources.GetAll(ctx); return err }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// zero is a context without tenant, which must match no rows
+			for _, tenant := range []uint{1, 2, 0} {
+				ctx := context.Background()
+				if tenant != 0 {
+					ctx = entity.WithTenant(ctx, tenant)
+				}
+				if err := tt.call(ctx); err != nil {
+					t.Fatalf("tenant %d: %v", tenant, err)
+				}
+				queries := rec.take()
+				if len(queries) == 0 {
+					t.Fatalf("tenant %d: no query run", tenant)
+				}
+				for _, q := range queries {
+					v, ok := tenantArg(q, tt.column)
+					if !ok {
+						t.Fatalf("tenant %d: %s not scoped by %s", tenant, q.sql, tt.column)
+					}
+					if v != int64(tenant) {
+						t.Errorf("tenant %d: %s bound to %v", tenant, tt.column, v)
+					}
+				}
+			}
+		})
+	}
+}
@@ -3,10 +3,14 @@ package mysql
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/filter"
 	"testcode/test3/pkg/mysql"
+	"testcode/test3/pkg/search"
 )
 
 type todoStorage struct {
@@ -19,29 +23,40 @@ func NewTodoStorage(db *mysql.Mysql) *todoStorage {
 	}
 }
 
-func (r *todoStorage) Create(ctx context.Context, dto entity.Todo) error {
+func (r *todoStorage) Create(ctx context.Context, dto entity.Todo) (uint, error) {
+	status := dto.Status
+	if status == 0 {
+		status = entity.TodoStatusDefault
+	}
 	sql, args, err := r.db.Builder.
 		Insert("todo").
-		Columns("owner_id, name, `desc`, status").
-		Values(dto.OwnerId, dto.Name, dto.Desc, entity.TodoStatusDefault).
+		Columns("tenant_id, owner_id, project_id, name, `desc`, status, due, labels, priority, rrule").
+		Values(entity.TenantFromContext(ctx), dto.OwnerId, nullableID(dto.ProjectId), dto.Name, dto.Desc, status,
+			nullableTime(dto.Due), joinLabels(dto.Labels), dto.Priority, dto.Recurrence).
 		ToSql()
 	if err != nil {
-		return fmt.Errorf("TodoStorage - Create - r.Builder: %w", err)
+		return 0, fmt.Errorf("TodoStorage - Create - r.Builder: %w", err)
 	}
 
-	_, err = r.Exec(ctx, sql, args...)
+	res, err := r.Exec(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("TodoStorage - Create - r.Exec: %w", err)
+		return 0, fmt.Errorf("TodoStorage - Create - r.Exec: %w", err)
 	}
 
-	return nil
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("TodoStorage - Create - res.LastInsertId: %w", err)
+	}
+
+	return uint(id), nil
 }
 
 func (r *todoStorage) Get(ctx context.Context, todoID uint) (*entity.Todo, error) {
 	sql, args, err := r.db.Builder.
-		Select("id, owner_id, name, `desc`, status").
+		Select("id, owner_id, COALESCE(project_id, 0), name, `desc`, status, due, labels, priority, rrule, version").
 		From("todo").
-		Where(sq.Eq{"id": todoID}).
+		Where(sq.Eq{"id": todoID, "deleted_at": nil}).
+		Where(tenantEq(ctx, "tenant_id")).
 		ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("TodoStorage - Get - r.Builder: %w", err)
@@ -55,7 +70,7 @@ func (r *todoStorage) Get(ctx context.Context, todoID uint) (*entity.Todo, error
 
 	if rows.Next() {
 		e := entity.Todo{}
-		err = rows.Scan(&e.Id, &e.OwnerId, &e.Name, &e.Desc, &e.Status)
+		err = rows.Scan(&e.Id, &e.OwnerId, &e.ProjectId, &e.Name, &e.Desc, &e.Status, &e.Due, labelsColumn{&e.Labels}, &e.Priority, &e.Recurrence, &e.Version)
 		if err != nil {
 			return nil, fmt.Errorf("TodoStorage - Get - rows.Scan: %w", err)
 		}
@@ -65,15 +80,19 @@ func (r *todoStorage) Get(ctx context.Context, todoID uint) (*entity.Todo, error
 }
 
 func (r *todoStorage) GetAll(ctx context.Context) ([]entity.Todo, error) {
-	sql, _, err := r.db.Builder.
-		Select("id, owner_id, name, `desc`, status").
-		From("todo").
+	sql, args, err := r.db.Builder.
+		Select("t.id, t.owner_id, COALESCE(t.project_id, 0), t.name, t.`desc`, t.status, t.due, t.labels, t.priority, t.rrule, t.version").
+		From("todo t").
+		LeftJoin("project p ON p.id = t.project_id").
+		Where(tenantEq(ctx, "t.tenant_id")).
+		Where(sq.Eq{"t.deleted_at": nil}).
+		Where(sq.Or{sq.Eq{"p.archived": nil}, sq.Eq{"p.archived": false}}).
 		ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("TodoStorage - GetAll - r.Builder: %w", err)
 	}
 
-	rows, err := r.Query(ctx, sql)
+	rows, err := r.Query(ctx, sql, args...)
 	if err != nil {
 		return nil, fmt.Errorf("TodoStorage - GetAll - r.Query: %w", err)
 	}
@@ -82,7 +101,7 @@ func (r *todoStorage) GetAll(ctx context.Context) ([]entity.Todo, error) {
 	entities := make([]entity.Todo, 0, _defaultEntityCap)
 	for rows.Next() {
 		e := entity.Todo{}
-		err = rows.Scan(&e.Id, &e.OwnerId, &e.Name, &e.Desc, &e.Status)
+		err = rows.Scan(&e.Id, &e.OwnerId, &e.ProjectId, &e.Name, &e.Desc, &e.Status, &e.Due, labelsColumn{&e.Labels}, &e.Priority, &e.Recurrence, &e.Version)
 		if err != nil {
 			return nil, fmt.Errorf("TodoStorage - GetAll - rows.Scan: %w", err)
 		}
@@ -91,8 +110,11 @@ func (r *todoStorage) GetAll(ctx context.Context) ([]entity.Todo, error) {
 	return entities, nil
 }
 
-func (r *todoStorage) Update(ctx context.Context, dto entity.Todo) error {
-	builder := r.db.Builder.Update("todo")
+// Update sets non-empty fields, non-nil Due and Labels, and bumps the
+// version. Non-zero dto.Version makes the update conditional on the current
+// version, the result reports whether the todo matched.
+func (r *todoStorage) Update(ctx context.Context, dto entity.Todo) (bool, error) {
+	builder := r.db.Builder.Update("todo").Set("version", sq.Expr("version + 1"))
 	if dto.Name != "" {
 		builder = builder.Set("name", dto.Name)
 	}
@@ -102,31 +124,489 @@ func (r *todoStorage) Update(ctx context.Context, dto entity.Todo) error {
 	if dto.Status > 0 {
 		builder = builder.Set("status", dto.Status)
 	}
-	sql, args, err := builder.Where(sq.Eq{"id": dto.Id}).ToSql()
+	if dto.Due != nil {
+		builder = builder.Set("due", nullableTime(dto.Due))
+	}
+	if dto.Labels != nil {
+		builder = builder.Set("labels", joinLabels(dto.Labels))
+	}
+	if dto.Priority > 0 {
+		builder = builder.Set("priority", dto.Priority)
+	}
+	if dto.Recurrence != "" {
+		builder = builder.Set("rrule", dto.Recurrence)
+	}
+	pred := sq.Eq{"id": dto.Id, "deleted_at": nil}
+	if dto.Version > 0 {
+		pred["version"] = dto.Version
+	}
+	sql, args, err := builder.
+		Where(pred).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("TodoStorage - Update - r.Builder: %w", err)
+	}
+
+	res, err := r.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("TodoStorage - Update - r.Exec: %w", err)
+	}
+
+	n, err := res.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("TodoStorage - Update - r.Builder: %w", err)
+		return false, fmt.Errorf("TodoStorage - Update - res.RowsAffected: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Patch sets the fields of the patch as they are, unlike Update empty
+// values clear the field.
+func (r *todoStorage) Patch(ctx context.Context, todoID uint, patch entity.TodoPatch) (bool, error) {
+	builder := r.db.Builder.Update("todo").Set("version", sq.Expr("version + 1"))
+	if patch.Name != nil {
+		builder = builder.Set("name", *patch.Name)
+	}
+	if patch.Desc != nil {
+		builder = builder.Set("`desc`", *patch.Desc)
+	}
+	if patch.Status != nil {
+		builder = builder.Set("status", *patch.Status)
+	}
+	if patch.Due != nil {
+		builder = builder.Set("due", nullableTime(patch.Due))
+	}
+	if patch.Labels != nil {
+		builder = builder.Set("labels", joinLabels(*patch.Labels))
+	}
+	if patch.Priority != nil {
+		builder = builder.Set("priority", *patch.Priority)
+	}
+	if patch.Recurrence != nil {
+		builder = builder.Set("rrule", *patch.Recurrence)
+	}
+	pred := sq.Eq{"id": todoID, "deleted_at": nil}
+	if patch.Version > 0 {
+		pred["version"] = patch.Version
+	}
+	sql, args, err := builder.
+		Where(pred).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("TodoStorage - Patch - r.Builder: %w", err)
+	}
+
+	res, err := r.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("TodoStorage - Patch - r.Exec: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("TodoStorage - Patch - res.RowsAffected: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Delete moves the todo to the trash, see Purge.
+func (r *todoStorage) Delete(ctx context.Context, todoID uint) error {
+	sql, args, err := r.db.Builder.
+		Update("todo").
+		Set("deleted_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"id": todoID, "deleted_at": nil}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("TodoStorage - Delete - r.Builder: %w", err)
 	}
 
 	_, err = r.Exec(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("TodoStorage - Update - r.Exec: %w", err)
+		return fmt.Errorf("TodoStorage - Delete - r.Exec: %w", err)
+	}
+	return nil
+}
+
+// GetDeleted returns the todo from the trash.
+func (r *todoStorage) GetDeleted(ctx context.Context, todoID uint) (*entity.Todo, error) {
+	ret, err := r.getAllDeleted(ctx, "GetDeleted", sq.Eq{"id": todoID})
+	if err != nil || len(ret) == 0 {
+		return nil, err
+	}
+	return &ret[0], nil
+}
+
+// GetAllDeleted returns the trash of the owner, zero ownerID returns the
+// whole trash of the tenant.
+func (r *todoStorage) GetAllDeleted(ctx context.Context, ownerID uint) ([]entity.Todo, error) {
+	pred := sq.Eq{}
+	if ownerID != 0 {
+		pred["owner_id"] = ownerID
+	}
+	return r.getAllDeleted(ctx, "GetAllDeleted", pred)
+}
+
+func (r *todoStorage) getAllDeleted(ctx context.Context, method string, pred sq.Eq) ([]entity.Todo, error) {
+	sql, args, err := r.db.Builder.
+		Select("id, owner_id, COALESCE(project_id, 0), name, `desc`, status, due, labels, priority, rrule, version, deleted_at").
+		From("todo").
+		Where(pred).
+		Where(sq.NotEq{"deleted_at": nil}).
+		Where(tenantEq(ctx, "tenant_id")).
+		OrderBy("deleted_at DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("TodoStorage - %s - r.Builder: %w", method, err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("TodoStorage - %s - r.Query: %w", method, err)
+	}
+	defer rows.Close()
+
+	entities := make([]entity.Todo, 0, _defaultEntityCap)
+	for rows.Next() {
+		e := entity.Todo{}
+		err = rows.Scan(&e.Id, &e.OwnerId, &e.ProjectId, &e.Name, &e.Desc, &e.Status, &e.Due, labelsColumn{&e.Labels}, &e.Priority, &e.Recurrence, &e.Version, &e.DeletedAt)
+		if err != nil {
+			return nil, fmt.Errorf("TodoStorage - %s - rows.Scan: %w", method, err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
+
+// Undelete takes the todo out of the trash.
+func (r *todoStorage) Undelete(ctx context.Context, todoID uint) error {
+	sql, args, err := r.db.Builder.
+		Update("todo").
+		Set("deleted_at", nil).
+		Where(sq.Eq{"id": todoID}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("TodoStorage - Undelete - r.Builder: %w", err)
 	}
 
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("TodoStorage - Undelete - r.Exec: %w", err)
+	}
 	return nil
 }
 
-func (r *todoStorage) Delete(ctx context.Context, todoID uint) error {
+// Purge drops todos deleted before the time in every tenant, it runs in the
+// background without tenant in context.
+func (r *todoStorage) Purge(ctx context.Context, before time.Time) (int64, error) {
 	sql, args, err := r.db.Builder.
 		Delete("todo").
+		Where(sq.Lt{"deleted_at": before}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("TodoStorage - Purge - r.Builder: %w", err)
+	}
+
+	res, err := r.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("TodoStorage - Purge - r.Exec: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("TodoStorage - Purge - res.RowsAffected: %w", err)
+	}
+	return n, nil
+}
+
+// visibleTo matches todos the account owns, is assigned to or shares through
+// membership on the todo or its project. Expects todo aliased as t and its
+// project as p.
+func visibleTo(accountID uint) sq.Sqlizer {
+	return sq.Or{
+		sq.Eq{"t.owner_id": accountID},
+		sq.Eq{"p.owner_id": accountID},
+		sq.Expr("EXISTS (SELECT 1 FROM todo_assignee a WHERE a.tenant_id = t.tenant_id AND a.todo_id = t.id AND a.account_id = ?)", accountID),
+		sq.Expr("EXISTS (SELECT 1 FROM member m WHERE m.tenant_id = t.tenant_id AND m.account_id = ? AND m.accepted AND "+
+			"((m.resource_type = ? AND m.resource_id = t.id) OR (m.resource_type = ? AND m.resource_id = t.project_id)))",
+			accountID, entity.MemberResourceTodo, entity.MemberResourceProject),
+	}
+}
+
+// GetAllVisible returns todos visible to the account, see visibleTo.
+func (r *todoStorage) GetAllVisible(ctx context.Context, accountID uint) ([]entity.Todo, error) {
+	sql, args, err := r.db.Builder.
+		Select("t.id, t.owner_id, COALESCE(t.project_id, 0), t.name, t.`desc`, t.status, t.due, t.labels, t.priority, t.rrule, t.version").
+		From("todo t").
+		LeftJoin("project p ON p.id = t.project_id").
+		Where(tenantEq(ctx, "t.tenant_id")).
+		Where(sq.Eq{"t.deleted_at": nil}).
+		Where(sq.Or{sq.Eq{"p.archived": nil}, sq.Eq{"p.archived": false}}).
+		Where(visibleTo(accountID)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("TodoStorage - GetAllVisible - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("TodoStorage - GetAllVisible - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	entities := make([]entity.Todo, 0, _defaultEntityCap)
+	for rows.Next() {
+		e := entity.Todo{}
+		err = rows.Scan(&e.Id, &e.OwnerId, &e.ProjectId, &e.Name, &e.Desc, &e.Status, &e.Due, labelsColumn{&e.Labels}, &e.Priority, &e.Recurrence, &e.Version)
+		if err != nil {
+			return nil, fmt.Errorf("TodoStorage - GetAllVisible - rows.Scan: %w", err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
+
+func (r *todoStorage) GetAllByAssignee(ctx context.Context, accountID uint) ([]entity.Todo, error) {
+	sql, args, err := r.db.Builder.
+		Select("t.id, t.owner_id, COALESCE(t.project_id, 0), t.name, t.`desc`, t.status, t.due, t.labels, t.priority, t.rrule, t.version").
+		From("todo t").
+		Join("todo_assignee a ON a.todo_id = t.id").
+		Where(sq.Eq{"a.account_id": accountID}).
+		Where(tenantEq(ctx, "t.tenant_id")).
+		Where(sq.Eq{"t.deleted_at": nil}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("TodoStorage - GetAllByAssignee - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("TodoStorage - GetAllByAssignee - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	entities := make([]entity.Todo, 0, _defaultEntityCap)
+	for rows.Next() {
+		e := entity.Todo{}
+		err = rows.Scan(&e.Id, &e.OwnerId, &e.ProjectId, &e.Name, &e.Desc, &e.Status, &e.Due, labelsColumn{&e.Labels}, &e.Priority, &e.Recurrence, &e.Version)
+		if err != nil {
+			return nil, fmt.Errorf("TodoStorage - GetAllByAssignee - rows.Scan: %w", err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
+
+func (r *todoStorage) GetAllByProject(ctx context.Context, projectID uint) ([]entity.Todo, error) {
+	sql, args, err := r.db.Builder.
+		Select("id, owner_id, COALESCE(project_id, 0), name, `desc`, status, due, labels, priority, rrule, version").
+		From("todo").
+		Where(sq.Eq{"project_id": projectID, "deleted_at": nil}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("TodoStorage - GetAllByProject - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("TodoStorage - GetAllByProject - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	entities := make([]entity.Todo, 0, _defaultEntityCap)
+	for rows.Next() {
+		e := entity.Todo{}
+		err = rows.Scan(&e.Id, &e.OwnerId, &e.ProjectId, &e.Name, &e.Desc, &e.Status, &e.Due, labelsColumn{&e.Labels}, &e.Priority, &e.Recurrence, &e.Version)
+		if err != nil {
+			return nil, fmt.Errorf("TodoStorage - GetAllByProject - rows.Scan: %w", err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
+
+// SetProject moves the todo to another project, zero projectID detaches it.
+func (r *todoStorage) SetProject(ctx context.Context, todoID uint, projectID uint) error {
+	sql, args, err := r.db.Builder.
+		Update("todo").
+		Set("project_id", nullableID(projectID)).
+		Set("version", sq.Expr("version + 1")).
 		Where(sq.Eq{"id": todoID}).
+		Where(tenantEq(ctx, "tenant_id")).
 		ToSql()
 	if err != nil {
-		return fmt.Errorf("TodoStorage - Delete - r.Builder: %w", err)
+		return fmt.Errorf("TodoStorage - SetProject - r.Builder: %w", err)
 	}
 
 	_, err = r.Exec(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("TodoStorage - Delete - r.Exec: %w", err)
+		return fmt.Errorf("TodoStorage - SetProject - r.Exec: %w", err)
+	}
+	return nil
+}
+
+// booleanMode renders the query for MATCH ... AGAINST in boolean mode with
+// every term and phrase required.
+func booleanMode(q search.Query) string {
+	parts := make([]string, 0, len(q.Terms)+len(q.Phrases))
+	for _, term := range q.Terms {
+		parts = append(parts, "+"+term)
+	}
+	for _, phrase := range q.Phrases {
+		parts = append(parts, `+"`+strings.Join(phrase, " ")+`"`)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Search runs the query against the FULLTEXT index on name and desc, hits are
+// ordered by relevance. Non admin viewers only get todos visible to them.
+func (r *todoStorage) Search(ctx context.Context, q search.Query, viewer entity.Account, limit uint64) ([]entity.TodoSearchHit, error) {
+	against := booleanMode(q)
+	builder := r.db.Builder.
+		Select("t.id, t.owner_id, COALESCE(t.project_id, 0), t.name, t.`desc`, t.status, t.due, t.labels, t.priority, t.rrule, t.version").
+		Column(sq.Expr("MATCH(t.name, t.`desc`) AGAINST(? IN BOOLEAN MODE) AS score", against)).
+		From("todo t").
+		LeftJoin("project p ON p.id = t.project_id").
+		Where(tenantEq(ctx, "t.tenant_id")).
+		Where(sq.Eq{"t.deleted_at": nil}).
+		Where(sq.Or{sq.Eq{"p.archived": nil}, sq.Eq{"p.archived": false}}).
+		Where(sq.Expr("MATCH(t.name, t.`desc`) AGAINST(? IN BOOLEAN MODE)", against))
+	if !viewer.IsAdmin() {
+		builder = builder.Where(visibleTo(viewer.Id))
+	}
+	sql, args, err := builder.
+		OrderBy("score DESC", "t.id").
+		Limit(limit).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("TodoStorage - Search - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("TodoStorage - Search - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	hits := make([]entity.TodoSearchHit, 0, _defaultEntityCap)
+	for rows.Next() {
+		e := entity.TodoSearchHit{}
+		err = rows.Scan(&e.Todo.Id, &e.Todo.OwnerId, &e.Todo.ProjectId, &e.Todo.Name, &e.Todo.Desc, &e.Todo.Status, &e.Todo.Due, labelsColumn{&e.Todo.Labels}, &e.Todo.Priority, &e.Todo.Recurrence, &e.Todo.Version, &e.Score)
+		if err != nil {
+			return nil, fmt.Errorf("TodoStorage - Search - rows.Scan: %w", err)
+		}
+		hits = append(hits, e)
+	}
+	return hits, nil
+}
+
+// GetAllByFilter returns todos matching the filter. Non admin viewers only
+// get todos visible to them.
+func (r *todoStorage) GetAllByFilter(ctx context.Context, f filter.Node, viewer entity.Account) ([]entity.Todo, error) {
+	cond, err := todoFilter(f, viewer)
+	if err != nil {
+		return nil, fmt.Errorf("TodoStorage - GetAllByFilter - todoFilter: %w", err)
+	}
+	builder := r.db.Builder.
+		Select("t.id, t.owner_id, COALESCE(t.project_id, 0), t.name, t.`desc`, t.status, t.due, t.labels, t.priority, t.rrule, t.version").
+		From("todo t").
+		LeftJoin("project p ON p.id = t.project_id").
+		Where(tenantEq(ctx, "t.tenant_id")).
+		Where(sq.Eq{"t.deleted_at": nil}).
+		Where(sq.Or{sq.Eq{"p.archived": nil}, sq.Eq{"p.archived": false}}).
+		Where(cond)
+	if !viewer.IsAdmin() {
+		builder = builder.Where(visibleTo(viewer.Id))
+	}
+	sql, args, err := builder.OrderBy("t.id").ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("TodoStorage - GetAllByFilter - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("TodoStorage - GetAllByFilter - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	entities := make([]entity.Todo, 0, _defaultEntityCap)
+	for rows.Next() {
+		e := entity.Todo{}
+		err = rows.Scan(&e.Id, &e.OwnerId, &e.ProjectId, &e.Name, &e.Desc, &e.Status, &e.Due, labelsColumn{&e.Labels}, &e.Priority, &e.Recurrence, &e.Version)
+		if err != nil {
+			return nil, fmt.Errorf("TodoStorage - GetAllByFilter - rows.Scan: %w", err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
+
+// GetAllByOwner returns todos the account owns, trash excluded.
+func (r *todoStorage) GetAllByOwner(ctx context.Context, ownerID uint) ([]entity.Todo, error) {
+	sql, args, err := r.db.Builder.
+		Select("id, owner_id, COALESCE(project_id, 0), name, `desc`, status, due, labels, priority, rrule, version").
+		From("todo").
+		Where(sq.Eq{"owner_id": ownerID, "deleted_at": nil}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("TodoStorage - GetAllByOwner - r.Builder: %w", err)
+	}
+
+	rows, err := r.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("TodoStorage - GetAllByOwner - r.Query: %w", err)
+	}
+	defer rows.Close()
+
+	entities := make([]entity.Todo, 0, _defaultEntityCap)
+	for rows.Next() {
+		e := entity.Todo{}
+		err = rows.Scan(&e.Id, &e.OwnerId, &e.ProjectId, &e.Name, &e.Desc, &e.Status, &e.Due, labelsColumn{&e.Labels}, &e.Priority, &e.Recurrence, &e.Version)
+		if err != nil {
+			return nil, fmt.Errorf("TodoStorage - GetAllByOwner - rows.Scan: %w", err)
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
+
+// SetOwner hands every todo of the owner to another account, trash included.
+func (r *todoStorage) SetOwner(ctx context.Context, ownerID uint, newOwnerID uint) error {
+	sql, args, err := r.db.Builder.
+		Update("todo").
+		Set("owner_id", newOwnerID).
+		Where(sq.Eq{"owner_id": ownerID}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("TodoStorage - SetOwner - r.Builder: %w", err)
+	}
+
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("TodoStorage - SetOwner - r.Exec: %w", err)
+	}
+	return nil
+}
+
+// DeleteAllByOwner moves every todo of the owner to the trash.
+func (r *todoStorage) DeleteAllByOwner(ctx context.Context, ownerID uint) error {
+	sql, args, err := r.db.Builder.
+		Update("todo").
+		Set("deleted_at", sq.Expr("NOW()")).
+		Where(sq.Eq{"owner_id": ownerID, "deleted_at": nil}).
+		Where(tenantEq(ctx, "tenant_id")).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("TodoStorage - DeleteAllByOwner - r.Builder: %w", err)
+	}
+
+	_, err = r.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("TodoStorage - DeleteAllByOwner - r.Exec: %w", err)
 	}
 	return nil
 }
@@ -0,0 +1,116 @@
+// Package event fans todo events out to the subscribers of this process.
+package event
+
+import (
+	"sync"
+	"time"
+
+	"testcode/test3/internal/domain/entity"
+)
+
+const (
+	_defaultBuffer  = 64
+	_defaultLogSize = 1024
+)
+
+type subscriber struct {
+	tenantID uint
+	ch       chan entity.TodoEvent
+}
+
+type todoBroker struct {
+	mu     sync.Mutex
+	subs   map[*subscriber]struct{}
+	buffer int
+
+	// seq is the id of the last event. Ids start from the time the broker
+	// was created so ids of an earlier process read as expired.
+	seq uint64
+	// log is a ring of the last events of all tenants, next is where the
+	// following event goes.
+	log  []entity.TodoEvent
+	next int
+}
+
+func NewTodoBroker() *todoBroker {
+	return &todoBroker{
+		subs:   make(map[*subscriber]struct{}),
+		buffer: _defaultBuffer,
+		seq:    uint64(time.Now().UnixNano() / int64(time.Microsecond)),
+		log:    make([]entity.TodoEvent, 0, _defaultLogSize),
+	}
+}
+
+// Publish never blocks, a subscriber whose buffer is full is dropped and its
+// channel closed so it can resubscribe and resume from the log.
+func (r *todoBroker) Publish(event entity.TodoEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.seq++
+	event.Id = r.seq
+	if len(r.log) < cap(r.log) {
+		r.log = append(r.log, event)
+	} else {
+		r.log[r.next] = event
+	}
+	r.next = (r.next + 1) % cap(r.log)
+
+	for s := range r.subs {
+		if s.tenantID != event.TenantId {
+			continue
+		}
+		select {
+		case s.ch <- event:
+		default:
+			delete(r.subs, s)
+			close(s.ch)
+		}
+	}
+}
+
+// Subscribe returns the events of the tenant until stop is called.
+func (r *todoBroker) Subscribe(tenantID uint) (<-chan entity.TodoEvent, func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.subscribe(tenantID)
+}
+
+// SubscribeSince is Subscribe returning first the logged events of the
+// tenant after lastEventID. ok is false when events after it are no longer
+// logged, the subscription starts from now then.
+func (r *todoBroker) SubscribeSince(tenantID uint, lastEventID uint64) ([]entity.TodoEvent, <-chan entity.TodoEvent, func(), bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ch, stop := r.subscribe(tenantID)
+	oldest := r.seq - uint64(len(r.log)) + 1
+	if lastEventID > r.seq || lastEventID+1 < oldest {
+		return nil, ch, stop, false
+	}
+
+	var backlog []entity.TodoEvent
+	for i := 0; i < len(r.log); i++ {
+		// oldest first, the ring starts at next once full
+		e := r.log[(r.next+i)%len(r.log)]
+		if e.Id > lastEventID && e.TenantId == tenantID {
+			backlog = append(backlog, e)
+		}
+	}
+	return backlog, ch, stop, true
+}
+
+// subscribe needs r.mu held.
+func (r *todoBroker) subscribe(tenantID uint) (<-chan entity.TodoEvent, func()) {
+	s := &subscriber{tenantID: tenantID, ch: make(chan entity.TodoEvent, r.buffer)}
+	r.subs[s] = struct{}{}
+
+	return s.ch, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if _, ok := r.subs[s]; ok {
+			delete(r.subs, s)
+			close(s.ch)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package event
+
+import (
+	"testing"
+
+	"testcode/test3/internal/domain/entity"
+)
+
+func TestTodoBrokerTenants(t *testing.T) {
+	b := NewTodoBroker()
+	ch1, stop1 := b.Subscribe(1)
+	defer stop1()
+	ch2, stop2 := b.Subscribe(2)
+	defer stop2()
+
+	b.Publish(entity.TodoEvent{Type: entity.TodoEventCreated, TenantId: 1, Todo: entity.Todo{Id: 10}})
+	b.Publish(entity.TodoEvent{Type: entity.TodoEventCreated, TenantId: 2, Todo: entity.Todo{Id: 20}})
+	b.Publish(entity.TodoEvent{Type: entity.TodoEventUpdated, TenantId: 1, Todo: entity.Todo{Id: 10}})
+
+	first := <-ch1
+	if first.Todo.Id != 10 {
+		t.Fatalf("tenant 1: got todo %d", first.Todo.Id)
+	}
+	if e := <-ch1; e.Todo.Id != 10 || e.Type != entity.TodoEventUpdated {
+		t.Fatalf("tenant 1: got %v of todo %d", e.Type, e.Todo.Id)
+	}
+	if e := <-ch2; e.Todo.Id != 20 {
+		t.Fatalf("tenant 2: got todo %d", e.Todo.Id)
+	}
+	select {
+	case e := <-ch1:
+		t.Errorf("tenant 1: got todo %d of tenant %d", e.Todo.Id, e.TenantId)
+	case e := <-ch2:
+		t.Errorf("tenant 2: got todo %d of tenant %d", e.Todo.Id, e.TenantId)
+	default:
+	}
+
+	for tenant, want := range map[uint]int{1: 2, 2: 1, 3: 0} {
+		backlog, _, stop, ok := b.SubscribeSince(tenant, first.Id-1)
+		stop()
+		if !ok {
+			t.Fatalf("tenant %d: backlog expired", tenant)
+		}
+		if len(backlog) != want {
+			t.Errorf("tenant %d: backlog of %d events, want %d", tenant, len(backlog), want)
+		}
+		for _, e := range backlog {
+			if e.TenantId != tenant {
+				t.Errorf("tenant %d: backlog has todo %d of tenant %d", tenant, e.Todo.Id, e.TenantId)
+			}
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package plaintext
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"testcode/test3/internal/domain/entity"
+)
+
+// Markdown checklists are GitHub-flavored task lists, the description is
+// indented under its item and todos of a project follow a "## Project <id>"
+// heading:
+//
+//   - [ ] name
+//     description
+//
+//     ## Project 3
+//
+//   - [x] name
+const (
+	_mdIndent  = "  "
+	_mdProject = "## Project "
+)
+
+// WriteMarkdown writes the todos without a project first, then a section per
+// project in id order.
+func WriteMarkdown(w io.Writer, todos []entity.Todo) error {
+	byProject := make(map[uint][]entity.Todo)
+	var projects []uint
+	for _, t := range todos {
+		if _, ok := byProject[t.ProjectId]; !ok && t.ProjectId != 0 {
+			projects = append(projects, t.ProjectId)
+		}
+		byProject[t.ProjectId] = append(byProject[t.ProjectId], t)
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i] < projects[j] })
+
+	bw := bufio.NewWriter(w)
+	writeItems := func(todos []entity.Todo) {
+		for _, t := range todos {
+			mark := " "
+			if t.Status == entity.TodoStatusDone {
+				mark = "x"
+			}
+			fmt.Fprintf(bw, "- [%s] %s\n", mark, t.Name)
+			if t.Desc == "" {
+				continue
+			}
+			for _, line := range strings.Split(t.Desc, "\n") {
+				if line == "" {
+					bw.WriteString("\n")
+					continue
+				}
+				bw.WriteString(_mdIndent + line + "\n")
+			}
+		}
+	}
+	writeItems(byProject[0])
+	for i, id := range projects {
+		if i > 0 || len(byProject[0]) > 0 {
+			bw.WriteString("\n")
+		}
+		fmt.Fprintf(bw, "%s%d\n\n", _mdProject, id)
+		writeItems(byProject[id])
+	}
+	return bw.Flush()
+}
+
+// checklistItem splits "- [ ] name", "* [x] name" and the like.
+func checklistItem(line string) (bool, string, bool) {
+	if len(line) < 6 || (line[0] != '-' && line[0] != '*' && line[0] != '+') || line[1] != ' ' ||
+		line[2] != '[' || line[4] != ']' || line[5] != ' ' {
+		return false, "", false
+	}
+	switch line[3] {
+	case ' ':
+		return false, line[6:], true
+	case 'x', 'X':
+		return true, line[6:], true
+	}
+	return false, "", false
+}
+
+// ReadMarkdown reads the checklist items as import rows numbered in order.
+// Indented lines after an item are its description, blank lines inside it
+// included, other lines are skipped. It stops after limit rows, limit 0 reads
+// them all.
+func ReadMarkdown(r io.Reader, limit int) ([]entity.TodoImportRow, error) {
+	var (
+		rows      []entity.TodoImportRow
+		projectID uint
+		desc      []string
+		blanks    int
+	)
+	flush := func() {
+		if len(rows) > 0 && len(desc) > 0 {
+			rows[len(rows)-1].Todo.Desc = strings.Join(desc, "\n")
+		}
+		desc, blanks = nil, 0
+	}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	inItem := false
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if inItem && strings.TrimSpace(line) == "" {
+			blanks++
+			continue
+		}
+		if inItem && strings.HasPrefix(line, _mdIndent) {
+			for ; blanks > 0; blanks-- {
+				desc = append(desc, "")
+			}
+			desc = append(desc, strings.TrimPrefix(line, _mdIndent))
+			continue
+		}
+		flush()
+		inItem = false
+
+		if done, name, ok := checklistItem(line); ok {
+			if limit > 0 && len(rows) == limit {
+				break
+			}
+			row := entity.TodoImportRow{Row: len(rows) + 1}
+			row.Todo.Name = strings.TrimSpace(name)
+			row.Todo.ProjectId = projectID
+			row.Todo.Status = entity.TodoStatusDefault
+			if done {
+				row.Todo.Status = entity.TodoStatusDone
+			}
+			rows = append(rows, row)
+			inItem = true
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			// any other heading ends the project section
+			projectID = 0
+			if strings.HasPrefix(line, _mdProject) {
+				id, err := strconv.ParseUint(strings.TrimSpace(strings.TrimPrefix(line, _mdProject)), 10, 32)
+				if err != nil {
+					return nil, fmt.Errorf("invalid heading %q", line)
+				}
+				projectID = uint(id)
+			}
+		}
+	}
+	flush()
+	return rows, sc.Err()
+}
@@ -0,0 +1,67 @@
+package plaintext
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"testcode/test3/internal/domain/entity"
+)
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	// in the order WriteMarkdown groups them
+	todos := []entity.Todo{
+		{Name: "buy milk", Status: entity.TodoStatusDefault},
+		{Name: "ship it", Status: entity.TodoStatusDone, Desc: "first line\n\nthird line"},
+		{Name: "- [ ] looks like an item", Status: entity.TodoStatusDefault, Desc: "  indented\n- [ ] not an item"},
+		{Name: "in project 3", Status: entity.TodoStatusDefault, ProjectId: 3, Desc: "## not a heading"},
+		{Name: "done in project 5", Status: entity.TodoStatusDone, ProjectId: 5},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, todos); err != nil {
+		t.Fatal(err)
+	}
+	text := buf.String()
+	rows, err := ReadMarkdown(&buf, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != len(todos) {
+		t.Fatalf("%d rows, want %d:\n%s", len(rows), len(todos), text)
+	}
+	for i, row := range rows {
+		if row.Row != i+1 || row.Error != "" || !reflect.DeepEqual(row.Todo, todos[i]) {
+			t.Errorf("row %d read as %+v, want %+v:\n%s", i+1, row, todos[i], text)
+		}
+	}
+}
+
+func TestMarkdownRead(t *testing.T) {
+	text := "# Todos\n\n* [X] done\nnot indented, skipped\n\n## Project 2\n\n+ [ ] in 2\n  notes\n\n## Other\n\n- [ ] in none\n- [-] skipped\n"
+	rows, err := ReadMarkdown(strings.NewReader(text), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []entity.Todo{
+		{Name: "done", Status: entity.TodoStatusDone},
+		{Name: "in 2", Status: entity.TodoStatusDefault, ProjectId: 2, Desc: "notes"},
+		{Name: "in none", Status: entity.TodoStatusDefault},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("rows %+v, want %d", rows, len(want))
+	}
+	for i, row := range rows {
+		if !reflect.DeepEqual(row.Todo, want[i]) {
+			t.Errorf("row %d read as %+v, want %+v", i+1, row.Todo, want[i])
+		}
+	}
+
+	if _, err = ReadMarkdown(strings.NewReader("## Project x\n"), 0); err == nil {
+		t.Errorf("invalid project heading: no error")
+	}
+	if rows, _ = ReadMarkdown(strings.NewReader("- [ ] a\n- [ ] b\n- [ ] c\n"), 2); len(rows) != 2 {
+		t.Errorf("limit 2: %d rows", len(rows))
+	}
+}
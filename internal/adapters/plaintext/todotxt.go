@@ -0,0 +1,213 @@
+// Package plaintext converts todos to and from the plain text formats people
+// keep todos in by hand: todo.txt and Markdown checklists.
+package plaintext
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/todotxt"
+)
+
+// Todos have no dates or contexts. The todo.txt priority A to I is the todo
+// priority 1 to 9, lower priorities import as 9, dates ahead of the text are
+// dropped on import. The project, due date and description are trailing
+// tags, done todos keep their priority as a pri: tag. @contexts, +projects
+// and other tags stay in the name as written.
+const (
+	_tagProject  = "project"
+	_tagDesc     = "desc"
+	_tagDue      = "due"
+	_tagPriority = "pri"
+)
+
+var _todoTxtTags = map[string]bool{_tagProject: true, _tagDesc: true, _tagDue: true, _tagPriority: true}
+
+// escapeTag keeps a value in one word, only '%', control and space
+// characters and a leading '/', which would read as a URL, are escaped so
+// the value stays readable.
+func escapeTag(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c == '%' || c <= ' ' || c == 0x7f || (i == 0 && c == '/') {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// escapeName keeps the name from reading as something else: '%' and control
+// characters, line breaks and tabs included, are escaped, so is the first
+// character when the name starts like a completion mark, priority or date,
+// and the ':' of words that read as the tags above.
+func escapeName(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; c == '%' || c < ' ' || c == 0x7f {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(name[i])
+	}
+	words := strings.Split(b.String(), " ")
+	for i, w := range words {
+		if k, _, ok := todotxt.Tag(w); ok && _todoTxtTags[k] {
+			words[i] = k + "%3A" + w[len(k)+1:]
+		}
+	}
+	ret := strings.Join(words, " ")
+	if todotxt.Parse(ret).Text != ret {
+		ret = fmt.Sprintf("%%%02X", ret[0]) + ret[1:]
+	}
+	return ret
+}
+
+// unescapeName decodes the %XX escapes, a '%' without two hex digits after
+// it, as in hand-written files, is kept.
+func unescapeName(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]) {
+			v, _ := strconv.ParseUint(s[i+1:i+3], 16, 8)
+			b.WriteByte(byte(v))
+			i += 2
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// formatDue writes midnight UTC as a date, other times as RFC 3339 in UTC.
+func formatDue(due time.Time) string {
+	due = due.UTC()
+	if due.Equal(due.Truncate(24 * time.Hour)) {
+		return due.Format(todotxt.DateFormat)
+	}
+	return due.Format(time.RFC3339)
+}
+
+func parseDue(s string) (time.Time, error) {
+	if t, err := time.Parse(todotxt.DateFormat, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// TodoTask renders the todo as a todo.txt task, the name escaped so it reads
+// back as written.
+func TodoTask(todo entity.Todo) todotxt.Task {
+	t := todotxt.Task{Done: todo.Status == entity.TodoStatusDone, Text: escapeName(todo.Name)}
+	if todo.Priority > 0 {
+		t.Priority = 'A' + byte(todo.Priority) - 1
+	}
+	if todo.ProjectId != 0 {
+		t.Text += fmt.Sprintf(" %s:%d", _tagProject, todo.ProjectId)
+	}
+	if todo.Due != nil {
+		t.Text += fmt.Sprintf(" %s:%s", _tagDue, formatDue(*todo.Due))
+	}
+	if todo.Desc != "" {
+		t.Text += fmt.Sprintf(" %s:%s", _tagDesc, escapeTag(todo.Desc))
+	}
+	return t
+}
+
+// WriteTodoTxt writes the todos a task per line.
+func WriteTodoTxt(w io.Writer, todos []entity.Todo) error {
+	tasks := make([]todotxt.Task, 0, len(todos))
+	for _, t := range todos {
+		tasks = append(tasks, TodoTask(t))
+	}
+	return todotxt.Write(w, tasks)
+}
+
+// taskRow reads the trailing tags written by TodoTask, the same words earlier
+// in the text are part of the name.
+func taskRow(n int, task todotxt.Task) entity.TodoImportRow {
+	row := entity.TodoImportRow{Row: n}
+	row.Todo.Status = entity.TodoStatusDefault
+	if task.Done {
+		row.Todo.Status = entity.TodoStatusDone
+	}
+	priority := task.Priority
+
+	text := strings.TrimRight(task.Text, " \t")
+	for {
+		i := strings.LastIndexAny(text, " \t")
+		k, v, ok := todotxt.Tag(text[i+1:])
+		if !ok || !_todoTxtTags[k] {
+			break
+		}
+		switch k {
+		case _tagProject:
+			id, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				row.Error = fmt.Sprintf("invalid project %q", v)
+				return row
+			}
+			row.Todo.ProjectId = uint(id)
+		case _tagDesc:
+			desc, err := url.PathUnescape(v)
+			if err != nil {
+				row.Error = fmt.Sprintf("invalid desc %q", v)
+				return row
+			}
+			row.Todo.Desc = desc
+		case _tagDue:
+			due, err := parseDue(v)
+			if err != nil {
+				row.Error = fmt.Sprintf("invalid due %q", v)
+				return row
+			}
+			row.Todo.Due = &due
+		case _tagPriority:
+			if len(v) != 1 || v[0] < 'A' || v[0] > 'Z' {
+				row.Error = fmt.Sprintf("invalid pri %q", v)
+				return row
+			}
+			priority = v[0]
+		}
+		if i < 0 {
+			text = ""
+			break
+		}
+		text = strings.TrimRight(text[:i], " \t")
+	}
+	if priority != 0 {
+		row.Todo.Priority = uint(priority-'A') + 1
+		if row.Todo.Priority > 9 {
+			row.Todo.Priority = 9
+		}
+	}
+	row.Todo.Name = unescapeName(strings.TrimSpace(text))
+	return row
+}
+
+// ReadTodoTxt reads the tasks as import rows numbered in order, blank lines
+// are not counted. It stops after limit rows, limit 0 reads them all.
+func ReadTodoTxt(r io.Reader, limit int) ([]entity.TodoImportRow, error) {
+	var rows []entity.TodoImportRow
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for (limit == 0 || len(rows) < limit) && sc.Scan() {
+		line := sc.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		rows = append(rows, taskRow(len(rows)+1, todotxt.Parse(line)))
+	}
+	return rows, sc.Err()
+}
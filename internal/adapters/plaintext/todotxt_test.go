@@ -0,0 +1,80 @@
+package plaintext
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"testcode/test3/internal/domain/entity"
+)
+
+func TestTodoTxtRoundTrip(t *testing.T) {
+	day := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	at := time.Date(2022, 10, 1, 9, 30, 0, 0, time.UTC)
+	todos := []entity.Todo{
+		{Name: "x marks spot", Status: entity.TodoStatusDefault},
+		{Name: "(B) call bob", Status: entity.TodoStatusDefault},
+		{Name: "2022-10-01 deadline", Status: entity.TodoStatusDefault},
+		{Name: "2022-10-01 deadline", Status: entity.TodoStatusDone},
+		{Name: "read desc:foo", Status: entity.TodoStatusDefault},
+		{Name: "due:friday pri:A project:7", Status: entity.TodoStatusDone},
+		{Name: "50% done %41", Status: entity.TodoStatusDefault},
+		{Name: "call bob", Status: entity.TodoStatusDefault, Priority: 2, Due: &day},
+		{Name: "x ship it", Status: entity.TodoStatusDone, Priority: 1, Due: &at, ProjectId: 3, Desc: "with notes: 100%"},
+		{Name: "visit http://example.com +home @town key:value", Status: entity.TodoStatusDefault, Priority: 9},
+		{Name: "two\nlines", Status: entity.TodoStatusDefault},
+		{Name: "crlf\r\nx done", Status: entity.TodoStatusDefault},
+		{Name: "tab\tdue:friday", Status: entity.TodoStatusDefault},
+		{Name: "\tx indented", Status: entity.TodoStatusDefault},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTodoTxt(&buf, todos); err != nil {
+		t.Fatal(err)
+	}
+	if n := bytes.Count(buf.Bytes(), []byte("\n")); n != len(todos) {
+		t.Fatalf("%d lines, want a line per todo:\n%s", n, buf.String())
+	}
+	rows, err := ReadTodoTxt(&buf, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != len(todos) {
+		t.Fatalf("%d rows, want %d", len(rows), len(todos))
+	}
+	for i, row := range rows {
+		if row.Error != "" {
+			t.Errorf("%q: %s", todos[i].Name, row.Error)
+			continue
+		}
+		if !reflect.DeepEqual(row.Todo, todos[i]) {
+			t.Errorf("line %q read as %+v, want %+v", TodoTask(todos[i]).String(), row.Todo, todos[i])
+		}
+	}
+}
+
+func TestTodoTxtRead(t *testing.T) {
+	tests := []struct {
+		line     string
+		name     string
+		priority uint
+		status   entity.TodoStatus
+	}{
+		{"(A) 2022-10-01 call bob +home", "call bob +home", 1, entity.TodoStatusDefault},
+		{"(Z) someday", "someday", 9, entity.TodoStatusDefault},
+		{"x 2022-10-02 2022-10-01 done pri:C", "done", 3, entity.TodoStatusDone},
+		{"100% sure", "100% sure", 0, entity.TodoStatusDefault},
+	}
+	for _, tt := range tests {
+		rows, err := ReadTodoTxt(bytes.NewBufferString(tt.line+"\n"), 0)
+		if err != nil || len(rows) != 1 {
+			t.Fatalf("%q: %v, %d rows", tt.line, err, len(rows))
+		}
+		todo := rows[0].Todo
+		if todo.Name != tt.name || todo.Priority != tt.priority || todo.Status != tt.status {
+			t.Errorf("%q: name %q, priority %d, status %d, want %q, %d, %d", tt.line, todo.Name, todo.Priority, todo.Status,
+				tt.name, tt.priority, tt.status)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Package memory searches todos with the pure Go inverted index from
+// pkg/search. It is the fallback for storage backends without full-text
+// support: the index is built per query over the todos visible to the viewer,
+// so it suits small datasets only.
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/search"
+)
+
+type TodoLister interface {
+	GetAll(ctx context.Context) ([]entity.Todo, error)
+	GetAllVisible(ctx context.Context, accountID uint) ([]entity.Todo, error)
+}
+
+type todoSearch struct {
+	storage TodoLister
+}
+
+func NewTodoSearch(storage TodoLister) *todoSearch {
+	return &todoSearch{storage}
+}
+
+func (r *todoSearch) Search(ctx context.Context, q search.Query, viewer entity.Account, limit uint64) ([]entity.TodoSearchHit, error) {
+	var (
+		todos []entity.Todo
+		err   error
+	)
+	if viewer.IsAdmin() {
+		todos, err = r.storage.GetAll(ctx)
+	} else {
+		todos, err = r.storage.GetAllVisible(ctx, viewer.Id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("TodoSearch - Search - r.storage: %w", err)
+	}
+
+	index := search.NewIndex()
+	byID := make(map[uint]entity.Todo, len(todos))
+	for _, t := range todos {
+		index.Add(t.Id, t.Name, t.Desc)
+		byID[t.Id] = t
+	}
+
+	results := index.Search(q)
+	if uint64(len(results)) > limit {
+		results = results[:limit]
+	}
+	hits := make([]entity.TodoSearchHit, 0, len(results))
+	for _, res := range results {
+		hits = append(hits, entity.TodoSearchHit{Todo: byID[res.Id], Score: res.Score})
+	}
+	return hits, nil
+}
@@ -2,17 +2,25 @@
 package app
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/gin-gonic/gin"
 	"testcode/test3/config"
+	"testcode/test3/internal/adapters/blob/local"
+	"testcode/test3/internal/adapters/blob/s3"
+	"testcode/test3/internal/adapters/db/idempotency"
 	"testcode/test3/internal/adapters/db/mysql"
 	"testcode/test3/internal/adapters/db/session"
+	"testcode/test3/internal/adapters/event"
 	"testcode/test3/internal/adapters/notification/telegram"
+	"testcode/test3/internal/adapters/search/memory"
+	"testcode/test3/internal/controller/grpc"
 	v1 "testcode/test3/internal/controller/http/v1"
 	"testcode/test3/internal/domain/usecase"
+	"testcode/test3/pkg/grpcserver"
 	"testcode/test3/pkg/httpserver"
 	"testcode/test3/pkg/logger"
 	mysqlpool "testcode/test3/pkg/mysql"
@@ -31,21 +39,84 @@ func Run(cfg *config.Config) {
 
 	accountStorage := mysql.NewAccountStorage(db)
 	todoStorage := mysql.NewTodoStorage(db)
+	assigneeStorage := mysql.NewAssigneeStorage(db)
+	projectStorage := mysql.NewProjectStorage(db)
+	memberStorage := mysql.NewMemberStorage(db)
+	orgStorage := mysql.NewOrgStorage(db)
+	commentStorage := mysql.NewCommentStorage(db)
+	attachmentStorage := mysql.NewAttachmentStorage(db)
+	savedFilterStorage := mysql.NewSavedFilterStorage(db)
+	revisionStorage := mysql.NewRevisionStorage(db)
+	auditStorage := mysql.NewAuditStorage(db)
+	calendarFeedStorage := mysql.NewCalendarFeedStorage(db)
+	davResourceStorage := mysql.NewDavResourceStorage(db)
+	transactor := mysql.NewTransactor(log, db)
 	sessionStorage := session.NewSessionStorage()
 
+	// Idempotency records, in memory they do not survive restarts
+	var idempotencyStorage usecase.IdempotencyStorage = mysql.NewIdempotencyStorage(db)
+	if cfg.Idempotency.Driver == "memory" {
+		idempotencyStorage = idempotency.NewIdempotencyStorage()
+	}
+
+	// Blob storage
+	var blobStorage usecase.BlobStorage
+	switch cfg.Blob.Driver {
+	case "s3":
+		blobStorage, err = s3.NewS3BlobStorage(cfg.Blob.S3Endpoint, cfg.Blob.S3Region, cfg.Blob.S3Bucket,
+			cfg.Blob.S3AccessKey, cfg.Blob.S3SecretKey)
+	default:
+		blobStorage, err = local.NewLocalBlobStorage(cfg.Blob.Path)
+	}
+	if err != nil {
+		log.Fatal("app - Run - blobStorage: %v", err)
+	}
+
+	// Search, the in-memory index serves backends without full-text support
+	var todoSearcher usecase.TodoSearcher = todoStorage
+	if cfg.Search.Driver == "memory" {
+		todoSearcher = memory.NewTodoSearch(todoStorage)
+	}
+
 	// Notification
 	telegramNotification := telegram.NewTelegramNotification(log)
+	todoEvents := event.NewTodoBroker()
 
 	// Use case
-	accountUsecase := usecase.NewAccountUsecase(log, accountStorage)
-	todoUsecase := usecase.NewTodoUsecase(log, todoStorage, telegramNotification)
+	auditUsecase := usecase.NewAuditUsecase(log, auditStorage)
+	accountUsecase := usecase.NewAccountUsecase(log, accountStorage, todoStorage, projectStorage, transactor,
+		auditUsecase)
+	todoUsecase := usecase.NewTodoUsecase(log, todoStorage, assigneeStorage, revisionStorage, transactor, telegramNotification,
+		auditUsecase, todoEvents)
+	projectUsecase := usecase.NewProjectUsecase(log, projectStorage)
+	memberUsecase := usecase.NewMemberUsecase(log, memberStorage, projectStorage, telegramNotification)
+	orgUsecase := usecase.NewOrgUsecase(log, orgStorage, auditUsecase)
+	commentUsecase := usecase.NewCommentUsecase(log, commentStorage, accountStorage, telegramNotification)
+	attachmentUsecase := usecase.NewAttachmentUsecase(log, attachmentStorage, blobStorage, cfg.Blob.MaxSize, cfg.Blob.AllowedTypes)
+	searchUsecase := usecase.NewSearchUsecase(log, todoSearcher)
+	filterUsecase := usecase.NewFilterUsecase(log, todoStorage, savedFilterStorage)
+	trashUsecase := usecase.NewTrashUsecase(log, todoStorage, accountStorage, attachmentStorage, blobStorage, transactor,
+		auditUsecase, todoEvents, cfg.Trash.Retention)
 	sessionUsecase := usecase.NewSessionUsecase(sessionStorage)
+	idempotencyUsecase := usecase.NewIdempotencyUsecase(log, idempotencyStorage, cfg.Idempotency.TTL)
+	calendarUsecase := usecase.NewCalendarUsecase(log, calendarFeedStorage, davResourceStorage)
+
+	// Background jobs
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go trashUsecase.RunPurge(ctx, cfg.Trash.PurgeInterval)
+	go idempotencyUsecase.RunPurge(ctx, cfg.Idempotency.PurgeInterval)
 
 	// HTTP Server
 	handler := gin.New()
-	v1.NewRouter(handler, log, accountUsecase, todoUsecase, sessionUsecase)
+	v1.NewRouter(handler, log, accountUsecase, todoUsecase, projectUsecase, memberUsecase, orgUsecase, commentUsecase, attachmentUsecase, searchUsecase, filterUsecase,
+		trashUsecase, auditUsecase, sessionUsecase, idempotencyUsecase, calendarUsecase)
 	httpServer := httpserver.New(handler, httpserver.Port(cfg.HTTP.Port))
 
+	// gRPC Server
+	grpcHandler := grpc.NewServer(log, accountUsecase, orgUsecase, todoUsecase, projectUsecase, memberUsecase, sessionUsecase)
+	grpcServer := grpcserver.New(grpcHandler, grpcserver.Port(cfg.GRPC.Port))
+
 	// Waiting signal
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
@@ -55,6 +126,8 @@ func Run(cfg *config.Config) {
 		log.Info("app - Run - signal: %v", s.String())
 	case err = <-httpServer.Notify():
 		log.Error("app - Run - httpServer.Notify: %v", err)
+	case err = <-grpcServer.Notify():
+		log.Error("app - Run - grpcServer.Notify: %v", err)
 	}
 
 	// Shutdown
@@ -62,4 +135,9 @@ func Run(cfg *config.Config) {
 	if err != nil {
 		log.Error("app - Run - httpServer.Shutdown: %v", err)
 	}
+
+	err = grpcServer.Shutdown()
+	if err != nil {
+		log.Error("app - Run - grpcServer.Shutdown: %v", err)
+	}
 }
@@ -0,0 +1,106 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+	"testcode/test3/pkg/logger"
+	"testcode/test3/pkg/todopb"
+)
+
+type accountServer struct {
+	todopb.UnimplementedAccountServiceServer
+	accountUsecase AccountUsecase
+	orgUsecase     OrgUsecase
+	sessionUsecase SessionUsecase
+	log            *logger.Logger
+}
+
+func (r *accountServer) Login(ctx context.Context, req *todopb.LoginRequest) (*todopb.LoginResponse, error) {
+	if req.Name == "" || req.Password == "" {
+		return nil, status.Error(codes.InvalidArgument, "name and password are required")
+	}
+
+	// failed attempts are audited in the org the client asked for
+	orgID := uint(req.OrgId)
+	account, err := r.accountUsecase.Authenticate(entity.WithTenant(ctx, orgID), req.Name, req.Password)
+	if err != nil {
+		r.log.Error("grpc - AccountService - Login: %v", err)
+		if errors.Is(err, usecase.ErrAccountNotFound) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return nil, toStatus(err)
+	}
+
+	acc, err := r.orgUsecase.EnterOrg(ctx, *account, orgID)
+	if err != nil {
+		r.log.Error("grpc - AccountService - Login: %v", err)
+		if errors.Is(err, usecase.ErrOrgRequired) || errors.Is(err, usecase.ErrOrgNoAccess) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return nil, toStatus(err)
+	}
+
+	return &todopb.LoginResponse{Token: r.sessionUsecase.Create(acc)}, nil
+}
+
+func (r *accountServer) Logout(ctx context.Context, req *todopb.LogoutRequest) (*todopb.LogoutResponse, error) {
+	r.sessionUsecase.Delete(metadataValue(ctx, MetadataAuthKey))
+	return &todopb.LogoutResponse{}, nil
+}
+
+func (r *accountServer) GetAccount(ctx context.Context, req *todopb.GetAccountRequest) (*todopb.Account, error) {
+	resp, err := r.accountUsecase.GetAccount(ctx, uint(req.Id))
+	if err != nil {
+		r.log.Error("grpc - AccountService - GetAccount: %v", err)
+		return nil, toStatus(err)
+	}
+	if resp == nil {
+		return nil, status.Error(codes.NotFound, usecase.ErrAccountNotFound.Error())
+	}
+	return toAccount(*resp), nil
+}
+
+func (r *accountServer) ListAccounts(ctx context.Context, req *todopb.ListAccountsRequest) (*todopb.ListAccountsResponse, error) {
+	accounts, err := r.accountUsecase.GetAccountAll(ctx)
+	if err != nil {
+		r.log.Error("grpc - AccountService - ListAccounts: %v", err)
+		return nil, toStatus(err)
+	}
+
+	resp := &todopb.ListAccountsResponse{Accounts: make([]*todopb.Account, 0, len(accounts))}
+	for _, a := range accounts {
+		resp.Accounts = append(resp.Accounts, toAccount(a))
+	}
+	return resp, nil
+}
+
+func (r *accountServer) CreateAccount(ctx context.Context, req *todopb.CreateAccountRequest) (*todopb.CreateAccountResponse, error) {
+	account := accountFromContext(ctx)
+	if !account.IsAdmin() {
+		r.log.Error("grpc - AccountService - CreateAccount: %v", errNoAccess)
+		return nil, errNoAccess
+	}
+	if req.Name == "" || req.Password == "" {
+		return nil, status.Error(codes.InvalidArgument, "name and password are required")
+	}
+	accountType := entity.AccountType(req.AccountType)
+	if accountType != entity.AccountTypeAdmin && accountType != entity.AccountTypeUser {
+		return nil, status.Error(codes.InvalidArgument, "account_type must be admin or user")
+	}
+
+	acc := entity.Account{
+		Name:        req.Name,
+		Password:    req.Password,
+		AccountType: accountType,
+	}
+	if err := r.accountUsecase.CreateAccount(ctx, acc); err != nil {
+		r.log.Error("grpc - AccountService - CreateAccount: %v", err)
+		return nil, toStatus(err)
+	}
+	return &todopb.CreateAccountResponse{}, nil
+}
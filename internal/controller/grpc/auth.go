@@ -0,0 +1,133 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/google/uuid"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"testcode/test3/internal/domain/entity"
+)
+
+// Metadata keys, the same names as the HTTP headers.
+const (
+	MetadataAuthKey   = "token"
+	MetadataRequestId = "x-request-id"
+)
+
+type accountKey struct{}
+
+// accountFromContext returns the caller set by the auth interceptors.
+func accountFromContext(ctx context.Context) entity.Account {
+	account, _ := ctx.Value(accountKey{}).(entity.Account)
+	return account
+}
+
+// authFunc puts the request metadata and, unless the method is ignored, the
+// account of the session to context.
+type authFunc func(ctx context.Context, method string) (context.Context, error)
+
+// newAuth lets methods in ignore through without a session, a method ending
+// with * ignores the whole service.
+func newAuth(session SessionUsecase, ignore ...string) authFunc {
+	m := make(map[string]struct{})
+	var prefixes []string
+	for _, v := range ignore {
+		if strings.HasSuffix(v, "*") {
+			prefixes = append(prefixes, strings.TrimSuffix(v, "*"))
+			continue
+		}
+		m[v] = struct{}{}
+	}
+	return func(ctx context.Context, method string) (context.Context, error) {
+		ctx = requestMeta(ctx)
+		if _, ok := m[method]; ok {
+			return ctx, nil
+		}
+		for _, p := range prefixes {
+			if strings.HasPrefix(method, p) {
+				return ctx, nil
+			}
+		}
+
+		token := metadataValue(ctx, MetadataAuthKey)
+		if token == "" {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		account, ok := session.Get(token)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		return withAccount(ctx, account), nil
+	}
+}
+
+// UnaryAuth -.
+func UnaryAuth(session SessionUsecase, ignore ...string) gogrpc.UnaryServerInterceptor {
+	auth := newAuth(session, ignore...)
+	return func(ctx context.Context, req interface{}, info *gogrpc.UnaryServerInfo, handler gogrpc.UnaryHandler) (interface{}, error) {
+		ctx, err := auth(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuth -.
+func StreamAuth(session SessionUsecase, ignore ...string) gogrpc.StreamServerInterceptor {
+	auth := newAuth(session, ignore...)
+	return func(srv interface{}, ss gogrpc.ServerStream, info *gogrpc.StreamServerInfo, handler gogrpc.StreamHandler) error {
+		ctx, err := auth(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// serverStream carries the context of the interceptor to the handler.
+type serverStream struct {
+	gogrpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func metadataValue(ctx context.Context, key string) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// requestMeta tags the call with an id, taken from the client when given,
+// and puts it with the client ip to context for the audit log.
+func requestMeta(ctx context.Context) context.Context {
+	id := metadataValue(ctx, MetadataRequestId)
+	if id == "" || len(id) > 64 {
+		id = uuid.New().String()
+	}
+	var ip string
+	if p, ok := peer.FromContext(ctx); ok {
+		ip, _, _ = net.SplitHostPort(p.Addr.String())
+	}
+	return entity.WithAuditMeta(ctx, entity.AuditMeta{RequestId: id, ClientIp: ip})
+}
+
+// withAccount makes account the caller of the call.
+func withAccount(ctx context.Context, account entity.Account) context.Context {
+	meta := entity.AuditMetaFromContext(ctx)
+	meta.ActorId = account.Id
+	ctx = entity.WithAuditMeta(ctx, meta)
+	ctx = context.WithValue(ctx, accountKey{}, account)
+	return entity.WithTenant(ctx, account.TenantId)
+}
@@ -0,0 +1,48 @@
+package grpc
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/todopb"
+)
+
+var _eventTypes = map[entity.TodoEventType]todopb.TodoEventType{
+	entity.TodoEventCreated: todopb.TodoEventType_TODO_EVENT_TYPE_CREATED,
+	entity.TodoEventUpdated: todopb.TodoEventType_TODO_EVENT_TYPE_UPDATED,
+	entity.TodoEventDeleted: todopb.TodoEventType_TODO_EVENT_TYPE_DELETED,
+}
+
+// toAccount leaves the password out.
+func toAccount(a entity.Account) *todopb.Account {
+	return &todopb.Account{
+		Id:          uint64(a.Id),
+		TenantId:    uint64(a.TenantId),
+		Name:        a.Name,
+		AccountType: todopb.AccountType(a.AccountType),
+	}
+}
+
+func toTodo(t entity.Todo) *todopb.Todo {
+	ret := &todopb.Todo{
+		Id:        uint64(t.Id),
+		OwnerId:   uint64(t.OwnerId),
+		ProjectId: uint64(t.ProjectId),
+		Name:      t.Name,
+		Desc:      t.Desc,
+		Status:    todopb.TodoStatus(t.Status),
+		Version:   uint64(t.Version),
+	}
+	for _, id := range t.Assignees {
+		ret.Assignees = append(ret.Assignees, uint64(id))
+	}
+	return ret
+}
+
+func toTodoEvent(e entity.TodoEvent) *todopb.TodoEvent {
+	return &todopb.TodoEvent{
+		Type:    _eventTypes[e.Type],
+		ActorId: uint64(e.ActorId),
+		Todo:    toTodo(e.Todo),
+		At:      timestamppb.New(e.At),
+	}
+}
@@ -0,0 +1,29 @@
+package grpc
+
+import (
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testcode/test3/internal/domain/usecase"
+)
+
+var errNoAccess = status.Error(codes.PermissionDenied, "No access")
+
+// toStatus maps use case errors to status codes, errors already carrying a
+// status keep it and the rest are Internal.
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	code := codes.Internal
+	switch {
+	case errors.Is(err, usecase.ErrTodoInvalid):
+		code = codes.InvalidArgument
+	case errors.Is(err, usecase.ErrTodoNotFound), errors.Is(err, usecase.ErrAccountNotFound):
+		code = codes.NotFound
+	case errors.Is(err, usecase.ErrTodoVersionConflict):
+		code = codes.Aborted
+	}
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,70 @@
+// Package grpc serves the Todo and Account services of pkg/todopb over the
+// same use cases as the HTTP API.
+package grpc
+
+import (
+	"context"
+
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/logger"
+	"testcode/test3/pkg/todopb"
+)
+
+type AccountUsecase interface {
+	CreateAccount(ctx context.Context, dto entity.Account) error
+	GetAccount(ctx context.Context, accountID uint) (*entity.Account, error)
+	Authenticate(ctx context.Context, name string, password string) (*entity.Account, error)
+	GetAccountAll(ctx context.Context) ([]entity.Account, error)
+}
+
+type OrgUsecase interface {
+	EnterOrg(ctx context.Context, account entity.Account, orgID uint) (entity.Account, error)
+}
+
+type TodoUsecase interface {
+	CreateTodo(ctx context.Context, dto entity.Todo) (uint, error)
+	GetTodo(ctx context.Context, todoID uint) (*entity.Todo, error)
+	GetTodoAll(ctx context.Context) ([]entity.Todo, error)
+	GetTodoAllVisible(ctx context.Context, accountID uint) ([]entity.Todo, error)
+	UpdateTodo(ctx context.Context, dto entity.Todo, actorID uint) error
+	DeleteTodo(ctx context.Context, todoID uint) error
+	WatchTodos(ctx context.Context) (<-chan entity.TodoEvent, func())
+}
+
+type ProjectUsecase interface {
+	GetProject(ctx context.Context, projectID uint) (*entity.Project, error)
+}
+
+type MemberUsecase interface {
+	TodoRole(ctx context.Context, account entity.Account, todo entity.Todo) (entity.MemberRole, error)
+	ProjectRole(ctx context.Context, account entity.Account, project entity.Project) (entity.MemberRole, error)
+}
+
+type SessionUsecase interface {
+	Get(key string) (entity.Account, bool)
+	Create(account entity.Account) string
+	Delete(key string)
+}
+
+// NewServer returns a server with the services and reflection registered,
+// every call but Login and reflection needs a session token.
+func NewServer(log *logger.Logger, accountUsecase AccountUsecase, orgUsecase OrgUsecase, todoUsecase TodoUsecase,
+	projectUsecase ProjectUsecase, memberUsecase MemberUsecase, sessionUsecase SessionUsecase) *gogrpc.Server {
+	ignore := []string{
+		"/todo.v1.AccountService/Login",
+		"/grpc.reflection.v1alpha.ServerReflection/*",
+	}
+	s := gogrpc.NewServer(
+		gogrpc.ChainUnaryInterceptor(UnaryAuth(sessionUsecase, ignore...)),
+		gogrpc.ChainStreamInterceptor(StreamAuth(sessionUsecase, ignore...)),
+	)
+
+	todopb.RegisterAccountServiceServer(s, &accountServer{accountUsecase: accountUsecase, orgUsecase: orgUsecase,
+		sessionUsecase: sessionUsecase, log: log})
+	todopb.RegisterTodoServiceServer(s, &todoServer{accountUsecase: accountUsecase, todoUsecase: todoUsecase,
+		projectUsecase: projectUsecase, memberUsecase: memberUsecase, log: log})
+	reflection.Register(s)
+	return s
+}
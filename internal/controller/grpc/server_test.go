@@ -0,0 +1,276 @@
+package grpc_test
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"testcode/test3/internal/adapters/db/session"
+	"testcode/test3/internal/controller/grpc"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+	"testcode/test3/pkg/logger"
+	"testcode/test3/pkg/todopb"
+)
+
+// accounts knows the accounts by name, the password is the name.
+type accounts struct {
+	grpc.AccountUsecase
+	byName map[string]entity.Account
+}
+
+func (r accounts) Authenticate(_ context.Context, name string, password string) (*entity.Account, error) {
+	a, ok := r.byName[name]
+	if !ok || password != name {
+		return nil, usecase.ErrAccountNotFound
+	}
+	return &a, nil
+}
+
+type orgs struct{}
+
+func (orgs) EnterOrg(_ context.Context, account entity.Account, _ uint) (entity.Account, error) {
+	account.TenantId = 1
+	return account, nil
+}
+
+// todos serves the todos of a map and hands out events to one watcher.
+type todos struct {
+	grpc.TodoUsecase
+	todos  map[uint]entity.Todo
+	events chan entity.TodoEvent
+}
+
+func (r *todos) GetTodo(_ context.Context, todoID uint) (*entity.Todo, error) {
+	t, ok := r.todos[todoID]
+	if !ok {
+		return nil, nil
+	}
+	return &t, nil
+}
+
+func (r *todos) UpdateTodo(_ context.Context, dto entity.Todo, _ uint) error {
+	t := r.todos[dto.Id]
+	t.Status = dto.Status
+	t.Version++
+	r.todos[dto.Id] = t
+	return nil
+}
+
+func (r *todos) DeleteTodo(_ context.Context, todoID uint) error {
+	delete(r.todos, todoID)
+	return nil
+}
+
+func (r *todos) WatchTodos(context.Context) (<-chan entity.TodoEvent, func()) {
+	return r.events, func() {}
+}
+
+// roles makes the owner of a todo its owner and the assignees viewers.
+type roles struct{ grpc.MemberUsecase }
+
+func (roles) TodoRole(_ context.Context, account entity.Account, todo entity.Todo) (entity.MemberRole, error) {
+	switch {
+	case todo.OwnerId == account.Id:
+		return entity.MemberRoleOwner, nil
+	case todo.HasAssignee(account.Id):
+		return entity.MemberRoleViewer, nil
+	}
+	return entity.MemberRoleNone, nil
+}
+
+type fixture struct {
+	conn  *gogrpc.ClientConn
+	todos *todos
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	store := &todos{todos: map[uint]entity.Todo{
+		1: {Id: 1, OwnerId: 2, Name: "of alice", Desc: "desc", Status: entity.TodoStatusDefault, Version: 1},
+		2: {Id: 2, OwnerId: 2, Name: "for bob", Desc: "desc", Status: entity.TodoStatusDefault, Version: 1,
+			Assignees: []uint{3}},
+	}}
+	users := accounts{byName: map[string]entity.Account{
+		"root":  {Id: 1, Name: "root", AccountType: entity.AccountTypeAdmin},
+		"alice": {Id: 2, Name: "alice", AccountType: entity.AccountTypeUser},
+		"bob":   {Id: 3, Name: "bob", AccountType: entity.AccountTypeUser},
+	}}
+	s := grpc.NewServer(logger.New("error"), users, orgs{}, store, nil, roles{},
+		usecase.NewSessionUsecase(session.NewSessionStorage()))
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := gogrpc.Dial("bufnet", gogrpc.WithTransportCredentials(insecure.NewCredentials()),
+		gogrpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return &fixture{conn: conn, todos: store}
+}
+
+// login returns a context carrying the session token of name.
+func (f *fixture) login(t *testing.T, name string) context.Context {
+	t.Helper()
+	resp, err := todopb.NewAccountServiceClient(f.conn).Login(context.Background(),
+		&todopb.LoginRequest{Name: name, Password: name})
+	if err != nil {
+		t.Fatalf("login %s: %v", name, err)
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), grpc.MetadataAuthKey, resp.Token)
+}
+
+func TestAuth(t *testing.T) {
+	f := newFixture(t)
+	todoClient := todopb.NewTodoServiceClient(f.conn)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+	}{
+		{"no token", context.Background()},
+		{"unknown token", metadata.AppendToOutgoingContext(context.Background(), grpc.MetadataAuthKey, "nope")},
+	}
+	for _, tt := range tests {
+		_, err := todoClient.GetTodo(tt.ctx, &todopb.GetTodoRequest{Id: 1})
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("%s: got %v, want %v", tt.name, err, codes.Unauthenticated)
+		}
+	}
+
+	_, err := todopb.NewAccountServiceClient(f.conn).Login(context.Background(),
+		&todopb.LoginRequest{Name: "alice", Password: "wrong"})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("wrong password: got %v, want %v", err, codes.Unauthenticated)
+	}
+
+	ctx := f.login(t, "alice")
+	if todo, err := todoClient.GetTodo(ctx, &todopb.GetTodoRequest{Id: 1}); err != nil || todo.Name != "of alice" {
+		t.Errorf("with token: got %v, %v", todo, err)
+	}
+	if _, err = todopb.NewAccountServiceClient(f.conn).Logout(ctx, &todopb.LogoutRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = todoClient.GetTodo(ctx, &todopb.GetTodoRequest{Id: 1}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("after logout: got %v, want %v", err, codes.Unauthenticated)
+	}
+}
+
+func TestTodoRoles(t *testing.T) {
+	f := newFixture(t)
+	client := todopb.NewTodoServiceClient(f.conn)
+	alice, bob := f.login(t, "alice"), f.login(t, "bob")
+	done := todopb.TodoStatus_TODO_STATUS_DONE
+
+	tests := []struct {
+		name string
+		call func() error
+		want codes.Code
+	}{
+		{"get of a stranger", func() error {
+			_, err := client.GetTodo(bob, &todopb.GetTodoRequest{Id: 1})
+			return err
+		}, codes.PermissionDenied},
+		{"get of an assignee", func() error {
+			_, err := client.GetTodo(bob, &todopb.GetTodoRequest{Id: 2})
+			return err
+		}, codes.OK},
+		{"get missing", func() error {
+			_, err := client.GetTodo(alice, &todopb.GetTodoRequest{Id: 9})
+			return err
+		}, codes.NotFound},
+		{"assignee renames", func() error {
+			_, err := client.UpdateTodo(bob, &todopb.UpdateTodoRequest{Id: 2, Name: "mine", Status: done})
+			return err
+		}, codes.PermissionDenied},
+		{"assignee completes", func() error {
+			todo, err := client.UpdateTodo(bob, &todopb.UpdateTodoRequest{Id: 2, Status: done})
+			if err == nil && todo.Status != done {
+				t.Errorf("assignee completes: status %v", todo.Status)
+			}
+			return err
+		}, codes.OK},
+		{"stranger completes", func() error {
+			_, err := client.UpdateTodo(bob, &todopb.UpdateTodoRequest{Id: 1, Status: done})
+			return err
+		}, codes.PermissionDenied},
+		{"invalid status", func() error {
+			_, err := client.UpdateTodo(alice, &todopb.UpdateTodoRequest{Id: 1, Status: 7})
+			return err
+		}, codes.InvalidArgument},
+		{"assignee deletes", func() error {
+			_, err := client.DeleteTodo(bob, &todopb.DeleteTodoRequest{Id: 2})
+			return err
+		}, codes.PermissionDenied},
+		{"owner deletes an old version", func() error {
+			_, err := client.DeleteTodo(alice, &todopb.DeleteTodoRequest{Id: 1, Version: 5})
+			return err
+		}, codes.FailedPrecondition},
+		{"owner deletes", func() error {
+			_, err := client.DeleteTodo(alice, &todopb.DeleteTodoRequest{Id: 1, Version: 1})
+			return err
+		}, codes.OK},
+	}
+	for _, tt := range tests {
+		if got := status.Code(tt.call()); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if _, ok := f.todos.todos[1]; ok {
+		t.Errorf("todo 1 not deleted")
+	}
+}
+
+func TestWatchTodos(t *testing.T) {
+	f := newFixture(t)
+	client := todopb.NewTodoServiceClient(f.conn)
+	events := []entity.TodoEvent{
+		{Type: entity.TodoEventUpdated, ActorId: 2, Todo: f.todos.todos[1]},
+		{Type: entity.TodoEventUpdated, ActorId: 2, Todo: f.todos.todos[2]},
+	}
+
+	tests := []struct {
+		name string
+		want []uint64
+	}{
+		{"alice", []uint64{1, 2}},
+		{"bob", []uint64{2}},
+		{"root", []uint64{1, 2}},
+	}
+	for _, tt := range tests {
+		// the closed channel ends the stream after the events
+		f.todos.events = make(chan entity.TodoEvent, len(events))
+		for _, e := range events {
+			f.todos.events <- e
+		}
+		close(f.todos.events)
+
+		stream, err := client.WatchTodos(f.login(t, tt.name), &todopb.WatchTodosRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []uint64
+		for {
+			event, err := stream.Recv()
+			if err != nil {
+				if status.Code(err) != codes.Unavailable {
+					t.Errorf("%s: stream ended with %v", tt.name, err)
+				}
+				break
+			}
+			got = append(got, event.Todo.Id)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got todos %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+	"testcode/test3/pkg/logger"
+	"testcode/test3/pkg/todopb"
+)
+
+type todoServer struct {
+	todopb.UnimplementedTodoServiceServer
+	accountUsecase AccountUsecase
+	todoUsecase    TodoUsecase
+	projectUsecase ProjectUsecase
+	memberUsecase  MemberUsecase
+	log            *logger.Logger
+}
+
+// todoAccess loads the todo and checks account has at least minRole on it.
+func (r *todoServer) todoAccess(ctx context.Context, todoID uint, account entity.Account, minRole entity.MemberRole) (*entity.Todo, entity.MemberRole, error) {
+	todo, err := r.todoUsecase.GetTodo(ctx, todoID)
+	if err != nil {
+		return nil, entity.MemberRoleNone, toStatus(err)
+	}
+	if todo == nil {
+		return nil, entity.MemberRoleNone, status.Error(codes.NotFound, usecase.ErrTodoNotFound.Error())
+	}
+
+	role, err := r.memberUsecase.TodoRole(ctx, account, *todo)
+	if err != nil {
+		return nil, entity.MemberRoleNone, toStatus(err)
+	}
+	if role < minRole {
+		return nil, role, errNoAccess
+	}
+	return todo, role, nil
+}
+
+func (r *todoServer) GetTodo(ctx context.Context, req *todopb.GetTodoRequest) (*todopb.Todo, error) {
+	todo, _, err := r.todoAccess(ctx, uint(req.Id), accountFromContext(ctx), entity.MemberRoleViewer)
+	if err != nil {
+		r.log.Error("grpc - TodoService - GetTodo: %v", err)
+		return nil, err
+	}
+	return toTodo(*todo), nil
+}
+
+func (r *todoServer) ListTodos(ctx context.Context, req *todopb.ListTodosRequest) (*todopb.ListTodosResponse, error) {
+	account := accountFromContext(ctx)
+
+	var (
+		todos []entity.Todo
+		err   error
+	)
+	if account.IsAdmin() {
+		todos, err = r.todoUsecase.GetTodoAll(ctx)
+	} else {
+		todos, err = r.todoUsecase.GetTodoAllVisible(ctx, account.Id)
+	}
+	if err != nil {
+		r.log.Error("grpc - TodoService - ListTodos: %v", err)
+		return nil, toStatus(err)
+	}
+
+	resp := &todopb.ListTodosResponse{Todos: make([]*todopb.Todo, 0, len(todos))}
+	for _, t := range todos {
+		resp.Todos = append(resp.Todos, toTodo(t))
+	}
+	return resp, nil
+}
+
+func (r *todoServer) CreateTodo(ctx context.Context, req *todopb.CreateTodoRequest) (*todopb.Todo, error) {
+	account := accountFromContext(ctx)
+	if req.Name == "" || req.Desc == "" {
+		return nil, status.Error(codes.InvalidArgument, "name and desc are required")
+	}
+
+	assignees := make([]uint, 0, len(req.Assignees))
+	for _, accountID := range req.Assignees {
+		assignee, err := r.accountUsecase.GetAccount(ctx, uint(accountID))
+		if err != nil {
+			r.log.Error("grpc - TodoService - CreateTodo: %v", err)
+			return nil, toStatus(err)
+		}
+		if assignee == nil {
+			return nil, status.Error(codes.InvalidArgument, "assignee not found")
+		}
+		assignees = append(assignees, assignee.Id)
+	}
+
+	if req.ProjectId != 0 {
+		if err := r.writableProject(ctx, uint(req.ProjectId), account); err != nil {
+			r.log.Error("grpc - TodoService - CreateTodo: %v", err)
+			return nil, err
+		}
+	}
+
+	id, err := r.todoUsecase.CreateTodo(ctx, entity.Todo{
+		OwnerId:   account.Id,
+		ProjectId: uint(req.ProjectId),
+		Name:      req.Name,
+		Desc:      req.Desc,
+		Assignees: assignees,
+	})
+	if err != nil {
+		r.log.Error("grpc - TodoService - CreateTodo: %v", err)
+		return nil, toStatus(err)
+	}
+	return r.getTodo(ctx, "CreateTodo", id)
+}
+
+// writableProject checks account may add todos to the project.
+func (r *todoServer) writableProject(ctx context.Context, projectID uint, account entity.Account) error {
+	project, err := r.projectUsecase.GetProject(ctx, projectID)
+	if err != nil {
+		return toStatus(err)
+	}
+	if project == nil {
+		return status.Error(codes.InvalidArgument, "project not found")
+	}
+
+	role, err := r.memberUsecase.ProjectRole(ctx, account, *project)
+	if err != nil {
+		return toStatus(err)
+	}
+	if role < entity.MemberRoleEditor {
+		return errNoAccess
+	}
+	if project.Archived {
+		return status.Error(codes.InvalidArgument, "project is archived")
+	}
+	return nil
+}
+
+func (r *todoServer) UpdateTodo(ctx context.Context, req *todopb.UpdateTodoRequest) (*todopb.Todo, error) {
+	account := accountFromContext(ctx)
+	if req.Status != todopb.TodoStatus_TODO_STATUS_UNSPECIFIED &&
+		req.Status != todopb.TodoStatus_TODO_STATUS_DEFAULT && req.Status != todopb.TodoStatus_TODO_STATUS_DONE {
+		return nil, status.Error(codes.InvalidArgument, "invalid status")
+	}
+
+	todo, role, err := r.todoAccess(ctx, uint(req.Id), account, entity.MemberRoleViewer)
+	if err != nil {
+		r.log.Error("grpc - TodoService - UpdateTodo: %v", err)
+		return nil, err
+	}
+	if role < entity.MemberRoleEditor {
+		// assignees may only move the todo between statuses
+		if !todo.HasAssignee(account.Id) || req.Name != "" || req.Desc != "" {
+			r.log.Error("grpc - TodoService - UpdateTodo: %v", errNoAccess)
+			return nil, errNoAccess
+		}
+	}
+
+	err = r.todoUsecase.UpdateTodo(ctx, entity.Todo{
+		Id:      todo.Id,
+		Name:    req.Name,
+		Desc:    req.Desc,
+		Status:  entity.TodoStatus(req.Status),
+		Version: uint(req.Version),
+	}, account.Id)
+	if err != nil {
+		r.log.Error("grpc - TodoService - UpdateTodo: %v", err)
+		return nil, toStatus(err)
+	}
+	return r.getTodo(ctx, "UpdateTodo", todo.Id)
+}
+
+func (r *todoServer) DeleteTodo(ctx context.Context, req *todopb.DeleteTodoRequest) (*todopb.DeleteTodoResponse, error) {
+	todo, _, err := r.todoAccess(ctx, uint(req.Id), accountFromContext(ctx), entity.MemberRoleOwner)
+	if err != nil {
+		r.log.Error("grpc - TodoService - DeleteTodo: %v", err)
+		return nil, err
+	}
+	if req.Version != 0 && uint(req.Version) != todo.Version {
+		err = status.Error(codes.FailedPrecondition, "version does not match the current version")
+		r.log.Error("grpc - TodoService - DeleteTodo: %v", err)
+		return nil, err
+	}
+
+	if err = r.todoUsecase.DeleteTodo(ctx, todo.Id); err != nil {
+		r.log.Error("grpc - TodoService - DeleteTodo: %v", err)
+		return nil, toStatus(err)
+	}
+	return &todopb.DeleteTodoResponse{}, nil
+}
+
+// getTodo returns the todo as stored after a change.
+func (r *todoServer) getTodo(ctx context.Context, name string, todoID uint) (*todopb.Todo, error) {
+	todo, err := r.todoUsecase.GetTodo(ctx, todoID)
+	if err != nil {
+		r.log.Error("grpc - TodoService - %s: %v", name, err)
+		return nil, toStatus(err)
+	}
+	if todo == nil {
+		return nil, status.Error(codes.NotFound, usecase.ErrTodoNotFound.Error())
+	}
+	return toTodo(*todo), nil
+}
+
+// WatchTodos sends the changes to the todos the caller can view, admins see
+// every todo of the tenant.
+func (r *todoServer) WatchTodos(req *todopb.WatchTodosRequest, stream todopb.TodoService_WatchTodosServer) error {
+	ctx := stream.Context()
+	account := accountFromContext(ctx)
+
+	events, stop := r.todoUsecase.WatchTodos(ctx)
+	defer stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return status.Error(codes.Unavailable, "watcher fell behind, watch again")
+			}
+			if !account.IsAdmin() {
+				role, err := r.memberUsecase.TodoRole(ctx, account, event.Todo)
+				if err != nil {
+					r.log.Error("grpc - TodoService - WatchTodos: %v", err)
+					return toStatus(err)
+				}
+				if role < entity.MemberRoleViewer {
+					continue
+				}
+			}
+			if err := stream.Send(toTodoEvent(event)); err != nil {
+				r.log.Error("grpc - TodoService - WatchTodos: %v", err)
+				return err
+			}
+		}
+	}
+}
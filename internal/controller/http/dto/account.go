@@ -3,6 +3,7 @@ package dto
 type LoginRequest struct {
 	Name     string `json:"name" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	OrgId    uint   `json:"org_id"`
 }
 
 type CreateAccountRequest struct {
@@ -16,5 +17,8 @@ type GetAccountRequest struct {
 }
 
 type DeleteAccountRequest struct {
-	Id uint `json:"id" binding:"required"`
+	Id         uint   `json:"id" binding:"required"`
+	Strategy   string `json:"strategy" binding:"omitempty,oneof=fail_if_owned reassign_to cascade"`
+	ReassignTo uint   `json:"reassign_to" binding:"required_if=Strategy reassign_to"`
+	DryRun     bool   `json:"dry_run"`
 }
@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+type GetAuditRequest struct {
+	ActorId    uint      `form:"actor_id"`
+	Action     string    `form:"action"`
+	TargetType string    `form:"target_type"`
+	TargetId   uint      `form:"target_id"`
+	From       time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Page       uint      `form:"page" binding:"omitempty,min=1"`
+	Limit      uint      `form:"limit" binding:"omitempty,min=1,max=100"`
+	Format     string    `form:"format" binding:"omitempty,oneof=csv ndjson"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+type CalendarFeedUri struct {
+	Token string `uri:"token" binding:"required"`
+}
+
+type ImportCalendarRequest struct {
+	DryRun bool `form:"dry_run"`
+}
@@ -0,0 +1,28 @@
+package dto
+
+type TodoUri struct {
+	Id uint `uri:"id" binding:"required"`
+}
+
+type CommentUri struct {
+	Id        uint `uri:"id" binding:"required"`
+	CommentId uint `uri:"comment_id" binding:"required"`
+}
+
+type GetCommentsRequest struct {
+	Page  uint `form:"page" binding:"omitempty,min=1"`
+	Limit uint `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type CreateCommentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+type EditCommentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+type AttachmentUri struct {
+	Id           uint `uri:"id" binding:"required"`
+	AttachmentId uint `uri:"attachment_id" binding:"required"`
+}
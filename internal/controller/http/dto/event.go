@@ -0,0 +1,7 @@
+package dto
+
+type EventsRequest struct {
+	LastEventId uint64 `form:"last_event_id"`
+	// Token is for clients that cannot set the token header, like EventSource.
+	Token string `form:"token"`
+}
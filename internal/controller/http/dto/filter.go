@@ -0,0 +1,15 @@
+package dto
+
+type FilterTodoRequest struct {
+	Query   string `form:"q" binding:"required_without=SavedId"`
+	SavedId uint   `form:"saved_id"`
+}
+
+type SaveFilterRequest struct {
+	Name  string `json:"name" binding:"required,max=40"`
+	Query string `json:"query" binding:"required"`
+}
+
+type DeleteSavedFilterRequest struct {
+	Id uint `json:"id" binding:"required"`
+}
@@ -0,0 +1,21 @@
+package dto
+
+type GetMembersRequest struct {
+	ResourceType uint `json:"resource_type" form:"resource_type" binding:"required,oneof=1 2"`
+	ResourceId   uint `json:"resource_id" form:"resource_id" binding:"required"`
+}
+
+type InviteMemberRequest struct {
+	ResourceType uint `json:"resource_type" binding:"required,oneof=1 2"`
+	ResourceId   uint `json:"resource_id" binding:"required"`
+	AccountId    uint `json:"account_id" binding:"required"`
+	Role         uint `json:"role" binding:"required,oneof=1 2 3"`
+}
+
+type AcceptInviteRequest struct {
+	Id uint `json:"id" binding:"required"`
+}
+
+type RemoveMemberRequest struct {
+	Id uint `json:"id" binding:"required"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+type CreateOrgRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type AddOrgAccountRequest struct {
+	OrgId     uint `json:"org_id" binding:"required"`
+	AccountId uint `json:"account_id" binding:"required"`
+	Role      uint `json:"role" binding:"required,oneof=1 2"`
+}
+
+type RemoveOrgAccountRequest struct {
+	OrgId     uint `json:"org_id" binding:"required"`
+	AccountId uint `json:"account_id" binding:"required"`
+}
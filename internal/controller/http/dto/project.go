@@ -0,0 +1,32 @@
+package dto
+
+type CreateProjectRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Desc  string `json:"desc"`
+	Color string `json:"color" binding:"omitempty,hexcolor"`
+}
+
+type GetProjectRequest struct {
+	Id uint `json:"id" form:"id" binding:"required"`
+}
+
+type UpdateProjectRequest struct {
+	Id    uint   `json:"id" binding:"required"`
+	Name  string `json:"name"`
+	Desc  string `json:"desc"`
+	Color string `json:"color" binding:"omitempty,hexcolor"`
+}
+
+type ArchiveProjectRequest struct {
+	Id       uint `json:"id" binding:"required"`
+	Archived bool `json:"archived"`
+}
+
+type DeleteProjectRequest struct {
+	Id uint `json:"id" binding:"required"`
+}
+
+type MoveTodoRequest struct {
+	TodoId    uint `json:"todo_id" binding:"required"`
+	ProjectId uint `json:"project_id"`
+}
@@ -1,8 +1,16 @@
 package dto
 
+import "time"
+
 type CreateTodoRequest struct {
-	Name string `json:"name" binding:"required"`
-	Desc string `json:"desc" binding:"required"`
+	Name       string     `json:"name" binding:"required"`
+	Desc       string     `json:"desc" binding:"required"`
+	Due        *time.Time `json:"due"`
+	Labels     []string   `json:"labels"`
+	Priority   uint       `json:"priority" binding:"max=9"`
+	Recurrence string     `json:"recurrence"`
+	ProjectId  uint       `json:"project_id"`
+	Assignees  []uint     `json:"assignees"`
 }
 
 type GetTodoRequest struct {
@@ -10,12 +18,68 @@ type GetTodoRequest struct {
 }
 
 type UpdateTodoRequest struct {
-	Id     uint   `json:"id" binding:"required"`
-	Name   string `json:"name"`
-	Desc   string `json:"desc"`
-	Status uint   `json:"status" binding:"omitempty,oneof=1 2"`
+	Id     uint       `json:"id" binding:"required"`
+	Name   string     `json:"name"`
+	Desc   string     `json:"desc"`
+	Status uint       `json:"status" binding:"omitempty,oneof=1 2"`
+	Due    *time.Time `json:"due"`
+	// Labels replace the labels of the todo when set
+	Labels     []string `json:"labels"`
+	Priority   uint     `json:"priority" binding:"max=9"`
+	Recurrence string   `json:"recurrence"`
+	// Version makes the update conditional like If-Match
+	Version uint `json:"version"`
 }
 
 type DeleteTodoRequest struct {
 	Id uint `json:"id" binding:"required"`
 }
+
+type AssignTodoRequest struct {
+	TodoId    uint `json:"todo_id" binding:"required"`
+	AccountId uint `json:"account_id" binding:"required"`
+}
+
+type UnassignTodoRequest struct {
+	TodoId    uint `json:"todo_id" binding:"required"`
+	AccountId uint `json:"account_id" binding:"required"`
+}
+
+type SearchTodoRequest struct {
+	Query string `form:"q" binding:"required"`
+	Limit uint   `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type RevisionUri struct {
+	Id         uint `uri:"id" binding:"required"`
+	RevisionId uint `uri:"revision_id" binding:"required"`
+}
+
+type BulkTodoOperation struct {
+	Op        string `json:"op" binding:"required,oneof=create update complete delete"`
+	Id        uint   `json:"id" binding:"required_unless=Op create"`
+	Name      string `json:"name" binding:"required_if=Op create"`
+	Desc      string `json:"desc" binding:"required_if=Op create"`
+	Status    uint   `json:"status" binding:"omitempty,oneof=1 2"`
+	ProjectId uint   `json:"project_id"`
+	Version   uint   `json:"version"`
+}
+
+type BulkTodoRequest struct {
+	// Atomic runs all operations in one transaction, otherwise each
+	// operation succeeds or fails on its own
+	Atomic     bool                `json:"atomic"`
+	Operations []BulkTodoOperation `json:"operations" binding:"required,min=1,max=100,dive"`
+}
+
+type ExportTodoRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv json ndjson todotxt markdown"`
+}
+
+type ImportTodoRequest struct {
+	Format string `form:"format" binding:"required,oneof=csv json ndjson todotxt markdown"`
+	// Map renames source columns or keys to todo fields, e.g.
+	// "Title:name,Notes:desc", it does not apply to todotxt and markdown
+	Map    string `form:"map"`
+	DryRun bool   `form:"dry_run"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+type RestoreTodoRequest struct {
+	Id uint `json:"id" binding:"required"`
+}
+
+type RestoreAccountRequest struct {
+	Id uint `json:"id" binding:"required"`
+}
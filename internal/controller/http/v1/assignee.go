@@ -0,0 +1,101 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/controller/http/dto"
+	"testcode/test3/internal/domain/entity"
+)
+
+func (r *todoHandler) GetAssignedTodos(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	resp, err := r.todoUsecase.GetTodoAllByAssignee(c.Request.Context(), account.Id)
+	if err != nil {
+		r.log.Error("http - v1 - GetAssignedTodos: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", resp))
+}
+
+func (r *todoHandler) AssignTodo(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var req dto.AssignTodoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.log.Error("http - v1 - AssignTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	todo, role, code, err := r.todoAccess(c.Request.Context(), req.TodoId, account)
+	if err != nil {
+		r.log.Error("http - v1 - AssignTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(code, err.Error()))
+		return
+	}
+	if role < entity.MemberRoleEditor {
+		err = errors.New("No access")
+		r.log.Error("http - v1 - AssignTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+		return
+	}
+
+	assignee, err := r.accountUsecase.GetAccount(c.Request.Context(), req.AccountId)
+	if err != nil {
+		r.log.Error("http - v1 - AssignTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+	if assignee == nil {
+		err = errors.New("assignee not found")
+		r.log.Error("http - v1 - AssignTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	if err = r.todoUsecase.AssignTodo(c.Request.Context(), *todo, req.AccountId, account.Id); err != nil {
+		r.log.Error("http - v1 - AssignTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok"))
+}
+
+func (r *todoHandler) UnassignTodo(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var req dto.UnassignTodoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.log.Error("http - v1 - UnassignTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	_, role, code, err := r.todoAccess(c.Request.Context(), req.TodoId, account)
+	if err != nil {
+		r.log.Error("http - v1 - UnassignTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(code, err.Error()))
+		return
+	}
+	// assignees are allowed to drop themselves from a todo
+	if role < entity.MemberRoleEditor && account.Id != req.AccountId {
+		err = errors.New("No access")
+		r.log.Error("http - v1 - UnassignTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+		return
+	}
+
+	if err = r.todoUsecase.UnassignTodo(c.Request.Context(), req.TodoId, req.AccountId); err != nil {
+		r.log.Error("http - v1 - UnassignTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok"))
+}
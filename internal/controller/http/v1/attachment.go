@@ -0,0 +1,248 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/controller/http/dto"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+)
+
+const (
+	_attachmentFormField = "file"
+	// _multipartOverhead leaves room for multipart boundaries and headers
+	_multipartOverhead = 1 << 20
+)
+
+var errRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// parseRange parses a single "bytes=" range against size. Returns length -1
+// when the header is absent or has several ranges, then the whole blob is
+// served as allowed by RFC 7233.
+func parseRange(header string, size int64) (offset int64, length int64, err error) {
+	if header == "" || !strings.HasPrefix(header, "bytes=") || strings.Contains(header, ",") {
+		return 0, -1, nil
+	}
+	spec := strings.TrimSpace(strings.TrimPrefix(header, "bytes="))
+	i := strings.Index(spec, "-")
+	if i < 0 {
+		return 0, -1, nil
+	}
+	start, end := strings.TrimSpace(spec[:i]), strings.TrimSpace(spec[i+1:])
+
+	if start == "" {
+		// suffix range, last n bytes
+		n, err := strconv.ParseInt(end, 10, 64)
+		if err != nil || n <= 0 {
+			return 0, 0, errRangeNotSatisfiable
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, n, nil
+	}
+
+	offset, err = strconv.ParseInt(start, 10, 64)
+	if err != nil || offset < 0 || offset >= size {
+		return 0, 0, errRangeNotSatisfiable
+	}
+	last := size - 1
+	if end != "" {
+		last, err = strconv.ParseInt(end, 10, 64)
+		if err != nil || last < offset {
+			return 0, 0, errRangeNotSatisfiable
+		}
+		if last >= size {
+			last = size - 1
+		}
+	}
+	return offset, last - offset + 1, nil
+}
+
+func (r *todoHandler) GetAttachments(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var uri dto.TodoUri
+	if err := c.ShouldBindUri(&uri); err != nil {
+		r.log.Error("http - v1 - GetAttachments: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	_, role, code, err := r.todoAccess(c.Request.Context(), uri.Id, account)
+	if err != nil {
+		r.log.Error("http - v1 - GetAttachments: %v", err)
+		c.JSON(http.StatusOK, NewResp(code, err.Error()))
+		return
+	}
+	if role < entity.MemberRoleViewer {
+		err = errors.New("No access")
+		r.log.Error("http - v1 - GetAttachments: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+		return
+	}
+
+	resp, err := r.attachmentUsecase.GetAttachmentAllByTodo(c.Request.Context(), uri.Id)
+	if err != nil {
+		r.log.Error("http - v1 - GetAttachments: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", resp))
+}
+
+func (r *todoHandler) UploadAttachment(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var uri dto.TodoUri
+	if err := c.ShouldBindUri(&uri); err != nil {
+		r.log.Error("http - v1 - UploadAttachment: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	_, role, code, err := r.todoAccess(c.Request.Context(), uri.Id, account)
+	if err != nil {
+		r.log.Error("http - v1 - UploadAttachment: %v", err)
+		c.JSON(http.StatusOK, NewResp(code, err.Error()))
+		return
+	}
+	if role < entity.MemberRoleEditor {
+		err = errors.New("No access")
+		r.log.Error("http - v1 - UploadAttachment: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, r.attachmentUsecase.MaxSize()+_multipartOverhead)
+	header, err := c.FormFile(_attachmentFormField)
+	if err != nil {
+		r.log.Error("http - v1 - UploadAttachment: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		r.log.Error("http - v1 - UploadAttachment: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+	defer file.Close()
+
+	attachment := entity.Attachment{
+		TodoId:     uri.Id,
+		UploaderId: account.Id,
+		Name:       header.Filename,
+		Size:       header.Size,
+	}
+	id, err := r.attachmentUsecase.UploadAttachment(c.Request.Context(), attachment, file)
+	if err != nil {
+		r.log.Error("http - v1 - UploadAttachment: %v", err)
+		if errors.Is(err, usecase.ErrAttachmentTooLarge) || errors.Is(err, usecase.ErrAttachmentType) {
+			c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+			return
+		}
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", CreateAttachmentResponse{id}))
+}
+
+// attachment loads the attachment of the todo from uri checking that account
+// has at least minRole on the todo.
+func (r *todoHandler) attachment(c *gin.Context, uri dto.AttachmentUri, account entity.Account, minRole entity.MemberRole) (*entity.Attachment, ErrCode, error) {
+	_, role, code, err := r.todoAccess(c.Request.Context(), uri.Id, account)
+	if err != nil {
+		return nil, code, err
+	}
+
+	attachment, err := r.attachmentUsecase.GetAttachment(c.Request.Context(), uri.AttachmentId)
+	if err != nil {
+		return nil, ErrCodeInternal, err
+	}
+	if attachment == nil || attachment.TodoId != uri.Id {
+		return nil, ErrCodeInternal, errors.New("not found")
+	}
+	if role < minRole && attachment.UploaderId != account.Id {
+		return nil, ErrCodeNoAccess, errors.New("No access")
+	}
+	return attachment, ErrCodeNone, nil
+}
+
+func (r *todoHandler) DownloadAttachment(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var uri dto.AttachmentUri
+	if err := c.ShouldBindUri(&uri); err != nil {
+		r.log.Error("http - v1 - DownloadAttachment: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	attachment, code, err := r.attachment(c, uri, account, entity.MemberRoleViewer)
+	if err != nil {
+		r.log.Error("http - v1 - DownloadAttachment: %v", err)
+		c.JSON(http.StatusOK, NewResp(code, err.Error()))
+		return
+	}
+
+	offset, length, err := parseRange(c.GetHeader("Range"), attachment.Size)
+	if err != nil {
+		c.Header("Content-Range", fmt.Sprintf("bytes */%d", attachment.Size))
+		c.AbortWithStatus(http.StatusRequestedRangeNotSatisfiable)
+		return
+	}
+
+	body, err := r.attachmentUsecase.OpenAttachment(c.Request.Context(), *attachment, offset, length)
+	if err != nil {
+		r.log.Error("http - v1 - DownloadAttachment: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+	defer body.Close()
+
+	status := http.StatusOK
+	size := attachment.Size
+	if length >= 0 {
+		status = http.StatusPartialContent
+		size = length
+		c.Header("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+length-1, attachment.Size))
+	}
+	c.Header("Accept-Ranges", "bytes")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name}))
+	c.DataFromReader(status, size, attachment.ContentType, body, nil)
+}
+
+func (r *todoHandler) DeleteAttachment(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var uri dto.AttachmentUri
+	if err := c.ShouldBindUri(&uri); err != nil {
+		r.log.Error("http - v1 - DeleteAttachment: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	attachment, code, err := r.attachment(c, uri, account, entity.MemberRoleEditor)
+	if err != nil {
+		r.log.Error("http - v1 - DeleteAttachment: %v", err)
+		c.JSON(http.StatusOK, NewResp(code, err.Error()))
+		return
+	}
+
+	if err = r.attachmentUsecase.DeleteAttachment(c.Request.Context(), *attachment); err != nil {
+		r.log.Error("http - v1 - DeleteAttachment: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok"))
+}
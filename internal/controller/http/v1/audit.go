@@ -0,0 +1,131 @@
+package v1
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/controller/http/dto"
+	"testcode/test3/internal/domain/entity"
+)
+
+const _defaultAuditLimit = 50
+
+var _auditCsvHeader = []string{"id", "created_at", "actor_id", "action", "target_type", "target_id", "request_id", "client_ip", "summary"}
+
+func auditFilter(req dto.GetAuditRequest) entity.AuditFilter {
+	return entity.AuditFilter{
+		ActorId:    req.ActorId,
+		Action:     req.Action,
+		TargetType: req.TargetType,
+		TargetId:   req.TargetId,
+		From:       req.From,
+		To:         req.To,
+	}
+}
+
+func (r *todoHandler) GetAudit(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+	if !account.IsAdmin() {
+		err := errors.New("No access")
+		r.log.Error("http - v1 - GetAudit: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+		return
+	}
+
+	var req dto.GetAuditRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		r.log.Error("http - v1 - GetAudit: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = _defaultAuditLimit
+	}
+
+	items, total, err := r.auditUsecase.GetAuditPage(c.Request.Context(), auditFilter(req), req.Page, req.Limit)
+	if err != nil {
+		r.log.Error("http - v1 - GetAudit: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", AuditResponse{items, total, req.Page, req.Limit}))
+}
+
+// ExportAudit streams every matching entry as NDJSON or CSV. Errors after
+// the first byte can only be logged.
+func (r *todoHandler) ExportAudit(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+	if !account.IsAdmin() {
+		err := errors.New("No access")
+		r.log.Error("http - v1 - ExportAudit: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+		return
+	}
+
+	var req dto.GetAuditRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		r.log.Error("http - v1 - ExportAudit: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	var (
+		write func(entity.AuditEntry) error
+		flush = func() error { return nil }
+	)
+	if req.Format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="audit.csv"`)
+		w := csv.NewWriter(c.Writer)
+		if err := w.Write(_auditCsvHeader); err != nil {
+			r.log.Error("http - v1 - ExportAudit: %v", err)
+			return
+		}
+		write = func(e entity.AuditEntry) error {
+			summary, err := json.Marshal(e.Summary)
+			if err != nil {
+				return err
+			}
+			return w.Write([]string{
+				strconv.FormatUint(uint64(e.Id), 10),
+				e.CreatedAt.UTC().Format(time.RFC3339),
+				strconv.FormatUint(uint64(e.ActorId), 10),
+				e.Action,
+				e.TargetType,
+				strconv.FormatUint(uint64(e.TargetId), 10),
+				e.RequestId,
+				e.ClientIp,
+				string(summary),
+			})
+		}
+		flush = func() error {
+			w.Flush()
+			return w.Error()
+		}
+	} else {
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Content-Disposition", `attachment; filename="audit.ndjson"`)
+		enc := json.NewEncoder(c.Writer)
+		write = func(e entity.AuditEntry) error {
+			return enc.Encode(e)
+		}
+	}
+	c.Status(http.StatusOK)
+
+	err := r.auditUsecase.ExportAudit(c.Request.Context(), auditFilter(req), write)
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		r.log.Error("http - v1 - ExportAudit: %v", err)
+	}
+}
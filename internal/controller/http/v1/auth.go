@@ -2,16 +2,25 @@ package v1
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/domain/entity"
 )
 
 const HeaderAuthKey = "token"
 const UserKey = "userKey"
 
+// Auth lets requests to ignorePath through without a session, a path ending
+// with * ignores everything under it.
 func Auth(session SessionUsecase, ignorePath ...string) func(*gin.Context) {
 	m := make(map[string]struct{})
+	var prefixes []string
 	for _, v := range ignorePath {
+		if strings.HasSuffix(v, "*") {
+			prefixes = append(prefixes, strings.TrimSuffix(v, "*"))
+			continue
+		}
 		m[v] = struct{}{}
 	}
 	return func(c *gin.Context) {
@@ -20,6 +29,12 @@ func Auth(session SessionUsecase, ignorePath ...string) func(*gin.Context) {
 			c.Next()
 			return
 		}
+		for _, p := range prefixes {
+			if strings.HasPrefix(path, p) {
+				c.Next()
+				return
+			}
+		}
 		token := c.Request.Header.Get(HeaderAuthKey)
 		if token == "" {
 			c.AbortWithStatusJSON(http.StatusOK, NewResp(ErrCodeUnauthenticated, "invalid token"))
@@ -30,7 +45,16 @@ func Auth(session SessionUsecase, ignorePath ...string) func(*gin.Context) {
 			c.AbortWithStatusJSON(http.StatusOK, NewResp(ErrCodeUnauthenticated, "invalid token"))
 			return
 		}
-		c.Set(UserKey, account)
+		setAccount(c, account)
 		c.Next()
 	}
 }
+
+// setAccount makes account the caller of the request.
+func setAccount(c *gin.Context, account entity.Account) {
+	c.Set(UserKey, account)
+	meta := entity.AuditMetaFromContext(c.Request.Context())
+	meta.ActorId = account.Id
+	ctx := entity.WithAuditMeta(c.Request.Context(), meta)
+	c.Request = c.Request.WithContext(entity.WithTenant(ctx, account.TenantId))
+}
@@ -0,0 +1,115 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/controller/http/dto"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+)
+
+// bulkAccess applies the checks of the single todo endpoints to the operation.
+func (r *todoHandler) bulkAccess(ctx context.Context, op dto.BulkTodoOperation, account entity.Account) (ErrCode, error) {
+	if entity.TodoBulkOpType(op.Op) == entity.TodoBulkCreate {
+		if op.ProjectId == 0 {
+			return ErrCodeNone, nil
+		}
+		project, code, err := r.writableProject(ctx, op.ProjectId, account, entity.MemberRoleEditor)
+		if err != nil {
+			return code, err
+		}
+		if project.Archived {
+			return ErrCodeInvalidArgument, errors.New("project is archived")
+		}
+		return ErrCodeNone, nil
+	}
+
+	todo, role, code, err := r.todoAccess(ctx, op.Id, account)
+	if err != nil {
+		return code, err
+	}
+	switch entity.TodoBulkOpType(op.Op) {
+	case entity.TodoBulkDelete:
+		if role < entity.MemberRoleOwner {
+			return ErrCodeNoAccess, errors.New("No access")
+		}
+	case entity.TodoBulkUpdate, entity.TodoBulkComplete:
+		if role < entity.MemberRoleEditor {
+			// assignees may only move the todo between statuses
+			if !todo.HasAssignee(account.Id) || op.Name != "" || op.Desc != "" {
+				return ErrCodeNoAccess, errors.New("No access")
+			}
+		}
+	}
+	return ErrCodeNone, nil
+}
+
+func (r *todoHandler) BulkTodos(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var req dto.BulkTodoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.log.Error("http - v1 - BulkTodos: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	results := make([]entity.TodoBulkResult, len(req.Operations))
+	ops := make([]entity.TodoBulkOp, 0, len(req.Operations))
+	// index of every passed operation in the request
+	indexes := make([]int, 0, len(req.Operations))
+	for i, op := range req.Operations {
+		results[i] = entity.TodoBulkResult{Index: i, Op: entity.TodoBulkOpType(op.Op), Id: op.Id}
+		code, err := r.bulkAccess(c.Request.Context(), op, account)
+		if err != nil {
+			r.log.Error("http - v1 - BulkTodos: %v; index=%v", err, i)
+			results[i].Error = err.Error()
+			if req.Atomic {
+				c.JSON(http.StatusOK, NewResp(code, (&usecase.BulkError{Index: i, Err: err}).Error(),
+					BulkTodoResponse{results[i : i+1]}))
+				return
+			}
+			continue
+		}
+		ops = append(ops, entity.TodoBulkOp{
+			Op: entity.TodoBulkOpType(op.Op),
+			Todo: entity.Todo{
+				Id:        op.Id,
+				ProjectId: op.ProjectId,
+				Name:      op.Name,
+				Desc:      op.Desc,
+				Status:    entity.TodoStatus(op.Status),
+				Version:   op.Version,
+			},
+		})
+		indexes = append(indexes, i)
+	}
+
+	done, err := r.todoUsecase.BulkTodo(c.Request.Context(), ops, req.Atomic, account.Id)
+	if err != nil {
+		r.log.Error("http - v1 - BulkTodos: %v", err)
+		var bulkErr *usecase.BulkError
+		if !errors.As(err, &bulkErr) {
+			c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+			return
+		}
+		failed := results[indexes[bulkErr.Index]]
+		failed.Error = bulkErr.Err.Error()
+		err = &usecase.BulkError{Index: failed.Index, Err: bulkErr.Err}
+		if errors.Is(err, usecase.ErrTodoVersionConflict) {
+			c.JSON(http.StatusConflict, NewResp(ErrCodeConflict, err.Error(), BulkTodoResponse{[]entity.TodoBulkResult{failed}}))
+			return
+		}
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error(), BulkTodoResponse{[]entity.TodoBulkResult{failed}}))
+		return
+	}
+	for i, res := range done {
+		res.Index = indexes[i]
+		results[res.Index] = res
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", BulkTodoResponse{results}))
+}
@@ -0,0 +1,117 @@
+package v1_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/adapters/db/session"
+	"testcode/test3/internal/controller/http/dto"
+	v1 "testcode/test3/internal/controller/http/v1"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+	"testcode/test3/pkg/logger"
+)
+
+// BulkTodo runs the operations like the usecase, an atomic batch stops at
+// the first version conflict.
+func (r *memTodos) BulkTodo(ctx context.Context, ops []entity.TodoBulkOp, atomic bool, actorID uint) ([]entity.TodoBulkResult, error) {
+	results := make([]entity.TodoBulkResult, len(ops))
+	for i, op := range ops {
+		results[i] = entity.TodoBulkResult{Index: i, Op: op.Op, Id: op.Todo.Id}
+		var err error
+		switch op.Op {
+		case entity.TodoBulkCreate:
+			op.Todo.OwnerId = actorID
+			results[i].Id, err = r.CreateTodo(ctx, op.Todo)
+		case entity.TodoBulkComplete:
+			status := entity.TodoStatusDone
+			_, err = r.PatchTodo(ctx, op.Todo.Id, entity.TodoPatch{Status: &status, Version: op.Todo.Version}, actorID)
+		}
+		if err != nil && atomic {
+			return nil, &usecase.BulkError{Index: i, Err: err}
+		}
+		if err != nil {
+			results[i].Error = err.Error()
+		}
+	}
+	return results, nil
+}
+
+func TestBulkTodos(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logger.New("error")
+	todos := &memTodos{nextID: 2, todos: map[uint]entity.Todo{
+		1: {Id: 1, OwnerId: 2, Name: "of alice", Status: entity.TodoStatusDefault, Version: 1},
+		2: {Id: 2, OwnerId: 3, Name: "of bob", Status: entity.TodoStatusDefault, Version: 1},
+	}}
+	orgs := &orgStorage{orgs: map[uint][]entity.OrgAccount{2: {{OrgId: 1, AccountId: 2, Role: entity.AccountTypeUser}}}}
+	accounts := accountUsecase{accounts: map[string]entity.Account{
+		"alice": {Id: 2, Name: "alice", Password: "pw", AccountType: entity.AccountTypeUser},
+	}}
+	e := gin.New()
+	v1.NewRouter(e, log, accounts, todos, nil, ownerRoles{}, usecase.NewOrgUsecase(log, orgs, nopAuditor{}),
+		nil, nil, nil, nil, nil, nil, usecase.NewSessionUsecase(session.NewSessionStorage()), nil, nil)
+	token := (&orgFixture{engine: e, orgs: orgs}).login(t, "alice", 0)
+
+	bulk := func(req dto.BulkTodoRequest) (int, v1.ErrCode, []entity.TodoBulkResult) {
+		body, _ := json.Marshal(req)
+		hreq := httptest.NewRequest(http.MethodPost, "/v1/todos/bulk", bytes.NewReader(body))
+		hreq.Header.Set("Content-Type", "application/json")
+		hreq.Header.Set(v1.HeaderAuthKey, token)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, hreq)
+		var resp struct {
+			Code v1.ErrCode          `json:"code"`
+			Data v1.BulkTodoResponse `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%v: %s", err, w.Body.String())
+		}
+		return w.Code, resp.Code, resp.Data.Results
+	}
+
+	// per item: the operation failing its access check keeps its index, the
+	// ones after it get the results of the usecase
+	status, code, results := bulk(dto.BulkTodoRequest{Operations: []dto.BulkTodoOperation{
+		{Op: "complete", Id: 2},
+		{Op: "complete", Id: 1, Version: 5},
+		{Op: "create", Name: "new", Desc: "desc"},
+	}})
+	if status != http.StatusOK || code != v1.ErrCodeNone || len(results) != 3 {
+		t.Fatalf("per item: status %d, code %d, results %+v", status, code, results)
+	}
+	if results[0].Index != 0 || results[0].Id != 2 || results[0].Error == "" {
+		t.Errorf("per item: result 0 %+v, want no access to todo 2", results[0])
+	}
+	if results[1].Index != 1 || results[1].Error != usecase.ErrTodoVersionConflict.Error() {
+		t.Errorf("per item: result 1 %+v, want a version conflict", results[1])
+	}
+	if results[2].Index != 2 || results[2].Id != 3 || results[2].Error != "" {
+		t.Errorf("per item: result 2 %+v, want todo 3 created", results[2])
+	}
+
+	// atomic: a failed access check aborts before anything runs
+	status, code, results = bulk(dto.BulkTodoRequest{Atomic: true, Operations: []dto.BulkTodoOperation{
+		{Op: "create", Name: "never", Desc: "desc"},
+		{Op: "complete", Id: 2},
+	}})
+	if code != v1.ErrCodeNoAccess || len(results) != 1 || results[0].Index != 1 || len(todos.todos) != 3 {
+		t.Errorf("atomic access: status %d, code %d, results %+v, %d todos", status, code, results, len(todos.todos))
+	}
+
+	// atomic: a version conflict answers 409 with the failed operation
+	status, code, results = bulk(dto.BulkTodoRequest{Atomic: true, Operations: []dto.BulkTodoOperation{
+		{Op: "complete", Id: 3},
+		{Op: "complete", Id: 1, Version: 5},
+	}})
+	if status != http.StatusConflict || code != v1.ErrCodeConflict || len(results) != 1 || results[0].Index != 1 ||
+		results[0].Id != 1 || results[0].Error == "" {
+		t.Errorf("atomic conflict: status %d, code %d, results %+v, want %d for operation 1", status, code, results,
+			http.StatusConflict)
+	}
+}
@@ -0,0 +1,701 @@
+package v1
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+	"testcode/test3/pkg/dav"
+	"testcode/test3/pkg/ical"
+)
+
+const (
+	_davPrincipal  = "/dav/principal/"
+	_davHome       = "/dav/calendars/"
+	_davCollection = "/dav/calendars/todos/"
+)
+
+var _davMethods = []string{http.MethodOptions, http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, "PROPFIND", "REPORT"}
+
+// davSessions maps a hash of the Basic credentials to a session key, so
+// clients polling with Basic auth are not logged in, and audited, on every
+// request.
+type davSessions struct {
+	mu sync.Mutex
+	m  map[string]string
+}
+
+func newDavSessions() *davSessions {
+	return &davSessions{m: make(map[string]string)}
+}
+
+func (r *davSessions) get(key string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, ok := r.m[key]
+	return v, ok
+}
+
+func (r *davSessions) set(key string, token string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.m[key] = token
+}
+
+func davName(local string) xml.Name {
+	return xml.Name{Space: dav.NS, Local: local}
+}
+
+func calName(local string) xml.Name {
+	return xml.Name{Space: dav.CalDAVNS, Local: local}
+}
+
+func davError(c *gin.Context, status int, err error) {
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.String(status, err.Error())
+}
+
+// davUser splits the Basic auth user "name" or "name@org_id".
+func davUser(user string) (string, uint) {
+	i := strings.LastIndexByte(user, '@')
+	if i < 0 {
+		return user, 0
+	}
+	orgID, err := strconv.ParseUint(user[i+1:], 10, 32)
+	if err != nil {
+		return user, 0
+	}
+	return user[:i], uint(orgID)
+}
+
+// DavAuth authenticates CalDAV clients with Basic auth, the user is the
+// account name, optionally followed by @org_id like the org_id of Login.
+// OPTIONS is answered without credentials for clients probing the server.
+func (r *todoHandler) DavAuth(c *gin.Context) {
+	if c.Request.Method == http.MethodOptions {
+		c.Next()
+		return
+	}
+	user, password, ok := c.Request.BasicAuth()
+	if !ok {
+		c.Header("WWW-Authenticate", `Basic realm="todo"`)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	cacheKey := fmt.Sprintf("%x", sha256.Sum256([]byte(c.Request.Header.Get("Authorization"))))
+	if token, ok := r.davSessions.get(cacheKey); ok {
+		if account, ok := r.sessionUsecase.Get(token); ok {
+			setAccount(c, account)
+			c.Next()
+			return
+		}
+	}
+
+	name, orgID := davUser(user)
+	ctx := entity.WithTenant(c.Request.Context(), orgID)
+	account, err := r.accountUsecase.Authenticate(ctx, name, password)
+	if err == nil {
+		var acc entity.Account
+		if acc, err = r.orgUsecase.EnterOrg(c.Request.Context(), *account, orgID); err == nil {
+			r.davSessions.set(cacheKey, r.sessionUsecase.Create(acc))
+			setAccount(c, acc)
+			c.Next()
+			return
+		}
+	}
+
+	r.log.Error("http - v1 - DavAuth: %v", err)
+	if errors.Is(err, usecase.ErrAccountNotFound) || errors.Is(err, usecase.ErrOrgRequired) || errors.Is(err, usecase.ErrOrgNoAccess) {
+		c.Header("WWW-Authenticate", `Basic realm="todo"`)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	c.AbortWithStatus(http.StatusInternalServerError)
+}
+
+// DavWellKnown points clients doing service discovery at the principal.
+func (r *todoHandler) DavWellKnown(c *gin.Context) {
+	c.Redirect(http.StatusMovedPermanently, _davPrincipal)
+}
+
+// Dav serves the todos the account sees as a single CalDAV calendar of VTODO
+// resources named <id>.ics, or the name the client created them under.
+func (r *todoHandler) Dav(c *gin.Context) {
+	p := strings.Trim(c.Param("path"), "/")
+	method := c.Request.Method
+	if method == http.MethodOptions {
+		c.Header("DAV", "1, 3, calendar-access")
+		c.Header("Allow", strings.Join(_davMethods, ", "))
+		c.Status(http.StatusOK)
+		return
+	}
+
+	switch {
+	case p == "" || p == "principal" || p == "calendars":
+		if method == "PROPFIND" {
+			r.davPropfindHome(c, "/dav/"+p)
+			return
+		}
+	case p == "calendars/todos":
+		switch method {
+		case "PROPFIND":
+			r.davPropfindCollection(c)
+			return
+		case "REPORT":
+			r.davReport(c)
+			return
+		}
+	case strings.HasPrefix(p, "calendars/todos/") && !strings.Contains(strings.TrimPrefix(p, "calendars/todos/"), "/"):
+		file := strings.TrimPrefix(p, "calendars/todos/")
+		switch method {
+		case "PROPFIND":
+			r.davPropfindTodo(c, file)
+			return
+		case http.MethodGet, http.MethodHead:
+			r.davGetTodo(c, file)
+			return
+		case http.MethodPut:
+			r.davPutTodo(c, file)
+			return
+		case http.MethodDelete:
+			r.davDeleteTodo(c, file)
+			return
+		}
+	default:
+		c.Status(http.StatusNotFound)
+		return
+	}
+	c.Header("Allow", strings.Join(_davMethods, ", "))
+	c.Status(http.StatusMethodNotAllowed)
+}
+
+// davResources maps todo ids to the resources clients created them as, other
+// todos are served as <id>.ics under their own UID.
+type davResources map[uint]entity.DavResource
+
+func (m davResources) href(todo entity.Todo) string {
+	if res, ok := m[todo.Id]; ok {
+		return _davCollection + res.Name
+	}
+	return fmt.Sprintf("%s%d.ics", _davCollection, todo.Id)
+}
+
+func (m davResources) uid(tenantID uint, todo entity.Todo) string {
+	if res, ok := m[todo.Id]; ok && res.Uid != "" {
+		return res.Uid
+	}
+	return todoUID(tenantID, todo)
+}
+
+// todoID resolves the resource name, zero when it names no todo.
+func (m davResources) todoID(file string) uint {
+	for id, res := range m {
+		if res.Name == file {
+			return id
+		}
+	}
+	return davTodoID(file)
+}
+
+// davTodoID parses the resource name <id>.ics, zero for other names.
+func davTodoID(file string) uint {
+	id, err := strconv.ParseUint(strings.TrimSuffix(file, ".ics"), 10, 32)
+	if err != nil || !strings.HasSuffix(file, ".ics") {
+		return 0
+	}
+	return uint(id)
+}
+
+// davTodos returns the todos the account sees, like GetTodos, and the
+// resources of the tenant.
+func (r *todoHandler) davTodos(c *gin.Context, account entity.Account) ([]entity.Todo, davResources, error) {
+	var (
+		todos []entity.Todo
+		err   error
+	)
+	if account.IsAdmin() {
+		todos, err = r.todoUsecase.GetTodoAll(c.Request.Context())
+	} else {
+		todos, err = r.todoUsecase.GetTodoAllVisible(c.Request.Context(), account.Id)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	all, err := r.calendarUsecase.GetDavResources(c.Request.Context())
+	if err != nil {
+		return nil, nil, err
+	}
+	resources := make(davResources, len(all))
+	for _, res := range all {
+		resources[res.TodoId] = res
+	}
+	return todos, resources, nil
+}
+
+// davTodo loads the resource and the role of the account on it, the status
+// is set when the request cannot go on.
+func (r *todoHandler) davTodo(c *gin.Context, file string, account entity.Account) (*entity.Todo, davResources, entity.MemberRole, int) {
+	ctx := c.Request.Context()
+	res, err := r.calendarUsecase.GetDavResource(ctx, file)
+	if err != nil {
+		r.log.Error("http - v1 - Dav: %v", err)
+		return nil, nil, entity.MemberRoleNone, http.StatusInternalServerError
+	}
+	id := davTodoID(file)
+	if res != nil {
+		id = res.TodoId
+	} else if id != 0 {
+		if res, err = r.calendarUsecase.GetDavResourceByTodo(ctx, id); err != nil {
+			r.log.Error("http - v1 - Dav: %v", err)
+			return nil, nil, entity.MemberRoleNone, http.StatusInternalServerError
+		}
+	}
+	if id == 0 {
+		return nil, nil, entity.MemberRoleNone, http.StatusNotFound
+	}
+	resources := davResources{}
+	if res != nil {
+		resources[res.TodoId] = *res
+	}
+
+	todo, err := r.todoUsecase.GetTodo(ctx, id)
+	if err != nil {
+		r.log.Error("http - v1 - Dav: %v", err)
+		return nil, nil, entity.MemberRoleNone, http.StatusInternalServerError
+	}
+	if todo == nil {
+		return nil, nil, entity.MemberRoleNone, http.StatusNotFound
+	}
+	role, err := r.memberUsecase.TodoRole(ctx, account, *todo)
+	if err != nil {
+		r.log.Error("http - v1 - Dav: %v", err)
+		return nil, nil, entity.MemberRoleNone, http.StatusInternalServerError
+	}
+	if role < entity.MemberRoleViewer {
+		return nil, nil, role, http.StatusNotFound
+	}
+	return todo, resources, role, 0
+}
+
+func (r *todoHandler) davCalendar(tenantID uint, resources davResources, todos ...entity.Todo) ([]byte, error) {
+	cal := ical.Component{Name: "VCALENDAR"}
+	cal.Add("VERSION", "2.0")
+	cal.Add("PRODID", _calendarProdId)
+	stamp := time.Now()
+	for _, t := range todos {
+		cal.Components = append(cal.Components, todoVTodo(resources.uid(tenantID, t), t, stamp))
+	}
+	var b bytes.Buffer
+	err := ical.Encode(&b, cal)
+	return b.Bytes(), err
+}
+
+// davTodoProps lists the properties of the resource, calendar-data only when
+// asked for by name.
+func (r *todoHandler) davTodoProps(account entity.Account, resources davResources, todo entity.Todo, names dav.PropNames) ([]dav.Prop, error) {
+	props := []dav.Prop{
+		{Name: davName("resourcetype")},
+		{Name: davName("getetag"), Inner: dav.Text(todoETag(todo))},
+		{Name: davName("getcontenttype"), Inner: "text/calendar; charset=utf-8; component=VTODO"},
+	}
+	for _, name := range names {
+		if name == calName("calendar-data") {
+			data, err := r.davCalendar(account.TenantId, resources, todo)
+			if err != nil {
+				return nil, err
+			}
+			props = append(props, dav.Prop{Name: name, Inner: dav.Text(string(data))})
+		}
+	}
+	return props, nil
+}
+
+func davDepth(c *gin.Context) string {
+	if d := c.Request.Header.Get("Depth"); d != "" {
+		return d
+	}
+	return "infinity"
+}
+
+func (r *todoHandler) writeMultistatus(c *gin.Context, responses []dav.Response) {
+	c.Header("Content-Type", "application/xml; charset=utf-8")
+	c.Status(http.StatusMultiStatus)
+	if err := dav.WriteMultistatus(c.Writer, responses); err != nil {
+		r.log.Error("http - v1 - Dav: %v", err)
+	}
+}
+
+func (r *todoHandler) davCollectionProps(account entity.Account, todos []entity.Todo) []dav.Prop {
+	// the ctag changes whenever a todo is added, changed or removed
+	h := sha256.New()
+	for _, t := range todos {
+		fmt.Fprintf(h, "%d:%d;", t.Id, t.Version)
+	}
+	return []dav.Prop{
+		{Name: davName("resourcetype"), Inner: "<d:collection/><c:calendar/>"},
+		{Name: davName("displayname"), Inner: dav.Text("Todos of " + account.Name)},
+		{Name: davName("current-user-principal"), Inner: dav.Href(_davPrincipal)},
+		{Name: davName("current-user-privilege-set"), Inner: "<d:privilege><d:read/></d:privilege><d:privilege><d:write/></d:privilege>"},
+		{Name: calName("supported-calendar-component-set"), Inner: `<c:comp name="VTODO"/>`},
+		{Name: xml.Name{Space: dav.CalServerNS, Local: "getctag"}, Inner: fmt.Sprintf("%x", h.Sum(nil))},
+	}
+}
+
+func (r *todoHandler) davPropfindHome(c *gin.Context, href string) {
+	account := c.MustGet(UserKey).(entity.Account)
+	req, err := dav.ReadPropfind(c.Request.Body)
+	if err != nil {
+		r.log.Error("http - v1 - Dav: %v", err)
+		davError(c, http.StatusBadRequest, err)
+		return
+	}
+	var names dav.PropNames
+	if req.Prop != nil {
+		names = *req.Prop
+	}
+
+	resourceType := "<d:collection/>"
+	if href == "/dav/principal" {
+		resourceType = "<d:collection/><d:principal/>"
+	}
+	props := []dav.Prop{
+		{Name: davName("resourcetype"), Inner: resourceType},
+		{Name: davName("displayname"), Inner: dav.Text(account.Name)},
+		{Name: davName("current-user-principal"), Inner: dav.Href(_davPrincipal)},
+		{Name: davName("principal-URL"), Inner: dav.Href(_davPrincipal)},
+		{Name: calName("calendar-home-set"), Inner: dav.Href(_davHome)},
+	}
+	responses := []dav.Response{dav.Select(strings.TrimSuffix(href, "/")+"/", props, names)}
+
+	if href == "/dav/calendars" && davDepth(c) != "0" {
+		todos, _, err := r.davTodos(c, account)
+		if err != nil {
+			r.log.Error("http - v1 - Dav: %v", err)
+			davError(c, http.StatusInternalServerError, err)
+			return
+		}
+		responses = append(responses, dav.Select(_davCollection, r.davCollectionProps(account, todos), names))
+	}
+	r.writeMultistatus(c, responses)
+}
+
+func (r *todoHandler) davPropfindCollection(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+	req, err := dav.ReadPropfind(c.Request.Body)
+	if err != nil {
+		r.log.Error("http - v1 - Dav: %v", err)
+		davError(c, http.StatusBadRequest, err)
+		return
+	}
+	var names dav.PropNames
+	if req.Prop != nil {
+		names = *req.Prop
+	}
+
+	todos, resources, err := r.davTodos(c, account)
+	if err != nil {
+		r.log.Error("http - v1 - Dav: %v", err)
+		davError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses := []dav.Response{dav.Select(_davCollection, r.davCollectionProps(account, todos), names)}
+	if davDepth(c) != "0" {
+		for _, t := range todos {
+			props, err := r.davTodoProps(account, resources, t, names)
+			if err != nil {
+				r.log.Error("http - v1 - Dav: %v", err)
+				davError(c, http.StatusInternalServerError, err)
+				return
+			}
+			responses = append(responses, dav.Select(resources.href(t), props, names))
+		}
+	}
+	r.writeMultistatus(c, responses)
+}
+
+func (r *todoHandler) davPropfindTodo(c *gin.Context, file string) {
+	account := c.MustGet(UserKey).(entity.Account)
+	req, err := dav.ReadPropfind(c.Request.Body)
+	if err != nil {
+		r.log.Error("http - v1 - Dav: %v", err)
+		davError(c, http.StatusBadRequest, err)
+		return
+	}
+	var names dav.PropNames
+	if req.Prop != nil {
+		names = *req.Prop
+	}
+
+	todo, resources, _, status := r.davTodo(c, file, account)
+	if status != 0 {
+		c.Status(status)
+		return
+	}
+	props, err := r.davTodoProps(account, resources, *todo, names)
+	if err != nil {
+		r.log.Error("http - v1 - Dav: %v", err)
+		davError(c, http.StatusInternalServerError, err)
+		return
+	}
+	r.writeMultistatus(c, []dav.Response{dav.Select(resources.href(*todo), props, names)})
+}
+
+// davReport answers calendar-multiget and calendar-query, filters other than
+// the component are not applied.
+func (r *todoHandler) davReport(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+	req, err := dav.ReadReport(c.Request.Body)
+	if err != nil {
+		r.log.Error("http - v1 - Dav: %v", err)
+		davError(c, http.StatusBadRequest, err)
+		return
+	}
+	var names dav.PropNames
+	if req.Prop != nil {
+		names = *req.Prop
+	}
+
+	todos, resources, err := r.davTodos(c, account)
+	if err != nil {
+		r.log.Error("http - v1 - Dav: %v", err)
+		davError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	var selected []entity.Todo
+	var responses []dav.Response
+	switch req.XMLName {
+	case calName("calendar-multiget"):
+		byID := make(map[uint]entity.Todo, len(todos))
+		for _, t := range todos {
+			byID[t.Id] = t
+		}
+		for _, href := range req.Hrefs {
+			t, ok := byID[resources.todoID(path.Base(href))]
+			if !ok {
+				responses = append(responses, dav.Response{Href: href, Status: http.StatusNotFound})
+				continue
+			}
+			selected = append(selected, t)
+		}
+	case calName("calendar-query"):
+		selected = todos
+		if req.Filter != nil {
+			for _, f := range req.Filter.CompFilter.CompFilters {
+				if f.Name != "VTODO" {
+					selected = nil
+				}
+			}
+		}
+	default:
+		err = fmt.Errorf("report %s is not supported", req.XMLName.Local)
+		r.log.Error("http - v1 - Dav: %v", err)
+		davError(c, http.StatusForbidden, err)
+		return
+	}
+
+	for _, t := range selected {
+		props, err := r.davTodoProps(account, resources, t, names)
+		if err != nil {
+			r.log.Error("http - v1 - Dav: %v", err)
+			davError(c, http.StatusInternalServerError, err)
+			return
+		}
+		responses = append(responses, dav.Select(resources.href(t), props, names))
+	}
+	r.writeMultistatus(c, responses)
+}
+
+func (r *todoHandler) davGetTodo(c *gin.Context, file string) {
+	account := c.MustGet(UserKey).(entity.Account)
+	todo, resources, _, status := r.davTodo(c, file, account)
+	if status != 0 {
+		c.Status(status)
+		return
+	}
+	data, err := r.davCalendar(account.TenantId, resources, *todo)
+	if err != nil {
+		r.log.Error("http - v1 - Dav: %v", err)
+		davError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Header(HeaderETag, todoETag(*todo))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", data)
+}
+
+// davPutTodo updates the todo of the resource or, for a resource that does
+// not exist, creates one served under the name and UID of the request.
+func (r *todoHandler) davPutTodo(c *gin.Context, file string) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	components, err := ical.Decode(c.Request.Body)
+	if err != nil {
+		r.log.Error("http - v1 - Dav: %v", err)
+		davError(c, http.StatusBadRequest, err)
+		return
+	}
+	rows := vtodoRows(components)
+	if len(rows) != 1 {
+		err = errors.New("the resource must hold exactly one VTODO")
+		r.log.Error("http - v1 - Dav: %v", err)
+		davError(c, http.StatusForbidden, err)
+		return
+	}
+	if rows[0].Error != "" {
+		err = errors.New(rows[0].Error)
+		r.log.Error("http - v1 - Dav: %v", err)
+		davError(c, http.StatusBadRequest, err)
+		return
+	}
+	in := rows[0].Todo
+
+	todo, _, role, status := r.davTodo(c, file, account)
+	if status == http.StatusNotFound {
+		if c.Request.Header.Get(HeaderIfMatch) != "" {
+			c.Status(http.StatusPreconditionFailed)
+			return
+		}
+		// the name may serve a todo the account cannot see or one in the
+		// trash, names like <id>.ics are left to the todos of the id
+		res, err := r.calendarUsecase.GetDavResource(c.Request.Context(), file)
+		if err != nil {
+			r.log.Error("http - v1 - Dav: %v", err)
+			davError(c, http.StatusInternalServerError, err)
+			return
+		}
+		if res != nil || davTodoID(file) != 0 {
+			davError(c, http.StatusConflict, usecase.ErrDavResourceTaken)
+			return
+		}
+		r.davCreateTodo(c, account, entity.DavResource{Name: file, Uid: vtodoUID(components)}, in)
+		return
+	}
+	if status != 0 {
+		c.Status(status)
+		return
+	}
+	if c.Request.Header.Get("If-None-Match") == "*" {
+		c.Status(http.StatusPreconditionFailed)
+		return
+	}
+	version, err := ifMatch(c)
+	if err != nil {
+		davError(c, http.StatusBadRequest, err)
+		return
+	}
+	if version != 0 && version != todo.Version {
+		c.Status(http.StatusPreconditionFailed)
+		return
+	}
+
+	patch := todoChanges(*todo, in)
+	if role < entity.MemberRoleEditor {
+		// assignees may only move the todo between statuses
+		if !todo.HasAssignee(account.Id) || !patch.StatusOnly() {
+			c.Status(http.StatusForbidden)
+			return
+		}
+	}
+	if patch.Empty() {
+		c.Header(HeaderETag, todoETag(*todo))
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	patch.Version = todo.Version
+	updated, err := r.todoUsecase.PatchTodo(c.Request.Context(), todo.Id, patch, account.Id)
+	if err != nil {
+		r.log.Error("http - v1 - Dav: %v", err)
+		switch {
+		case errors.Is(err, usecase.ErrTodoInvalid):
+			davError(c, http.StatusBadRequest, err)
+		case errors.Is(err, usecase.ErrTodoVersionConflict):
+			c.Status(http.StatusPreconditionFailed)
+		default:
+			davError(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	c.Header(HeaderETag, todoETag(*updated))
+	c.Status(http.StatusNoContent)
+}
+
+// davCreateTodo creates the todo and maps the resource to it. When that fails
+// the todo is still served as <id>.ics, given in Location.
+func (r *todoHandler) davCreateTodo(c *gin.Context, account entity.Account, res entity.DavResource, in entity.Todo) {
+	in.OwnerId = account.Id
+	id, err := r.todoUsecase.CreateTodo(c.Request.Context(), in)
+	if err != nil {
+		r.log.Error("http - v1 - Dav: %v", err)
+		if errors.Is(err, usecase.ErrTodoInvalid) {
+			davError(c, http.StatusBadRequest, err)
+			return
+		}
+		davError(c, http.StatusInternalServerError, err)
+		return
+	}
+	in.Id = id
+	in.Version = 1
+	res.TodoId = id
+	if err = r.calendarUsecase.SetDavResource(c.Request.Context(), res); err != nil {
+		r.log.Error("http - v1 - Dav: %v", err)
+		c.Header("Location", davResources{}.href(in))
+	}
+	c.Header(HeaderETag, todoETag(in))
+	c.Status(http.StatusCreated)
+}
+
+func (r *todoHandler) davDeleteTodo(c *gin.Context, file string) {
+	account := c.MustGet(UserKey).(entity.Account)
+	todo, _, role, status := r.davTodo(c, file, account)
+	if status != 0 {
+		c.Status(status)
+		return
+	}
+	if role < entity.MemberRoleOwner {
+		c.Status(http.StatusForbidden)
+		return
+	}
+	version, err := ifMatch(c)
+	if err != nil {
+		davError(c, http.StatusBadRequest, err)
+		return
+	}
+	if version != 0 && version != todo.Version {
+		c.Status(http.StatusPreconditionFailed)
+		return
+	}
+
+	if err = r.todoUsecase.DeleteTodo(c.Request.Context(), todo.Id); err != nil {
+		r.log.Error("http - v1 - Dav: %v", err)
+		davError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// vtodoUID returns the UID of the first VTODO, wherever it is nested.
+func vtodoUID(components []ical.Component) string {
+	for i := range components {
+		if components[i].Name == "VTODO" {
+			return components[i].Text("UID")
+		}
+		if uid := vtodoUID(components[i].Components); uid != "" {
+			return uid
+		}
+	}
+	return ""
+}
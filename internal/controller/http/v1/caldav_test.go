@@ -0,0 +1,270 @@
+package v1_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/adapters/db/session"
+	v1 "testcode/test3/internal/controller/http/v1"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+	"testcode/test3/pkg/logger"
+)
+
+// memTodos keeps the todos in memory, deleted ones are dropped.
+type memTodos struct {
+	v1.TodoUsecase
+	mu     sync.Mutex
+	nextID uint
+	todos  map[uint]entity.Todo
+}
+
+func (r *memTodos) CreateTodo(_ context.Context, dto entity.Todo) (uint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	dto.Id, dto.Version = r.nextID, 1
+	r.todos[dto.Id] = dto
+	return dto.Id, nil
+}
+
+func (r *memTodos) GetTodo(_ context.Context, todoID uint) (*entity.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	todo, ok := r.todos[todoID]
+	if !ok {
+		return nil, nil
+	}
+	return &todo, nil
+}
+
+func (r *memTodos) GetTodoAllVisible(_ context.Context, accountID uint) ([]entity.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var ret []entity.Todo
+	for _, t := range r.todos {
+		if t.OwnerId == accountID {
+			ret = append(ret, t)
+		}
+	}
+	return ret, nil
+}
+
+func (r *memTodos) PatchTodo(_ context.Context, todoID uint, patch entity.TodoPatch, _ uint) (*entity.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	todo, ok := r.todos[todoID]
+	if !ok {
+		return nil, usecase.ErrTodoNotFound
+	}
+	if patch.Version != 0 && patch.Version != todo.Version {
+		return nil, usecase.ErrTodoVersionConflict
+	}
+	if patch.Name != nil {
+		todo.Name = *patch.Name
+	}
+	if patch.Desc != nil {
+		todo.Desc = *patch.Desc
+	}
+	if patch.Status != nil {
+		todo.Status = *patch.Status
+	}
+	todo.Version++
+	r.todos[todoID] = todo
+	return &todo, nil
+}
+
+func (r *memTodos) DeleteTodo(_ context.Context, todoID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.todos, todoID)
+	return nil
+}
+
+// ownerRoles makes owners the only members of their todos.
+type ownerRoles struct{ v1.MemberUsecase }
+
+func (ownerRoles) TodoRole(_ context.Context, account entity.Account, todo entity.Todo) (entity.MemberRole, error) {
+	if todo.OwnerId == account.Id {
+		return entity.MemberRoleOwner, nil
+	}
+	return entity.MemberRoleNone, nil
+}
+
+type davStorage struct {
+	mu        sync.Mutex
+	resources []entity.DavResource
+}
+
+func (r *davStorage) Set(_ context.Context, dto entity.DavResource) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, res := range r.resources {
+		if res.Name == dto.Name {
+			return false, nil
+		}
+	}
+	r.resources = append(r.resources, dto)
+	return true, nil
+}
+
+func (r *davStorage) find(match func(entity.DavResource) bool) *entity.DavResource {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, res := range r.resources {
+		if match(res) {
+			return &res
+		}
+	}
+	return nil
+}
+
+func (r *davStorage) GetByName(_ context.Context, name string) (*entity.DavResource, error) {
+	return r.find(func(res entity.DavResource) bool { return res.Name == name }), nil
+}
+
+func (r *davStorage) GetByTodo(_ context.Context, todoID uint) (*entity.DavResource, error) {
+	return r.find(func(res entity.DavResource) bool { return res.TodoId == todoID }), nil
+}
+
+func (r *davStorage) GetAll(context.Context) ([]entity.DavResource, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]entity.DavResource(nil), r.resources...), nil
+}
+
+func TestDavClientResources(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logger.New("error")
+	todos := &memTodos{nextID: 1, todos: map[uint]entity.Todo{
+		1: {Id: 1, OwnerId: 2, Name: "from the api", Status: entity.TodoStatusDefault, Version: 1},
+	}}
+	orgs := &orgStorage{orgs: map[uint][]entity.OrgAccount{2: {{OrgId: 1, AccountId: 2, Role: entity.AccountTypeUser}}}}
+	accounts := accountUsecase{accounts: map[string]entity.Account{
+		"alice": {Id: 2, Name: "alice", Password: "pw", AccountType: entity.AccountTypeUser},
+	}}
+	e := gin.New()
+	v1.NewRouter(e, log, accounts, todos, nil, ownerRoles{}, usecase.NewOrgUsecase(log, orgs, nopAuditor{}),
+		nil, nil, nil, nil, nil, nil, usecase.NewSessionUsecase(session.NewSessionStorage()), nil,
+		usecase.NewCalendarUsecase(log, nil, &davStorage{}))
+
+	call := func(method string, name string, body string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/dav/calendars/todos/"+name, strings.NewReader(body))
+		req.SetBasicAuth("alice@1", "pw")
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		return w
+	}
+	vtodo := func(summary string) string {
+		return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\nUID:3f2a-client\r\nSUMMARY:" + summary +
+			"\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+	}
+	const name = "3f2a-client.ics"
+
+	w := call(http.MethodPut, name, vtodo("first"), "If-None-Match", "*")
+	if w.Code != http.StatusCreated || w.Header().Get("Location") != "" {
+		t.Fatalf("create: status %d, Location %q, want %d at the request URI", w.Code, w.Header().Get("Location"), http.StatusCreated)
+	}
+	etag := w.Header().Get(v1.HeaderETag)
+
+	w = call(http.MethodPut, name, vtodo("edited"), v1.HeaderIfMatch, etag)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("edit: status %d %s, want %d", w.Code, w.Body.String(), http.StatusNoContent)
+	}
+	if len(todos.todos) != 2 || todos.todos[2].Name != "edited" {
+		t.Errorf("todos %v, want the edit applied to the created todo", todos.todos)
+	}
+
+	w = call(http.MethodGet, name, "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "UID:3f2a-client\r\n") ||
+		!strings.Contains(w.Body.String(), "SUMMARY:edited\r\n") {
+		t.Errorf("get: status %d, body %s", w.Code, w.Body.String())
+	}
+
+	// the todo of the API keeps its own name and UID
+	w = call(http.MethodGet, "1.ics", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "UID:todo-1-1\r\n") {
+		t.Errorf("get 1.ics: status %d, body %s", w.Code, w.Body.String())
+	}
+
+	w = call("PROPFIND", "", `<?xml version="1.0"?><d:propfind xmlns:d="DAV:"><d:prop><d:getetag/></d:prop></d:propfind>`, "Depth", "1")
+	if !strings.Contains(w.Body.String(), "/dav/calendars/todos/"+name) || strings.Contains(w.Body.String(), "/dav/calendars/todos/2.ics") ||
+		!strings.Contains(w.Body.String(), "/dav/calendars/todos/1.ics") {
+		t.Errorf("propfind: %s", w.Body.String())
+	}
+
+	w = call("REPORT", "", `<?xml version="1.0"?><c:calendar-multiget xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">`+
+		`<d:prop><c:calendar-data/></d:prop><d:href>/dav/calendars/todos/`+name+`</d:href></c:calendar-multiget>`)
+	if !strings.Contains(w.Body.String(), "UID:3f2a-client") {
+		t.Errorf("multiget: %s", w.Body.String())
+	}
+
+	if w = call(http.MethodDelete, name, ""); w.Code != http.StatusNoContent {
+		t.Errorf("delete: status %d", w.Code)
+	}
+	if _, ok := todos.todos[2]; ok {
+		t.Errorf("delete: todo 2 kept")
+	}
+	if w = call(http.MethodGet, name, ""); w.Code != http.StatusNotFound {
+		t.Errorf("get deleted: status %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestDavResourceNameTaken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logger.New("error")
+	todos := &memTodos{nextID: 1, todos: map[uint]entity.Todo{
+		1: {Id: 1, OwnerId: 2, Name: "from the api", Status: entity.TodoStatusDefault, Version: 1},
+	}}
+	orgs := &orgStorage{orgs: map[uint][]entity.OrgAccount{
+		2: {{OrgId: 1, AccountId: 2, Role: entity.AccountTypeUser}},
+		3: {{OrgId: 1, AccountId: 3, Role: entity.AccountTypeUser}},
+	}}
+	accounts := accountUsecase{accounts: map[string]entity.Account{
+		"alice": {Id: 2, Name: "alice", Password: "pw", AccountType: entity.AccountTypeUser},
+		"bob":   {Id: 3, Name: "bob", Password: "pw", AccountType: entity.AccountTypeUser},
+	}}
+	resources := &davStorage{}
+	e := gin.New()
+	v1.NewRouter(e, log, accounts, todos, nil, ownerRoles{}, usecase.NewOrgUsecase(log, orgs, nopAuditor{}),
+		nil, nil, nil, nil, nil, nil, usecase.NewSessionUsecase(session.NewSessionStorage()), nil,
+		usecase.NewCalendarUsecase(log, nil, resources))
+
+	put := func(user string, name string, summary string) *httptest.ResponseRecorder {
+		body := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\nUID:" + user + "\r\nSUMMARY:" + summary +
+			"\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+		req := httptest.NewRequest(http.MethodPut, "/dav/calendars/todos/"+name, strings.NewReader(body))
+		req.SetBasicAuth(user+"@1", "pw")
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := put("alice", "shared.ics", "of alice"); w.Code != http.StatusCreated {
+		t.Fatalf("alice: status %d %s, want %d", w.Code, w.Body.String(), http.StatusCreated)
+	}
+	// bob cannot see the todo of alice, nor take its name
+	for _, name := range []string{"shared.ics", "1.ics", "9.ics"} {
+		if w := put("bob", name, "of bob"); w.Code != http.StatusConflict {
+			t.Errorf("bob %s: status %d, want %d", name, w.Code, http.StatusConflict)
+		}
+	}
+	if len(todos.todos) != 2 || todos.todos[2].Name != "of alice" {
+		t.Errorf("todos %v, want only the todo of alice created", todos.todos)
+	}
+	if res, _ := resources.GetByName(context.Background(), "shared.ics"); res == nil || res.TodoId != 2 || res.Uid != "alice" {
+		t.Errorf("shared.ics: %+v, want the todo of alice", res)
+	}
+
+	if w := put("bob", "other.ics", "of bob"); w.Code != http.StatusCreated {
+		t.Errorf("bob other.ics: status %d, want %d", w.Code, http.StatusCreated)
+	}
+}
@@ -0,0 +1,235 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/controller/http/dto"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/ical"
+)
+
+const _calendarProdId = "-//test3//Todo Feed//EN"
+
+// todoUID is the UID of todos not created over CalDAV.
+func todoUID(tenantID uint, todo entity.Todo) string {
+	return fmt.Sprintf("todo-%d-%d", tenantID, todo.Id)
+}
+
+// todoVTodo renders the todo as a VTODO, the labels are its CATEGORIES.
+func todoVTodo(uid string, todo entity.Todo, stamp time.Time) ical.Component {
+	c := ical.Component{Name: "VTODO"}
+	c.AddText("UID", uid)
+	c.AddTime("DTSTAMP", stamp)
+	c.AddText("SUMMARY", todo.Name)
+	if todo.Desc != "" {
+		c.AddText("DESCRIPTION", todo.Desc)
+	}
+	if todo.Due != nil {
+		c.AddTime("DUE", *todo.Due)
+	}
+	if todo.Priority > 0 {
+		c.Add("PRIORITY", strconv.FormatUint(uint64(todo.Priority), 10))
+	}
+	if todo.Recurrence != "" {
+		c.Add("RRULE", todo.Recurrence)
+	}
+	if len(todo.Labels) > 0 {
+		// labels have no commas to escape
+		c.Add("CATEGORIES", strings.Join(todo.Labels, ","))
+	}
+	if todo.Status == entity.TodoStatusDone {
+		c.Add("STATUS", "COMPLETED")
+		c.Add("PERCENT-COMPLETE", "100")
+	} else {
+		c.Add("STATUS", "NEEDS-ACTION")
+	}
+	if todo.Version > 0 {
+		c.Add("SEQUENCE", fmt.Sprint(todo.Version-1))
+	}
+	return c
+}
+
+// vtodoRows reads the VTODO components, wherever they are nested, into import
+// rows numbered in order. Rows with unreadable properties carry the error.
+func vtodoRows(components []ical.Component) []entity.TodoImportRow {
+	var rows []entity.TodoImportRow
+	var walk func([]ical.Component)
+	walk = func(cs []ical.Component) {
+		for i := range cs {
+			if cs[i].Name != "VTODO" {
+				walk(cs[i].Components)
+				continue
+			}
+			row := entity.TodoImportRow{Row: len(rows) + 1}
+			row.Todo, row.Error = vtodoTodo(&cs[i])
+			rows = append(rows, row)
+		}
+	}
+	walk(components)
+	return rows
+}
+
+// vtodoTodo reads the fields of the todo from the VTODO.
+func vtodoTodo(c *ical.Component) (entity.Todo, string) {
+	todo := entity.Todo{
+		Name:       strings.TrimSpace(c.Text("SUMMARY")),
+		Desc:       c.Text("DESCRIPTION"),
+		Status:     entity.TodoStatusDefault,
+		Recurrence: c.Text("RRULE"),
+	}
+	if strings.EqualFold(c.Text("STATUS"), "COMPLETED") {
+		todo.Status = entity.TodoStatusDone
+	}
+	due, err := c.Time("DUE")
+	if err != nil {
+		return todo, err.Error()
+	}
+	if due != nil {
+		utc := due.UTC()
+		todo.Due = &utc
+	}
+	if p := c.Prop("PRIORITY"); p != nil {
+		priority, err := strconv.ParseUint(p.Value, 10, 8)
+		if err != nil {
+			return todo, fmt.Sprintf("PRIORITY: invalid value %q", p.Value)
+		}
+		todo.Priority = uint(priority)
+	}
+	for i := range c.Props {
+		if c.Props[i].Name != "CATEGORIES" {
+			continue
+		}
+		for _, label := range strings.Split(c.Props[i].Value, ",") {
+			if label = strings.TrimSpace(ical.UnescapeText(label)); label != "" {
+				todo.Labels = append(todo.Labels, label)
+			}
+		}
+	}
+	return todo, ""
+}
+
+func (r *todoHandler) CreateCalendarToken(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	token, err := r.calendarUsecase.CreateFeedToken(c.Request.Context(), account.Id)
+	if err != nil {
+		r.log.Error("http - v1 - CreateCalendarToken: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", CalendarTokenResponse{token, "/v1/calendar/feed/" + token + ".ics"}))
+}
+
+func (r *todoHandler) RevokeCalendarToken(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	if err := r.calendarUsecase.RevokeFeedToken(c.Request.Context(), account.Id); err != nil {
+		r.log.Error("http - v1 - RevokeCalendarToken: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok"))
+}
+
+// CalendarFeed serves the todos the feed owner sees as an iCalendar object.
+// Calendar apps cannot log in, the token in the path is the credential.
+func (r *todoHandler) CalendarFeed(c *gin.Context) {
+	var uri dto.CalendarFeedUri
+	if err := c.ShouldBindUri(&uri); err != nil {
+		r.log.Error("http - v1 - CalendarFeed: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	feed, err := r.calendarUsecase.GetFeed(c.Request.Context(), strings.TrimSuffix(uri.Token, ".ics"))
+	if err != nil {
+		r.log.Error("http - v1 - CalendarFeed: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+	if feed == nil {
+		err = errors.New("feed not found")
+		r.log.Error("http - v1 - CalendarFeed: %v", err)
+		c.JSON(http.StatusNotFound, NewResp(ErrCodeUnauthenticated, err.Error()))
+		return
+	}
+
+	ctx := entity.WithTenant(c.Request.Context(), feed.TenantId)
+	account, err := r.accountUsecase.GetAccount(ctx, feed.AccountId)
+	if err != nil {
+		r.log.Error("http - v1 - CalendarFeed: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+	if account == nil {
+		err = errors.New("feed not found")
+		r.log.Error("http - v1 - CalendarFeed: %v", err)
+		c.JSON(http.StatusNotFound, NewResp(ErrCodeUnauthenticated, err.Error()))
+		return
+	}
+
+	var todos []entity.Todo
+	if account.IsAdmin() {
+		todos, err = r.todoUsecase.GetTodoAll(ctx)
+	} else {
+		todos, err = r.todoUsecase.GetTodoAllVisible(ctx, account.Id)
+	}
+	if err != nil {
+		r.log.Error("http - v1 - CalendarFeed: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	cal := ical.Component{Name: "VCALENDAR"}
+	cal.Add("VERSION", "2.0")
+	cal.Add("PRODID", _calendarProdId)
+	cal.AddText("X-WR-CALNAME", "Todos of "+account.Name)
+	stamp := time.Now()
+	for _, t := range todos {
+		cal.Components = append(cal.Components, todoVTodo(todoUID(feed.TenantId, t), t, stamp))
+	}
+
+	c.Header("Content-Type", "text/calendar; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err = ical.Encode(c.Writer, cal); err != nil {
+		r.log.Error("http - v1 - CalendarFeed: %v", err)
+	}
+}
+
+// ImportCalendar creates a todo of every VTODO in the uploaded .ics file,
+// see ImportTodos.
+func (r *todoHandler) ImportCalendar(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var req dto.ImportCalendarRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		r.log.Error("http - v1 - ImportCalendar: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	body, err := uploadedFile(c)
+	if err != nil {
+		r.log.Error("http - v1 - ImportCalendar: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+	defer body.Close()
+
+	components, err := ical.Decode(body)
+	if err != nil {
+		r.log.Error("http - v1 - ImportCalendar: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	r.importRows(c, "ImportCalendar", account, vtodoRows(components), req.DryRun)
+}
@@ -0,0 +1,84 @@
+package v1
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/ical"
+)
+
+func TestVTodoRoundTrip(t *testing.T) {
+	due := time.Date(2026, 11, 1, 9, 30, 0, 0, time.UTC)
+	todo := entity.Todo{
+		Id:         3,
+		Name:       "weekly report",
+		Desc:       "send it, to everyone",
+		Status:     entity.TodoStatusDone,
+		Due:        &due,
+		Labels:     []string{"work", "urgent"},
+		Priority:   1,
+		Recurrence: "FREQ=WEEKLY;BYDAY=MO",
+		Version:    2,
+	}
+	cal := ical.Component{Name: "VCALENDAR", Components: []ical.Component{todoVTodo(todoUID(1, todo), todo, time.Now())}}
+	var buf bytes.Buffer
+	if err := ical.Encode(&buf, cal); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"DUE:20261101T093000Z", "PRIORITY:1", "RRULE:FREQ=WEEKLY;BYDAY=MO", "CATEGORIES:work,urgent"} {
+		if !strings.Contains(buf.String(), line+"\r\n") {
+			t.Errorf("VTODO misses %s:\n%s", line, buf.String())
+		}
+	}
+
+	components, err := ical.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := vtodoRows(components)
+	if len(rows) != 1 || rows[0].Error != "" {
+		t.Fatalf("rows %+v, want one without error", rows)
+	}
+	want := todo
+	want.Id, want.Version = 0, 0
+	if !reflect.DeepEqual(rows[0].Todo, want) {
+		t.Errorf("imported %+v, want %+v", rows[0].Todo, want)
+	}
+}
+
+func TestVTodoImport(t *testing.T) {
+	tests := []struct {
+		name  string
+		props string
+		due   time.Time
+		error string
+	}{
+		{"date", "DUE;VALUE=DATE:20261101", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), ""},
+		{"floating", "DUE:20261101T080000", time.Date(2026, 11, 1, 8, 0, 0, 0, time.UTC), ""},
+		{"zone", "DUE;TZID=Europe/Berlin:20261101T080000", time.Date(2026, 11, 1, 7, 0, 0, 0, time.UTC), ""},
+		{"bad due", "DUE:tomorrow", time.Time{}, `DUE: invalid date "tomorrow"`},
+		{"bad priority", "PRIORITY:high", time.Time{}, `PRIORITY: invalid value "high"`},
+	}
+	for _, tt := range tests {
+		data := "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nSUMMARY:x\r\n" + tt.props + "\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+		components, err := ical.Decode(strings.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		rows := vtodoRows(components)
+		if len(rows) != 1 {
+			t.Fatalf("%s: %d rows", tt.name, len(rows))
+		}
+		if rows[0].Error != tt.error {
+			t.Errorf("%s: error %q, want %q", tt.name, rows[0].Error, tt.error)
+		}
+		if tt.error == "" && (rows[0].Todo.Due == nil || !rows[0].Todo.Due.Equal(tt.due)) {
+			t.Errorf("%s: due %v, want %v", tt.name, rows[0].Todo.Due, tt.due)
+		}
+	}
+}
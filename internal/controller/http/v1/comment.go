@@ -0,0 +1,183 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/controller/http/dto"
+	"testcode/test3/internal/domain/entity"
+)
+
+const _defaultCommentLimit = 20
+
+func (r *todoHandler) GetComments(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var uri dto.TodoUri
+	if err := c.ShouldBindUri(&uri); err != nil {
+		r.log.Error("http - v1 - GetComments: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+	var req dto.GetCommentsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		r.log.Error("http - v1 - GetComments: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = _defaultCommentLimit
+	}
+
+	_, role, code, err := r.todoAccess(c.Request.Context(), uri.Id, account)
+	if err != nil {
+		r.log.Error("http - v1 - GetComments: %v", err)
+		c.JSON(http.StatusOK, NewResp(code, err.Error()))
+		return
+	}
+	if role < entity.MemberRoleViewer {
+		err = errors.New("No access")
+		r.log.Error("http - v1 - GetComments: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+		return
+	}
+
+	items, total, err := r.commentUsecase.GetCommentPage(c.Request.Context(), uri.Id, req.Page, req.Limit)
+	if err != nil {
+		r.log.Error("http - v1 - GetComments: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", CommentsResponse{items, total, req.Page, req.Limit}))
+}
+
+func (r *todoHandler) CreateComment(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var uri dto.TodoUri
+	if err := c.ShouldBindUri(&uri); err != nil {
+		r.log.Error("http - v1 - CreateComment: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+	var req dto.CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.log.Error("http - v1 - CreateComment: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	_, role, code, err := r.todoAccess(c.Request.Context(), uri.Id, account)
+	if err != nil {
+		r.log.Error("http - v1 - CreateComment: %v", err)
+		c.JSON(http.StatusOK, NewResp(code, err.Error()))
+		return
+	}
+	if role < entity.MemberRoleViewer {
+		err = errors.New("No access")
+		r.log.Error("http - v1 - CreateComment: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+		return
+	}
+
+	comment := entity.Comment{
+		TodoId:   uri.Id,
+		AuthorId: account.Id,
+		Body:     req.Body,
+	}
+	id, err := r.commentUsecase.CreateComment(c.Request.Context(), comment)
+	if err != nil {
+		r.log.Error("http - v1 - CreateComment: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", CreateCommentResponse{id}))
+}
+
+func (r *todoHandler) EditComment(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var uri dto.CommentUri
+	if err := c.ShouldBindUri(&uri); err != nil {
+		r.log.Error("http - v1 - EditComment: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+	var req dto.EditCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.log.Error("http - v1 - EditComment: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	comment, err := r.commentUsecase.GetComment(c.Request.Context(), uri.CommentId)
+	if err != nil {
+		r.log.Error("http - v1 - EditComment: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+	if comment == nil || comment.TodoId != uri.Id {
+		err = errors.New("not found")
+		r.log.Error("http - v1 - EditComment: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+	if !account.IsAdmin() && account.Id != comment.AuthorId {
+		err = errors.New("No access")
+		r.log.Error("http - v1 - EditComment: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+		return
+	}
+
+	if err = r.commentUsecase.EditComment(c.Request.Context(), *comment, req.Body); err != nil {
+		r.log.Error("http - v1 - EditComment: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok"))
+}
+
+func (r *todoHandler) DeleteComment(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var uri dto.CommentUri
+	if err := c.ShouldBindUri(&uri); err != nil {
+		r.log.Error("http - v1 - DeleteComment: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	comment, err := r.commentUsecase.GetComment(c.Request.Context(), uri.CommentId)
+	if err != nil {
+		r.log.Error("http - v1 - DeleteComment: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+	if comment == nil || comment.TodoId != uri.Id {
+		err = errors.New("not found")
+		r.log.Error("http - v1 - DeleteComment: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+	if !account.IsAdmin() && account.Id != comment.AuthorId {
+		err = errors.New("No access")
+		r.log.Error("http - v1 - DeleteComment: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+		return
+	}
+
+	if err = r.commentUsecase.DeleteComment(c.Request.Context(), uri.CommentId); err != nil {
+		r.log.Error("http - v1 - DeleteComment: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok"))
+}
@@ -8,4 +8,6 @@ const (
 	ErrCodeInternal
 	ErrCodeUnauthenticated
 	ErrCodeNoAccess
+	ErrCodeConflict
+	ErrCodePreconditionFailed
 )
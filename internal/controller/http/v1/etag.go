@@ -0,0 +1,78 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/domain/entity"
+)
+
+const (
+	HeaderETag    = "ETag"
+	HeaderIfMatch = "If-Match"
+)
+
+var errBadIfMatch = errors.New("If-Match must be a single todo ETag or *")
+
+func todoETag(todo entity.Todo) string {
+	return fmt.Sprintf(`"%d"`, todo.Version)
+}
+
+// ifMatch parses the If-Match header into the expected todo version, zero
+// when the header is absent or *.
+func ifMatch(c *gin.Context) (uint, error) {
+	h := strings.TrimSpace(c.Request.Header.Get(HeaderIfMatch))
+	if h == "" || h == "*" {
+		return 0, nil
+	}
+	h = strings.TrimPrefix(h, "W/")
+	if len(h) < 2 || h[0] != '"' || h[len(h)-1] != '"' {
+		return 0, errBadIfMatch
+	}
+	v, err := strconv.ParseUint(h[1:len(h)-1], 10, 32)
+	if err != nil || v == 0 {
+		return 0, errBadIfMatch
+	}
+	return uint(v), nil
+}
+
+// todoConflict responds with status and the current state of the todo so the
+// client can merge and retry.
+func (r *todoHandler) todoConflict(ctx context.Context, c *gin.Context, status int, todoID uint, err error) {
+	code := ErrCodeConflict
+	if status == http.StatusPreconditionFailed {
+		code = ErrCodePreconditionFailed
+	}
+
+	todo, getErr := r.todoUsecase.GetTodo(ctx, todoID)
+	if getErr != nil || todo == nil {
+		c.JSON(status, NewResp(code, err.Error()))
+		return
+	}
+	c.Header(HeaderETag, todoETag(*todo))
+	c.JSON(status, NewResp(code, err.Error(), todo))
+}
+
+// checkIfMatch answers 412 with the current state when If-Match does not match
+// the loaded todo and reports whether the request may go on.
+func (r *todoHandler) checkIfMatch(c *gin.Context, name string, todo entity.Todo) bool {
+	version, err := ifMatch(c)
+	if err != nil {
+		r.log.Error("http - v1 - %s: %v", name, err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return false
+	}
+	if version != 0 && version != todo.Version {
+		err = errors.New("If-Match does not match the current version")
+		r.log.Error("http - v1 - %s: %v", name, err)
+		c.Header(HeaderETag, todoETag(todo))
+		c.JSON(http.StatusPreconditionFailed, NewResp(ErrCodePreconditionFailed, err.Error(), todo))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,231 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"testcode/test3/internal/controller/http/dto"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+	"testcode/test3/pkg/sse"
+)
+
+const HeaderLastEventId = "Last-Event-ID"
+
+const (
+	_eventsHeartbeat    = 15 * time.Second
+	_eventsWriteTimeout = 10 * time.Second
+	_eventsRetry        = 3 * time.Second
+	// _eventReset tells the client the events it missed have expired and it
+	// has to reload.
+	_eventReset = "reset"
+)
+
+var errEventsBehind = errors.New("falling behind, resume from the last event id")
+
+var _eventsUpgrader = websocket.Upgrader{}
+
+// Events streams the changes to the todos the caller can view as
+// Server-Sent Events. A client reconnecting with Last-Event-ID, or
+// last_event_id, gets the events it missed first. Clients falling behind are
+// disconnected and resume the same way.
+func (r *todoHandler) Events(c *gin.Context) {
+	account, lastEventID, ok := r.eventsRequest(c, "Events")
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+
+	backlog, events, stop, err := r.todoUsecase.WatchTodosSince(ctx, lastEventID)
+	defer stop()
+	expired := errors.Is(err, usecase.ErrTodoEventsExpired)
+
+	stream, err := sse.Open(c.Writer, c.Request, _eventsWriteTimeout)
+	if err != nil {
+		r.log.Error("http - v1 - Events: %v", err)
+		return
+	}
+	defer stream.Close()
+
+	if err := stream.Retry(_eventsRetry); err != nil {
+		return
+	}
+	if expired {
+		if err := stream.Send(sse.Event{Event: _eventReset, Data: []byte("{}")}); err != nil {
+			return
+		}
+	}
+	send := func(event entity.TodoEvent) error {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		return stream.Send(sse.Event{Id: strconv.FormatUint(event.Id, 10), Event: string(event.Type), Data: data})
+	}
+	ping := func() error {
+		return stream.Comment("ping")
+	}
+	// a client falling behind reconnects with Last-Event-ID
+	err = r.streamEvents(ctx, account, backlog, events, stream.Done(), send, ping)
+	if err != nil && !errors.Is(err, errEventsBehind) {
+		r.log.Error("http - v1 - Events: %v", err)
+	}
+}
+
+// EventsWebSocket is Events over WebSocket, every event is a JSON message
+// with its id and type. A client falling behind is closed with 1013 and
+// resumes with last_event_id.
+func (r *todoHandler) EventsWebSocket(c *gin.Context) {
+	account, lastEventID, ok := r.eventsRequest(c, "EventsWebSocket")
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+
+	ws, err := _eventsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// the upgrader has answered already
+		r.log.Error("http - v1 - EventsWebSocket: %v", err)
+		return
+	}
+	defer ws.Close()
+
+	backlog, events, stop, err := r.todoUsecase.WatchTodosSince(ctx, lastEventID)
+	defer stop()
+	expired := errors.Is(err, usecase.ErrTodoEventsExpired)
+
+	// the client sends nothing, reading handles pongs and notices it going
+	// away
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ws.SetReadDeadline(time.Now().Add(2 * _eventsHeartbeat))
+		ws.SetPongHandler(func(string) error {
+			return ws.SetReadDeadline(time.Now().Add(2 * _eventsHeartbeat))
+		})
+		for {
+			if _, _, err := ws.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	write := func(v interface{}) error {
+		ws.SetWriteDeadline(time.Now().Add(_eventsWriteTimeout))
+		return ws.WriteJSON(v)
+	}
+	if expired {
+		if err := write(gin.H{"type": _eventReset}); err != nil {
+			return
+		}
+	}
+	send := func(event entity.TodoEvent) error {
+		return write(event)
+	}
+	ping := func() error {
+		return ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(_eventsWriteTimeout))
+	}
+	err = r.streamEvents(ctx, account, backlog, events, done, send, ping)
+	switch {
+	case errors.Is(err, errEventsBehind):
+		ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, err.Error()),
+			time.Now().Add(_eventsWriteTimeout))
+	case err != nil:
+		r.log.Error("http - v1 - EventsWebSocket: %v", err)
+	default:
+		ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+			time.Now().Add(_eventsWriteTimeout))
+	}
+}
+
+// eventsRequest takes the caller and the id to resume from. Browsers cannot
+// set headers on EventSource and WebSocket requests, the token can go in the
+// token parameter instead.
+func (r *todoHandler) eventsRequest(c *gin.Context, name string) (entity.Account, uint64, bool) {
+	var req dto.EventsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		r.log.Error("http - v1 - %s: %v", name, err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return entity.Account{}, 0, false
+	}
+
+	token := c.Request.Header.Get(HeaderAuthKey)
+	if token == "" {
+		token = req.Token
+	}
+	account, ok := r.sessionUsecase.Get(token)
+	if token == "" || !ok {
+		r.log.Error("http - v1 - %s: invalid token", name)
+		c.JSON(http.StatusOK, NewResp(ErrCodeUnauthenticated, "invalid token"))
+		return entity.Account{}, 0, false
+	}
+	setAccount(c, account)
+
+	lastEventID := req.LastEventId
+	if v := c.Request.Header.Get(HeaderLastEventId); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			r.log.Error("http - v1 - %s: %v", name, err)
+			c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, "invalid "+HeaderLastEventId))
+			return entity.Account{}, 0, false
+		}
+		lastEventID = id
+	}
+	return account, lastEventID, true
+}
+
+// streamEvents sends the backlog and then the events the account can view
+// until done is closed, pinging every heartbeat. errEventsBehind means the
+// subscription was dropped for not keeping up.
+func (r *todoHandler) streamEvents(ctx context.Context, account entity.Account, backlog []entity.TodoEvent,
+	events <-chan entity.TodoEvent, done <-chan struct{}, send func(entity.TodoEvent) error, ping func() error) error {
+	visible := func(event entity.TodoEvent) (bool, error) {
+		if account.IsAdmin() {
+			return true, nil
+		}
+		role, err := r.memberUsecase.TodoRole(ctx, account, event.Todo)
+		if err != nil {
+			return false, err
+		}
+		return role >= entity.MemberRoleViewer, nil
+	}
+	sendVisible := func(event entity.TodoEvent) error {
+		ok, err := visible(event)
+		if err != nil || !ok {
+			return err
+		}
+		return send(event)
+	}
+
+	for _, event := range backlog {
+		if err := sendVisible(event); err != nil {
+			return err
+		}
+	}
+
+	t := time.NewTicker(_eventsHeartbeat)
+	defer t.Stop()
+	for {
+		select {
+		case <-done:
+			return nil
+		case <-t.C:
+			if err := ping(); err != nil {
+				return err
+			}
+		case event, ok := <-events:
+			if !ok {
+				return errEventsBehind
+			}
+			if err := sendVisible(event); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/controller/http/dto"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+	"testcode/test3/pkg/filter"
+)
+
+// filterErrResp maps filter syntax errors to InvalidArgument with the error
+// position as payload.
+func filterErrResp(err error) *ResponseMessage {
+	var syntaxErr *filter.Error
+	if errors.As(err, &syntaxErr) {
+		return NewResp(ErrCodeInvalidArgument, err.Error(), FilterErrorResponse{syntaxErr.Pos})
+	}
+	if errors.Is(err, usecase.ErrFilterTooLong) {
+		return NewResp(ErrCodeInvalidArgument, err.Error())
+	}
+	return NewResp(ErrCodeInternal, err.Error())
+}
+
+func (r *todoHandler) FilterTodos(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var req dto.FilterTodoRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		r.log.Error("http - v1 - FilterTodos: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	if req.SavedId != 0 {
+		saved, err := r.filterUsecase.GetSavedFilter(c.Request.Context(), req.SavedId)
+		if err != nil {
+			r.log.Error("http - v1 - FilterTodos: %v", err)
+			c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+			return
+		}
+		if saved == nil || saved.AccountId != account.Id {
+			err = errors.New("saved filter not found")
+			r.log.Error("http - v1 - FilterTodos: %v", err)
+			c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+			return
+		}
+		req.Query = saved.Query
+	}
+
+	resp, err := r.filterUsecase.FilterTodo(c.Request.Context(), req.Query, account)
+	if err != nil {
+		r.log.Error("http - v1 - FilterTodos: %v", err)
+		c.JSON(http.StatusOK, filterErrResp(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", resp))
+}
+
+func (r *todoHandler) GetSavedFilters(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	resp, err := r.filterUsecase.GetSavedFilterAllByAccount(c.Request.Context(), account.Id)
+	if err != nil {
+		r.log.Error("http - v1 - GetSavedFilters: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", resp))
+}
+
+func (r *todoHandler) SaveFilter(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var req dto.SaveFilterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.log.Error("http - v1 - SaveFilter: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	id, err := r.filterUsecase.SaveFilter(c.Request.Context(), entity.SavedFilter{
+		AccountId: account.Id,
+		Name:      req.Name,
+		Query:     req.Query,
+	})
+	if err != nil {
+		r.log.Error("http - v1 - SaveFilter: %v", err)
+		c.JSON(http.StatusOK, filterErrResp(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", SaveFilterResponse{id}))
+}
+
+func (r *todoHandler) DeleteSavedFilter(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var req dto.DeleteSavedFilterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.log.Error("http - v1 - DeleteSavedFilter: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	saved, err := r.filterUsecase.GetSavedFilter(c.Request.Context(), req.Id)
+	if err != nil {
+		r.log.Error("http - v1 - DeleteSavedFilter: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+	if saved == nil || saved.AccountId != account.Id {
+		err = errors.New("saved filter not found")
+		r.log.Error("http - v1 - DeleteSavedFilter: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	if err = r.filterUsecase.DeleteSavedFilter(c.Request.Context(), req.Id); err != nil {
+		r.log.Error("http - v1 - DeleteSavedFilter: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok"))
+}
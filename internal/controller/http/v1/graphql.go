@@ -0,0 +1,220 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graph-gophers/graphql-go"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/gqlcost"
+	"testcode/test3/pkg/gqlws"
+)
+
+// Limits of a GraphQL operation, the cost counts every field once and the
+// fields under a list as many times as the list is expected to hold.
+const (
+	_graphqlMaxDepth = 6
+	_graphqlMaxCost  = 5000
+)
+
+var _graphqlListCost = map[string]int{
+	"accounts":      50,
+	"todos":         50,
+	"assignedTodos": 20,
+	"assignees":     5,
+}
+
+const _graphqlSchema = `
+schema {
+	query: Query
+	subscription: Subscription
+}
+
+scalar Time
+
+type Query {
+	"The caller."
+	me: Account!
+	account(id: ID!): Account
+	accounts: [Account!]!
+	"Null when the todo does not exist."
+	todo(id: ID!): Todo
+	"Every todo of the tenant for admins, the visible ones for other accounts."
+	todos: [Todo!]!
+}
+
+type Subscription {
+	"Changes to the todos the caller can view. The subscription ends when the client falls behind."
+	todoChanged: TodoEvent!
+}
+
+enum AccountType {
+	ADMIN
+	USER
+	SUPER_ADMIN
+}
+
+type Account {
+	id: ID!
+	name: String!
+	type: AccountType!
+	"The todos assigned to the account the caller can view."
+	assignedTodos: [Todo!]!
+}
+
+type Project {
+	id: ID!
+	name: String!
+	desc: String!
+	color: String!
+	archived: Boolean!
+	owner: Account
+}
+
+enum TodoStatus {
+	DEFAULT
+	DONE
+}
+
+type Todo {
+	id: ID!
+	name: String!
+	desc: String!
+	status: TodoStatus!
+	version: Int!
+	"Null once the owner is deleted."
+	owner: Account
+	"Null without a project or when the caller cannot view it."
+	project: Project
+	assignees: [Account!]!
+}
+
+enum TodoEventType {
+	CREATED
+	UPDATED
+	DELETED
+}
+
+type TodoEvent {
+	type: TodoEventType!
+	actor: Account
+	"The state after the change, before it for DELETED."
+	todo: Todo!
+	at: Time!
+}
+`
+
+func newGraphQLSchema(r *todoHandler) *graphql.Schema {
+	return graphql.MustParseSchema(_graphqlSchema, &gqlResolver{r},
+		graphql.UseStringDescriptions(), graphql.MaxDepth(_graphqlMaxDepth))
+}
+
+// GraphQLRequest -.
+type GraphQLRequest struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// graphqlError is an error of the response.
+type graphqlError struct {
+	Message string `json:"message"`
+}
+
+// graphqlCost rejects operations over the cost limit. Queries the estimate
+// cannot read are left to the schema, which reports the syntax error.
+func graphqlCost(query string, operationName string) error {
+	cost, err := gqlcost.Estimate(query, operationName, _graphqlListCost)
+	if err != nil || cost <= _graphqlMaxCost {
+		return nil
+	}
+	return fmt.Errorf("query cost %d exceeds the limit of %d", cost, _graphqlMaxCost)
+}
+
+// GraphQL runs queries, subscriptions are served over WebSocket by
+// GraphQLWebSocket.
+func (r *todoHandler) GraphQL(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var req GraphQLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.log.Error("http - v1 - GraphQL: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"errors": []graphqlError{{err.Error()}}})
+		return
+	}
+	if err := graphqlCost(req.Query, req.OperationName); err != nil {
+		r.log.Error("http - v1 - GraphQL: %v", err)
+		c.JSON(http.StatusOK, gin.H{"errors": []graphqlError{{err.Error()}}})
+		return
+	}
+
+	ctx := withGQLAccount(c.Request.Context(), account)
+	ctx = withGQLLoader(ctx, r.accountUsecase, r.projectUsecase)
+	resp := r.graphqlSchema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	for _, err := range resp.Errors {
+		r.log.Error("http - v1 - GraphQL: %v", err)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GraphQLWebSocket serves queries and subscriptions with the
+// graphql-transport-ws protocol. Browsers cannot set headers on WebSocket
+// requests, the token goes in the connection_init payload instead.
+func (r *todoHandler) GraphQLWebSocket(c *gin.Context) {
+	header := c.Request.Header.Get(HeaderAuthKey)
+	s := &gqlws.Server{
+		Init: func(ctx context.Context, payload json.RawMessage) (context.Context, error) {
+			return r.graphqlInit(ctx, payload, header)
+		},
+		Subscribe: r.graphqlSubscribe,
+	}
+	s.ServeHTTP(c.Writer, c.Request)
+}
+
+// graphqlInit takes the caller from the token of the connection_init
+// payload, the token header of the upgrade request otherwise.
+func (r *todoHandler) graphqlInit(ctx context.Context, payload json.RawMessage, token string) (context.Context, error) {
+	var init struct {
+		Token string `json:"token"`
+	}
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &init); err != nil {
+			return nil, errors.New("invalid payload")
+		}
+	}
+	if init.Token != "" {
+		token = init.Token
+	}
+	account, ok := r.sessionUsecase.Get(token)
+	if token == "" || !ok {
+		r.log.Error("http - v1 - GraphQLWebSocket: invalid token")
+		return nil, errors.New("invalid token")
+	}
+
+	meta := entity.AuditMetaFromContext(ctx)
+	meta.ActorId = account.Id
+	ctx = entity.WithAuditMeta(ctx, meta)
+	ctx = entity.WithTenant(ctx, account.TenantId)
+	return withGQLAccount(ctx, account), nil
+}
+
+func (r *todoHandler) graphqlSubscribe(ctx context.Context, p gqlws.Payload) (<-chan interface{}, error) {
+	if err := graphqlCost(p.Query, p.OperationName); err != nil {
+		r.log.Error("http - v1 - GraphQLWebSocket: %v", err)
+		return nil, err
+	}
+	if errs := r.graphqlSchema.ValidateWithVariables(p.Query, p.Variables); len(errs) > 0 {
+		ret := make(gqlws.Errors, 0, len(errs))
+		for _, err := range errs {
+			ret = append(ret, err)
+		}
+		return nil, ret
+	}
+
+	ctx = withGQLLoader(ctx, r.accountUsecase, r.projectUsecase)
+	return r.graphqlSchema.Subscribe(ctx, p.Query, p.OperationName, p.Variables)
+}
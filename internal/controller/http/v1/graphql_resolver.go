@@ -0,0 +1,446 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go"
+	"testcode/test3/internal/domain/entity"
+)
+
+var errGQLNoAccess = errors.New("No access")
+
+type gqlAccountKey struct{}
+
+func withGQLAccount(ctx context.Context, account entity.Account) context.Context {
+	return context.WithValue(ctx, gqlAccountKey{}, account)
+}
+
+func gqlAccount(ctx context.Context) (entity.Account, bool) {
+	account, ok := ctx.Value(gqlAccountKey{}).(entity.Account)
+	return account, ok
+}
+
+type gqlLoaderKey struct{}
+
+// gqlLoader batches and caches the lookups of one operation. List resolvers
+// queue the accounts their items refer to, the first load fetches all queued
+// accounts in one query instead of one per item.
+type gqlLoader struct {
+	accountUsecase AccountUsecase
+	projectUsecase ProjectUsecase
+
+	mu       sync.Mutex
+	queued   map[uint]struct{}
+	accounts map[uint]*entity.Account
+	projects map[uint]*entity.Project
+}
+
+func withGQLLoader(ctx context.Context, accountUsecase AccountUsecase, projectUsecase ProjectUsecase) context.Context {
+	l := &gqlLoader{accountUsecase: accountUsecase, projectUsecase: projectUsecase}
+	l.reset()
+	return context.WithValue(ctx, gqlLoaderKey{}, l)
+}
+
+func loaderFrom(ctx context.Context) *gqlLoader {
+	return ctx.Value(gqlLoaderKey{}).(*gqlLoader)
+}
+
+// reset drops the cache, subscriptions reset it between events.
+func (l *gqlLoader) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.queued = make(map[uint]struct{})
+	l.accounts = make(map[uint]*entity.Account)
+	l.projects = make(map[uint]*entity.Project)
+}
+
+func (l *gqlLoader) queueTodos(todos []entity.Todo) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, t := range todos {
+		l.queued[t.OwnerId] = struct{}{}
+		for _, id := range t.Assignees {
+			l.queued[id] = struct{}{}
+		}
+	}
+}
+
+func (l *gqlLoader) primeAccounts(accounts []entity.Account) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i := range accounts {
+		l.accounts[accounts[i].Id] = &accounts[i]
+	}
+}
+
+// account returns nil for an account not in the tenant.
+func (l *gqlLoader) account(ctx context.Context, accountID uint) (*entity.Account, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if a, ok := l.accounts[accountID]; ok {
+		return a, nil
+	}
+
+	l.queued[accountID] = struct{}{}
+	ids := make([]uint, 0, len(l.queued))
+	for id := range l.queued {
+		if _, ok := l.accounts[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	accounts, err := l.accountUsecase.GetAccountAllByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		l.accounts[id] = nil
+	}
+	for i := range accounts {
+		l.accounts[accounts[i].Id] = &accounts[i]
+	}
+	l.queued = make(map[uint]struct{})
+	return l.accounts[accountID], nil
+}
+
+func (l *gqlLoader) project(ctx context.Context, projectID uint) (*entity.Project, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if p, ok := l.projects[projectID]; ok {
+		return p, nil
+	}
+	p, err := l.projectUsecase.GetProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	l.projects[projectID] = p
+	return p, nil
+}
+
+func gqlID(id uint) graphql.ID {
+	return graphql.ID(strconv.FormatUint(uint64(id), 10))
+}
+
+func parseGQLID(id graphql.ID) (uint, error) {
+	n, err := strconv.ParseUint(string(id), 10, 32)
+	if err != nil {
+		return 0, errors.New("invalid id")
+	}
+	return uint(n), nil
+}
+
+// gqlResolver is the root of the schema.
+type gqlResolver struct {
+	h *todoHandler
+}
+
+// visible keeps the todos the caller can view.
+func (r *gqlResolver) visible(ctx context.Context, todos []entity.Todo) ([]entity.Todo, error) {
+	account, _ := gqlAccount(ctx)
+	if account.IsAdmin() {
+		return todos, nil
+	}
+	ret := todos[:0]
+	for _, t := range todos {
+		role, err := r.h.memberUsecase.TodoRole(ctx, account, t)
+		if err != nil {
+			return nil, err
+		}
+		if role >= entity.MemberRoleViewer {
+			ret = append(ret, t)
+		}
+	}
+	return ret, nil
+}
+
+func (r *gqlResolver) todoResolvers(ctx context.Context, todos []entity.Todo) []*gqlTodoResolver {
+	loaderFrom(ctx).queueTodos(todos)
+	ret := make([]*gqlTodoResolver, 0, len(todos))
+	for _, t := range todos {
+		ret = append(ret, &gqlTodoResolver{r, t})
+	}
+	return ret
+}
+
+func (r *gqlResolver) Me(ctx context.Context) (*gqlAccountResolver, error) {
+	account, _ := gqlAccount(ctx)
+	a, err := loaderFrom(ctx).account(ctx, account.Id)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return nil, errors.New("account not found")
+	}
+	return &gqlAccountResolver{r, *a}, nil
+}
+
+func (r *gqlResolver) Account(ctx context.Context, args struct{ Id graphql.ID }) (*gqlAccountResolver, error) {
+	id, err := parseGQLID(args.Id)
+	if err != nil {
+		return nil, err
+	}
+	a, err := loaderFrom(ctx).account(ctx, id)
+	if err != nil || a == nil {
+		return nil, err
+	}
+	return &gqlAccountResolver{r, *a}, nil
+}
+
+func (r *gqlResolver) Accounts(ctx context.Context) ([]*gqlAccountResolver, error) {
+	accounts, err := r.h.accountUsecase.GetAccountAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	loaderFrom(ctx).primeAccounts(accounts)
+	ret := make([]*gqlAccountResolver, 0, len(accounts))
+	for _, a := range accounts {
+		ret = append(ret, &gqlAccountResolver{r, a})
+	}
+	return ret, nil
+}
+
+func (r *gqlResolver) Todo(ctx context.Context, args struct{ Id graphql.ID }) (*gqlTodoResolver, error) {
+	id, err := parseGQLID(args.Id)
+	if err != nil {
+		return nil, err
+	}
+	todo, err := r.h.todoUsecase.GetTodo(ctx, id)
+	if err != nil || todo == nil {
+		return nil, err
+	}
+	todos, err := r.visible(ctx, []entity.Todo{*todo})
+	if err != nil {
+		return nil, err
+	}
+	if len(todos) == 0 {
+		return nil, errGQLNoAccess
+	}
+	return &gqlTodoResolver{r, *todo}, nil
+}
+
+func (r *gqlResolver) Todos(ctx context.Context) ([]*gqlTodoResolver, error) {
+	account, _ := gqlAccount(ctx)
+
+	var (
+		todos []entity.Todo
+		err   error
+	)
+	if account.IsAdmin() {
+		todos, err = r.h.todoUsecase.GetTodoAll(ctx)
+	} else {
+		todos, err = r.h.todoUsecase.GetTodoAllVisible(ctx, account.Id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.todoResolvers(ctx, todos), nil
+}
+
+// TodoChanged forwards the events the caller can view. The loader is reset
+// before each event so names stay current over a long subscription, the
+// previous event is resolved by the time the next one is accepted.
+func (r *gqlResolver) TodoChanged(ctx context.Context) (<-chan *gqlTodoEventResolver, error) {
+	events, stop := r.h.todoUsecase.WatchTodos(ctx)
+	ret := make(chan *gqlTodoEventResolver)
+	go func() {
+		defer close(ret)
+		defer stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				todos, err := r.visible(ctx, []entity.Todo{event.Todo})
+				if err != nil {
+					r.h.log.Error("http - v1 - GraphQL - TodoChanged: %v", err)
+					return
+				}
+				if len(todos) == 0 {
+					continue
+				}
+				loaderFrom(ctx).reset()
+				select {
+				case ret <- &gqlTodoEventResolver{r, event}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return ret, nil
+}
+
+type gqlAccountResolver struct {
+	root    *gqlResolver
+	account entity.Account
+}
+
+func (r *gqlAccountResolver) Id() graphql.ID {
+	return gqlID(r.account.Id)
+}
+
+func (r *gqlAccountResolver) Name() string {
+	return r.account.Name
+}
+
+func (r *gqlAccountResolver) Type() string {
+	switch r.account.AccountType {
+	case entity.AccountTypeAdmin:
+		return "ADMIN"
+	case entity.AccountTypeSuperAdmin:
+		return "SUPER_ADMIN"
+	}
+	return "USER"
+}
+
+func (r *gqlAccountResolver) AssignedTodos(ctx context.Context) ([]*gqlTodoResolver, error) {
+	todos, err := r.root.h.todoUsecase.GetTodoAllByAssignee(ctx, r.account.Id)
+	if err != nil {
+		return nil, err
+	}
+	if todos, err = r.root.visible(ctx, todos); err != nil {
+		return nil, err
+	}
+	return r.root.todoResolvers(ctx, todos), nil
+}
+
+type gqlProjectResolver struct {
+	root    *gqlResolver
+	project entity.Project
+}
+
+func (r *gqlProjectResolver) Id() graphql.ID {
+	return gqlID(r.project.Id)
+}
+
+func (r *gqlProjectResolver) Name() string {
+	return r.project.Name
+}
+
+func (r *gqlProjectResolver) Desc() string {
+	return r.project.Desc
+}
+
+func (r *gqlProjectResolver) Color() string {
+	return r.project.Color
+}
+
+func (r *gqlProjectResolver) Archived() bool {
+	return r.project.Archived
+}
+
+func (r *gqlProjectResolver) Owner(ctx context.Context) (*gqlAccountResolver, error) {
+	return r.root.accountResolver(ctx, r.project.OwnerId)
+}
+
+// accountResolver returns nil for an account not in the tenant.
+func (r *gqlResolver) accountResolver(ctx context.Context, accountID uint) (*gqlAccountResolver, error) {
+	a, err := loaderFrom(ctx).account(ctx, accountID)
+	if err != nil || a == nil {
+		return nil, err
+	}
+	return &gqlAccountResolver{r, *a}, nil
+}
+
+type gqlTodoResolver struct {
+	root *gqlResolver
+	todo entity.Todo
+}
+
+func (r *gqlTodoResolver) Id() graphql.ID {
+	return gqlID(r.todo.Id)
+}
+
+func (r *gqlTodoResolver) Name() string {
+	return r.todo.Name
+}
+
+func (r *gqlTodoResolver) Desc() string {
+	return r.todo.Desc
+}
+
+func (r *gqlTodoResolver) Status() string {
+	if r.todo.Status == entity.TodoStatusDone {
+		return "DONE"
+	}
+	return "DEFAULT"
+}
+
+func (r *gqlTodoResolver) Version() int32 {
+	return int32(r.todo.Version)
+}
+
+func (r *gqlTodoResolver) Owner(ctx context.Context) (*gqlAccountResolver, error) {
+	return r.root.accountResolver(ctx, r.todo.OwnerId)
+}
+
+// Project is null when the caller can view the todo but not its project.
+func (r *gqlTodoResolver) Project(ctx context.Context) (*gqlProjectResolver, error) {
+	if r.todo.ProjectId == 0 {
+		return nil, nil
+	}
+	p, err := loaderFrom(ctx).project(ctx, r.todo.ProjectId)
+	if err != nil || p == nil {
+		return nil, err
+	}
+	account, _ := gqlAccount(ctx)
+	role, err := r.root.h.memberUsecase.ProjectRole(ctx, account, *p)
+	if err != nil {
+		return nil, err
+	}
+	if role < entity.MemberRoleViewer {
+		return nil, nil
+	}
+	return &gqlProjectResolver{r.root, *p}, nil
+}
+
+// Assignees leaves out the accounts deleted since.
+func (r *gqlTodoResolver) Assignees(ctx context.Context) ([]*gqlAccountResolver, error) {
+	ret := make([]*gqlAccountResolver, 0, len(r.todo.Assignees))
+	for _, id := range r.todo.Assignees {
+		a, err := r.root.accountResolver(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if a != nil {
+			ret = append(ret, a)
+		}
+	}
+	return ret, nil
+}
+
+type gqlTodoEventResolver struct {
+	root  *gqlResolver
+	event entity.TodoEvent
+}
+
+func (r *gqlTodoEventResolver) Type() string {
+	switch r.event.Type {
+	case entity.TodoEventCreated:
+		return "CREATED"
+	case entity.TodoEventDeleted:
+		return "DELETED"
+	}
+	return "UPDATED"
+}
+
+func (r *gqlTodoEventResolver) Actor(ctx context.Context) (*gqlAccountResolver, error) {
+	if r.event.ActorId == 0 {
+		return nil, nil
+	}
+	return r.root.accountResolver(ctx, r.event.ActorId)
+}
+
+func (r *gqlTodoEventResolver) Todo(ctx context.Context) *gqlTodoResolver {
+	loaderFrom(ctx).queueTodos([]entity.Todo{r.event.Todo})
+	return &gqlTodoResolver{r.root, r.event.Todo}
+}
+
+func (r *gqlTodoEventResolver) At() graphql.Time {
+	return graphql.Time{Time: r.event.At}
+}
@@ -0,0 +1,100 @@
+package v1_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/adapters/db/session"
+	v1 "testcode/test3/internal/controller/http/v1"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+	"testcode/test3/pkg/logger"
+)
+
+type memProjects struct {
+	v1.ProjectUsecase
+	projects map[uint]entity.Project
+}
+
+func (r memProjects) GetProject(_ context.Context, projectID uint) (*entity.Project, error) {
+	p, ok := r.projects[projectID]
+	if !ok {
+		return nil, nil
+	}
+	return &p, nil
+}
+
+// assigneeRoles makes assignees viewers of their todos and project owners the
+// only members of projects.
+type assigneeRoles struct{ ownerRoles }
+
+func (r assigneeRoles) TodoRole(ctx context.Context, account entity.Account, todo entity.Todo) (entity.MemberRole, error) {
+	if todo.OwnerId != account.Id && todo.HasAssignee(account.Id) {
+		return entity.MemberRoleViewer, nil
+	}
+	return r.ownerRoles.TodoRole(ctx, account, todo)
+}
+
+func (assigneeRoles) ProjectRole(_ context.Context, account entity.Account, project entity.Project) (entity.MemberRole, error) {
+	if project.OwnerId == account.Id {
+		return entity.MemberRoleOwner, nil
+	}
+	return entity.MemberRoleNone, nil
+}
+
+func TestGraphQLTodoProject(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logger.New("error")
+	todos := &memTodos{nextID: 2, todos: map[uint]entity.Todo{
+		1: {Id: 1, OwnerId: 3, ProjectId: 7, Name: "assigned to alice", Assignees: []uint{2}, Version: 1},
+		2: {Id: 2, OwnerId: 2, ProjectId: 8, Name: "of alice", Version: 1},
+	}}
+	projects := memProjects{projects: map[uint]entity.Project{
+		7: {Id: 7, OwnerId: 3, Name: "secret plans"},
+		8: {Id: 8, OwnerId: 2, Name: "home"},
+	}}
+	orgs := &orgStorage{orgs: map[uint][]entity.OrgAccount{2: {{OrgId: 1, AccountId: 2, Role: entity.AccountTypeUser}}}}
+	accounts := accountUsecase{accounts: map[string]entity.Account{
+		"alice": {Id: 2, Name: "alice", Password: "pw", AccountType: entity.AccountTypeUser},
+	}}
+	e := gin.New()
+	v1.NewRouter(e, log, accounts, todos, projects, assigneeRoles{}, usecase.NewOrgUsecase(log, orgs, nopAuditor{}),
+		nil, nil, nil, nil, nil, nil, usecase.NewSessionUsecase(session.NewSessionStorage()), nil, nil)
+	token := (&orgFixture{engine: e, orgs: orgs}).login(t, "alice", 0)
+
+	body, _ := json.Marshal(v1.GraphQLRequest{Query: `{
+		assigned: todo(id: 1) { name project { name } }
+		own: todo(id: 2) { name project { name } }
+	}`})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(v1.HeaderAuthKey, token)
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+
+	type todo struct {
+		Name    string
+		Project *struct{ Name string }
+	}
+	var resp struct {
+		Data   struct{ Assigned, Own *todo }
+		Errors []interface{}
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%v: %s", err, w.Body.String())
+	}
+	if len(resp.Errors) > 0 || resp.Data.Assigned == nil || resp.Data.Own == nil {
+		t.Fatalf("response %s", w.Body.String())
+	}
+	if resp.Data.Assigned.Project != nil {
+		t.Errorf("assigned: project %+v, want null for a project alice is no member of", resp.Data.Assigned.Project)
+	}
+	if p := resp.Data.Own.Project; p == nil || p.Name != "home" {
+		t.Errorf("own: project %+v, want home", p)
+	}
+}
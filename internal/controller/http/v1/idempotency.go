@@ -0,0 +1,147 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+)
+
+const (
+	HeaderIdempotencyKey      = "Idempotency-Key"
+	HeaderIdempotencyReplayed = "Idempotent-Replayed"
+)
+
+// _idempotencyMaxBody caps the request bodies read to compare retries and
+// _idempotencyMaxResponse the responses kept to replay them, the column is a
+// MEDIUMBLOB.
+const (
+	_idempotencyMaxBody     = 1 << 20
+	_idempotencyMaxResponse = 1 << 20
+)
+
+// _idempotencySkip are the upload routes, their bodies are too large to keep
+// in memory and the requests are not replayed.
+var _idempotencySkip = map[string]bool{
+	"/v1/todos/:id/attachments": true,
+	"/v1/todos/import":          true,
+	"/v1/calendar/import":       true,
+}
+
+// responseRecorder keeps a copy of the body written to the client, up to
+// limit bytes.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (w *responseRecorder) keep(n int) bool {
+	if w.overflow || w.body.Len()+n > w.limit {
+		w.overflow = true
+		w.body.Reset()
+		return false
+	}
+	return true
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	if w.keep(len(b)) {
+		w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	if w.keep(len(s)) {
+		w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// failed tells whether the response is a server error. Errors are answered
+// with 200 and the code in the envelope, so both are checked.
+func (w *responseRecorder) failed() bool {
+	if w.Status() >= http.StatusInternalServerError {
+		return true
+	}
+	var resp struct {
+		Code ErrCode `json:"code"`
+	}
+	if json.Unmarshal(w.body.Bytes(), &resp) != nil {
+		return false
+	}
+	return resp.Code == ErrCodeInternal
+}
+
+// Idempotency replays the first response to authenticated POST requests that
+// carry an Idempotency-Key. Reusing the key for another request answers 422,
+// retrying while the first request still runs 409. Server errors, including
+// ErrCodeInternal answers and panics, release the key so the request can be
+// retried. Upload routes are not covered.
+func Idempotency(idempotency IdempotencyUsecase) func(*gin.Context) {
+	return func(c *gin.Context) {
+		key := c.Request.Header.Get(HeaderIdempotencyKey)
+		v, ok := c.Get(UserKey)
+		if c.Request.Method != http.MethodPost || key == "" || !ok || _idempotencySkip[c.FullPath()] {
+			c.Next()
+			return
+		}
+		account := v.(entity.Account)
+		ctx := c.Request.Context()
+
+		body, err := ioutil.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, _idempotencyMaxBody))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, NewResp(ErrCodeInvalidArgument, err.Error()))
+			return
+		}
+		c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		request := c.Request.Method + " " + c.Request.URL.RequestURI() + "\n" + string(body)
+		rec, err := idempotency.Begin(ctx, account.Id, key, request)
+		switch {
+		case errors.Is(err, usecase.ErrIdempotencyKeyInvalid):
+			c.AbortWithStatusJSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+			return
+		case errors.Is(err, usecase.ErrIdempotencyKeyReused):
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, NewResp(ErrCodeConflict, err.Error()))
+			return
+		case errors.Is(err, usecase.ErrIdempotencyKeyInProgress):
+			c.AbortWithStatusJSON(http.StatusConflict, NewResp(ErrCodeConflict, err.Error()))
+			return
+		case err != nil:
+			c.AbortWithStatusJSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+			return
+		}
+		if rec != nil {
+			c.Header(HeaderIdempotencyReplayed, "true")
+			c.Data(rec.StatusCode, rec.ContentType, rec.Body)
+			c.Abort()
+			return
+		}
+
+		// the key is released unless the response was stored, a panicking
+		// handler included
+		finished := false
+		defer func() {
+			if !finished {
+				_ = idempotency.Release(ctx, account.Id, key)
+			}
+		}()
+
+		w := &responseRecorder{ResponseWriter: c.Writer, limit: _idempotencyMaxResponse}
+		c.Writer = w
+		c.Next()
+
+		if w.overflow || w.failed() {
+			return
+		}
+		finished = idempotency.Finish(ctx, account.Id, key, w.Status(), w.Header().Get("Content-Type"), w.body.Bytes()) == nil
+	}
+}
@@ -0,0 +1,136 @@
+package v1_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	v1 "testcode/test3/internal/controller/http/v1"
+	"testcode/test3/internal/domain/entity"
+)
+
+// idempotencyUsecase keeps the reserved and finished keys in memory.
+type idempotencyUsecase struct {
+	mu       sync.Mutex
+	records  map[string]*entity.IdempotencyRecord
+	released []string
+}
+
+func newIdempotencyUsecase() *idempotencyUsecase {
+	return &idempotencyUsecase{records: map[string]*entity.IdempotencyRecord{}}
+}
+
+func (r *idempotencyUsecase) Begin(_ context.Context, _ uint, key string, request string) (*entity.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rec, ok := r.records[key]; ok {
+		return rec, nil
+	}
+	r.records[key] = &entity.IdempotencyRecord{Key: key, RequestHash: request}
+	return nil, nil
+}
+
+func (r *idempotencyUsecase) Finish(_ context.Context, _ uint, key string, statusCode int, contentType string, body []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec := r.records[key]
+	rec.Completed, rec.StatusCode, rec.ContentType, rec.Body = true, statusCode, contentType, body
+	return nil
+}
+
+func (r *idempotencyUsecase) Release(_ context.Context, _ uint, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.records, key)
+	r.released = append(r.released, key)
+	return nil
+}
+
+func (r *idempotencyUsecase) stored(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec, ok := r.records[key]
+	return ok && rec.Completed
+}
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	idempotency := newIdempotencyUsecase()
+	calls := 0
+
+	e := gin.New()
+	e.Use(func(c *gin.Context) { c.Set(v1.UserKey, entity.Account{Id: 1}) })
+	e.Use(v1.Idempotency(idempotency))
+	e.POST("/ok", func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusOK, v1.NewResp(v1.ErrCodeNone, "ok"))
+	})
+	e.POST("/invalid", func(c *gin.Context) {
+		c.JSON(http.StatusOK, v1.NewResp(v1.ErrCodeInvalidArgument, "bad"))
+	})
+	e.POST("/internal", func(c *gin.Context) {
+		c.JSON(http.StatusOK, v1.NewResp(v1.ErrCodeInternal, "db down"))
+	})
+	e.POST("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	e.POST("/large", func(c *gin.Context) {
+		c.String(http.StatusOK, strings.Repeat("x", 2<<20))
+	})
+	e.POST("/v1/todos/import", func(c *gin.Context) {
+		c.JSON(http.StatusOK, v1.NewResp(v1.ErrCodeNone, "ok"))
+	})
+
+	post := func(path string, key string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set(v1.HeaderIdempotencyKey, key)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	// the second request is answered from the stored response
+	post("/ok", "k-ok", "{}")
+	if w := post("/ok", "k-ok", "{}"); calls != 1 || w.Header().Get(v1.HeaderIdempotencyReplayed) != "true" {
+		t.Errorf("ok: %d calls, replayed %q, want 1 call and a replay", calls, w.Header().Get(v1.HeaderIdempotencyReplayed))
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		stored bool
+	}{
+		{"client error", "/invalid", true},
+		{"internal error", "/internal", false},
+		{"large response", "/large", false},
+		{"upload route", "/v1/todos/import", false},
+	}
+	for _, tt := range tests {
+		key := "k" + tt.path
+		post(tt.path, key, "{}")
+		if got := idempotency.stored(key); got != tt.stored {
+			t.Errorf("%s: stored %v, want %v", tt.name, got, tt.stored)
+		}
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("panic: not propagated")
+			}
+		}()
+		post("/panic", "k-panic", "{}")
+	}()
+	if idempotency.stored("k-panic") || len(idempotency.records) != 2 {
+		t.Errorf("panic: key kept, records %v", idempotency.records)
+	}
+
+	w := post("/ok", "k-body", strings.Repeat("x", 2<<20))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("large body: status %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+}
@@ -0,0 +1,189 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/controller/http/dto"
+	"testcode/test3/internal/domain/entity"
+)
+
+// checkResourceRole checks that account has at least minRole on the shared
+// project or todo.
+func (r *todoHandler) checkResourceRole(ctx context.Context, resourceType entity.MemberResource, resourceID uint, account entity.Account, minRole entity.MemberRole) (ErrCode, error) {
+	if resourceType == entity.MemberResourceProject {
+		_, code, err := r.writableProject(ctx, resourceID, account, minRole)
+		return code, err
+	}
+
+	_, role, code, err := r.todoAccess(ctx, resourceID, account)
+	if err != nil {
+		return code, err
+	}
+	if role < minRole {
+		return ErrCodeNoAccess, errors.New("No access")
+	}
+	return ErrCodeNone, nil
+}
+
+func (r *todoHandler) GetMembers(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var req dto.GetMembersRequest
+	if err := c.ShouldBind(&req); err != nil {
+		r.log.Error("http - v1 - GetMembers: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	resourceType := entity.MemberResource(req.ResourceType)
+	if code, err := r.checkResourceRole(c.Request.Context(), resourceType, req.ResourceId, account, entity.MemberRoleViewer); err != nil {
+		r.log.Error("http - v1 - GetMembers: %v", err)
+		c.JSON(http.StatusOK, NewResp(code, err.Error()))
+		return
+	}
+
+	resp, err := r.memberUsecase.GetMemberAllByResource(c.Request.Context(), resourceType, req.ResourceId)
+	if err != nil {
+		r.log.Error("http - v1 - GetMembers: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", resp))
+}
+
+func (r *todoHandler) GetInvites(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	resp, err := r.memberUsecase.GetMemberAllByAccount(c.Request.Context(), account.Id)
+	if err != nil {
+		r.log.Error("http - v1 - GetInvites: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", resp))
+}
+
+func (r *todoHandler) InviteMember(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var req dto.InviteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.log.Error("http - v1 - InviteMember: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	resourceType := entity.MemberResource(req.ResourceType)
+	if code, err := r.checkResourceRole(c.Request.Context(), resourceType, req.ResourceId, account, entity.MemberRoleOwner); err != nil {
+		r.log.Error("http - v1 - InviteMember: %v", err)
+		c.JSON(http.StatusOK, NewResp(code, err.Error()))
+		return
+	}
+
+	invitee, err := r.accountUsecase.GetAccount(c.Request.Context(), req.AccountId)
+	if err != nil {
+		r.log.Error("http - v1 - InviteMember: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+	if invitee == nil {
+		err = errors.New("account not found")
+		r.log.Error("http - v1 - InviteMember: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	member := entity.Member{
+		ResourceType: resourceType,
+		ResourceId:   req.ResourceId,
+		AccountId:    req.AccountId,
+		Role:         entity.MemberRole(req.Role),
+		InvitedBy:    account.Id,
+	}
+	id, err := r.memberUsecase.InviteMember(c.Request.Context(), member)
+	if err != nil {
+		r.log.Error("http - v1 - InviteMember: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", InviteMemberResponse{id}))
+}
+
+func (r *todoHandler) AcceptInvite(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var req dto.AcceptInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.log.Error("http - v1 - AcceptInvite: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	member, err := r.memberUsecase.GetMember(c.Request.Context(), req.Id)
+	if err != nil {
+		r.log.Error("http - v1 - AcceptInvite: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+	if member == nil || member.AccountId != account.Id {
+		err = errors.New("invite not found")
+		r.log.Error("http - v1 - AcceptInvite: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	if err = r.memberUsecase.AcceptInvite(c.Request.Context(), req.Id); err != nil {
+		r.log.Error("http - v1 - AcceptInvite: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok"))
+}
+
+func (r *todoHandler) RemoveMember(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var req dto.RemoveMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.log.Error("http - v1 - RemoveMember: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	member, err := r.memberUsecase.GetMember(c.Request.Context(), req.Id)
+	if err != nil {
+		r.log.Error("http - v1 - RemoveMember: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+	if member == nil {
+		err = errors.New("not found")
+		r.log.Error("http - v1 - RemoveMember: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+	// members may leave or decline on their own
+	if member.AccountId != account.Id {
+		code, err := r.checkResourceRole(c.Request.Context(), member.ResourceType, member.ResourceId, account, entity.MemberRoleOwner)
+		if err != nil {
+			r.log.Error("http - v1 - RemoveMember: %v", err)
+			c.JSON(http.StatusOK, NewResp(code, err.Error()))
+			return
+		}
+	}
+
+	if err = r.memberUsecase.RemoveMember(c.Request.Context(), req.Id); err != nil {
+		r.log.Error("http - v1 - RemoveMember: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok"))
+}
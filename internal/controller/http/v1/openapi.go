@@ -0,0 +1,479 @@
+package v1
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/controller/http/dto"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/jsonpatch"
+	"testcode/test3/pkg/openapi"
+)
+
+const (
+	_apiTokenScheme = "token"
+	_apiBasicScheme = "basic"
+)
+
+// apiRoute documents a route of NewRouter, each route has to have one.
+type apiRoute struct {
+	method  string
+	path    string
+	tag     string
+	summary string
+	desc    string
+	uri     interface{}
+	query   interface{}
+	body    interface{}
+	// bodyTypes are the media types of a body that is not json
+	bodyTypes []string
+	// upload takes the body as a multipart "file" field too, see uploadedFile
+	upload bool
+	// data is the payload of ResponseMessage
+	data interface{}
+	// content are the media types of a response that is not ResponseMessage
+	content []string
+	// statuses are answered besides 200
+	statuses []int
+	ifMatch  bool
+	// auth is a security scheme, "" for the token and "none" for none
+	auth string
+}
+
+var _apiStatusText = map[int]string{
+	http.StatusSwitchingProtocols:           "Switched to WebSocket",
+	http.StatusCreated:                      "Created",
+	http.StatusNoContent:                    "No content",
+	http.StatusMovedPermanently:             "Redirect",
+	http.StatusPartialContent:               "The requested range",
+	http.StatusBadRequest:                   "Invalid request",
+	http.StatusUnauthorized:                 "Missing or wrong credentials",
+	http.StatusForbidden:                    "No access",
+	http.StatusNotFound:                     "Not found",
+	http.StatusConflict:                     "The todo changed, data holds its current state",
+	http.StatusPreconditionFailed:           "If-Match does not match, data holds the current state",
+	http.StatusUnsupportedMediaType:         "Unsupported patch media type",
+	http.StatusRequestedRangeNotSatisfiable: "Range outside the file",
+}
+
+var _apiRoutes = []apiRoute{
+	{method: "POST", path: "/v1/login", tag: "auth", summary: "Log in", auth: "none",
+		desc: "org_id picks the org to work in, it is required for accounts in more than one org.",
+		body: dto.LoginRequest{}, data: LoginResponse{}},
+	{method: "POST", path: "/v1/logout", tag: "auth", summary: "Log out"},
+
+	{method: "GET", path: "/v1/accounts", tag: "accounts", summary: "List accounts", data: []entity.Account{}},
+	{method: "POST", path: "/v1/account", tag: "accounts", summary: "Create an account", desc: "Admins only.",
+		body: dto.CreateAccountRequest{}},
+	{method: "DELETE", path: "/v1/account", tag: "accounts", summary: "Delete an account",
+		desc: "Admins only. reassign_to is required with the reassign_to strategy.",
+		body: dto.DeleteAccountRequest{}, data: entity.AccountDeletionReport{}},
+
+	{method: "GET", path: "/v1/audit", tag: "audit", summary: "Page through the audit log",
+		query: dto.GetAuditRequest{}, data: AuditResponse{}},
+	{method: "GET", path: "/v1/audit/export", tag: "audit", summary: "Export the audit log",
+		query: dto.GetAuditRequest{}, content: []string{"text/csv", "application/x-ndjson"}},
+
+	{method: "GET", path: "/v1/orgs", tag: "orgs", summary: "List orgs", data: []entity.Org{}},
+	{method: "POST", path: "/v1/org", tag: "orgs", summary: "Create an org",
+		body: dto.CreateOrgRequest{}, data: CreateOrgResponse{}},
+	{method: "POST", path: "/v1/org/account", tag: "orgs", summary: "Add an account to an org",
+		body: dto.AddOrgAccountRequest{}},
+	{method: "DELETE", path: "/v1/org/account", tag: "orgs", summary: "Remove an account from an org",
+		body: dto.RemoveOrgAccountRequest{}},
+
+	{method: "GET", path: "/v1/todos", tag: "todos", summary: "List visible todos", data: []entity.Todo{}},
+	{method: "GET", path: "/v1/todo", tag: "todos", summary: "Get a todo", desc: "ETag holds the version.",
+		query: dto.GetTodoRequest{}, data: entity.Todo{}},
+	{method: "POST", path: "/v1/todo", tag: "todos", summary: "Create a todo",
+		body: dto.CreateTodoRequest{}, data: CreateTodoResponse{}},
+	{method: "PUT", path: "/v1/todo", tag: "todos", summary: "Update a todo",
+		desc:     "If-Match or version make the update conditional. Assignees may only change the status.",
+		body:     dto.UpdateTodoRequest{},
+		statuses: []int{http.StatusConflict, http.StatusPreconditionFailed}, ifMatch: true},
+	{method: "GET", path: "/v1/events", tag: "todos", summary: "Stream todo changes as Server-Sent Events",
+		desc: "Events are created, updated and deleted with the todo as data. Last-Event-ID or last_event_id " +
+			"resumes after that event, a reset event means the missed events expired and the client has to reload. " +
+			"The token may go in the token parameter.",
+		query: dto.EventsRequest{}, content: []string{"text/event-stream"}},
+	{method: "GET", path: "/v1/events/ws", tag: "todos", summary: "Stream todo changes over WebSocket",
+		desc: "Every message is a todo event with its id, {\"type\": \"reset\"} as for /v1/events. " +
+			"A client falling behind is closed with 1013 and resumes with last_event_id.",
+		query: dto.EventsRequest{}, content: []string{}, statuses: []int{http.StatusSwitchingProtocols}},
+	{method: "PATCH", path: "/v1/todos/:id", tag: "todos", summary: "Patch a todo",
+		desc:      "Takes a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902) of name, desc and status.",
+		uri:       dto.TodoUri{},
+		bodyTypes: []string{jsonpatch.MergePatchType, jsonpatch.JSONPatchType},
+		data:      entity.Todo{},
+		statuses:  []int{http.StatusConflict, http.StatusPreconditionFailed, http.StatusUnsupportedMediaType},
+		ifMatch:   true},
+	{method: "POST", path: "/v1/todos/bulk", tag: "todos", summary: "Run todo operations in bulk",
+		desc: "atomic runs all operations in one transaction and answers 409 with the failed one.",
+		body: dto.BulkTodoRequest{}, data: BulkTodoResponse{}, statuses: []int{http.StatusConflict}},
+	{method: "GET", path: "/v1/todos/export", tag: "todos", summary: "Export visible todos",
+		query:   dto.ExportTodoRequest{},
+		content: []string{"application/json", "text/csv", "application/x-ndjson", "text/plain", "text/markdown"}},
+	{method: "POST", path: "/v1/todos/import", tag: "todos", summary: "Import todos",
+		desc: "Nothing is imported unless every row is valid, the report lists the invalid rows. " +
+			"Files are limited to 10 MiB and 1000 rows.",
+		query:     dto.ImportTodoRequest{},
+		bodyTypes: []string{"text/csv", "application/json", "application/x-ndjson", "text/plain", "text/markdown"},
+		upload:    true, data: entity.TodoImportReport{}},
+	{method: "DELETE", path: "/v1/todo", tag: "todos", summary: "Delete a todo", body: dto.DeleteTodoRequest{},
+		statuses: []int{http.StatusPreconditionFailed}, ifMatch: true},
+
+	{method: "GET", path: "/v1/todos/search", tag: "search", summary: "Search todos",
+		query: dto.SearchTodoRequest{}, data: []entity.TodoSearchHit{}},
+	{method: "GET", path: "/v1/todos/filter", tag: "search", summary: "Filter todos",
+		desc:  "A query that does not parse answers InvalidArgument with the position in data.pos.",
+		query: dto.FilterTodoRequest{}, data: []entity.Todo{}},
+	{method: "GET", path: "/v1/filters", tag: "search", summary: "List saved filters", data: []entity.SavedFilter{}},
+	{method: "POST", path: "/v1/filter", tag: "search", summary: "Save a filter",
+		body: dto.SaveFilterRequest{}, data: SaveFilterResponse{}},
+	{method: "DELETE", path: "/v1/filter", tag: "search", summary: "Delete a saved filter",
+		body: dto.DeleteSavedFilterRequest{}},
+
+	{method: "GET", path: "/v1/todos/assigned", tag: "assignees", summary: "List todos assigned to the caller",
+		data: []entity.Todo{}},
+	{method: "POST", path: "/v1/todo/assignee", tag: "assignees", summary: "Assign a todo",
+		body: dto.AssignTodoRequest{}},
+	{method: "DELETE", path: "/v1/todo/assignee", tag: "assignees", summary: "Unassign a todo",
+		body: dto.UnassignTodoRequest{}},
+	{method: "PUT", path: "/v1/todo/project", tag: "todos", summary: "Move a todo to a project",
+		desc: "project_id 0 takes the todo out of its project.", body: dto.MoveTodoRequest{},
+		statuses: []int{http.StatusPreconditionFailed}, ifMatch: true},
+
+	{method: "GET", path: "/v1/todos/:id/history", tag: "history", summary: "List revisions of a todo",
+		uri: dto.TodoUri{}, data: []entity.TodoRevision{}},
+	{method: "POST", path: "/v1/todos/:id/history/:revision_id/restore", tag: "history",
+		summary: "Restore a revision of a todo", uri: dto.RevisionUri{},
+		statuses: []int{http.StatusPreconditionFailed}, ifMatch: true},
+
+	{method: "GET", path: "/v1/todos/:id/comments", tag: "comments", summary: "Page through comments",
+		uri: dto.TodoUri{}, query: dto.GetCommentsRequest{}, data: CommentsResponse{}},
+	{method: "POST", path: "/v1/todos/:id/comments", tag: "comments", summary: "Comment on a todo",
+		uri: dto.TodoUri{}, body: dto.CreateCommentRequest{}, data: CreateCommentResponse{}},
+	{method: "PUT", path: "/v1/todos/:id/comments/:comment_id", tag: "comments", summary: "Edit a comment",
+		uri: dto.CommentUri{}, body: dto.EditCommentRequest{}},
+	{method: "DELETE", path: "/v1/todos/:id/comments/:comment_id", tag: "comments", summary: "Delete a comment",
+		uri: dto.CommentUri{}},
+
+	{method: "GET", path: "/v1/todos/:id/attachments", tag: "attachments", summary: "List attachments",
+		uri: dto.TodoUri{}, data: []entity.Attachment{}},
+	{method: "POST", path: "/v1/todos/:id/attachments", tag: "attachments", summary: "Upload an attachment",
+		uri: dto.TodoUri{}, upload: true, data: CreateAttachmentResponse{}},
+	{method: "GET", path: "/v1/todos/:id/attachments/:attachment_id", tag: "attachments",
+		summary: "Download an attachment", desc: "Supports a single byte Range.",
+		uri: dto.AttachmentUri{}, content: []string{"application/octet-stream"},
+		statuses: []int{http.StatusPartialContent, http.StatusRequestedRangeNotSatisfiable}},
+	{method: "DELETE", path: "/v1/todos/:id/attachments/:attachment_id", tag: "attachments",
+		summary: "Delete an attachment", uri: dto.AttachmentUri{}},
+
+	{method: "POST", path: "/v1/calendar/token", tag: "calendar", summary: "Create the calendar feed token",
+		desc: "Replaces the previous token.", data: CalendarTokenResponse{}},
+	{method: "DELETE", path: "/v1/calendar/token", tag: "calendar", summary: "Revoke the calendar feed token"},
+	{method: "GET", path: "/v1/calendar/feed/:token", tag: "calendar", summary: "iCalendar feed of todos",
+		desc: "The token is the credential, a trailing .ics is ignored.", auth: "none",
+		uri: dto.CalendarFeedUri{}, content: []string{"text/calendar"}, statuses: []int{http.StatusNotFound}},
+	{method: "POST", path: "/v1/calendar/import", tag: "calendar", summary: "Import VTODOs from an .ics file",
+		query: dto.ImportCalendarRequest{}, bodyTypes: []string{"text/calendar"}, upload: true,
+		data: entity.TodoImportReport{}},
+
+	{method: "GET", path: "/v1/trash/todos", tag: "trash", summary: "List trashed todos", data: []entity.Todo{}},
+	{method: "POST", path: "/v1/trash/todo/restore", tag: "trash", summary: "Restore a trashed todo",
+		body: dto.RestoreTodoRequest{}},
+	{method: "GET", path: "/v1/trash/accounts", tag: "trash", summary: "List trashed accounts",
+		data: []entity.Account{}},
+	{method: "POST", path: "/v1/trash/account/restore", tag: "trash", summary: "Restore a trashed account",
+		body: dto.RestoreAccountRequest{}},
+
+	{method: "GET", path: "/v1/projects", tag: "projects", summary: "List projects", data: []entity.Project{}},
+	{method: "GET", path: "/v1/project", tag: "projects", summary: "Get a project",
+		query: dto.GetProjectRequest{}, data: entity.Project{}},
+	{method: "GET", path: "/v1/project/todos", tag: "projects", summary: "List todos of a project",
+		query: dto.GetProjectRequest{}, data: []entity.Todo{}},
+	{method: "POST", path: "/v1/project", tag: "projects", summary: "Create a project",
+		body: dto.CreateProjectRequest{}, data: CreateProjectResponse{}},
+	{method: "PUT", path: "/v1/project", tag: "projects", summary: "Update a project",
+		body: dto.UpdateProjectRequest{}},
+	{method: "PUT", path: "/v1/project/archive", tag: "projects", summary: "Archive or unarchive a project",
+		body: dto.ArchiveProjectRequest{}},
+	{method: "DELETE", path: "/v1/project", tag: "projects", summary: "Delete a project, its todos go to the trash",
+		body: dto.DeleteProjectRequest{}},
+
+	{method: "GET", path: "/v1/members", tag: "members", summary: "List members of a todo or project",
+		query: dto.GetMembersRequest{}, data: []entity.Member{}},
+	{method: "GET", path: "/v1/invites", tag: "members", summary: "List invites of the caller",
+		data: []entity.Member{}},
+	{method: "POST", path: "/v1/member", tag: "members", summary: "Invite a member",
+		body: dto.InviteMemberRequest{}, data: InviteMemberResponse{}},
+	{method: "POST", path: "/v1/member/accept", tag: "members", summary: "Accept an invite",
+		body: dto.AcceptInviteRequest{}},
+	{method: "DELETE", path: "/v1/member", tag: "members", summary: "Remove a member",
+		body: dto.RemoveMemberRequest{}},
+
+	{method: "GET", path: "/.well-known/caldav", tag: "caldav", summary: "CalDAV service discovery",
+		auth: "none", content: []string{}, statuses: []int{http.StatusMovedPermanently}},
+	{method: "OPTIONS", path: "/dav/*path", tag: "caldav", summary: "DAV capabilities",
+		desc: "PROPFIND and REPORT, which OpenAPI cannot describe, serve the collection /dav/calendars/todos/.",
+		auth: "none", content: []string{}},
+	{method: "GET", path: "/dav/*path", tag: "caldav", summary: "Get a VTODO resource <id>.ics",
+		auth: _apiBasicScheme, content: []string{"text/calendar"}, statuses: []int{http.StatusNotFound}},
+	{method: "HEAD", path: "/dav/*path", tag: "caldav", summary: "Check a VTODO resource <id>.ics",
+		auth: _apiBasicScheme, content: []string{}, statuses: []int{http.StatusNotFound}},
+	{method: "PUT", path: "/dav/*path", tag: "caldav", summary: "Create or update a VTODO resource",
+		desc: "A new resource is named after the todo, see Location.",
+		auth: _apiBasicScheme, bodyTypes: []string{"text/calendar"}, content: []string{},
+		statuses: []int{http.StatusCreated, http.StatusNoContent, http.StatusBadRequest, http.StatusForbidden,
+			http.StatusPreconditionFailed}, ifMatch: true},
+	{method: "DELETE", path: "/dav/*path", tag: "caldav", summary: "Delete a VTODO resource",
+		auth: _apiBasicScheme, content: []string{},
+		statuses: []int{http.StatusNoContent, http.StatusForbidden, http.StatusNotFound, http.StatusPreconditionFailed},
+		ifMatch:  true},
+
+	{method: "POST", path: "/graphql", tag: "graphql", summary: "Run a GraphQL query",
+		desc: "The response is a GraphQL response, not a ResponseMessage. Queries are limited in depth and cost, " +
+			"subscriptions run over /graphql/ws.",
+		body: GraphQLRequest{}, content: []string{"application/json"}, statuses: []int{http.StatusBadRequest}},
+	{method: "GET", path: "/graphql/ws", tag: "graphql", summary: "GraphQL over WebSocket",
+		desc: "Queries and subscriptions with the graphql-transport-ws protocol, " +
+			"the token goes in the connection_init payload as {\"token\": ...} or the token header.",
+		auth: "none", content: []string{}, statuses: []int{http.StatusSwitchingProtocols}},
+
+	{method: "GET", path: "/openapi.json", tag: "docs", summary: "This document", auth: "none",
+		content: []string{"application/json"}},
+	{method: "GET", path: "/docs", tag: "docs", summary: "API reference page", auth: "none",
+		content: []string{"text/html"}},
+}
+
+// _apiUndocumented are methods OpenAPI has no operation for.
+var _apiUndocumented = map[string]struct{}{"PROPFIND": {}, "REPORT": {}}
+
+// checkAPIRoutes lists the registered routes without an apiRoute and the
+// apiRoutes without a route, the router test runs it.
+func checkAPIRoutes(routes gin.RoutesInfo) error {
+	documented := make(map[string]bool, len(_apiRoutes))
+	for _, a := range _apiRoutes {
+		documented[a.method+" "+a.path] = false
+	}
+	var missing, stale []string
+	for _, rt := range routes {
+		if _, ok := _apiUndocumented[rt.Method]; ok {
+			continue
+		}
+		key := rt.Method + " " + rt.Path
+		if _, ok := documented[key]; !ok {
+			missing = append(missing, key)
+			continue
+		}
+		documented[key] = true
+	}
+	for key, seen := range documented {
+		if !seen {
+			stale = append(stale, key)
+		}
+	}
+	sort.Strings(stale)
+	switch {
+	case len(missing) > 0:
+		return fmt.Errorf("routes missing from _apiRoutes: %s", strings.Join(missing, ", "))
+	case len(stale) > 0:
+		return fmt.Errorf("_apiRoutes without a route: %s", strings.Join(stale, ", "))
+	}
+	return nil
+}
+
+// apiPath turns gin parameters into OpenAPI ones.
+func apiPath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, s := range parts {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			parts[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func apiOperationID(a apiRoute) string {
+	id := strings.ToLower(a.method)
+	for _, s := range strings.FieldsFunc(a.path, func(r rune) bool { return r == '/' || r == '.' || r == '-' || r == '_' }) {
+		s = strings.TrimLeft(s, ":*")
+		id += strings.ToUpper(s[:1]) + s[1:]
+	}
+	return id
+}
+
+func apiErrCodes() *openapi.Schema {
+	return &openapi.Schema{
+		Type: "integer",
+		Description: "0 None, 1 InvalidArgument, 2 Internal, 3 Unauthenticated, 4 NoAccess, 5 Conflict, " +
+			"6 PreconditionFailed. Errors are answered with status 200 unless the operation lists another.",
+		Enum: []interface{}{ErrCodeNone, ErrCodeInvalidArgument, ErrCodeInternal, ErrCodeUnauthenticated,
+			ErrCodeNoAccess, ErrCodeConflict, ErrCodePreconditionFailed},
+	}
+}
+
+// newAPIDocument describes _apiRoutes.
+func newAPIDocument() *openapi.Document {
+	g := openapi.NewGenerator()
+	g.Schema(ResponseMessage{})
+	g.Schemas["ResponseMessage"].Properties["code"] = apiErrCodes()
+	g.Schemas["ResponseMessage"].Required = []string{"code", "data"}
+
+	doc := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:   "Todo API",
+			Version: "v1",
+			Description: "JSON responses are a ResponseMessage, code tells the outcome. " +
+				"POST requests with an Idempotency-Key header are answered once and replayed.",
+		},
+		Paths:    make(map[string]openapi.PathItem),
+		Security: []openapi.SecurityRequirement{{_apiTokenScheme: {}}},
+	}
+	doc.Components.SecuritySchemes = map[string]*openapi.SecurityScheme{
+		_apiTokenScheme: {Type: "apiKey", In: "header", Name: HeaderAuthKey, Description: "Session token from /v1/login."},
+		_apiBasicScheme: {Type: "http", Scheme: "basic",
+			Description: "Account name, or name@org_id, and password."},
+	}
+
+	tags := make(map[string]struct{})
+	for _, a := range _apiRoutes {
+		op := &openapi.Operation{
+			Tags:        []string{a.tag},
+			Summary:     a.summary,
+			Description: a.desc,
+			OperationID: apiOperationID(a),
+			Responses:   make(map[string]*openapi.Response),
+		}
+		tags[a.tag] = struct{}{}
+		switch a.auth {
+		case "none":
+			op.Security = &[]openapi.SecurityRequirement{}
+		case _apiBasicScheme:
+			op.Security = &[]openapi.SecurityRequirement{{_apiBasicScheme: {}}}
+		}
+
+		if a.uri != nil {
+			op.Parameters = append(op.Parameters, g.Parameters(a.uri, "uri", "path")...)
+		} else if strings.Contains(a.path, "*") {
+			op.Parameters = append(op.Parameters, openapi.Parameter{Name: "path", In: "path", Required: true,
+				Schema: &openapi.Schema{Type: "string"}})
+		}
+		if a.query != nil {
+			op.Parameters = append(op.Parameters, g.Parameters(a.query, "form", "query")...)
+		}
+		if a.method == "POST" && a.auth == "" {
+			op.Parameters = append(op.Parameters, openapi.Parameter{Name: HeaderIdempotencyKey, In: "header",
+				Description: "Replays the first response to a retry with the same key.",
+				Schema:      &openapi.Schema{Type: "string"}})
+		}
+		if a.ifMatch {
+			op.Parameters = append(op.Parameters, openapi.Parameter{Name: HeaderIfMatch, In: "header",
+				Description: "ETag of the version the change is based on.", Schema: &openapi.Schema{Type: "string"}})
+		}
+
+		switch {
+		case a.body != nil:
+			op.RequestBody = &openapi.RequestBody{Required: true, Content: openapi.JSON(g.Schema(a.body))}
+		case len(a.bodyTypes) > 0 || a.upload:
+			op.RequestBody = &openapi.RequestBody{Required: true, Content: make(map[string]openapi.MediaType)}
+			for _, t := range a.bodyTypes {
+				s := &openapi.Schema{Type: "string", Format: "binary"}
+				switch t {
+				case jsonpatch.MergePatchType:
+					s = g.Schema(todoDocument{})
+				case jsonpatch.JSONPatchType:
+					s = g.Schema([]jsonpatch.Operation{})
+				}
+				op.RequestBody.Content[t] = openapi.MediaType{Schema: s}
+			}
+			if a.upload {
+				op.RequestBody.Content["multipart/form-data"] = openapi.MediaType{Schema: &openapi.Schema{
+					Type:       "object",
+					Properties: map[string]*openapi.Schema{"file": {Type: "string", Format: "binary"}},
+					Required:   []string{"file"},
+				}}
+			}
+		}
+
+		if a.content != nil {
+			if len(a.content) > 0 {
+				resp := &openapi.Response{Description: "OK", Content: make(map[string]openapi.MediaType)}
+				for _, t := range a.content {
+					resp.Content[t] = openapi.MediaType{Schema: &openapi.Schema{Type: "string", Format: "binary"}}
+				}
+				op.Responses["200"] = resp
+			} else if !apiHasStatus(a, http.StatusCreated) && !apiHasStatus(a, http.StatusNoContent) &&
+				!apiHasStatus(a, http.StatusMovedPermanently) && !apiHasStatus(a, http.StatusSwitchingProtocols) {
+				op.Responses["200"] = &openapi.Response{Description: "OK"}
+			}
+		} else {
+			s := openapi.Ref("ResponseMessage")
+			if a.data != nil {
+				s = &openapi.Schema{AllOf: []*openapi.Schema{s, {
+					Type:       "object",
+					Properties: map[string]*openapi.Schema{"data": g.Schema(a.data)},
+				}}}
+			}
+			op.Responses["200"] = &openapi.Response{Description: "ResponseMessage, see code", Content: openapi.JSON(s)}
+		}
+		for _, status := range a.statuses {
+			resp := &openapi.Response{Description: _apiStatusText[status]}
+			if a.content == nil {
+				resp.Content = openapi.JSON(openapi.Ref("ResponseMessage"))
+			}
+			op.Responses[fmt.Sprint(status)] = resp
+		}
+
+		p := apiPath(a.path)
+		if doc.Paths[p] == nil {
+			doc.Paths[p] = make(openapi.PathItem)
+		}
+		doc.Paths[p][strings.ToLower(a.method)] = op
+	}
+
+	for name := range tags {
+		doc.Tags = append(doc.Tags, openapi.Tag{Name: name})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+	doc.Components.Schemas = g.Schemas
+	return doc
+}
+
+func apiHasStatus(a apiRoute, status int) bool {
+	for _, s := range a.statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// OpenAPI serves the document of the routes.
+func OpenAPI(doc *openapi.Document) func(*gin.Context) {
+	body, err := json.Marshal(doc)
+	return func(c *gin.Context) {
+		if err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.Data(http.StatusOK, "application/json", body)
+	}
+}
+
+// _apiDocsPage renders /openapi.json, it is served from the binary and loads
+// nothing from elsewhere.
+//
+//go:embed docs.html
+var _apiDocsPage []byte
+
+// APIDocs serves the API reference page.
+func APIDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", _apiDocsPage)
+}
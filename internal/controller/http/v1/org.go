@@ -0,0 +1,170 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/controller/http/dto"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+)
+
+// canManageOrg reports whether account may change membership of the org:
+// superadmins manage every org, org admins only the one they are logged into.
+func canManageOrg(account entity.Account, orgID uint) bool {
+	if account.AccountType == entity.AccountTypeSuperAdmin {
+		return true
+	}
+	return account.AccountType == entity.AccountTypeAdmin && account.TenantId == orgID
+}
+
+// canInviteAccount reports whether account may add the account with the
+// memberships orgs to an org. Org admins may only take accounts that belong
+// to no org they do not manage, else they could pull in an account of
+// another tenant and act for it.
+func canInviteAccount(account entity.Account, orgs []entity.OrgAccount) bool {
+	for _, o := range orgs {
+		if !canManageOrg(account, o.OrgId) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *todoHandler) GetOrgs(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	if account.AccountType == entity.AccountTypeSuperAdmin {
+		resp, err := r.orgUsecase.GetOrgAll(c.Request.Context())
+		if err != nil {
+			r.log.Error("http - v1 - GetOrgs: %v", err)
+			c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+			return
+		}
+		c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", resp))
+		return
+	}
+
+	orgs, err := r.orgUsecase.GetAccountOrgs(c.Request.Context(), account.Id)
+	if err != nil {
+		r.log.Error("http - v1 - GetOrgs: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	resp := make([]entity.Org, 0, len(orgs))
+	for _, o := range orgs {
+		org, err := r.orgUsecase.GetOrg(c.Request.Context(), o.OrgId)
+		if err != nil {
+			r.log.Error("http - v1 - GetOrgs: %v", err)
+			c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+			return
+		}
+		if org != nil {
+			resp = append(resp, *org)
+		}
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", resp))
+}
+
+func (r *todoHandler) CreateOrg(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+	if account.AccountType != entity.AccountTypeSuperAdmin {
+		err := errors.New("No access")
+		r.log.Error("http - v1 - CreateOrg: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+		return
+	}
+
+	var req dto.CreateOrgRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.log.Error("http - v1 - CreateOrg: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	id, err := r.orgUsecase.CreateOrg(c.Request.Context(), entity.Org{Name: req.Name})
+	if err != nil {
+		r.log.Error("http - v1 - CreateOrg: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", CreateOrgResponse{id}))
+}
+
+func (r *todoHandler) AddOrgAccount(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var req dto.AddOrgAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.log.Error("http - v1 - AddOrgAccount: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	if !canManageOrg(account, req.OrgId) {
+		err := errors.New("No access")
+		r.log.Error("http - v1 - AddOrgAccount: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+		return
+	}
+
+	orgs, err := r.orgUsecase.GetAccountOrgs(c.Request.Context(), req.AccountId)
+	if err != nil {
+		r.log.Error("http - v1 - AddOrgAccount: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+	if !canInviteAccount(account, orgs) {
+		err := errors.New("No access")
+		r.log.Error("http - v1 - AddOrgAccount: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+		return
+	}
+
+	org := entity.OrgAccount{
+		OrgId:     req.OrgId,
+		AccountId: req.AccountId,
+		Role:      entity.AccountType(req.Role),
+	}
+	if err := r.orgUsecase.AddOrgAccount(c.Request.Context(), org); err != nil {
+		r.log.Error("http - v1 - AddOrgAccount: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok"))
+}
+
+func (r *todoHandler) RemoveOrgAccount(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var req dto.RemoveOrgAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.log.Error("http - v1 - RemoveOrgAccount: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	if !canManageOrg(account, req.OrgId) {
+		err := errors.New("No access")
+		r.log.Error("http - v1 - RemoveOrgAccount: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+		return
+	}
+
+	if err := r.orgUsecase.RemoveOrgAccount(c.Request.Context(), req.OrgId, req.AccountId, account.Id); err != nil {
+		r.log.Error("http - v1 - RemoveOrgAccount: %v", err)
+		if errors.Is(err, usecase.ErrOrgSelf) || errors.Is(err, usecase.ErrOrgLastAdmin) || errors.Is(err, usecase.ErrOrgNoAccess) {
+			c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+			return
+		}
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok"))
+}
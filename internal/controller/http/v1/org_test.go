@@ -0,0 +1,262 @@
+package v1_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/adapters/db/session"
+	"testcode/test3/internal/controller/http/dto"
+	v1 "testcode/test3/internal/controller/http/v1"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+	"testcode/test3/pkg/logger"
+)
+
+// orgStorage keeps the memberships in memory.
+type orgStorage struct {
+	usecase.OrgStorage
+	mu   sync.Mutex
+	orgs map[uint][]entity.OrgAccount
+}
+
+func (r *orgStorage) GetAccountOrgs(_ context.Context, accountID uint) ([]entity.OrgAccount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]entity.OrgAccount(nil), r.orgs[accountID]...), nil
+}
+
+func (r *orgStorage) AddAccount(_ context.Context, dto entity.OrgAccount) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.orgs[dto.AccountId] = append(r.orgs[dto.AccountId], dto)
+	return nil
+}
+
+func (r *orgStorage) GetOrgAccounts(_ context.Context, orgID uint) ([]entity.OrgAccount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var ret []entity.OrgAccount
+	for _, orgs := range r.orgs {
+		for _, o := range orgs {
+			if o.OrgId == orgID {
+				ret = append(ret, o)
+			}
+		}
+	}
+	return ret, nil
+}
+
+func (r *orgStorage) RemoveAccount(_ context.Context, orgID uint, accountID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var kept []entity.OrgAccount
+	for _, o := range r.orgs[accountID] {
+		if o.OrgId != orgID {
+			kept = append(kept, o)
+		}
+	}
+	r.orgs[accountID] = kept
+	return nil
+}
+
+type nopAuditor struct{}
+
+func (nopAuditor) Audit(context.Context, entity.AuditEntry) {}
+
+// accountUsecase authenticates the accounts by name and lists them with the
+// tenant the request was scoped to.
+type accountUsecase struct {
+	v1.AccountUsecase
+	accounts map[string]entity.Account
+}
+
+func (r accountUsecase) Authenticate(_ context.Context, name string, password string) (*entity.Account, error) {
+	a, ok := r.accounts[name]
+	if !ok || a.Password != password {
+		return nil, usecase.ErrAccountNotFound
+	}
+	return &a, nil
+}
+
+func (r accountUsecase) GetAccountAll(ctx context.Context) ([]entity.Account, error) {
+	tenant := entity.TenantFromContext(ctx)
+	return []entity.Account{{Id: 99, TenantId: tenant, Name: "someone", Password: "secret", AccountType: entity.AccountTypeUser}}, nil
+}
+
+type orgFixture struct {
+	engine *gin.Engine
+	orgs   *orgStorage
+}
+
+// newOrgFixture serves root, a superadmin of no org, alice, the admin of org
+// 1, bob, a user of org 2, carol, who belongs to no org, and dave, a user of
+// org 1.
+func newOrgFixture() *orgFixture {
+	gin.SetMode(gin.TestMode)
+	orgs := &orgStorage{orgs: map[uint][]entity.OrgAccount{
+		2: {{OrgId: 1, AccountId: 2, Role: entity.AccountTypeAdmin}},
+		3: {{OrgId: 2, AccountId: 3, Role: entity.AccountTypeUser}},
+		5: {{OrgId: 1, AccountId: 5, Role: entity.AccountTypeUser}},
+	}}
+	accounts := accountUsecase{accounts: map[string]entity.Account{
+		"root":  {Id: 1, Name: "root", Password: "pw", AccountType: entity.AccountTypeSuperAdmin},
+		"alice": {Id: 2, Name: "alice", Password: "pw", AccountType: entity.AccountTypeUser},
+		"bob":   {Id: 3, Name: "bob", Password: "pw", AccountType: entity.AccountTypeUser},
+		"carol": {Id: 4, Name: "carol", Password: "pw", AccountType: entity.AccountTypeUser},
+		"dave":  {Id: 5, Name: "dave", Password: "pw", AccountType: entity.AccountTypeUser},
+	}}
+	log := logger.New("error")
+
+	e := gin.New()
+	v1.NewRouter(e, log, accounts, nil, nil, nil, usecase.NewOrgUsecase(log, orgs, nopAuditor{}),
+		nil, nil, nil, nil, nil, nil, usecase.NewSessionUsecase(session.NewSessionStorage()), nil, nil)
+	return &orgFixture{engine: e, orgs: orgs}
+}
+
+func (f *orgFixture) call(t *testing.T, method string, path string, token string, body interface{}) v1.ResponseMessage {
+	t.Helper()
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set(v1.HeaderAuthKey, token)
+	}
+	w := httptest.NewRecorder()
+	f.engine.ServeHTTP(w, req)
+
+	var resp v1.ResponseMessage
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s %s: %v; body=%s", method, path, err, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "password") {
+		t.Errorf("%s %s: response has a password: %s", method, path, w.Body.String())
+	}
+	return resp
+}
+
+func (f *orgFixture) login(t *testing.T, name string, orgID uint) string {
+	t.Helper()
+	resp := f.call(t, http.MethodPost, "/v1/login", "", dto.LoginRequest{Name: name, Password: "pw", OrgId: orgID})
+	if resp.Code != v1.ErrCodeNone {
+		t.Fatalf("login %s: code %d %s", name, resp.Code, resp.Message)
+	}
+	return resp.Data.(map[string]interface{})["token"].(string)
+}
+
+// tenant returns the tenant the requests of the session are scoped to.
+func (f *orgFixture) tenant(t *testing.T, token string) uint {
+	t.Helper()
+	resp := f.call(t, http.MethodGet, "/v1/accounts", token, nil)
+	if resp.Code != v1.ErrCodeNone {
+		t.Fatalf("accounts: code %d %s", resp.Code, resp.Message)
+	}
+	accounts := resp.Data.([]interface{})
+	tenant, _ := accounts[0].(map[string]interface{})["tenant_id"].(float64)
+	return uint(tenant)
+}
+
+func TestLoginTenant(t *testing.T) {
+	f := newOrgFixture()
+
+	tests := []struct {
+		name   string
+		org    uint
+		tenant uint
+	}{
+		{"alice", 0, 1},
+		{"bob", 0, 2},
+		{"dave", 1, 1},
+		// superadmins log in without an org, their requests see no tenant's
+		// data until they name one
+		{"root", 0, 0},
+		{"root", 2, 2},
+	}
+	for _, tt := range tests {
+		if got := f.tenant(t, f.login(t, tt.name, tt.org)); got != tt.tenant {
+			t.Errorf("%s in org %d: tenant %d, want %d", tt.name, tt.org, got, tt.tenant)
+		}
+	}
+
+	resp := f.call(t, http.MethodPost, "/v1/login", "", dto.LoginRequest{Name: "bob", Password: "pw", OrgId: 1})
+	if resp.Code != v1.ErrCodeUnauthenticated {
+		t.Errorf("bob in org 1: code %d, want %d", resp.Code, v1.ErrCodeUnauthenticated)
+	}
+}
+
+func TestAddOrgAccount(t *testing.T) {
+	f := newOrgFixture()
+	alice := f.login(t, "alice", 0)
+	root := f.login(t, "root", 0)
+	dave := f.login(t, "dave", 0)
+
+	tests := []struct {
+		name  string
+		token string
+		req   dto.AddOrgAccountRequest
+		code  v1.ErrCode
+	}{
+		{"admin takes account of other org", alice, dto.AddOrgAccountRequest{OrgId: 1, AccountId: 3, Role: 2}, v1.ErrCodeNoAccess},
+		{"admin adds to other org", alice, dto.AddOrgAccountRequest{OrgId: 2, AccountId: 4, Role: 2}, v1.ErrCodeNoAccess},
+		{"user adds to own org", dave, dto.AddOrgAccountRequest{OrgId: 1, AccountId: 4, Role: 2}, v1.ErrCodeNoAccess},
+		{"admin adds account of no org", alice, dto.AddOrgAccountRequest{OrgId: 1, AccountId: 4, Role: 2}, v1.ErrCodeNone},
+		{"superadmin adds account of other org", root, dto.AddOrgAccountRequest{OrgId: 1, AccountId: 3, Role: 2}, v1.ErrCodeNone},
+	}
+	for _, tt := range tests {
+		resp := f.call(t, http.MethodPost, "/v1/org/account", tt.token, tt.req)
+		if resp.Code != tt.code {
+			t.Errorf("%s: code %d %s, want %d", tt.name, resp.Code, resp.Message, tt.code)
+		}
+	}
+
+	orgs, _ := f.orgs.GetAccountOrgs(context.Background(), 4)
+	if len(orgs) != 1 || orgs[0].OrgId != 1 {
+		t.Errorf("carol: orgs %v, want only org 1", orgs)
+	}
+	orgs, _ = f.orgs.GetAccountOrgs(context.Background(), 3)
+	if len(orgs) != 2 {
+		t.Errorf("bob: orgs %v, want orgs 2 and 1", orgs)
+	}
+}
+
+func TestRemoveOrgAccount(t *testing.T) {
+	f := newOrgFixture()
+	alice := f.login(t, "alice", 0)
+	root := f.login(t, "root", 0)
+	dave := f.login(t, "dave", 0)
+
+	tests := []struct {
+		name  string
+		token string
+		req   dto.RemoveOrgAccountRequest
+		code  v1.ErrCode
+	}{
+		{"user removes from own org", dave, dto.RemoveOrgAccountRequest{OrgId: 1, AccountId: 2}, v1.ErrCodeNoAccess},
+		{"admin removes itself", alice, dto.RemoveOrgAccountRequest{OrgId: 1, AccountId: 2}, v1.ErrCodeInvalidArgument},
+		{"superadmin removes last admin", root, dto.RemoveOrgAccountRequest{OrgId: 1, AccountId: 2}, v1.ErrCodeInvalidArgument},
+		{"admin removes account of other org", alice, dto.RemoveOrgAccountRequest{OrgId: 1, AccountId: 3}, v1.ErrCodeInvalidArgument},
+		{"admin removes user", alice, dto.RemoveOrgAccountRequest{OrgId: 1, AccountId: 5}, v1.ErrCodeNone},
+	}
+	for _, tt := range tests {
+		resp := f.call(t, http.MethodDelete, "/v1/org/account", tt.token, tt.req)
+		if resp.Code != tt.code {
+			t.Errorf("%s: code %d %s, want %d", tt.name, resp.Code, resp.Message, tt.code)
+		}
+	}
+
+	if orgs, _ := f.orgs.GetAccountOrgs(context.Background(), 2); len(orgs) != 1 {
+		t.Errorf("alice: orgs %v, want org 1 kept", orgs)
+	}
+	if orgs, _ := f.orgs.GetAccountOrgs(context.Background(), 5); len(orgs) != 0 {
+		t.Errorf("dave: orgs %v, want none", orgs)
+	}
+}
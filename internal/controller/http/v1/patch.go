@@ -0,0 +1,201 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/controller/http/dto"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+	"testcode/test3/pkg/jsonpatch"
+)
+
+var errUnsupportedPatch = fmt.Errorf("Content-Type must be %s or %s", jsonpatch.MergePatchType, jsonpatch.JSONPatchType)
+
+// todoDocument is the part of a todo a PATCH may change.
+type todoDocument struct {
+	Name       string            `json:"name"`
+	Desc       string            `json:"desc"`
+	Status     entity.TodoStatus `json:"status"`
+	Due        *time.Time        `json:"due"`
+	Labels     []string          `json:"labels"`
+	Priority   uint              `json:"priority"`
+	Recurrence string            `json:"recurrence"`
+}
+
+// applyTodoPatch applies the merge patch or JSON patch body to the todo and
+// returns the fields that changed. Fields missing from the result are cleared,
+// so null in a merge patch and remove in a JSON patch clear the field.
+func applyTodoPatch(todo entity.Todo, contentType string, body []byte) (entity.TodoPatch, error) {
+	doc, err := json.Marshal(todoDocument{Name: todo.Name, Desc: todo.Desc, Status: todo.Status, Due: todo.Due, Labels: todo.Labels,
+		Priority: todo.Priority, Recurrence: todo.Recurrence})
+	if err != nil {
+		return entity.TodoPatch{}, err
+	}
+
+	switch contentType {
+	case jsonpatch.MergePatchType, "application/json", "":
+		doc, err = jsonpatch.MergePatch(doc, body)
+	case jsonpatch.JSONPatchType:
+		doc, err = jsonpatch.Apply(doc, body)
+	default:
+		return entity.TodoPatch{}, errUnsupportedPatch
+	}
+	if err != nil {
+		return entity.TodoPatch{}, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(doc, &fields); err != nil {
+		return entity.TodoPatch{}, errors.New("patched todo must be an object")
+	}
+	var patched todoDocument
+	for k, v := range fields {
+		switch k {
+		case "name":
+			err = json.Unmarshal(v, &patched.Name)
+		case "desc":
+			err = json.Unmarshal(v, &patched.Desc)
+		case "status":
+			err = json.Unmarshal(v, &patched.Status)
+		case "due":
+			err = json.Unmarshal(v, &patched.Due)
+		case "labels":
+			err = json.Unmarshal(v, &patched.Labels)
+		case "priority":
+			err = json.Unmarshal(v, &patched.Priority)
+		case "recurrence":
+			err = json.Unmarshal(v, &patched.Recurrence)
+		default:
+			return entity.TodoPatch{}, fmt.Errorf("field %q cannot be patched", k)
+		}
+		if err != nil {
+			return entity.TodoPatch{}, fmt.Errorf("field %q: %w", k, err)
+		}
+	}
+
+	return todoChanges(todo, entity.Todo{Name: patched.Name, Desc: patched.Desc, Status: patched.Status, Due: patched.Due,
+		Labels: patched.Labels, Priority: patched.Priority, Recurrence: patched.Recurrence}), nil
+}
+
+// todoChanges returns the patch setting the fields of new that differ from
+// old, a missing due date clears it.
+func todoChanges(old, new entity.Todo) entity.TodoPatch {
+	var patch entity.TodoPatch
+	if new.Name != old.Name {
+		patch.Name = &new.Name
+	}
+	if new.Desc != old.Desc {
+		patch.Desc = &new.Desc
+	}
+	if new.Status != old.Status {
+		patch.Status = &new.Status
+	}
+	switch {
+	case new.Due == nil && old.Due != nil:
+		// the zero time clears the due date
+		patch.Due = &time.Time{}
+	case new.Due != nil && (old.Due == nil || !new.Due.Equal(*old.Due)):
+		patch.Due = new.Due
+	}
+	if strings.Join(new.Labels, ",") != strings.Join(old.Labels, ",") {
+		labels := append([]string{}, new.Labels...)
+		patch.Labels = &labels
+	}
+	if new.Priority != old.Priority {
+		patch.Priority = &new.Priority
+	}
+	if new.Recurrence != old.Recurrence {
+		patch.Recurrence = &new.Recurrence
+	}
+	return patch
+}
+
+func (r *todoHandler) PatchTodo(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var uri dto.TodoUri
+	if err := c.ShouldBindUri(&uri); err != nil {
+		r.log.Error("http - v1 - PatchTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	todo, role, code, err := r.todoAccess(c.Request.Context(), uri.Id, account)
+	if err != nil {
+		r.log.Error("http - v1 - PatchTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(code, err.Error()))
+		return
+	}
+	// before If-Match, whose mismatch answers the current todo
+	if role < entity.MemberRoleViewer || (role < entity.MemberRoleEditor && !todo.HasAssignee(account.Id)) {
+		err = errors.New("No access")
+		r.log.Error("http - v1 - PatchTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+		return
+	}
+	if !r.checkIfMatch(c, "PatchTodo", *todo) {
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		r.log.Error("http - v1 - PatchTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+	patch, err := applyTodoPatch(*todo, c.ContentType(), body)
+	if err != nil {
+		r.log.Error("http - v1 - PatchTodo: %v", err)
+		if errors.Is(err, errUnsupportedPatch) {
+			c.JSON(http.StatusUnsupportedMediaType, NewResp(ErrCodeInvalidArgument, err.Error()))
+			return
+		}
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+	if role < entity.MemberRoleEditor {
+		// assignees may only move the todo between statuses
+		if !patch.StatusOnly() {
+			err = errors.New("No access")
+			r.log.Error("http - v1 - PatchTodo: %v", err)
+			c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+			return
+		}
+	}
+	if patch.Empty() {
+		c.Header(HeaderETag, todoETag(*todo))
+		c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", todo))
+		return
+	}
+
+	// the patch was applied to the loaded version, someone changing the todo
+	// in between is a conflict
+	patch.Version = todo.Version
+	conflictStatus := http.StatusConflict
+	if c.Request.Header.Get(HeaderIfMatch) != "" {
+		conflictStatus = http.StatusPreconditionFailed
+	}
+
+	resp, err := r.todoUsecase.PatchTodo(c.Request.Context(), uri.Id, patch, account.Id)
+	if err != nil {
+		r.log.Error("http - v1 - PatchTodo: %v", err)
+		switch {
+		case errors.Is(err, usecase.ErrTodoInvalid):
+			c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		case errors.Is(err, usecase.ErrTodoVersionConflict):
+			r.todoConflict(c.Request.Context(), c, conflictStatus, uri.Id, err)
+		default:
+			c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		}
+		return
+	}
+
+	c.Header(HeaderETag, todoETag(*resp))
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", resp))
+}
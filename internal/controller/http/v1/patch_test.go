@@ -0,0 +1,61 @@
+package v1_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/adapters/db/session"
+	v1 "testcode/test3/internal/controller/http/v1"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+	"testcode/test3/pkg/logger"
+)
+
+func TestPatchTodoAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logger.New("error")
+	todos := &memTodos{nextID: 1, todos: map[uint]entity.Todo{
+		1: {Id: 1, OwnerId: 2, Name: "secret plan", Status: entity.TodoStatusDefault, Version: 1},
+	}}
+	orgs := &orgStorage{orgs: map[uint][]entity.OrgAccount{
+		2: {{OrgId: 1, AccountId: 2, Role: entity.AccountTypeUser}},
+		4: {{OrgId: 1, AccountId: 4, Role: entity.AccountTypeUser}},
+	}}
+	accounts := accountUsecase{accounts: map[string]entity.Account{
+		"alice": {Id: 2, Name: "alice", Password: "pw", AccountType: entity.AccountTypeUser},
+		"carol": {Id: 4, Name: "carol", Password: "pw", AccountType: entity.AccountTypeUser},
+	}}
+	e := gin.New()
+	v1.NewRouter(e, log, accounts, todos, nil, ownerRoles{}, usecase.NewOrgUsecase(log, orgs, nopAuditor{}),
+		nil, nil, nil, nil, nil, nil, usecase.NewSessionUsecase(session.NewSessionStorage()), nil, nil)
+	f := &orgFixture{engine: e, orgs: orgs}
+
+	patch := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/v1/todos/1", strings.NewReader(`{"name":"mine now"}`))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		req.Header.Set(v1.HeaderAuthKey, token)
+		req.Header.Set(v1.HeaderIfMatch, `"999"`)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	// a stale If-Match of someone without access tells nothing about the todo
+	w := patch(f.login(t, "carol", 0))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"code":4`) || strings.Contains(w.Body.String(), "secret plan") ||
+		w.Header().Get(v1.HeaderETag) != "" {
+		t.Errorf("non-member: status %d, ETag %q, body %s, want no access without the todo", w.Code,
+			w.Header().Get(v1.HeaderETag), w.Body.String())
+	}
+
+	w = patch(f.login(t, "alice", 0))
+	if w.Code != http.StatusPreconditionFailed || !strings.Contains(w.Body.String(), "secret plan") {
+		t.Errorf("owner: status %d, body %s, want %d with the current todo", w.Code, w.Body.String(), http.StatusPreconditionFailed)
+	}
+	if todos.todos[1].Name != "secret plan" {
+		t.Errorf("todo renamed to %q", todos.todos[1].Name)
+	}
+}
@@ -0,0 +1,253 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/controller/http/dto"
+	"testcode/test3/internal/domain/entity"
+)
+
+// writableProject loads the project and checks that account has at least
+// minRole on it.
+func (r *todoHandler) writableProject(ctx context.Context, projectID uint, account entity.Account, minRole entity.MemberRole) (*entity.Project, ErrCode, error) {
+	project, err := r.projectUsecase.GetProject(ctx, projectID)
+	if err != nil {
+		return nil, ErrCodeInternal, err
+	}
+	if project == nil {
+		return nil, ErrCodeInvalidArgument, errors.New("project not found")
+	}
+
+	role, err := r.memberUsecase.ProjectRole(ctx, account, *project)
+	if err != nil {
+		return nil, ErrCodeInternal, err
+	}
+	if role < minRole {
+		return nil, ErrCodeNoAccess, errors.New("No access")
+	}
+	return project, ErrCodeNone, nil
+}
+
+func (r *todoHandler) GetProjects(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var resp []entity.Project
+	var err error
+	if account.IsAdmin() {
+		resp, err = r.projectUsecase.GetProjectAll(c.Request.Context())
+	} else {
+		resp, err = r.projectUsecase.GetProjectAllVisible(c.Request.Context(), account.Id)
+	}
+	if err != nil {
+		r.log.Error("http - v1 - GetProjects: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", resp))
+}
+
+func (r *todoHandler) GetProject(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var req dto.GetProjectRequest
+	if err := c.ShouldBind(&req); err != nil {
+		r.log.Error("http - v1 - GetProject: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	resp, code, err := r.writableProject(c.Request.Context(), req.Id, account, entity.MemberRoleViewer)
+	if err != nil {
+		r.log.Error("http - v1 - GetProject: %v", err)
+		c.JSON(http.StatusOK, NewResp(code, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", resp))
+}
+
+func (r *todoHandler) GetProjectTodos(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var req dto.GetProjectRequest
+	if err := c.ShouldBind(&req); err != nil {
+		r.log.Error("http - v1 - GetProjectTodos: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	if _, code, err := r.writableProject(c.Request.Context(), req.Id, account, entity.MemberRoleViewer); err != nil {
+		r.log.Error("http - v1 - GetProjectTodos: %v", err)
+		c.JSON(http.StatusOK, NewResp(code, err.Error()))
+		return
+	}
+
+	resp, err := r.todoUsecase.GetTodoAllByProject(c.Request.Context(), req.Id)
+	if err != nil {
+		r.log.Error("http - v1 - GetProjectTodos: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", resp))
+}
+
+func (r *todoHandler) CreateProject(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+	var req dto.CreateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.log.Error("http - v1 - CreateProject: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	project := entity.Project{
+		OwnerId: account.Id,
+		Name:    req.Name,
+		Desc:    req.Desc,
+		Color:   req.Color,
+	}
+	id, err := r.projectUsecase.CreateProject(c.Request.Context(), project)
+	if err != nil {
+		r.log.Error("http - v1 - CreateProject: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", CreateProjectResponse{id}))
+}
+
+func (r *todoHandler) UpdateProject(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var req dto.UpdateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.log.Error("http - v1 - UpdateProject: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	if _, code, err := r.writableProject(c.Request.Context(), req.Id, account, entity.MemberRoleOwner); err != nil {
+		r.log.Error("http - v1 - UpdateProject: %v", err)
+		c.JSON(http.StatusOK, NewResp(code, err.Error()))
+		return
+	}
+
+	project := entity.Project{
+		Id:    req.Id,
+		Name:  req.Name,
+		Desc:  req.Desc,
+		Color: req.Color,
+	}
+	if err := r.projectUsecase.UpdateProject(c.Request.Context(), project); err != nil {
+		r.log.Error("http - v1 - UpdateProject: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok"))
+}
+
+func (r *todoHandler) ArchiveProject(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var req dto.ArchiveProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.log.Error("http - v1 - ArchiveProject: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	if _, code, err := r.writableProject(c.Request.Context(), req.Id, account, entity.MemberRoleOwner); err != nil {
+		r.log.Error("http - v1 - ArchiveProject: %v", err)
+		c.JSON(http.StatusOK, NewResp(code, err.Error()))
+		return
+	}
+
+	if err := r.projectUsecase.ArchiveProject(c.Request.Context(), req.Id, req.Archived); err != nil {
+		r.log.Error("http - v1 - ArchiveProject: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok"))
+}
+
+func (r *todoHandler) DeleteProject(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var req dto.DeleteProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.log.Error("http - v1 - DeleteProject: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	if _, code, err := r.writableProject(c.Request.Context(), req.Id, account, entity.MemberRoleOwner); err != nil {
+		r.log.Error("http - v1 - DeleteProject: %v", err)
+		c.JSON(http.StatusOK, NewResp(code, err.Error()))
+		return
+	}
+
+	if err := r.projectUsecase.DeleteProject(c.Request.Context(), req.Id); err != nil {
+		r.log.Error("http - v1 - DeleteProject: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok"))
+}
+
+func (r *todoHandler) MoveTodo(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var req dto.MoveTodoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.log.Error("http - v1 - MoveTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	todo, role, code, err := r.todoAccess(c.Request.Context(), req.TodoId, account)
+	if err != nil {
+		r.log.Error("http - v1 - MoveTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(code, err.Error()))
+		return
+	}
+	if role < entity.MemberRoleOwner {
+		err = errors.New("No access")
+		r.log.Error("http - v1 - MoveTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+		return
+	}
+	if !r.checkIfMatch(c, "MoveTodo", *todo) {
+		return
+	}
+
+	if req.ProjectId != 0 {
+		project, code, err := r.writableProject(c.Request.Context(), req.ProjectId, account, entity.MemberRoleEditor)
+		if err != nil {
+			r.log.Error("http - v1 - MoveTodo: %v", err)
+			c.JSON(http.StatusOK, NewResp(code, err.Error()))
+			return
+		}
+		if project.Archived {
+			err = errors.New("project is archived")
+			r.log.Error("http - v1 - MoveTodo: %v", err)
+			c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+			return
+		}
+	}
+
+	if err = r.todoUsecase.MoveTodo(c.Request.Context(), req.TodoId, req.ProjectId, account.Id); err != nil {
+		r.log.Error("http - v1 - MoveTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok"))
+}
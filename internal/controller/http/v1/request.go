@@ -0,0 +1,26 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"testcode/test3/internal/domain/entity"
+)
+
+const HeaderRequestId = "X-Request-Id"
+
+// RequestMeta tags the request with an id, taken from the client when given,
+// and puts it with the client ip to context for the audit log.
+func RequestMeta() func(*gin.Context) {
+	return func(c *gin.Context) {
+		id := c.Request.Header.Get(HeaderRequestId)
+		if id == "" || len(id) > 64 {
+			id = uuid.New().String()
+		}
+		c.Header(HeaderRequestId, id)
+		c.Request = c.Request.WithContext(entity.WithAuditMeta(c.Request.Context(), entity.AuditMeta{
+			RequestId: id,
+			ClientIp:  c.ClientIP(),
+		}))
+		c.Next()
+	}
+}
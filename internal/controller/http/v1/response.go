@@ -1,5 +1,7 @@
 package v1
 
+import "testcode/test3/internal/domain/entity"
+
 type ResponseMessage struct {
 	Code    ErrCode     `json:"code"`
 	Message string      `json:"message,omitempty"`
@@ -26,3 +28,58 @@ func NewDebugResp(code ErrCode, message string, debug interface{}, payload ...in
 type LoginResponse struct {
 	Token string `json:"token"`
 }
+
+type CreateTodoResponse struct {
+	Id uint `json:"id"`
+}
+
+type CreateProjectResponse struct {
+	Id uint `json:"id"`
+}
+
+type InviteMemberResponse struct {
+	Id uint `json:"id"`
+}
+
+type CreateOrgResponse struct {
+	Id uint `json:"id"`
+}
+
+type CreateCommentResponse struct {
+	Id uint `json:"id"`
+}
+
+type CommentsResponse struct {
+	Items []entity.Comment `json:"items"`
+	Total uint             `json:"total"`
+	Page  uint             `json:"page"`
+	Limit uint             `json:"limit"`
+}
+
+type CreateAttachmentResponse struct {
+	Id uint `json:"id"`
+}
+
+type FilterErrorResponse struct {
+	Pos int `json:"pos"`
+}
+
+type SaveFilterResponse struct {
+	Id uint `json:"id"`
+}
+
+type AuditResponse struct {
+	Items []entity.AuditEntry `json:"items"`
+	Total uint                `json:"total"`
+	Page  uint                `json:"page"`
+	Limit uint                `json:"limit"`
+}
+
+type BulkTodoResponse struct {
+	Results []entity.TodoBulkResult `json:"results"`
+}
+
+type CalendarTokenResponse struct {
+	Token string `json:"token"`
+	Url   string `json:"url"`
+}
@@ -0,0 +1,122 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/controller/http/dto"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+)
+
+func (r *todoHandler) GetTodoHistory(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var uri dto.TodoUri
+	if err := c.ShouldBindUri(&uri); err != nil {
+		r.log.Error("http - v1 - GetTodoHistory: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	_, role, code, err := r.todoAccess(c.Request.Context(), uri.Id, account)
+	if err != nil {
+		r.log.Error("http - v1 - GetTodoHistory: %v", err)
+		c.JSON(http.StatusOK, NewResp(code, err.Error()))
+		return
+	}
+	if role < entity.MemberRoleViewer {
+		err = errors.New("No access")
+		r.log.Error("http - v1 - GetTodoHistory: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+		return
+	}
+
+	resp, err := r.todoUsecase.GetTodoHistory(c.Request.Context(), uri.Id)
+	if err != nil {
+		r.log.Error("http - v1 - GetTodoHistory: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", resp))
+}
+
+// RestoreTodo needs editor role, restoring into another project needs the
+// same rights as MoveTodo.
+func (r *todoHandler) RestoreTodo(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var uri dto.RevisionUri
+	if err := c.ShouldBindUri(&uri); err != nil {
+		r.log.Error("http - v1 - RestoreTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	todo, role, code, err := r.todoAccess(c.Request.Context(), uri.Id, account)
+	if err != nil {
+		r.log.Error("http - v1 - RestoreTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(code, err.Error()))
+		return
+	}
+	if role < entity.MemberRoleEditor {
+		err = errors.New("No access")
+		r.log.Error("http - v1 - RestoreTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+		return
+	}
+	if !r.checkIfMatch(c, "RestoreTodo", *todo) {
+		return
+	}
+
+	rev, err := r.todoUsecase.GetTodoRevision(c.Request.Context(), uri.RevisionId)
+	if err != nil {
+		r.log.Error("http - v1 - RestoreTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+	if rev == nil || rev.TodoId != todo.Id {
+		err = usecase.ErrRevisionNotFound
+		r.log.Error("http - v1 - RestoreTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	if rev.ProjectId != todo.ProjectId {
+		if role < entity.MemberRoleOwner {
+			err = errors.New("No access")
+			r.log.Error("http - v1 - RestoreTodo: %v", err)
+			c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+			return
+		}
+		if rev.ProjectId != 0 {
+			project, code, err := r.writableProject(c.Request.Context(), rev.ProjectId, account, entity.MemberRoleEditor)
+			if err != nil {
+				r.log.Error("http - v1 - RestoreTodo: %v", err)
+				c.JSON(http.StatusOK, NewResp(code, err.Error()))
+				return
+			}
+			if project.Archived {
+				err = errors.New("project is archived")
+				r.log.Error("http - v1 - RestoreTodo: %v", err)
+				c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+				return
+			}
+		}
+	}
+
+	err = r.todoUsecase.RestoreTodo(c.Request.Context(), uri.Id, uri.RevisionId, account.Id)
+	if err != nil {
+		r.log.Error("http - v1 - RestoreTodo: %v", err)
+		if errors.Is(err, usecase.ErrRevisionNotFound) || errors.Is(err, usecase.ErrTodoNotFound) {
+			c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+			return
+		}
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok"))
+}
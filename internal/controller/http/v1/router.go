@@ -5,10 +5,17 @@ import (
 	"testcode/test3/pkg/logger"
 )
 
-func NewRouter(handler *gin.Engine, log *logger.Logger, accountUsecase AccountUsecase, todoUsecase TodoUsecase, sessionUsecase SessionUsecase) {
-	r := &todoHandler{accountUsecase, todoUsecase, sessionUsecase, log}
+func NewRouter(handler *gin.Engine, log *logger.Logger, accountUsecase AccountUsecase, todoUsecase TodoUsecase, projectUsecase ProjectUsecase, memberUsecase MemberUsecase, orgUsecase OrgUsecase, commentUsecase CommentUsecase, attachmentUsecase AttachmentUsecase, searchUsecase SearchUsecase, filterUsecase FilterUsecase, trashUsecase TrashUsecase, auditUsecase AuditUsecase, sessionUsecase SessionUsecase, idempotencyUsecase IdempotencyUsecase,
+	calendarUsecase CalendarUsecase) {
+	r := &todoHandler{accountUsecase, todoUsecase, projectUsecase, memberUsecase, orgUsecase, commentUsecase, attachmentUsecase, searchUsecase,
+		filterUsecase, trashUsecase, auditUsecase, sessionUsecase, calendarUsecase,
+		newDavSessions(), nil, log}
+	r.graphqlSchema = newGraphQLSchema(r)
 
-	handler.Use(Auth(sessionUsecase, "/v1/login"))
+	handler.Use(RequestMeta())
+	handler.Use(Auth(sessionUsecase, "/v1/login", "/v1/calendar/feed/*", "/dav/*", "/.well-known/caldav", "/graphql/ws",
+		"/v1/events", "/v1/events/ws", "/openapi.json", "/docs"))
+	handler.Use(Idempotency(idempotencyUsecase))
 	// Routers
 	h := handler.Group("/v1")
 	{
@@ -19,10 +26,84 @@ func NewRouter(handler *gin.Engine, log *logger.Logger, accountUsecase AccountUs
 		h.POST("/account", r.CreateAccount)
 		h.DELETE("/account", r.DeleteAccount)
 
+		h.GET("/audit", r.GetAudit)
+		h.GET("/audit/export", r.ExportAudit)
+
+		h.GET("/orgs", r.GetOrgs)
+		h.POST("/org", r.CreateOrg)
+		h.POST("/org/account", r.AddOrgAccount)
+		h.DELETE("/org/account", r.RemoveOrgAccount)
+
 		h.GET("/todos", r.GetTodos)
 		h.GET("/todo", r.GetTodo)
 		h.POST("/todo", r.CreateTodo)
 		h.PUT("/todo", r.UpdateTodo)
+		h.GET("/events", r.Events)
+		h.GET("/events/ws", r.EventsWebSocket)
+		h.PATCH("/todos/:id", r.PatchTodo)
+		h.POST("/todos/bulk", r.BulkTodos)
+		h.GET("/todos/export", r.ExportTodos)
+		h.POST("/todos/import", r.ImportTodos)
 		h.DELETE("/todo", r.DeleteTodo)
+
+		h.GET("/todos/search", r.SearchTodos)
+		h.GET("/todos/filter", r.FilterTodos)
+		h.GET("/filters", r.GetSavedFilters)
+		h.POST("/filter", r.SaveFilter)
+		h.DELETE("/filter", r.DeleteSavedFilter)
+		h.GET("/todos/assigned", r.GetAssignedTodos)
+		h.POST("/todo/assignee", r.AssignTodo)
+		h.DELETE("/todo/assignee", r.UnassignTodo)
+		h.PUT("/todo/project", r.MoveTodo)
+
+		h.GET("/todos/:id/history", r.GetTodoHistory)
+		h.POST("/todos/:id/history/:revision_id/restore", r.RestoreTodo)
+
+		h.GET("/todos/:id/comments", r.GetComments)
+		h.POST("/todos/:id/comments", r.CreateComment)
+		h.PUT("/todos/:id/comments/:comment_id", r.EditComment)
+		h.DELETE("/todos/:id/comments/:comment_id", r.DeleteComment)
+
+		h.GET("/todos/:id/attachments", r.GetAttachments)
+		h.POST("/todos/:id/attachments", r.UploadAttachment)
+		h.GET("/todos/:id/attachments/:attachment_id", r.DownloadAttachment)
+		h.DELETE("/todos/:id/attachments/:attachment_id", r.DeleteAttachment)
+
+		h.POST("/calendar/token", r.CreateCalendarToken)
+		h.DELETE("/calendar/token", r.RevokeCalendarToken)
+		h.GET("/calendar/feed/:token", r.CalendarFeed)
+		h.POST("/calendar/import", r.ImportCalendar)
+
+		h.GET("/trash/todos", r.GetTrashedTodos)
+		h.POST("/trash/todo/restore", r.RestoreTrashedTodo)
+		h.GET("/trash/accounts", r.GetTrashedAccounts)
+		h.POST("/trash/account/restore", r.RestoreTrashedAccount)
+
+		h.GET("/projects", r.GetProjects)
+		h.GET("/project", r.GetProject)
+		h.GET("/project/todos", r.GetProjectTodos)
+		h.POST("/project", r.CreateProject)
+		h.PUT("/project", r.UpdateProject)
+		h.PUT("/project/archive", r.ArchiveProject)
+		h.DELETE("/project", r.DeleteProject)
+
+		h.GET("/members", r.GetMembers)
+		h.GET("/invites", r.GetInvites)
+		h.POST("/member", r.InviteMember)
+		h.POST("/member/accept", r.AcceptInvite)
+		h.DELETE("/member", r.RemoveMember)
 	}
+
+	handler.GET("/.well-known/caldav", r.DavWellKnown)
+	handler.Handle("PROPFIND", "/.well-known/caldav", r.DavWellKnown)
+	d := handler.Group("/dav", r.DavAuth)
+	for _, method := range _davMethods {
+		d.Handle(method, "/*path", r.Dav)
+	}
+
+	handler.POST("/graphql", r.GraphQL)
+	handler.GET("/graphql/ws", r.GraphQLWebSocket)
+
+	handler.GET("/openapi.json", OpenAPI(newAPIDocument()))
+	handler.GET("/docs", APIDocs)
 }
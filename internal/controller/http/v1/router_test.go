@@ -0,0 +1,41 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/pkg/logger"
+)
+
+// TestAPIRoutesDocumented checks every route is documented in _apiRoutes and
+// every documented route exists.
+func TestAPIRoutesDocumented(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := gin.New()
+	NewRouter(handler, logger.New("error"), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	if err := checkAPIRoutes(handler.Routes()); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestAPIDocsSelfContained checks the reference page loads nothing but the
+// document of the server.
+func TestAPIDocsSelfContained(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := gin.New()
+	NewRouter(handler, logger.New("error"), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	body := w.Body.String()
+	if w.Code != http.StatusOK || !strings.Contains(body, "fetch('/openapi.json')") {
+		t.Fatalf("status %d, body %.200s", w.Code, body)
+	}
+	for _, external := range []string{"http://", "https://", "//cdn", " src="} {
+		if strings.Contains(body, external) {
+			t.Errorf("page has %q", external)
+		}
+	}
+}
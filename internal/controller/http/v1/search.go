@@ -0,0 +1,40 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/controller/http/dto"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+)
+
+const _defaultSearchLimit = 20
+
+func (r *todoHandler) SearchTodos(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var req dto.SearchTodoRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		r.log.Error("http - v1 - SearchTodos: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = _defaultSearchLimit
+	}
+
+	resp, err := r.searchUsecase.SearchTodo(c.Request.Context(), req.Query, account, req.Limit)
+	if err != nil {
+		r.log.Error("http - v1 - SearchTodos: %v", err)
+		if errors.Is(err, usecase.ErrEmptySearchQuery) {
+			c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+			return
+		}
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", resp))
+}
@@ -3,28 +3,120 @@ package v1
 import (
 	"context"
 	"errors"
+	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/graph-gophers/graphql-go"
 	"testcode/test3/internal/controller/http/dto"
 	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
 	"testcode/test3/pkg/logger"
 )
 
 type AccountUsecase interface {
 	CreateAccount(ctx context.Context, dto entity.Account) error
 	GetAccount(ctx context.Context, accountID uint) (*entity.Account, error)
+	GetAccountAllByIds(ctx context.Context, accountIDs []uint) ([]entity.Account, error)
 	GetAccountByName(ctx context.Context, name string) (*entity.Account, error)
+	Authenticate(ctx context.Context, name string, password string) (*entity.Account, error)
 	GetAccountAll(ctx context.Context) ([]entity.Account, error)
-	DeleteAccount(ctx context.Context, accountID uint) error
+	DeleteAccount(ctx context.Context, dto entity.AccountDeletion, actorID uint) (*entity.AccountDeletionReport, error)
 }
 
 type TodoUsecase interface {
-	CreateTodo(ctx context.Context, dto entity.Todo) error
+	CreateTodo(ctx context.Context, dto entity.Todo) (uint, error)
 	GetTodo(ctx context.Context, todoID uint) (*entity.Todo, error)
 	GetTodoAll(ctx context.Context) ([]entity.Todo, error)
-	UpdateTodo(ctx context.Context, dto entity.Todo) error
+	GetTodoAllVisible(ctx context.Context, accountID uint) ([]entity.Todo, error)
+	GetTodoAllByAssignee(ctx context.Context, accountID uint) ([]entity.Todo, error)
+	GetTodoAllByProject(ctx context.Context, projectID uint) ([]entity.Todo, error)
+	UpdateTodo(ctx context.Context, dto entity.Todo, actorID uint) error
+	PatchTodo(ctx context.Context, todoID uint, patch entity.TodoPatch, actorID uint) (*entity.Todo, error)
+	MoveTodo(ctx context.Context, todoID uint, projectID uint, actorID uint) error
 	DeleteTodo(ctx context.Context, todoID uint) error
+	AssignTodo(ctx context.Context, todo entity.Todo, accountID uint, assignedBy uint) error
+	UnassignTodo(ctx context.Context, todoID uint, accountID uint) error
+	GetTodoRevision(ctx context.Context, revisionID uint) (*entity.TodoRevision, error)
+	GetTodoHistory(ctx context.Context, todoID uint) ([]entity.TodoRevision, error)
+	RestoreTodo(ctx context.Context, todoID uint, revisionID uint, actorID uint) error
+	BulkTodo(ctx context.Context, ops []entity.TodoBulkOp, atomic bool, actorID uint) ([]entity.TodoBulkResult, error)
+	ImportTodos(ctx context.Context, rows []entity.TodoImportRow, ownerID uint, dryRun bool) (*entity.TodoImportReport, error)
+	WatchTodos(ctx context.Context) (<-chan entity.TodoEvent, func())
+	WatchTodosSince(ctx context.Context, lastEventID uint64) ([]entity.TodoEvent, <-chan entity.TodoEvent, func(), error)
+}
+
+type ProjectUsecase interface {
+	CreateProject(ctx context.Context, dto entity.Project) (uint, error)
+	GetProject(ctx context.Context, projectID uint) (*entity.Project, error)
+	GetProjectAll(ctx context.Context) ([]entity.Project, error)
+	GetProjectAllVisible(ctx context.Context, accountID uint) ([]entity.Project, error)
+	UpdateProject(ctx context.Context, dto entity.Project) error
+	ArchiveProject(ctx context.Context, projectID uint, archived bool) error
+	DeleteProject(ctx context.Context, projectID uint) error
+}
+
+type MemberUsecase interface {
+	InviteMember(ctx context.Context, dto entity.Member) (uint, error)
+	AcceptInvite(ctx context.Context, memberID uint) error
+	GetMember(ctx context.Context, memberID uint) (*entity.Member, error)
+	GetMemberAllByResource(ctx context.Context, resourceType entity.MemberResource, resourceID uint) ([]entity.Member, error)
+	GetMemberAllByAccount(ctx context.Context, accountID uint) ([]entity.Member, error)
+	RemoveMember(ctx context.Context, memberID uint) error
+	TodoRole(ctx context.Context, account entity.Account, todo entity.Todo) (entity.MemberRole, error)
+	ProjectRole(ctx context.Context, account entity.Account, project entity.Project) (entity.MemberRole, error)
+}
+
+type OrgUsecase interface {
+	CreateOrg(ctx context.Context, dto entity.Org) (uint, error)
+	GetOrg(ctx context.Context, orgID uint) (*entity.Org, error)
+	GetOrgAll(ctx context.Context) ([]entity.Org, error)
+	GetAccountOrgs(ctx context.Context, accountID uint) ([]entity.OrgAccount, error)
+	AddOrgAccount(ctx context.Context, dto entity.OrgAccount) error
+	RemoveOrgAccount(ctx context.Context, orgID uint, accountID uint, actorID uint) error
+	EnterOrg(ctx context.Context, account entity.Account, orgID uint) (entity.Account, error)
+}
+
+type CommentUsecase interface {
+	CreateComment(ctx context.Context, dto entity.Comment) (uint, error)
+	GetComment(ctx context.Context, commentID uint) (*entity.Comment, error)
+	GetCommentPage(ctx context.Context, todoID uint, page uint, limit uint) ([]entity.Comment, uint, error)
+	EditComment(ctx context.Context, comment entity.Comment, body string) error
+	DeleteComment(ctx context.Context, commentID uint) error
+}
+
+type AttachmentUsecase interface {
+	MaxSize() int64
+	UploadAttachment(ctx context.Context, dto entity.Attachment, body io.Reader) (uint, error)
+	GetAttachment(ctx context.Context, attachmentID uint) (*entity.Attachment, error)
+	GetAttachmentAllByTodo(ctx context.Context, todoID uint) ([]entity.Attachment, error)
+	OpenAttachment(ctx context.Context, attachment entity.Attachment, offset int64, length int64) (io.ReadCloser, error)
+	DeleteAttachment(ctx context.Context, attachment entity.Attachment) error
+}
+
+type SearchUsecase interface {
+	SearchTodo(ctx context.Context, query string, viewer entity.Account, limit uint) ([]entity.TodoSearchHit, error)
+}
+
+type FilterUsecase interface {
+	FilterTodo(ctx context.Context, query string, viewer entity.Account) ([]entity.Todo, error)
+	SaveFilter(ctx context.Context, dto entity.SavedFilter) (uint, error)
+	GetSavedFilter(ctx context.Context, filterID uint) (*entity.SavedFilter, error)
+	GetSavedFilterAllByAccount(ctx context.Context, accountID uint) ([]entity.SavedFilter, error)
+	DeleteSavedFilter(ctx context.Context, filterID uint) error
+}
+
+type TrashUsecase interface {
+	GetTrashedTodo(ctx context.Context, todoID uint) (*entity.Todo, error)
+	GetTrashedTodos(ctx context.Context, ownerID uint) ([]entity.Todo, error)
+	RestoreTodo(ctx context.Context, todoID uint) error
+	GetTrashedAccounts(ctx context.Context) ([]entity.Account, error)
+	RestoreAccount(ctx context.Context, accountID uint) error
+}
+
+type AuditUsecase interface {
+	GetAuditPage(ctx context.Context, f entity.AuditFilter, page uint, limit uint) ([]entity.AuditEntry, uint, error)
+	ExportAudit(ctx context.Context, f entity.AuditFilter, fn func(entity.AuditEntry) error) error
 }
 
 type SessionUsecase interface {
@@ -33,11 +125,39 @@ type SessionUsecase interface {
 	Delete(key string)
 }
 
+type IdempotencyUsecase interface {
+	Begin(ctx context.Context, accountID uint, key string, request string) (*entity.IdempotencyRecord, error)
+	Finish(ctx context.Context, accountID uint, key string, statusCode int, contentType string, body []byte) error
+	Release(ctx context.Context, accountID uint, key string) error
+}
+
+type CalendarUsecase interface {
+	CreateFeedToken(ctx context.Context, accountID uint) (string, error)
+	RevokeFeedToken(ctx context.Context, accountID uint) error
+	GetFeed(ctx context.Context, token string) (*entity.CalendarFeed, error)
+	SetDavResource(ctx context.Context, dto entity.DavResource) error
+	GetDavResource(ctx context.Context, name string) (*entity.DavResource, error)
+	GetDavResourceByTodo(ctx context.Context, todoID uint) (*entity.DavResource, error)
+	GetDavResources(ctx context.Context) ([]entity.DavResource, error)
+}
+
 type todoHandler struct {
-	accountUsecase AccountUsecase
-	todoUsecase    TodoUsecase
-	sessionUsecase SessionUsecase
-	log            *logger.Logger
+	accountUsecase    AccountUsecase
+	todoUsecase       TodoUsecase
+	projectUsecase    ProjectUsecase
+	memberUsecase     MemberUsecase
+	orgUsecase        OrgUsecase
+	commentUsecase    CommentUsecase
+	attachmentUsecase AttachmentUsecase
+	searchUsecase     SearchUsecase
+	filterUsecase     FilterUsecase
+	trashUsecase      TrashUsecase
+	auditUsecase      AuditUsecase
+	sessionUsecase    SessionUsecase
+	calendarUsecase   CalendarUsecase
+	davSessions       *davSessions
+	graphqlSchema     *graphql.Schema
+	log               *logger.Logger
 }
 
 func (r *todoHandler) Login(c *gin.Context) {
@@ -48,20 +168,31 @@ func (r *todoHandler) Login(c *gin.Context) {
 		return
 	}
 
-	account, err := r.accountUsecase.GetAccountByName(c.Request.Context(), req.Name)
+	// failed attempts are audited in the org the client asked for
+	ctx := entity.WithTenant(c.Request.Context(), req.OrgId)
+	account, err := r.accountUsecase.Authenticate(ctx, req.Name, req.Password)
 	if err != nil {
 		r.log.Error("http - v1 - Login: %v", err)
+		if errors.Is(err, usecase.ErrAccountNotFound) {
+			c.JSON(http.StatusOK, NewResp(ErrCodeUnauthenticated, err.Error()))
+			return
+		}
 		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
 		return
 	}
-	if account == nil || account.Password != req.Password {
-		err = errors.New("account not found")
+
+	acc, err := r.orgUsecase.EnterOrg(c.Request.Context(), *account, req.OrgId)
+	if err != nil {
 		r.log.Error("http - v1 - Login: %v", err)
-		c.JSON(http.StatusOK, NewResp(ErrCodeUnauthenticated, err.Error()))
+		if errors.Is(err, usecase.ErrOrgRequired) || errors.Is(err, usecase.ErrOrgNoAccess) {
+			c.JSON(http.StatusOK, NewResp(ErrCodeUnauthenticated, err.Error()))
+			return
+		}
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
 		return
 	}
 
-	resp := r.sessionUsecase.Create(*account)
+	resp := r.sessionUsecase.Create(acc)
 	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", LoginResponse{resp}))
 }
 
@@ -75,7 +206,7 @@ func (r *todoHandler) Logout(c *gin.Context) {
 
 func (r *todoHandler) CreateAccount(c *gin.Context) {
 	account := c.MustGet(UserKey).(entity.Account)
-	if account.AccountType != entity.AccountTypeAdmin {
+	if !account.IsAdmin() {
 		err := errors.New("No access")
 		r.log.Error("http - v1 - CreateAccount: %v", err)
 		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
@@ -134,7 +265,7 @@ func (r *todoHandler) GetAccounts(c *gin.Context) {
 
 func (r *todoHandler) DeleteAccount(c *gin.Context) {
 	account := c.MustGet(UserKey).(entity.Account)
-	if account.AccountType != entity.AccountTypeAdmin {
+	if !account.IsAdmin() {
 		err := errors.New("No access")
 		r.log.Error("http - v1 - DeleteAccount: %v", err)
 		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
@@ -148,14 +279,33 @@ func (r *todoHandler) DeleteAccount(c *gin.Context) {
 		return
 	}
 
-	err := r.accountUsecase.DeleteAccount(c.Request.Context(), req.Id)
+	strategy := entity.AccountDeleteStrategy(req.Strategy)
+	if strategy == "" {
+		strategy = entity.AccountDeleteFailIfOwned
+	}
+	resp, err := r.accountUsecase.DeleteAccount(c.Request.Context(), entity.AccountDeletion{
+		AccountId:  req.Id,
+		Strategy:   strategy,
+		ReassignTo: req.ReassignTo,
+		DryRun:     req.DryRun,
+	}, account.Id)
 	if err != nil {
 		r.log.Error("http - v1 - DeleteAccount: %v", err)
-		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		switch {
+		case errors.Is(err, usecase.ErrAccountOwnsItems):
+			c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error(), resp))
+		case errors.Is(err, usecase.ErrAccountNotFound), errors.Is(err, usecase.ErrAccountReassignTo):
+			c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		case errors.Is(err, usecase.ErrAccountDeleteSelf), errors.Is(err, usecase.ErrAccountLastAdmin),
+			errors.Is(err, usecase.ErrAccountSuperAdmin):
+			c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+		default:
+			c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		}
 		return
 	}
 
-	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok"))
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", resp))
 }
 
 func (r *todoHandler) CreateTodo(c *gin.Context) {
@@ -167,21 +317,81 @@ func (r *todoHandler) CreateTodo(c *gin.Context) {
 		return
 	}
 
+	for _, accountID := range req.Assignees {
+		assignee, err := r.accountUsecase.GetAccount(c.Request.Context(), accountID)
+		if err != nil {
+			r.log.Error("http - v1 - CreateTodo: %v", err)
+			c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+			return
+		}
+		if assignee == nil {
+			err = errors.New("assignee not found")
+			r.log.Error("http - v1 - CreateTodo: %v", err)
+			c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+			return
+		}
+	}
+
+	if req.ProjectId != 0 {
+		project, code, err := r.writableProject(c.Request.Context(), req.ProjectId, account, entity.MemberRoleEditor)
+		if err != nil {
+			r.log.Error("http - v1 - CreateTodo: %v", err)
+			c.JSON(http.StatusOK, NewResp(code, err.Error()))
+			return
+		}
+		if project.Archived {
+			err = errors.New("project is archived")
+			r.log.Error("http - v1 - CreateTodo: %v", err)
+			c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+			return
+		}
+	}
+
 	todo := entity.Todo{
-		OwnerId: account.Id,
-		Name:    req.Name,
-		Desc:    req.Desc,
+		OwnerId:    account.Id,
+		ProjectId:  req.ProjectId,
+		Name:       req.Name,
+		Desc:       req.Desc,
+		Due:        req.Due,
+		Labels:     req.Labels,
+		Priority:   req.Priority,
+		Recurrence: req.Recurrence,
+		Assignees:  req.Assignees,
 	}
-	if err := r.todoUsecase.CreateTodo(c.Request.Context(), todo); err != nil {
+	id, err := r.todoUsecase.CreateTodo(c.Request.Context(), todo)
+	if err != nil {
 		r.log.Error("http - v1 - CreateTodo: %v", err)
+		if errors.Is(err, usecase.ErrTodoInvalid) {
+			c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+			return
+		}
 		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok"))
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", CreateTodoResponse{id}))
+}
+
+// todoAccess loads the todo and resolves the role account has on it.
+func (r *todoHandler) todoAccess(ctx context.Context, todoID uint, account entity.Account) (*entity.Todo, entity.MemberRole, ErrCode, error) {
+	todo, err := r.todoUsecase.GetTodo(ctx, todoID)
+	if err != nil {
+		return nil, entity.MemberRoleNone, ErrCodeInternal, err
+	}
+	if todo == nil {
+		return nil, entity.MemberRoleNone, ErrCodeInternal, errors.New("not found")
+	}
+
+	role, err := r.memberUsecase.TodoRole(ctx, account, *todo)
+	if err != nil {
+		return nil, entity.MemberRoleNone, ErrCodeInternal, err
+	}
+	return todo, role, ErrCodeNone, nil
 }
 
 func (r *todoHandler) GetTodo(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
 	var req dto.GetTodoRequest
 	if err := c.ShouldBind(&req); err != nil {
 		r.log.Error("http - v1 - GetTodo: %v", err)
@@ -189,18 +399,35 @@ func (r *todoHandler) GetTodo(c *gin.Context) {
 		return
 	}
 
-	resp, err := r.todoUsecase.GetTodo(c.Request.Context(), req.Id)
+	resp, role, code, err := r.todoAccess(c.Request.Context(), req.Id, account)
 	if err != nil {
 		r.log.Error("http - v1 - GetTodo: %v", err)
-		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		c.JSON(http.StatusOK, NewResp(code, err.Error()))
+		return
+	}
+	if role < entity.MemberRoleViewer {
+		err = errors.New("No access")
+		r.log.Error("http - v1 - GetTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
 		return
 	}
 
+	c.Header(HeaderETag, todoETag(*resp))
 	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", resp))
 }
 
 func (r *todoHandler) GetTodos(c *gin.Context) {
-	resp, err := r.todoUsecase.GetTodoAll(c.Request.Context())
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var (
+		resp []entity.Todo
+		err  error
+	)
+	if account.IsAdmin() {
+		resp, err = r.todoUsecase.GetTodoAll(c.Request.Context())
+	} else {
+		resp, err = r.todoUsecase.GetTodoAllVisible(c.Request.Context(), account.Id)
+	}
 	if err != nil {
 		r.log.Error("http - v1 - GetTodoAll: %v", err)
 		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
@@ -220,38 +447,65 @@ func (r *todoHandler) UpdateTodo(c *gin.Context) {
 		return
 	}
 
-	resp, err := r.todoUsecase.GetTodo(c.Request.Context(), req.Id)
+	resp, role, code, err := r.todoAccess(c.Request.Context(), req.Id, account)
 	if err != nil {
 		r.log.Error("http - v1 - UpdateTodo: %v", err)
-		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		c.JSON(http.StatusOK, NewResp(code, err.Error()))
 		return
 	}
-	if resp == nil {
-		err = errors.New("not found")
-		r.log.Error("http - v1 - UpdateTodo: %v", err)
-		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
-		return
+	if role < entity.MemberRoleEditor {
+		// assignees may only move the todo between statuses
+		if !resp.HasAssignee(account.Id) || req.Name != "" || req.Desc != "" || req.Due != nil || req.Labels != nil ||
+			req.Priority > 0 || req.Recurrence != "" {
+			err = errors.New("No access")
+			r.log.Error("http - v1 - UpdateTodo: %v", err)
+			c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+			return
+		}
 	}
-	if account.AccountType != entity.AccountTypeAdmin && account.Id != resp.OwnerId {
-		err = errors.New("No access")
+
+	// If-Match answers 412 on mismatch, version in the body 409
+	version, err := ifMatch(c)
+	if err != nil {
 		r.log.Error("http - v1 - UpdateTodo: %v", err)
-		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
 		return
 	}
+	conflictStatus := http.StatusPreconditionFailed
+	if version == 0 {
+		version = req.Version
+		conflictStatus = http.StatusConflict
+	}
 
 	todo := entity.Todo{
-		Id:     req.Id,
-		Name:   req.Name,
-		Desc:   req.Desc,
-		Status: entity.TodoStatus(req.Status),
+		Id:         req.Id,
+		Name:       req.Name,
+		Desc:       req.Desc,
+		Status:     entity.TodoStatus(req.Status),
+		Due:        req.Due,
+		Labels:     req.Labels,
+		Priority:   req.Priority,
+		Recurrence: req.Recurrence,
+		Version:    version,
 	}
 
-	if err = r.todoUsecase.UpdateTodo(c.Request.Context(), todo); err != nil {
+	if err = r.todoUsecase.UpdateTodo(c.Request.Context(), todo, account.Id); err != nil {
 		r.log.Error("http - v1 - UpdateTodo: %v", err)
+		if errors.Is(err, usecase.ErrTodoVersionConflict) {
+			r.todoConflict(c.Request.Context(), c, conflictStatus, req.Id, err)
+			return
+		}
+		if errors.Is(err, usecase.ErrTodoInvalid) {
+			c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+			return
+		}
 		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
 		return
 	}
 
+	if updated, err := r.todoUsecase.GetTodo(c.Request.Context(), req.Id); err == nil && updated != nil {
+		c.Header(HeaderETag, todoETag(*updated))
+	}
 	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok"))
 }
 
@@ -265,24 +519,21 @@ func (r *todoHandler) DeleteTodo(c *gin.Context) {
 		return
 	}
 
-	resp, err := r.todoUsecase.GetTodo(c.Request.Context(), req.Id)
+	todo, role, code, err := r.todoAccess(c.Request.Context(), req.Id, account)
 	if err != nil {
 		r.log.Error("http - v1 - DeleteTodo: %v", err)
-		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		c.JSON(http.StatusOK, NewResp(code, err.Error()))
 		return
 	}
-	if resp == nil {
-		err = errors.New("not found")
-		r.log.Error("http - v1 - DeleteTodo: %v", err)
-		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
-		return
-	}
-	if account.AccountType != entity.AccountTypeAdmin && account.Id != resp.OwnerId {
+	if role < entity.MemberRoleOwner {
 		err = errors.New("No access")
 		r.log.Error("http - v1 - DeleteTodo: %v", err)
 		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
 		return
 	}
+	if !r.checkIfMatch(c, "DeleteTodo", *todo) {
+		return
+	}
 
 	if err = r.todoUsecase.DeleteTodo(c.Request.Context(), req.Id); err != nil {
 		r.log.Error("http - v1 - DeleteTodo: %v", err)
@@ -0,0 +1,428 @@
+package v1
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/adapters/plaintext"
+	"testcode/test3/internal/controller/http/dto"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+)
+
+// _todoImportMaxBody caps the uploaded file of an import, multipart
+// boundaries and headers included.
+const _todoImportMaxBody = 10 << 20
+
+// _todoCsvHeader names the csv columns, labels are comma separated and
+// assignees semicolon separated.
+var _todoCsvHeader = []string{"id", "name", "desc", "status", "due", "labels", "priority", "recurrence", "project_id",
+	"owner_id", "assignees", "version"}
+
+// _todoImportFields are the fields an import sets, other columns are ignored.
+var _todoImportFields = map[string]struct{}{"name": {}, "desc": {}, "status": {}, "due": {}, "labels": {}, "priority": {},
+	"recurrence": {}, "project_id": {}}
+
+func todoStatusName(status entity.TodoStatus) string {
+	if status == entity.TodoStatusDone {
+		return "done"
+	}
+	return "open"
+}
+
+func parseTodoStatus(s string) (entity.TodoStatus, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "open", "1":
+		return entity.TodoStatusDefault, nil
+	case "done", "2":
+		return entity.TodoStatusDone, nil
+	}
+	return 0, fmt.Errorf("invalid status %q", s)
+}
+
+// todoCsvRecord returns the values of the todo in _todoCsvHeader order.
+func todoCsvRecord(t entity.Todo) []string {
+	var due, priority string
+	if t.Due != nil {
+		due = t.Due.UTC().Format(time.RFC3339)
+	}
+	if t.Priority > 0 {
+		priority = strconv.FormatUint(uint64(t.Priority), 10)
+	}
+	assignees := make([]string, 0, len(t.Assignees))
+	for _, id := range t.Assignees {
+		assignees = append(assignees, strconv.FormatUint(uint64(id), 10))
+	}
+	return []string{
+		strconv.FormatUint(uint64(t.Id), 10),
+		t.Name,
+		t.Desc,
+		todoStatusName(t.Status),
+		due,
+		strings.Join(t.Labels, ","),
+		priority,
+		t.Recurrence,
+		strconv.FormatUint(uint64(t.ProjectId), 10),
+		strconv.FormatUint(uint64(t.OwnerId), 10),
+		strings.Join(assignees, ";"),
+		strconv.FormatUint(uint64(t.Version), 10),
+	}
+}
+
+// parseImportMap parses "source:field,..." into source to field.
+func parseImportMap(s string) (map[string]string, error) {
+	ret := make(map[string]string)
+	if s == "" {
+		return ret, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, ":", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid mapping %q, want source:field", pair)
+		}
+		if _, ok := _todoImportFields[kv[1]]; !ok {
+			return nil, fmt.Errorf("invalid mapping %q, unknown field %q", pair, kv[1])
+		}
+		ret[kv[0]] = kv[1]
+	}
+	return ret, nil
+}
+
+// importRow turns the values of a row, keyed by source column, into a todo.
+func importRow(row int, values map[string]string, mapping map[string]string) entity.TodoImportRow {
+	ret := entity.TodoImportRow{Row: row}
+	for k, v := range values {
+		field, ok := mapping[k]
+		if !ok {
+			field = k
+		}
+		switch field {
+		case "name":
+			ret.Todo.Name = strings.TrimSpace(v)
+		case "desc":
+			ret.Todo.Desc = v
+		case "status":
+			status, err := parseTodoStatus(v)
+			if err != nil {
+				ret.Error = err.Error()
+				return ret
+			}
+			ret.Todo.Status = status
+		case "project_id":
+			if strings.TrimSpace(v) == "" {
+				continue
+			}
+			id, err := strconv.ParseUint(strings.TrimSpace(v), 10, 32)
+			if err != nil {
+				ret.Error = fmt.Sprintf("invalid project_id %q", v)
+				return ret
+			}
+			ret.Todo.ProjectId = uint(id)
+		case "due":
+			if strings.TrimSpace(v) == "" {
+				continue
+			}
+			due, err := time.Parse(time.RFC3339, strings.TrimSpace(v))
+			if err != nil {
+				ret.Error = fmt.Sprintf("invalid due %q, want RFC 3339", v)
+				return ret
+			}
+			ret.Todo.Due = &due
+		case "labels":
+			for _, label := range strings.Split(v, ",") {
+				if label = strings.TrimSpace(label); label != "" {
+					ret.Todo.Labels = append(ret.Todo.Labels, label)
+				}
+			}
+		case "priority":
+			if strings.TrimSpace(v) == "" {
+				continue
+			}
+			priority, err := strconv.ParseUint(strings.TrimSpace(v), 10, 32)
+			if err != nil {
+				ret.Error = fmt.Sprintf("invalid priority %q", v)
+				return ret
+			}
+			ret.Todo.Priority = uint(priority)
+		case "recurrence":
+			ret.Todo.Recurrence = strings.TrimSpace(v)
+		}
+	}
+	return ret
+}
+
+// readImportRows reads csv with a header row, a json array, ndjson objects,
+// todo.txt tasks or a Markdown checklist. It stops one row past the import
+// limit, enough for the usecase to reject the import.
+func readImportRows(format string, body io.Reader, mapping map[string]string) ([]entity.TodoImportRow, error) {
+	const limit = usecase.TodoImportMaxRows + 1
+	var rows []entity.TodoImportRow
+	switch format {
+	case "todotxt":
+		return plaintext.ReadTodoTxt(body, limit)
+	case "markdown":
+		return plaintext.ReadMarkdown(body, limit)
+	}
+	if format == "csv" {
+		rd := csv.NewReader(body)
+		rd.FieldsPerRecord = -1
+		header, err := rd.Read()
+		if err != nil {
+			return nil, fmt.Errorf("csv header: %w", err)
+		}
+		for n := 1; len(rows) < limit; n++ {
+			record, err := rd.Read()
+			if err == io.EOF {
+				return rows, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			values := make(map[string]string, len(header))
+			for i, v := range record {
+				if i < len(header) {
+					values[strings.TrimSpace(header[i])] = v
+				}
+			}
+			rows = append(rows, importRow(n, values, mapping))
+		}
+		return rows, nil
+	}
+
+	dec := json.NewDecoder(body)
+	dec.UseNumber()
+	if format == "json" {
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+			return nil, errors.New("json import must be an array of objects")
+		}
+	}
+	for n := 1; len(rows) < limit && dec.More(); n++ {
+		var obj map[string]interface{}
+		if err := dec.Decode(&obj); err != nil {
+			return nil, fmt.Errorf("row %d: %w", n, err)
+		}
+		values := make(map[string]string, len(obj))
+		var rowErr string
+		for k, v := range obj {
+			switch val := v.(type) {
+			case nil:
+				values[k] = ""
+			case string:
+				values[k] = val
+			case json.Number:
+				values[k] = val.String()
+			case bool:
+				values[k] = strconv.FormatBool(val)
+			case []interface{}:
+				// labels and assignees as exported, comma separated like
+				// the csv labels
+				list := make([]string, 0, len(val))
+				for _, e := range val {
+					switch e := e.(type) {
+					case string:
+						list = append(list, e)
+					case json.Number:
+						list = append(list, e.String())
+					default:
+						rowErr = fmt.Sprintf("%s must be a scalar or a list of scalars", k)
+					}
+				}
+				values[k] = strings.Join(list, ",")
+			default:
+				rowErr = fmt.Sprintf("%s must be a scalar or a list of scalars", k)
+			}
+		}
+		row := importRow(n, values, mapping)
+		if rowErr != "" {
+			row.Error = rowErr
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// ExportTodos streams the todos the account sees as csv, a json array,
+// ndjson, todo.txt or a Markdown checklist. Errors after the first byte can
+// only be logged.
+func (r *todoHandler) ExportTodos(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var req dto.ExportTodoRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		r.log.Error("http - v1 - ExportTodos: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	var (
+		todos []entity.Todo
+		err   error
+	)
+	if account.IsAdmin() {
+		todos, err = r.todoUsecase.GetTodoAll(c.Request.Context())
+	} else {
+		todos, err = r.todoUsecase.GetTodoAllVisible(c.Request.Context(), account.Id)
+	}
+	if err != nil {
+		r.log.Error("http - v1 - ExportTodos: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	switch req.Format {
+	case "csv":
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="todos.csv"`)
+		c.Status(http.StatusOK)
+		w := csv.NewWriter(c.Writer)
+		err = w.Write(_todoCsvHeader)
+		for i := 0; i < len(todos) && err == nil; i++ {
+			err = w.Write(todoCsvRecord(todos[i]))
+		}
+		if err == nil {
+			w.Flush()
+			err = w.Error()
+		}
+	case "todotxt":
+		c.Header("Content-Type", "text/plain; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="todo.txt"`)
+		c.Status(http.StatusOK)
+		err = plaintext.WriteTodoTxt(c.Writer, todos)
+	case "markdown":
+		c.Header("Content-Type", "text/markdown; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="todos.md"`)
+		c.Status(http.StatusOK)
+		err = plaintext.WriteMarkdown(c.Writer, todos)
+	case "ndjson":
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Content-Disposition", `attachment; filename="todos.ndjson"`)
+		c.Status(http.StatusOK)
+		enc := json.NewEncoder(c.Writer)
+		for i := 0; i < len(todos) && err == nil; i++ {
+			err = enc.Encode(todos[i])
+		}
+	default:
+		c.Header("Content-Type", "application/json")
+		c.Header("Content-Disposition", `attachment; filename="todos.json"`)
+		c.Status(http.StatusOK)
+		enc := json.NewEncoder(c.Writer)
+		_, err = c.Writer.WriteString("[")
+		for i := 0; i < len(todos) && err == nil; i++ {
+			if i > 0 {
+				_, err = c.Writer.WriteString(",")
+			}
+			if err == nil {
+				err = enc.Encode(todos[i])
+			}
+		}
+		if err == nil {
+			_, err = c.Writer.WriteString("]")
+		}
+	}
+	if err != nil {
+		r.log.Error("http - v1 - ExportTodos: %v", err)
+	}
+}
+
+// ImportTodos creates todos from an uploaded file, see uploadedFile. Rows are
+// checked first and nothing is imported unless all pass.
+func (r *todoHandler) ImportTodos(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var req dto.ImportTodoRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		r.log.Error("http - v1 - ImportTodos: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+	mapping, err := parseImportMap(req.Map)
+	if err != nil {
+		r.log.Error("http - v1 - ImportTodos: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	body, err := uploadedFile(c)
+	if err != nil {
+		r.log.Error("http - v1 - ImportTodos: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+	defer body.Close()
+
+	rows, err := readImportRows(req.Format, body, mapping)
+	if err != nil {
+		r.log.Error("http - v1 - ImportTodos: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	r.importRows(c, "ImportTodos", account, rows, req.DryRun)
+}
+
+// uploadedFile returns the multipart field "file" or else the raw body, both
+// limited to _todoImportMaxBody.
+func uploadedFile(c *gin.Context) (io.ReadCloser, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, _todoImportMaxBody)
+	if !strings.HasPrefix(c.ContentType(), "multipart/") {
+		return c.Request.Body, nil
+	}
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+	return fh.Open()
+}
+
+// importRows checks the projects of the rows, once each like CreateTodo
+// does, and imports them for the account.
+func (r *todoHandler) importRows(c *gin.Context, name string, account entity.Account, rows []entity.TodoImportRow, dryRun bool) {
+	projectErrs := make(map[uint]string)
+	for i := range rows {
+		projectID := rows[i].Todo.ProjectId
+		if rows[i].Error != "" || projectID == 0 {
+			continue
+		}
+		msg, ok := projectErrs[projectID]
+		if !ok {
+			project, code, err := r.writableProject(c.Request.Context(), projectID, account, entity.MemberRoleEditor)
+			switch {
+			case code == ErrCodeInternal:
+				r.log.Error("http - v1 - %s: %v", name, err)
+				c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+				return
+			case err != nil:
+				msg = err.Error()
+			case project.Archived:
+				msg = "project is archived"
+			}
+			projectErrs[projectID] = msg
+		}
+		rows[i].Error = msg
+	}
+
+	report, err := r.todoUsecase.ImportTodos(c.Request.Context(), rows, account.Id, dryRun)
+	if err != nil {
+		r.log.Error("http - v1 - %s: %v", name, err)
+		if errors.Is(err, usecase.ErrImportTooLarge) {
+			c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+			return
+		}
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+	if len(report.Errors) > 0 {
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, "import has invalid rows", report))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", report))
+}
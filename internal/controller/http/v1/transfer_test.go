@@ -0,0 +1,106 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+)
+
+func TestImportExportedTodos(t *testing.T) {
+	due := time.Date(2026, 11, 2, 9, 30, 0, 0, time.UTC)
+	todo := entity.Todo{Id: 7, OwnerId: 2, ProjectId: 3, Name: "plan, \"release\"", Desc: "line\nbreak",
+		Status: entity.TodoStatusDone, Due: &due, Labels: []string{"work", "q4"}, Priority: 2,
+		Recurrence: "FREQ=WEEKLY;BYDAY=MO", Assignees: []uint{2, 4}, Version: 3}
+	want := entity.Todo{ProjectId: 3, Name: todo.Name, Desc: todo.Desc, Status: todo.Status, Due: &due,
+		Labels: todo.Labels, Priority: 2, Recurrence: todo.Recurrence}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write(_todoCsvHeader)
+	_ = w.Write(todoCsvRecord(todo))
+	w.Flush()
+	rows, err := readImportRows("csv", &buf, nil)
+	if err != nil || len(rows) != 1 || rows[0].Error != "" {
+		t.Fatalf("csv: %v, rows %+v", err, rows)
+	}
+	if !reflect.DeepEqual(rows[0].Todo, want) {
+		t.Errorf("csv: got %+v, want %+v", rows[0].Todo, want)
+	}
+
+	data, _ := json.Marshal([]entity.Todo{todo})
+	rows, err = readImportRows("json", bytes.NewReader(data), nil)
+	if err != nil || len(rows) != 1 || rows[0].Error != "" {
+		t.Fatalf("json: %v, rows %+v", err, rows)
+	}
+	if !reflect.DeepEqual(rows[0].Todo, want) {
+		t.Errorf("json: got %+v, want %+v", rows[0].Todo, want)
+	}
+
+	for _, obj := range []string{`{"name":"x","due":"tomorrow"}`, `{"name":"x","priority":"high"}`,
+		`{"name":"x","labels":[["a"]]}`} {
+		rows, err = readImportRows("ndjson", strings.NewReader(obj), nil)
+		if err != nil || len(rows) != 1 || rows[0].Error == "" {
+			t.Errorf("%s: %v, rows %+v, want a row error", obj, err, rows)
+		}
+	}
+}
+
+func TestReadImportRowsLimit(t *testing.T) {
+	rows := 2 * usecase.TodoImportMaxRows
+	files := map[string]func(i int) string{
+		"csv":      func(i int) string { return fmt.Sprintf("todo %d,desc\n", i) },
+		"json":     func(i int) string { return fmt.Sprintf(`{"name":"todo %d","desc":"desc"},`, i) },
+		"ndjson":   func(i int) string { return fmt.Sprintf(`{"name":"todo %d","desc":"desc"}`+"\n", i) },
+		"todotxt":  func(i int) string { return fmt.Sprintf("todo %d desc:desc\n", i) },
+		"markdown": func(i int) string { return fmt.Sprintf("- [ ] todo %d\n  desc\n", i) },
+	}
+	for format, row := range files {
+		var b strings.Builder
+		switch format {
+		case "csv":
+			b.WriteString("name,desc\n")
+		case "json":
+			b.WriteString("[")
+		}
+		for i := 1; i <= rows; i++ {
+			b.WriteString(row(i))
+		}
+		got, err := readImportRows(format, strings.NewReader(b.String()), nil)
+		if err != nil {
+			t.Errorf("%s: %v", format, err)
+			continue
+		}
+		if len(got) != usecase.TodoImportMaxRows+1 {
+			t.Errorf("%s: read %d rows, want %d", format, len(got), usecase.TodoImportMaxRows+1)
+		}
+	}
+}
+
+func TestUploadedFileLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, size := range []int{_todoImportMaxBody, _todoImportMaxBody + 1} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/todos/import", strings.NewReader(strings.Repeat("x", size)))
+		c.Request.Header.Set("Content-Type", "text/csv")
+		body, err := uploadedFile(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = ioutil.ReadAll(body)
+		if tooLarge := size > _todoImportMaxBody; (err != nil) != tooLarge {
+			t.Errorf("%d bytes: %v", size, err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/controller/http/dto"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+)
+
+// GetTrashedTodos lists the whole trash for admins and own deleted todos for
+// everybody else.
+func (r *todoHandler) GetTrashedTodos(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	ownerID := account.Id
+	if account.IsAdmin() {
+		ownerID = 0
+	}
+	resp, err := r.trashUsecase.GetTrashedTodos(c.Request.Context(), ownerID)
+	if err != nil {
+		r.log.Error("http - v1 - GetTrashedTodos: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", resp))
+}
+
+func (r *todoHandler) RestoreTrashedTodo(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+
+	var req dto.RestoreTodoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.log.Error("http - v1 - RestoreTrashedTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	todo, err := r.trashUsecase.GetTrashedTodo(c.Request.Context(), req.Id)
+	if err != nil {
+		r.log.Error("http - v1 - RestoreTrashedTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+	if todo == nil {
+		err = usecase.ErrNotInTrash
+		r.log.Error("http - v1 - RestoreTrashedTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+	if !account.IsAdmin() && todo.OwnerId != account.Id {
+		err = errors.New("No access")
+		r.log.Error("http - v1 - RestoreTrashedTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+		return
+	}
+
+	if err = r.trashUsecase.RestoreTodo(c.Request.Context(), req.Id); err != nil {
+		r.log.Error("http - v1 - RestoreTrashedTodo: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok"))
+}
+
+func (r *todoHandler) GetTrashedAccounts(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+	if !account.IsAdmin() {
+		err := errors.New("No access")
+		r.log.Error("http - v1 - GetTrashedAccounts: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+		return
+	}
+
+	resp, err := r.trashUsecase.GetTrashedAccounts(c.Request.Context())
+	if err != nil {
+		r.log.Error("http - v1 - GetTrashedAccounts: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok", resp))
+}
+
+func (r *todoHandler) RestoreTrashedAccount(c *gin.Context) {
+	account := c.MustGet(UserKey).(entity.Account)
+	if !account.IsAdmin() {
+		err := errors.New("No access")
+		r.log.Error("http - v1 - RestoreTrashedAccount: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeNoAccess, err.Error()))
+		return
+	}
+
+	var req dto.RestoreAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.log.Error("http - v1 - RestoreTrashedAccount: %v", err)
+		c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+		return
+	}
+
+	if err := r.trashUsecase.RestoreAccount(c.Request.Context(), req.Id); err != nil {
+		r.log.Error("http - v1 - RestoreTrashedAccount: %v", err)
+		if errors.Is(err, usecase.ErrNotInTrash) {
+			c.JSON(http.StatusOK, NewResp(ErrCodeInvalidArgument, err.Error()))
+			return
+		}
+		c.JSON(http.StatusOK, NewResp(ErrCodeInternal, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResp(ErrCodeNone, "ok"))
+}
@@ -1,16 +1,54 @@
 package entity
 
+import "time"
+
 type AccountType uint
 
 const (
 	_ AccountType = iota
 	AccountTypeAdmin
 	AccountTypeUser
+	AccountTypeSuperAdmin
 )
 
 type Account struct {
 	Id          uint        `json:"id"`
+	TenantId    uint        `json:"tenant_id,omitempty"`
 	Name        string      `json:"name"`
-	Password    string      `json:"password"`
+	Password    string      `json:"-"`
 	AccountType AccountType `json:"account_type"`
+	DeletedAt   *time.Time  `json:"deleted_at,omitempty"`
+}
+
+// IsAdmin reports whether the account manages the tenant it is logged into.
+func (a *Account) IsAdmin() bool {
+	return a.AccountType == AccountTypeAdmin || a.AccountType == AccountTypeSuperAdmin
+}
+
+// AccountDeleteStrategy tells what happens to todos and projects owned by
+// the deleted account.
+type AccountDeleteStrategy string
+
+const (
+	AccountDeleteFailIfOwned AccountDeleteStrategy = "fail_if_owned"
+	AccountDeleteReassign    AccountDeleteStrategy = "reassign_to"
+	AccountDeleteCascade     AccountDeleteStrategy = "cascade"
+)
+
+type AccountDeletion struct {
+	AccountId  uint
+	Strategy   AccountDeleteStrategy
+	ReassignTo uint
+	DryRun     bool
+}
+
+// AccountDeletionReport lists what the deletion affected, or would affect
+// in dry-run mode.
+type AccountDeletionReport struct {
+	AccountId  uint                  `json:"account_id"`
+	Strategy   AccountDeleteStrategy `json:"strategy"`
+	ReassignTo uint                  `json:"reassign_to,omitempty"`
+	DryRun     bool                  `json:"dry_run"`
+	Todos      []uint                `json:"todos"`
+	Projects   []uint                `json:"projects"`
 }
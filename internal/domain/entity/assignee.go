@@ -0,0 +1,13 @@
+package entity
+
+type TodoAssignee struct {
+	TodoId    uint `json:"todo_id"`
+	AccountId uint `json:"account_id"`
+}
+
+type TodoAssignedEvent struct {
+	TodoId     uint   `json:"todo_id"`
+	TodoName   string `json:"todo_name"`
+	AccountId  uint   `json:"account_id"`
+	AssignedBy uint   `json:"assigned_by"`
+}
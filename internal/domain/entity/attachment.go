@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+type Attachment struct {
+	Id          uint      `json:"id"`
+	TodoId      uint      `json:"todo_id"`
+	UploaderId  uint      `json:"uploader_id"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	BlobKey     string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	AuditLogin          = "account.login"
+	AuditLoginFailed    = "account.login_failed"
+	AuditAccountCreate  = "account.create"
+	AuditAccountDelete  = "account.delete"
+	AuditAccountRestore = "account.restore"
+	AuditOrgCreate      = "org.create"
+	AuditOrgAccountAdd  = "org.account_add"
+	AuditOrgAccountDel  = "org.account_remove"
+	AuditTodoCreate     = "todo.create"
+	AuditTodoUpdate     = "todo.update"
+	AuditTodoMove       = "todo.move"
+	AuditTodoDelete     = "todo.delete"
+	AuditTodoRestore    = "todo.restore"
+	AuditTodoRevert     = "todo.revert"
+	AuditTodoAssign     = "todo.assign"
+	AuditTodoUnassign   = "todo.unassign"
+)
+
+const (
+	AuditTargetAccount = "account"
+	AuditTargetOrg     = "org"
+	AuditTargetTodo    = "todo"
+)
+
+// AuditEntry is an append-only record of an action, Summary holds the
+// relevant part of the payload and never secrets.
+type AuditEntry struct {
+	Id         uint                   `json:"id"`
+	ActorId    uint                   `json:"actor_id,omitempty"`
+	Action     string                 `json:"action"`
+	TargetType string                 `json:"target_type"`
+	TargetId   uint                   `json:"target_id,omitempty"`
+	RequestId  string                 `json:"request_id"`
+	ClientIp   string                 `json:"client_ip"`
+	Summary    map[string]interface{} `json:"summary,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+type AuditFilter struct {
+	ActorId    uint
+	Action     string
+	TargetType string
+	TargetId   uint
+	From       time.Time
+	To         time.Time
+}
+
+// AuditMeta describes the request an action came with.
+type AuditMeta struct {
+	RequestId string
+	ClientIp  string
+	ActorId   uint
+}
+
+type auditMetaKey struct{}
+
+// WithAuditMeta injects request metadata to context
+func WithAuditMeta(ctx context.Context, meta AuditMeta) context.Context {
+	return context.WithValue(ctx, auditMetaKey{}, meta)
+}
+
+// AuditMetaFromContext extracts request metadata from context
+func AuditMetaFromContext(ctx context.Context) AuditMeta {
+	meta, _ := ctx.Value(auditMetaKey{}).(AuditMeta)
+	return meta
+}
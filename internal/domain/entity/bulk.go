@@ -0,0 +1,50 @@
+package entity
+
+type TodoBulkOpType string
+
+const (
+	TodoBulkCreate   TodoBulkOpType = "create"
+	TodoBulkUpdate   TodoBulkOpType = "update"
+	TodoBulkComplete TodoBulkOpType = "complete"
+	TodoBulkDelete   TodoBulkOpType = "delete"
+)
+
+// TodoBulkOp is one operation of a batch, Todo carries the fields the
+// operation needs, Todo.Id is set for all but create.
+type TodoBulkOp struct {
+	Op   TodoBulkOpType
+	Todo Todo
+}
+
+type TodoBulkResult struct {
+	Index int            `json:"index"`
+	Op    TodoBulkOpType `json:"op"`
+	Id    uint           `json:"id,omitempty"`
+	Error string         `json:"error,omitempty"`
+}
+
+// TodoBulkEvent is the one notification sent for a batch.
+type TodoBulkEvent struct {
+	ActorId   uint   `json:"actor_id"`
+	Created   []uint `json:"created,omitempty"`
+	Updated   []uint `json:"updated,omitempty"`
+	Completed []uint `json:"completed,omitempty"`
+	Deleted   []uint `json:"deleted,omitempty"`
+}
+
+func (e *TodoBulkEvent) Add(op TodoBulkOpType, todoID uint) {
+	switch op {
+	case TodoBulkCreate:
+		e.Created = append(e.Created, todoID)
+	case TodoBulkUpdate:
+		e.Updated = append(e.Updated, todoID)
+	case TodoBulkComplete:
+		e.Completed = append(e.Completed, todoID)
+	case TodoBulkDelete:
+		e.Deleted = append(e.Deleted, todoID)
+	}
+}
+
+func (e *TodoBulkEvent) Empty() bool {
+	return len(e.Created)+len(e.Updated)+len(e.Completed)+len(e.Deleted) == 0
+}
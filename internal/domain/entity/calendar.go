@@ -0,0 +1,17 @@
+package entity
+
+// CalendarFeed binds the secret token of an account's iCalendar feed to the
+// account and the tenant it was created in, the feed is read without a
+// session.
+type CalendarFeed struct {
+	TenantId  uint
+	AccountId uint
+}
+
+// DavResource is a todo created over CalDAV under a resource name and UID the
+// client chose, it is served under them from then on.
+type DavResource struct {
+	TodoId uint
+	Name   string
+	Uid    string
+}
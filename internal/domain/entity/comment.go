@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+type Comment struct {
+	Id        uint       `json:"id"`
+	TodoId    uint       `json:"todo_id"`
+	AuthorId  uint       `json:"author_id"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+}
+
+type CommentMentionEvent struct {
+	CommentId uint `json:"comment_id"`
+	TodoId    uint `json:"todo_id"`
+	AuthorId  uint `json:"author_id"`
+	AccountId uint `json:"account_id"`
+}
@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+type TodoEventType string
+
+const (
+	TodoEventCreated TodoEventType = "created"
+	TodoEventUpdated TodoEventType = "updated"
+	TodoEventDeleted TodoEventType = "deleted"
+)
+
+// TodoEvent is a committed change of a todo, Todo is the state after it or,
+// for deleted, before it. Ids grow with every event of the process.
+type TodoEvent struct {
+	Id       uint64        `json:"id"`
+	Type     TodoEventType `json:"type"`
+	TenantId uint          `json:"-"`
+	ActorId  uint          `json:"actor_id,omitempty"`
+	Todo     Todo          `json:"todo"`
+	At       time.Time     `json:"at"`
+}
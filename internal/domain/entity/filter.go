@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+type SavedFilter struct {
+	Id        uint      `json:"id"`
+	AccountId uint      `json:"account_id"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	CreatedAt time.Time `json:"created_at"`
+}
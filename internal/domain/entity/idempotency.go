@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// IdempotencyRecord is the first response to a request sent with an
+// Idempotency-Key, replayed to retries until it expires. It is reserved
+// before the request runs and completed after.
+type IdempotencyRecord struct {
+	AccountId   uint
+	Key         string
+	RequestHash string
+	Completed   bool
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,38 @@
+package entity
+
+type MemberRole uint
+
+// Roles are ordered, a higher role includes every permission of the lower ones.
+const (
+	MemberRoleNone MemberRole = iota
+	MemberRoleViewer
+	MemberRoleEditor
+	MemberRoleOwner
+)
+
+type MemberResource uint
+
+const (
+	_ MemberResource = iota
+	MemberResourceProject
+	MemberResourceTodo
+)
+
+type Member struct {
+	Id           uint           `json:"id"`
+	ResourceType MemberResource `json:"resource_type"`
+	ResourceId   uint           `json:"resource_id"`
+	AccountId    uint           `json:"account_id"`
+	Role         MemberRole     `json:"role"`
+	InvitedBy    uint           `json:"invited_by"`
+	Accepted     bool           `json:"accepted"`
+}
+
+type MemberInvitedEvent struct {
+	MemberId     uint           `json:"member_id"`
+	ResourceType MemberResource `json:"resource_type"`
+	ResourceId   uint           `json:"resource_id"`
+	AccountId    uint           `json:"account_id"`
+	Role         MemberRole     `json:"role"`
+	InvitedBy    uint           `json:"invited_by"`
+}
@@ -0,0 +1,31 @@
+package entity
+
+import "context"
+
+type Org struct {
+	Id   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// OrgAccount binds an account to an org, Role is either AccountTypeAdmin or
+// AccountTypeUser and replaces the account type inside that org.
+type OrgAccount struct {
+	OrgId     uint        `json:"org_id"`
+	AccountId uint        `json:"account_id"`
+	Role      AccountType `json:"role"`
+}
+
+type tenantKey struct{}
+
+// WithTenant injects tenant id to context
+func WithTenant(ctx context.Context, tenantID uint) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFromContext extracts tenant id from context, zero means no tenant
+func TenantFromContext(ctx context.Context) uint {
+	if id, ok := ctx.Value(tenantKey{}).(uint); ok {
+		return id
+	}
+	return 0
+}
@@ -0,0 +1,10 @@
+package entity
+
+type Project struct {
+	Id       uint   `json:"id"`
+	OwnerId  uint   `json:"owner_id"`
+	Name     string `json:"name"`
+	Desc     string `json:"desc"`
+	Color    string `json:"color"`
+	Archived bool   `json:"archived"`
+}
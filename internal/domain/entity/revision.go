@@ -0,0 +1,43 @@
+package entity
+
+import "time"
+
+// TodoRevision is a snapshot of the todo after a change together with the
+// fields the change touched.
+type TodoRevision struct {
+	Id           uint              `json:"id"`
+	TodoId       uint              `json:"todo_id"`
+	ActorId      uint              `json:"actor_id"`
+	Name         string            `json:"name"`
+	Desc         string            `json:"desc"`
+	Status       TodoStatus        `json:"status"`
+	Due          *time.Time        `json:"due,omitempty"`
+	Labels       []string          `json:"labels,omitempty"`
+	Priority     uint              `json:"priority,omitempty"`
+	Recurrence   string            `json:"recurrence,omitempty"`
+	ProjectId    uint              `json:"project_id,omitempty"`
+	RestoredFrom uint              `json:"restored_from,omitempty"`
+	Changes      []TodoFieldChange `json:"changes"`
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+type TodoFieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// Snapshot returns the todo as it was at the revision.
+func (r *TodoRevision) Snapshot() Todo {
+	return Todo{
+		Id:         r.TodoId,
+		ProjectId:  r.ProjectId,
+		Name:       r.Name,
+		Desc:       r.Desc,
+		Status:     r.Status,
+		Due:        r.Due,
+		Labels:     r.Labels,
+		Priority:   r.Priority,
+		Recurrence: r.Recurrence,
+	}
+}
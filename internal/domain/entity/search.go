@@ -0,0 +1,10 @@
+package entity
+
+// TodoSearchHit is a todo matching a search. Name and Snippet are escaped
+// HTML with the matches in <mark>.
+type TodoSearchHit struct {
+	Todo    Todo    `json:"todo"`
+	Score   float64 `json:"score"`
+	Name    string  `json:"name_highlight"`
+	Snippet string  `json:"snippet"`
+}
@@ -1,5 +1,7 @@
 package entity
 
+import "time"
+
 type TodoStatus uint
 
 const (
@@ -9,9 +11,51 @@ const (
 )
 
 type Todo struct {
-	Id      uint       `json:"id"`
-	OwnerId uint       `json:"owner_id"`
-	Name    string     `json:"name"`
-	Desc    string     `json:"desc"`
-	Status  TodoStatus `json:"status"`
+	Id        uint       `json:"id"`
+	OwnerId   uint       `json:"owner_id"`
+	ProjectId uint       `json:"project_id,omitempty"`
+	Name      string     `json:"name"`
+	Desc      string     `json:"desc"`
+	Status    TodoStatus `json:"status"`
+	Due       *time.Time `json:"due,omitempty"`
+	Labels    []string   `json:"labels,omitempty"`
+	// Priority runs from 1, the highest, to 9, zero is undefined
+	Priority uint `json:"priority,omitempty"`
+	// Recurrence is an RFC 5545 RRULE value like FREQ=WEEKLY;BYDAY=MO
+	Recurrence string     `json:"recurrence,omitempty"`
+	Assignees  []uint     `json:"assignees,omitempty"`
+	Version    uint       `json:"version"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+}
+
+func (t *Todo) HasAssignee(accountID uint) bool {
+	for _, id := range t.Assignees {
+		if id == accountID {
+			return true
+		}
+	}
+	return false
+}
+
+// TodoPatch sets the non-nil fields, empty values included, non-zero Version
+// makes it conditional. Due pointing to the zero time clears the due date.
+type TodoPatch struct {
+	Name       *string
+	Desc       *string
+	Status     *TodoStatus
+	Due        *time.Time
+	Labels     *[]string
+	Priority   *uint
+	Recurrence *string
+	Version    uint
+}
+
+// StatusOnly tells whether the patch sets no field but the status.
+func (p *TodoPatch) StatusOnly() bool {
+	return p.Name == nil && p.Desc == nil && p.Due == nil && p.Labels == nil && p.Priority == nil && p.Recurrence == nil
+}
+
+// Empty tells whether the patch sets no field.
+func (p *TodoPatch) Empty() bool {
+	return p.StatusOnly() && p.Status == nil
 }
@@ -0,0 +1,24 @@
+package entity
+
+// TodoImportRow is one parsed row of an import, Error is set when the row
+// could not be read into Todo.
+type TodoImportRow struct {
+	Row   int
+	Todo  Todo
+	Error string
+}
+
+type TodoImportError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// TodoImportReport lists the invalid rows, nothing is imported unless there
+// are none.
+type TodoImportReport struct {
+	Total    int               `json:"total"`
+	Imported int               `json:"imported"`
+	DryRun   bool              `json:"dry_run"`
+	Ids      []uint            `json:"ids,omitempty"`
+	Errors   []TodoImportError `json:"errors,omitempty"`
+}
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"errors"
 
 	"testcode/test3/internal/domain/entity"
 	"testcode/test3/pkg/logger"
@@ -10,25 +11,71 @@ import (
 type AccountStorage interface {
 	Create(ctx context.Context, dto entity.Account) error
 	Get(ctx context.Context, accountID uint) (*entity.Account, error)
+	GetByIds(ctx context.Context, accountIDs []uint) ([]entity.Account, error)
 	GetByName(ctx context.Context, name string) (*entity.Account, error)
 	GetAll(ctx context.Context) ([]entity.Account, error)
 	Delete(ctx context.Context, accountID uint) error
 }
 
+var (
+	ErrAccountNotFound     = errors.New("account not found")
+	ErrAccountDeleteSelf   = errors.New("cannot delete your own account")
+	ErrAccountLastAdmin    = errors.New("cannot delete the last admin")
+	ErrAccountOwnsItems    = errors.New("account owns todos or projects")
+	ErrAccountReassignTo   = errors.New("reassign_to must be another active account")
+	ErrAccountSuperAdmin   = errors.New("cannot delete a super admin")
+	errAccountDeleteDryRun = errors.New("dry run")
+)
+
+// OwnedTodoStorage manages todos by their owner on account deletion.
+type OwnedTodoStorage interface {
+	GetAllByOwner(ctx context.Context, ownerID uint) ([]entity.Todo, error)
+	GetAllByProject(ctx context.Context, projectID uint) ([]entity.Todo, error)
+	SetOwner(ctx context.Context, ownerID uint, newOwnerID uint) error
+	DeleteAllByOwner(ctx context.Context, ownerID uint) error
+}
+
+// OwnedProjectStorage manages projects by their owner on account deletion.
+type OwnedProjectStorage interface {
+	GetAllByOwner(ctx context.Context, ownerID uint) ([]entity.Project, error)
+	SetOwner(ctx context.Context, ownerID uint, newOwnerID uint) error
+	Delete(ctx context.Context, projectID uint) error
+}
+
 type accountUsecase struct {
-	storage AccountStorage
-	log     *logger.Logger
+	storage        AccountStorage
+	todoStorage    OwnedTodoStorage
+	projectStorage OwnedProjectStorage
+	transactor     Transactor
+	auditor        Auditor
+	log            *logger.Logger
 }
 
-func NewAccountUsecase(log *logger.Logger, storage AccountStorage) *accountUsecase {
+func NewAccountUsecase(log *logger.Logger, storage AccountStorage, todoStorage OwnedTodoStorage, projectStorage OwnedProjectStorage,
+	transactor Transactor, auditor Auditor) *accountUsecase {
 	return &accountUsecase{
-		storage: storage,
-		log:     log,
+		storage:        storage,
+		todoStorage:    todoStorage,
+		projectStorage: projectStorage,
+		transactor:     transactor,
+		auditor:        auditor,
+		log:            log,
 	}
 }
 
 func (r *accountUsecase) CreateAccount(ctx context.Context, dto entity.Account) error {
-	if err := r.storage.Create(ctx, dto); err != nil {
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := r.storage.Create(ctx, dto); err != nil {
+			return err
+		}
+		r.auditor.Audit(ctx, entity.AuditEntry{
+			Action:     entity.AuditAccountCreate,
+			TargetType: entity.AuditTargetAccount,
+			Summary:    map[string]interface{}{"name": dto.Name, "account_type": dto.AccountType},
+		})
+		return nil
+	})
+	if err != nil {
 		r.log.Error("AccountUsecase - CreateAccount - r.storage.Create: %v; Name=%v, Password=%v, AccountType=%v",
 			err,
 			dto.Name,
@@ -49,6 +96,17 @@ func (r *accountUsecase) GetAccount(ctx context.Context, accountID uint) (*entit
 	return ret, nil
 }
 
+// GetAccountAllByIds loads the accounts in one query, ids not found in the
+// tenant are left out.
+func (r *accountUsecase) GetAccountAllByIds(ctx context.Context, accountIDs []uint) ([]entity.Account, error) {
+	ret, err := r.storage.GetByIds(ctx, accountIDs)
+	if err != nil {
+		r.log.Error("AccountUsecase - GetAccountAllByIds - r.storage.GetByIds: %v; accountIDs=%v", err, accountIDs)
+		return nil, err
+	}
+	return ret, nil
+}
+
 func (r *accountUsecase) GetAccountByName(ctx context.Context, name string) (*entity.Account, error) {
 	ret, err := r.storage.GetByName(ctx, name)
 	if err != nil {
@@ -58,6 +116,29 @@ func (r *accountUsecase) GetAccountByName(ctx context.Context, name string) (*en
 	return ret, nil
 }
 
+// Authenticate checks the credentials, failed attempts go to the audit log of
+// the tenant from context.
+func (r *accountUsecase) Authenticate(ctx context.Context, name string, password string) (*entity.Account, error) {
+	account, err := r.storage.GetByName(ctx, name)
+	if err != nil {
+		r.log.Error("AccountUsecase - Authenticate - r.storage.GetByName: %v; name=%v", err, name)
+		return nil, err
+	}
+	if account == nil || account.Password != password {
+		entry := entity.AuditEntry{
+			Action:     entity.AuditLoginFailed,
+			TargetType: entity.AuditTargetAccount,
+			Summary:    map[string]interface{}{"name": name},
+		}
+		if account != nil {
+			entry.TargetId = account.Id
+		}
+		r.auditor.Audit(ctx, entry)
+		return nil, ErrAccountNotFound
+	}
+	return account, nil
+}
+
 func (r *accountUsecase) GetAccountAll(ctx context.Context) ([]entity.Account, error) {
 	ret, err := r.storage.GetAll(ctx)
 	if err != nil {
@@ -67,10 +148,147 @@ func (r *accountUsecase) GetAccountAll(ctx context.Context) ([]entity.Account, e
 	return ret, nil
 }
 
-func (r *accountUsecase) DeleteAccount(ctx context.Context, accountID uint) error {
-	if err := r.storage.Delete(ctx, accountID); err != nil {
-		r.log.Error("AccountUsecase - DeleteAccount - r.storage.Delete: %v", err)
+// DeleteAccount moves the account to the trash handling what it owns by the
+// strategy. The report lists affected todos and projects, todos of other
+// accounts in its projects included, in dry-run mode nothing is changed.
+// Cascading moves the todos to the trash, the projects are dropped. Guards keep actor from deleting itself, the last admin
+// of the tenant or a super admin.
+func (r *accountUsecase) DeleteAccount(ctx context.Context, dto entity.AccountDeletion, actorID uint) (*entity.AccountDeletionReport, error) {
+	report := &entity.AccountDeletionReport{
+		AccountId:  dto.AccountId,
+		Strategy:   dto.Strategy,
+		ReassignTo: dto.ReassignTo,
+		DryRun:     dto.DryRun,
+		Todos:      []uint{},
+		Projects:   []uint{},
+	}
+
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := r.checkDeletion(ctx, dto, actorID); err != nil {
+			return err
+		}
+
+		todos, err := r.todoStorage.GetAllByOwner(ctx, dto.AccountId)
+		if err != nil {
+			return err
+		}
+		projects, err := r.projectStorage.GetAllByOwner(ctx, dto.AccountId)
+		if err != nil {
+			return err
+		}
+		for _, p := range projects {
+			report.Projects = append(report.Projects, p.Id)
+			projectTodos, err := r.todoStorage.GetAllByProject(ctx, p.Id)
+			if err != nil {
+				return err
+			}
+			for _, t := range projectTodos {
+				if t.OwnerId != dto.AccountId {
+					todos = append(todos, t)
+				}
+			}
+		}
+		for _, t := range todos {
+			report.Todos = append(report.Todos, t.Id)
+		}
+
+		owns := len(todos) > 0 || len(projects) > 0
+		if dto.Strategy == entity.AccountDeleteFailIfOwned && owns {
+			return ErrAccountOwnsItems
+		}
+		if dto.DryRun {
+			return errAccountDeleteDryRun
+		}
+
+		switch dto.Strategy {
+		case entity.AccountDeleteReassign:
+			if err = r.todoStorage.SetOwner(ctx, dto.AccountId, dto.ReassignTo); err != nil {
+				return err
+			}
+			if err = r.projectStorage.SetOwner(ctx, dto.AccountId, dto.ReassignTo); err != nil {
+				return err
+			}
+		case entity.AccountDeleteCascade:
+			for _, p := range projects {
+				if err = r.projectStorage.Delete(ctx, p.Id); err != nil {
+					return err
+				}
+			}
+			if err = r.todoStorage.DeleteAllByOwner(ctx, dto.AccountId); err != nil {
+				return err
+			}
+			for _, t := range todos {
+				r.auditor.Audit(ctx, entity.AuditEntry{
+					ActorId:    actorID,
+					Action:     entity.AuditTodoDelete,
+					TargetType: entity.AuditTargetTodo,
+					TargetId:   t.Id,
+				})
+			}
+		}
+		r.auditor.Audit(ctx, entity.AuditEntry{
+			ActorId:    actorID,
+			Action:     entity.AuditAccountDelete,
+			TargetType: entity.AuditTargetAccount,
+			TargetId:   dto.AccountId,
+			Summary: map[string]interface{}{
+				"strategy":    dto.Strategy,
+				"reassign_to": dto.ReassignTo,
+				"todos":       len(report.Todos),
+				"projects":    len(report.Projects),
+			},
+		})
+		return r.storage.Delete(ctx, dto.AccountId)
+	})
+	if errors.Is(err, errAccountDeleteDryRun) {
+		return report, nil
+	}
+	if err != nil {
+		r.log.Error("AccountUsecase - DeleteAccount: %v; accountID=%v, strategy=%v", err, dto.AccountId, dto.Strategy)
+		return report, err
+	}
+	return report, nil
+}
+
+// checkDeletion applies the self, super admin, last admin and reassign
+// target guards.
+func (r *accountUsecase) checkDeletion(ctx context.Context, dto entity.AccountDeletion, actorID uint) error {
+	if dto.AccountId == actorID {
+		return ErrAccountDeleteSelf
+	}
+	accounts, err := r.storage.GetAll(ctx)
+	if err != nil {
 		return err
 	}
+
+	var (
+		target   *entity.Account
+		reassign bool
+		admins   int
+	)
+	for i := range accounts {
+		a := &accounts[i]
+		if a.IsAdmin() {
+			admins++
+		}
+		if a.Id == dto.AccountId {
+			target = a
+		}
+		if a.Id == dto.ReassignTo && a.Id != dto.AccountId {
+			reassign = true
+		}
+	}
+	if target == nil {
+		return ErrAccountNotFound
+	}
+	if target.AccountType == entity.AccountTypeSuperAdmin {
+		return ErrAccountSuperAdmin
+	}
+	if target.IsAdmin() && admins <= 1 {
+		return ErrAccountLastAdmin
+	}
+	if dto.Strategy == entity.AccountDeleteReassign && !reassign {
+		return ErrAccountReassignTo
+	}
 	return nil
 }
@@ -0,0 +1,156 @@
+package usecase_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+	"testcode/test3/pkg/logger"
+)
+
+type accountStorage struct {
+	usecase.AccountStorage
+	accounts []entity.Account
+	deleted  []uint
+}
+
+func (r *accountStorage) GetAll(context.Context) ([]entity.Account, error) {
+	return r.accounts, nil
+}
+
+func (r *accountStorage) Delete(_ context.Context, accountID uint) error {
+	r.deleted = append(r.deleted, accountID)
+	return nil
+}
+
+// ownedStorage keeps todos and projects, deleting a project moves its todos
+// to the trash the way the mysql storage does.
+type ownedStorage struct {
+	todos    []entity.Todo
+	projects []entity.Project
+	trashed  []uint
+}
+
+func (r *ownedStorage) GetAllByOwner(_ context.Context, ownerID uint) ([]entity.Todo, error) {
+	var ret []entity.Todo
+	for _, t := range r.todos {
+		if t.OwnerId == ownerID {
+			ret = append(ret, t)
+		}
+	}
+	return ret, nil
+}
+
+func (r *ownedStorage) GetAllByProject(_ context.Context, projectID uint) ([]entity.Todo, error) {
+	var ret []entity.Todo
+	for _, t := range r.todos {
+		if t.ProjectId == projectID {
+			ret = append(ret, t)
+		}
+	}
+	return ret, nil
+}
+
+func (r *ownedStorage) SetOwner(context.Context, uint, uint) error { return nil }
+
+func (r *ownedStorage) trash(keep func(entity.Todo) bool) {
+	var kept []entity.Todo
+	for _, t := range r.todos {
+		if keep(t) {
+			kept = append(kept, t)
+			continue
+		}
+		r.trashed = append(r.trashed, t.Id)
+	}
+	r.todos = kept
+}
+
+func (r *ownedStorage) DeleteAllByOwner(_ context.Context, ownerID uint) error {
+	r.trash(func(t entity.Todo) bool { return t.OwnerId != ownerID })
+	return nil
+}
+
+type ownedProjects struct{ *ownedStorage }
+
+func (r ownedProjects) GetAllByOwner(_ context.Context, ownerID uint) ([]entity.Project, error) {
+	var ret []entity.Project
+	for _, p := range r.projects {
+		if p.OwnerId == ownerID {
+			ret = append(ret, p)
+		}
+	}
+	return ret, nil
+}
+
+func (r ownedProjects) SetOwner(context.Context, uint, uint) error { return nil }
+
+func (r ownedProjects) Delete(_ context.Context, projectID uint) error {
+	r.trash(func(t entity.Todo) bool { return t.ProjectId != projectID })
+	return nil
+}
+
+type auditLog struct{ entries []entity.AuditEntry }
+
+func (r *auditLog) Audit(_ context.Context, entry entity.AuditEntry) {
+	r.entries = append(r.entries, entry)
+}
+
+func TestDeleteAccountCascade(t *testing.T) {
+	newFixture := func() (*accountStorage, *ownedStorage, *auditLog) {
+		accounts := &accountStorage{accounts: []entity.Account{
+			{Id: 1, AccountType: entity.AccountTypeAdmin},
+			{Id: 2, AccountType: entity.AccountTypeUser},
+			{Id: 3, AccountType: entity.AccountTypeUser},
+		}}
+		owned := &ownedStorage{
+			projects: []entity.Project{{Id: 10, OwnerId: 2}},
+			todos: []entity.Todo{
+				{Id: 1, OwnerId: 2},
+				{Id: 2, OwnerId: 2, ProjectId: 10},
+				// of another member of the project
+				{Id: 3, OwnerId: 3, ProjectId: 10},
+				{Id: 4, OwnerId: 3},
+			},
+		}
+		return accounts, owned, &auditLog{}
+	}
+	deletion := entity.AccountDeletion{AccountId: 2, Strategy: entity.AccountDeleteCascade}
+
+	accounts, owned, audit := newFixture()
+	accountUsecase := usecase.NewAccountUsecase(logger.New("error"), accounts, owned, ownedProjects{owned}, transactor{}, audit)
+	dry := deletion
+	dry.DryRun = true
+	report, err := accountUsecase.DeleteAccount(context.Background(), dry, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(report.Todos, []uint{1, 2, 3}) || !reflect.DeepEqual(report.Projects, []uint{10}) {
+		t.Errorf("dry run: todos %v, projects %v, want todos 1, 2 and 3 of project 10", report.Todos, report.Projects)
+	}
+	if len(owned.trashed) != 0 || len(accounts.deleted) != 0 {
+		t.Errorf("dry run: trashed %v, deleted %v, want nothing changed", owned.trashed, accounts.deleted)
+	}
+
+	accounts, owned, audit = newFixture()
+	accountUsecase = usecase.NewAccountUsecase(logger.New("error"), accounts, owned, ownedProjects{owned}, transactor{}, audit)
+	report, err = accountUsecase.DeleteAccount(context.Background(), deletion, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(owned.trashed, []uint{2, 3, 1}) || !reflect.DeepEqual(accounts.deleted, []uint{2}) {
+		t.Errorf("cascade: trashed %v, deleted %v, want todos 1, 2 and 3 in the trash", owned.trashed, accounts.deleted)
+	}
+	trashed := map[uint]bool{}
+	for _, e := range audit.entries {
+		if e.Action == entity.AuditTodoDelete {
+			trashed[e.TargetId] = true
+		}
+	}
+	for _, id := range report.Todos {
+		if !trashed[id] {
+			t.Errorf("cascade: no audit entry for todo %d", id)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/logger"
+)
+
+// _sniffLen is the amount of bytes http.DetectContentType looks at
+const _sniffLen = 512
+
+var (
+	ErrAttachmentTooLarge = errors.New("attachment is too large")
+	ErrAttachmentType     = errors.New("attachment type is not allowed")
+)
+
+type BlobStorage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Get reads length bytes starting at offset, negative length reads to the end
+	Get(ctx context.Context, key string, offset int64, length int64) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type AttachmentStorage interface {
+	Create(ctx context.Context, dto entity.Attachment) (uint, error)
+	Get(ctx context.Context, attachmentID uint) (*entity.Attachment, error)
+	GetAllByTodo(ctx context.Context, todoID uint) ([]entity.Attachment, error)
+	GetAllByProject(ctx context.Context, projectID uint) ([]entity.Attachment, error)
+	Delete(ctx context.Context, attachmentID uint) error
+}
+
+type attachmentUsecase struct {
+	storage      AttachmentStorage
+	blobStorage  BlobStorage
+	maxSize      int64
+	allowedTypes map[string]struct{}
+	log          *logger.Logger
+}
+
+func NewAttachmentUsecase(log *logger.Logger, storage AttachmentStorage, blobStorage BlobStorage, maxSize int64, allowedTypes []string) *attachmentUsecase {
+	types := make(map[string]struct{}, len(allowedTypes))
+	for _, t := range allowedTypes {
+		types[t] = struct{}{}
+	}
+	return &attachmentUsecase{
+		storage:      storage,
+		blobStorage:  blobStorage,
+		maxSize:      maxSize,
+		allowedTypes: types,
+		log:          log,
+	}
+}
+
+// MaxSize returns the upload size limit in bytes
+func (r *attachmentUsecase) MaxSize() int64 {
+	return r.maxSize
+}
+
+// removeBlobs deletes blobs of attachments whose rows are already gone,
+// failures only leave orphan blobs behind so they are logged and skipped.
+func removeBlobs(ctx context.Context, log *logger.Logger, blobStorage BlobStorage, attachments []entity.Attachment) {
+	for _, a := range attachments {
+		if err := blobStorage.Delete(ctx, a.BlobKey); err != nil {
+			log.Error("removeBlobs - blobStorage.Delete: %v; key=%v", err, a.BlobKey)
+		}
+	}
+}
+
+// UploadAttachment checks size and content type sniffed from the body
+// against the limits and stores the blob with its metadata.
+func (r *attachmentUsecase) UploadAttachment(ctx context.Context, dto entity.Attachment, body io.Reader) (uint, error) {
+	if dto.Size > r.maxSize {
+		return 0, ErrAttachmentTooLarge
+	}
+
+	head := make([]byte, _sniffLen)
+	n, err := io.ReadFull(body, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		r.log.Error("AttachmentUsecase - UploadAttachment - io.ReadFull: %v; TodoId=%v, Name=%v", err, dto.TodoId, dto.Name)
+		return 0, err
+	}
+	head = head[:n]
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrAttachmentType, err)
+	}
+	if _, ok := r.allowedTypes[mediaType]; !ok {
+		return 0, fmt.Errorf("%w: %s", ErrAttachmentType, mediaType)
+	}
+	dto.ContentType = mediaType
+	body = io.MultiReader(bytes.NewReader(head), body)
+
+	dto.BlobKey = strings.ReplaceAll(uuid.New().String(), "-", "")
+	if err = r.blobStorage.Put(ctx, dto.BlobKey, body, dto.Size, dto.ContentType); err != nil {
+		r.log.Error("AttachmentUsecase - UploadAttachment - r.blobStorage.Put: %v; TodoId=%v, Name=%v", err, dto.TodoId, dto.Name)
+		return 0, err
+	}
+
+	id, err := r.storage.Create(ctx, dto)
+	if err != nil {
+		r.log.Error("AttachmentUsecase - UploadAttachment - r.storage.Create: %v; TodoId=%v, Name=%v", err, dto.TodoId, dto.Name)
+		removeBlobs(ctx, r.log, r.blobStorage, []entity.Attachment{dto})
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *attachmentUsecase) GetAttachment(ctx context.Context, attachmentID uint) (*entity.Attachment, error) {
+	ret, err := r.storage.Get(ctx, attachmentID)
+	if err != nil {
+		r.log.Error("AttachmentUsecase - GetAttachment - r.storage.Get: %v; attachmentID=%v", err, attachmentID)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *attachmentUsecase) GetAttachmentAllByTodo(ctx context.Context, todoID uint) ([]entity.Attachment, error) {
+	ret, err := r.storage.GetAllByTodo(ctx, todoID)
+	if err != nil {
+		r.log.Error("AttachmentUsecase - GetAttachmentAllByTodo - r.storage.GetAllByTodo: %v; todoID=%v", err, todoID)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *attachmentUsecase) OpenAttachment(ctx context.Context, attachment entity.Attachment, offset int64, length int64) (io.ReadCloser, error) {
+	ret, err := r.blobStorage.Get(ctx, attachment.BlobKey, offset, length)
+	if err != nil {
+		r.log.Error("AttachmentUsecase - OpenAttachment - r.blobStorage.Get: %v; attachmentID=%v", err, attachment.Id)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *attachmentUsecase) DeleteAttachment(ctx context.Context, attachment entity.Attachment) error {
+	if err := r.storage.Delete(ctx, attachment.Id); err != nil {
+		r.log.Error("AttachmentUsecase - DeleteAttachment - r.storage.Delete: %v; attachmentID=%v", err, attachment.Id)
+		return err
+	}
+	removeBlobs(ctx, r.log, r.blobStorage, []entity.Attachment{attachment})
+	return nil
+}
@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/logger"
+)
+
+// Auditor records actions to the audit log. Called with a transaction in
+// context the entry is written within it and rolls back together with the
+// action.
+type Auditor interface {
+	Audit(ctx context.Context, entry entity.AuditEntry)
+}
+
+type AuditStorage interface {
+	Append(ctx context.Context, dto entity.AuditEntry) error
+	Count(ctx context.Context, f entity.AuditFilter) (uint, error)
+	GetPage(ctx context.Context, f entity.AuditFilter, offset uint64, limit uint64) ([]entity.AuditEntry, error)
+	Each(ctx context.Context, f entity.AuditFilter, fn func(entity.AuditEntry) error) error
+}
+
+type auditUsecase struct {
+	storage AuditStorage
+	log     *logger.Logger
+}
+
+func NewAuditUsecase(log *logger.Logger, storage AuditStorage) *auditUsecase {
+	return &auditUsecase{
+		storage: storage,
+		log:     log,
+	}
+}
+
+// Audit fills request id, client ip and actor, unless set, from the request
+// metadata in context. Failures are logged and do not fail the action.
+func (r *auditUsecase) Audit(ctx context.Context, entry entity.AuditEntry) {
+	meta := entity.AuditMetaFromContext(ctx)
+	entry.RequestId = meta.RequestId
+	entry.ClientIp = meta.ClientIp
+	if entry.ActorId == 0 {
+		entry.ActorId = meta.ActorId
+	}
+
+	if err := r.storage.Append(ctx, entry); err != nil {
+		r.log.Error("AuditUsecase - Audit - r.storage.Append: %v; Action=%v, TargetType=%v, TargetId=%v",
+			err,
+			entry.Action,
+			entry.TargetType,
+			entry.TargetId,
+		)
+	}
+}
+
+func (r *auditUsecase) GetAuditPage(ctx context.Context, f entity.AuditFilter, page uint, limit uint) ([]entity.AuditEntry, uint, error) {
+	total, err := r.storage.Count(ctx, f)
+	if err != nil {
+		r.log.Error("AuditUsecase - GetAuditPage - r.storage.Count: %v", err)
+		return nil, 0, err
+	}
+
+	ret, err := r.storage.GetPage(ctx, f, uint64((page-1)*limit), uint64(limit))
+	if err != nil {
+		r.log.Error("AuditUsecase - GetAuditPage - r.storage.GetPage: %v; page=%v, limit=%v", err, page, limit)
+		return nil, 0, err
+	}
+	return ret, total, nil
+}
+
+func (r *auditUsecase) ExportAudit(ctx context.Context, f entity.AuditFilter, fn func(entity.AuditEntry) error) error {
+	if err := r.storage.Each(ctx, f, fn); err != nil {
+		r.log.Error("AuditUsecase - ExportAudit - r.storage.Each: %v", err)
+		return err
+	}
+	return nil
+}
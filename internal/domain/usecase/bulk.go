@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"testcode/test3/internal/domain/entity"
+)
+
+var ErrBulkOpUnknown = errors.New("unknown bulk operation")
+
+// BulkError reports the operation that aborted an atomic batch.
+type BulkError struct {
+	Index int
+	Err   error
+}
+
+func (e *BulkError) Error() string {
+	return fmt.Sprintf("operation %d: %v", e.Index, e.Err)
+}
+
+func (e *BulkError) Unwrap() error {
+	return e.Err
+}
+
+// BulkTodo runs the batch with actorID as the owner of created todos. An
+// atomic batch runs in one transaction and stops at the first failure with
+// *BulkError, otherwise every operation runs on its own and reports its error
+// in the result. One TodoBulkEvent covers whatever succeeded.
+func (r *todoUsecase) BulkTodo(ctx context.Context, ops []entity.TodoBulkOp, atomic bool, actorID uint) ([]entity.TodoBulkResult, error) {
+	results := make([]entity.TodoBulkResult, len(ops))
+	event := entity.TodoBulkEvent{ActorId: actorID}
+
+	if atomic {
+		err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			for i, op := range ops {
+				id, err := r.bulkOp(ctx, op, actorID)
+				if err != nil {
+					return &BulkError{Index: i, Err: err}
+				}
+				results[i] = entity.TodoBulkResult{Index: i, Op: op.Op, Id: id}
+			}
+			return nil
+		})
+		if err != nil {
+			r.log.Error("TodoUsecase - BulkTodo - r.bulkOp: %v; actorID=%v", err, actorID)
+			return nil, err
+		}
+		for _, res := range results {
+			event.Add(res.Op, res.Id)
+		}
+	} else {
+		for i, op := range ops {
+			results[i] = entity.TodoBulkResult{Index: i, Op: op.Op}
+			err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+				results[i].Id, err = r.bulkOp(ctx, op, actorID)
+				return err
+			})
+			if err != nil {
+				r.log.Error("TodoUsecase - BulkTodo - r.bulkOp: %v; index=%v, actorID=%v", err, i, actorID)
+				results[i].Error = err.Error()
+				continue
+			}
+			event.Add(op.Op, results[i].Id)
+		}
+	}
+
+	if !event.Empty() {
+		r.notification.Send(event)
+	}
+	return results, nil
+}
+
+func (r *todoUsecase) bulkOp(ctx context.Context, op entity.TodoBulkOp, actorID uint) (uint, error) {
+	switch op.Op {
+	case entity.TodoBulkCreate:
+		todo := op.Todo
+		todo.OwnerId = actorID
+		return r.createTodo(ctx, todo)
+	case entity.TodoBulkUpdate:
+		return op.Todo.Id, r.updateTodo(ctx, op.Todo, actorID)
+	case entity.TodoBulkComplete:
+		return op.Todo.Id, r.updateTodo(ctx, entity.Todo{
+			Id:      op.Todo.Id,
+			Status:  entity.TodoStatusDone,
+			Version: op.Todo.Version,
+		}, actorID)
+	case entity.TodoBulkDelete:
+		return op.Todo.Id, r.deleteTodo(ctx, op.Todo.Id)
+	}
+	return 0, ErrBulkOpUnknown
+}
@@ -0,0 +1,120 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+	"testcode/test3/pkg/logger"
+)
+
+// memTodoStorage keeps the todos in a map.
+type memTodoStorage struct {
+	usecase.TodoStorage
+	todos  map[uint]entity.Todo
+	nextID uint
+}
+
+func (r *memTodoStorage) Create(_ context.Context, dto entity.Todo) (uint, error) {
+	r.nextID++
+	dto.Id, dto.Version = r.nextID, 1
+	r.todos[dto.Id] = dto
+	return dto.Id, nil
+}
+
+func (r *memTodoStorage) Get(_ context.Context, todoID uint) (*entity.Todo, error) {
+	todo, ok := r.todos[todoID]
+	if !ok {
+		return nil, nil
+	}
+	return &todo, nil
+}
+
+func (r *memTodoStorage) Update(_ context.Context, dto entity.Todo) (bool, error) {
+	todo := r.todos[dto.Id]
+	if dto.Status > 0 {
+		todo.Status = dto.Status
+	}
+	todo.Version++
+	r.todos[dto.Id] = todo
+	return true, nil
+}
+
+// rollbackTransactor restores the storage when the outermost transaction
+// fails.
+type rollbackTransactor struct {
+	storage *memTodoStorage
+	depth   *int
+}
+
+func (r rollbackTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	saved := map[uint]entity.Todo{}
+	for id, t := range r.storage.todos {
+		saved[id] = t
+	}
+	nextID := r.storage.nextID
+	*r.depth++
+	err := fn(ctx)
+	*r.depth--
+	if err != nil && *r.depth == 0 {
+		r.storage.todos, r.storage.nextID = saved, nextID
+	}
+	return err
+}
+
+func (rollbackTransactor) AfterCommit(_ context.Context, fn func()) { fn() }
+
+type notifications struct{ sent []interface{} }
+
+func (r *notifications) Send(msg interface{}) { r.sent = append(r.sent, msg) }
+
+func TestBulkTodo(t *testing.T) {
+	ops := []entity.TodoBulkOp{
+		{Op: entity.TodoBulkCreate, Todo: entity.Todo{Name: "new", Desc: "desc"}},
+		{Op: entity.TodoBulkComplete, Todo: entity.Todo{Id: 1, Version: 3}},
+		{Op: entity.TodoBulkComplete, Todo: entity.Todo{Id: 1, Version: 1}},
+	}
+	newUsecase := func() (*memTodoStorage, *notifications, interface {
+		BulkTodo(ctx context.Context, ops []entity.TodoBulkOp, atomic bool, actorID uint) ([]entity.TodoBulkResult, error)
+	}) {
+		storage := &memTodoStorage{nextID: 1, todos: map[uint]entity.Todo{
+			1: {Id: 1, OwnerId: 2, Name: "first", Status: entity.TodoStatusDefault, Version: 1},
+		}}
+		sent := &notifications{}
+		todos := usecase.NewTodoUsecase(logger.New("error"), storage, nil, &revisionStorage{}, rollbackTransactor{storage, new(int)},
+			sent, nopAuditor{}, nopEvents{})
+		return storage, sent, todos
+	}
+
+	// atomic: the conflict of the second operation rolls back the create
+	storage, sent, todos := newUsecase()
+	_, err := todos.BulkTodo(context.Background(), ops, true, 2)
+	var bulkErr *usecase.BulkError
+	if !errors.As(err, &bulkErr) || bulkErr.Index != 1 || !errors.Is(err, usecase.ErrTodoVersionConflict) {
+		t.Fatalf("atomic: %v, want a version conflict of operation 1", err)
+	}
+	if len(storage.todos) != 1 || storage.todos[1].Status != entity.TodoStatusDefault || len(sent.sent) != 0 {
+		t.Errorf("atomic: todos %v, %d notifications, want nothing changed", storage.todos, len(sent.sent))
+	}
+
+	// per item: the conflict is reported, the others go through
+	storage, sent, todos = newUsecase()
+	results, err := todos.BulkTodo(context.Background(), ops, false, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Id != 2 || results[0].Error != "" || results[1].Error != usecase.ErrTodoVersionConflict.Error() ||
+		results[2].Error != "" || results[2].Index != 2 {
+		t.Errorf("per item: results %+v, want only operation 1 failed", results)
+	}
+	if storage.todos[2].OwnerId != 2 || storage.todos[1].Status != entity.TodoStatusDone {
+		t.Errorf("per item: todos %v, want todo 2 created and todo 1 done", storage.todos)
+	}
+	want := entity.TodoBulkEvent{ActorId: 2, Created: []uint{2}, Completed: []uint{1}}
+	if len(sent.sent) != 1 || !reflect.DeepEqual(sent.sent[0], want) {
+		t.Errorf("per item: notifications %v, want one %+v", sent.sent, want)
+	}
+}
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/logger"
+)
+
+type CalendarFeedStorage interface {
+	Set(ctx context.Context, accountID uint, tokenHash string) error
+	Delete(ctx context.Context, accountID uint) error
+	GetByToken(ctx context.Context, tokenHash string) (*entity.CalendarFeed, error)
+}
+
+type DavResourceStorage interface {
+	Set(ctx context.Context, dto entity.DavResource) (bool, error)
+	GetByName(ctx context.Context, name string) (*entity.DavResource, error)
+	GetByTodo(ctx context.Context, todoID uint) (*entity.DavResource, error)
+	GetAll(ctx context.Context) ([]entity.DavResource, error)
+}
+
+// _davResourceMaxLen matches the dav_resource name and uid columns.
+const _davResourceMaxLen = 255
+
+var (
+	ErrDavResourceInvalid = errors.New("invalid resource name or UID")
+	ErrDavResourceTaken   = errors.New("resource name is taken")
+)
+
+type calendarUsecase struct {
+	storage    CalendarFeedStorage
+	davStorage DavResourceStorage
+	log        *logger.Logger
+}
+
+func NewCalendarUsecase(log *logger.Logger, storage CalendarFeedStorage, davStorage DavResourceStorage) *calendarUsecase {
+	return &calendarUsecase{
+		storage:    storage,
+		davStorage: davStorage,
+		log:        log,
+	}
+}
+
+// CreateFeedToken issues a new feed token for the account, the previous one
+// stops working. Only the hash is stored, like session keys.
+func (r *calendarUsecase) CreateFeedToken(ctx context.Context, accountID uint) (string, error) {
+	token := sha256hash(uuid.New().String())
+	if err := r.storage.Set(ctx, accountID, sha256hash(token)); err != nil {
+		r.log.Error("CalendarUsecase - CreateFeedToken - r.storage.Set: %v; accountID=%v", err, accountID)
+		return "", err
+	}
+	return token, nil
+}
+
+func (r *calendarUsecase) RevokeFeedToken(ctx context.Context, accountID uint) error {
+	if err := r.storage.Delete(ctx, accountID); err != nil {
+		r.log.Error("CalendarUsecase - RevokeFeedToken - r.storage.Delete: %v; accountID=%v", err, accountID)
+		return err
+	}
+	return nil
+}
+
+// GetFeed resolves the token, nil when it is unknown or revoked.
+func (r *calendarUsecase) GetFeed(ctx context.Context, token string) (*entity.CalendarFeed, error) {
+	ret, err := r.storage.GetByToken(ctx, sha256hash(token))
+	if err != nil {
+		r.log.Error("CalendarUsecase - GetFeed - r.storage.GetByToken: %v", err)
+		return nil, err
+	}
+	return ret, nil
+}
+
+// SetDavResource serves the todo under the resource name and UID the client
+// created it with, ErrDavResourceTaken when the name serves another todo.
+func (r *calendarUsecase) SetDavResource(ctx context.Context, dto entity.DavResource) error {
+	if dto.Name == "" || len(dto.Name) > _davResourceMaxLen || len(dto.Uid) > _davResourceMaxLen {
+		return ErrDavResourceInvalid
+	}
+	ok, err := r.davStorage.Set(ctx, dto)
+	if err != nil {
+		r.log.Error("CalendarUsecase - SetDavResource - r.davStorage.Set: %v; TodoId=%v, Name=%v", err, dto.TodoId, dto.Name)
+		return err
+	}
+	if !ok {
+		return ErrDavResourceTaken
+	}
+	return nil
+}
+
+// GetDavResource resolves the resource name, nil when no client created a
+// todo under it.
+func (r *calendarUsecase) GetDavResource(ctx context.Context, name string) (*entity.DavResource, error) {
+	ret, err := r.davStorage.GetByName(ctx, name)
+	if err != nil {
+		r.log.Error("CalendarUsecase - GetDavResource - r.davStorage.GetByName: %v; name=%v", err, name)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *calendarUsecase) GetDavResourceByTodo(ctx context.Context, todoID uint) (*entity.DavResource, error) {
+	ret, err := r.davStorage.GetByTodo(ctx, todoID)
+	if err != nil {
+		r.log.Error("CalendarUsecase - GetDavResourceByTodo - r.davStorage.GetByTodo: %v; todoID=%v", err, todoID)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *calendarUsecase) GetDavResources(ctx context.Context) ([]entity.DavResource, error) {
+	ret, err := r.davStorage.GetAll(ctx)
+	if err != nil {
+		r.log.Error("CalendarUsecase - GetDavResources - r.davStorage.GetAll: %v", err)
+		return nil, err
+	}
+	return ret, nil
+}
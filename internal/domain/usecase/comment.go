@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"regexp"
+
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/logger"
+)
+
+// _mentionRe matches @name preceded by start of text or a non word char, so
+// e-mail addresses are not taken for mentions.
+var _mentionRe = regexp.MustCompile(`(?:^|[^\w@])@([\w.-]*\w)`)
+
+type CommentStorage interface {
+	Create(ctx context.Context, dto entity.Comment) (uint, error)
+	Get(ctx context.Context, commentID uint) (*entity.Comment, error)
+	GetAllByTodo(ctx context.Context, todoID uint, offset uint64, limit uint64) ([]entity.Comment, error)
+	CountByTodo(ctx context.Context, todoID uint) (uint, error)
+	Update(ctx context.Context, commentID uint, body string) error
+	Delete(ctx context.Context, commentID uint) error
+}
+
+type commentUsecase struct {
+	storage        CommentStorage
+	accountStorage AccountStorage
+	notification   Notification
+	log            *logger.Logger
+}
+
+func NewCommentUsecase(log *logger.Logger, storage CommentStorage, accountStorage AccountStorage, notification Notification) *commentUsecase {
+	return &commentUsecase{
+		storage:        storage,
+		accountStorage: accountStorage,
+		notification:   notification,
+		log:            log,
+	}
+}
+
+// parseMentions returns unique account names mentioned in the body
+func parseMentions(body string) []string {
+	matches := _mentionRe.FindAllStringSubmatch(body, -1)
+	seen := make(map[string]struct{}, len(matches))
+	names := make([]string, 0, len(matches))
+	for _, m := range matches {
+		if _, ok := seen[m[1]]; ok {
+			continue
+		}
+		seen[m[1]] = struct{}{}
+		names = append(names, m[1])
+	}
+	return names
+}
+
+// notifyMentions sends an event to every account of the current tenant
+// mentioned in body and not listed in skip.
+func (r *commentUsecase) notifyMentions(ctx context.Context, comment entity.Comment, body string, skip []string) {
+	skipped := make(map[string]struct{}, len(skip))
+	for _, name := range skip {
+		skipped[name] = struct{}{}
+	}
+
+	for _, name := range parseMentions(body) {
+		if _, ok := skipped[name]; ok {
+			continue
+		}
+		account, err := r.accountStorage.GetByName(ctx, name)
+		if err != nil {
+			r.log.Error("CommentUsecase - notifyMentions - r.accountStorage.GetByName: %v; name=%v", err, name)
+			continue
+		}
+		if account == nil || account.Id == comment.AuthorId {
+			continue
+		}
+		// names are global, make sure the account is visible in the tenant
+		account, err = r.accountStorage.Get(ctx, account.Id)
+		if err != nil {
+			r.log.Error("CommentUsecase - notifyMentions - r.accountStorage.Get: %v; name=%v", err, name)
+			continue
+		}
+		if account == nil {
+			continue
+		}
+		r.notification.Send(entity.CommentMentionEvent{
+			CommentId: comment.Id,
+			TodoId:    comment.TodoId,
+			AuthorId:  comment.AuthorId,
+			AccountId: account.Id,
+		})
+	}
+}
+
+func (r *commentUsecase) CreateComment(ctx context.Context, dto entity.Comment) (uint, error) {
+	id, err := r.storage.Create(ctx, dto)
+	if err != nil {
+		r.log.Error("CommentUsecase - CreateComment - r.storage.Create: %v; TodoId=%v, AuthorId=%v", err, dto.TodoId, dto.AuthorId)
+		return 0, err
+	}
+	dto.Id = id
+	r.notifyMentions(ctx, dto, dto.Body, nil)
+	return id, nil
+}
+
+func (r *commentUsecase) GetComment(ctx context.Context, commentID uint) (*entity.Comment, error) {
+	ret, err := r.storage.Get(ctx, commentID)
+	if err != nil {
+		r.log.Error("CommentUsecase - GetComment - r.storage.Get: %v; commentID=%v", err, commentID)
+		return nil, err
+	}
+	return ret, nil
+}
+
+// GetCommentPage returns the page of todo comments and the total count of them.
+func (r *commentUsecase) GetCommentPage(ctx context.Context, todoID uint, page uint, limit uint) ([]entity.Comment, uint, error) {
+	total, err := r.storage.CountByTodo(ctx, todoID)
+	if err != nil {
+		r.log.Error("CommentUsecase - GetCommentPage - r.storage.CountByTodo: %v; todoID=%v", err, todoID)
+		return nil, 0, err
+	}
+
+	offset := uint64(page-1) * uint64(limit)
+	ret, err := r.storage.GetAllByTodo(ctx, todoID, offset, uint64(limit))
+	if err != nil {
+		r.log.Error("CommentUsecase - GetCommentPage - r.storage.GetAllByTodo: %v; todoID=%v", err, todoID)
+		return nil, 0, err
+	}
+	return ret, total, nil
+}
+
+// EditComment replaces the body, only accounts newly mentioned are notified.
+func (r *commentUsecase) EditComment(ctx context.Context, comment entity.Comment, body string) error {
+	if err := r.storage.Update(ctx, comment.Id, body); err != nil {
+		r.log.Error("CommentUsecase - EditComment - r.storage.Update: %v; commentID=%v", err, comment.Id)
+		return err
+	}
+	r.notifyMentions(ctx, comment, body, parseMentions(comment.Body))
+	return nil
+}
+
+func (r *commentUsecase) DeleteComment(ctx context.Context, commentID uint) error {
+	if err := r.storage.Delete(ctx, commentID); err != nil {
+		r.log.Error("CommentUsecase - DeleteComment - r.storage.Delete: %v; commentID=%v", err, commentID)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/filter"
+	"testcode/test3/pkg/logger"
+)
+
+const _maxFilterLen = 1024
+
+var ErrFilterTooLong = errors.New("filter query is too long")
+
+// TodoFilterFields are the fields the todo filter language accepts. owner and
+// assignee take an account name or me, assignee, project and label also take
+// none. due compares to a date, due:none matches todos without one.
+var TodoFilterFields = filter.Schema{
+	"id":       {Numeric: true},
+	"status":   {Values: []string{"open", "done"}},
+	"owner":    {},
+	"assignee": {},
+	"project":  {},
+	"label":    {},
+	"due":      {Date: true},
+}
+
+type TodoFilterStorage interface {
+	GetAllByFilter(ctx context.Context, f filter.Node, viewer entity.Account) ([]entity.Todo, error)
+}
+
+type SavedFilterStorage interface {
+	Create(ctx context.Context, dto entity.SavedFilter) (uint, error)
+	Get(ctx context.Context, filterID uint) (*entity.SavedFilter, error)
+	GetAllByAccount(ctx context.Context, accountID uint) ([]entity.SavedFilter, error)
+	Delete(ctx context.Context, filterID uint) error
+}
+
+type filterUsecase struct {
+	todoStorage  TodoFilterStorage
+	savedStorage SavedFilterStorage
+	log          *logger.Logger
+}
+
+func NewFilterUsecase(log *logger.Logger, todoStorage TodoFilterStorage, savedStorage SavedFilterStorage) *filterUsecase {
+	return &filterUsecase{
+		todoStorage:  todoStorage,
+		savedStorage: savedStorage,
+		log:          log,
+	}
+}
+
+// ParseFilter parses the query, syntax errors are *filter.Error.
+func (r *filterUsecase) ParseFilter(query string) (filter.Node, error) {
+	if len(query) > _maxFilterLen {
+		return nil, ErrFilterTooLong
+	}
+	return filter.Parse(query, TodoFilterFields)
+}
+
+// FilterTodo returns todos visible to viewer matching the query.
+func (r *filterUsecase) FilterTodo(ctx context.Context, query string, viewer entity.Account) ([]entity.Todo, error) {
+	f, err := r.ParseFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, err := r.todoStorage.GetAllByFilter(ctx, f, viewer)
+	if err != nil {
+		r.log.Error("FilterUsecase - FilterTodo - r.todoStorage.GetAllByFilter: %v; query=%v", err, query)
+		return nil, err
+	}
+	return ret, nil
+}
+
+// SaveFilter validates and saves the query under the name, normalizing it to
+// its canonical form.
+func (r *filterUsecase) SaveFilter(ctx context.Context, dto entity.SavedFilter) (uint, error) {
+	f, err := r.ParseFilter(dto.Query)
+	if err != nil {
+		return 0, err
+	}
+	dto.Query = f.String()
+
+	id, err := r.savedStorage.Create(ctx, dto)
+	if err != nil {
+		r.log.Error("FilterUsecase - SaveFilter - r.savedStorage.Create: %v; AccountId=%v, Name=%v", err, dto.AccountId, dto.Name)
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *filterUsecase) GetSavedFilter(ctx context.Context, filterID uint) (*entity.SavedFilter, error) {
+	ret, err := r.savedStorage.Get(ctx, filterID)
+	if err != nil {
+		r.log.Error("FilterUsecase - GetSavedFilter - r.savedStorage.Get: %v; filterID=%v", err, filterID)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *filterUsecase) GetSavedFilterAllByAccount(ctx context.Context, accountID uint) ([]entity.SavedFilter, error) {
+	ret, err := r.savedStorage.GetAllByAccount(ctx, accountID)
+	if err != nil {
+		r.log.Error("FilterUsecase - GetSavedFilterAllByAccount - r.savedStorage.GetAllByAccount: %v; accountID=%v", err, accountID)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *filterUsecase) DeleteSavedFilter(ctx context.Context, filterID uint) error {
+	err := r.savedStorage.Delete(ctx, filterID)
+	if err != nil {
+		r.log.Error("FilterUsecase - DeleteSavedFilter - r.savedStorage.Delete: %v; filterID=%v", err, filterID)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/logger"
+)
+
+// _idempotencyKeyMaxLen matches the idempotency_key.idem_key column.
+const _idempotencyKeyMaxLen = 255
+
+var (
+	ErrIdempotencyKeyInvalid    = errors.New("Idempotency-Key must be 1 to 255 characters")
+	ErrIdempotencyKeyReused     = errors.New("Idempotency-Key was used for a different request")
+	ErrIdempotencyKeyInProgress = errors.New("request with this Idempotency-Key is in progress")
+)
+
+type IdempotencyStorage interface {
+	Reserve(ctx context.Context, dto entity.IdempotencyRecord) (*entity.IdempotencyRecord, error)
+	Complete(ctx context.Context, dto entity.IdempotencyRecord) error
+	Release(ctx context.Context, accountID uint, key string) error
+	Purge(ctx context.Context, before time.Time) error
+}
+
+type idempotencyUsecase struct {
+	storage IdempotencyStorage
+	ttl     time.Duration
+	log     *logger.Logger
+}
+
+func NewIdempotencyUsecase(log *logger.Logger, storage IdempotencyStorage, ttl time.Duration) *idempotencyUsecase {
+	return &idempotencyUsecase{
+		storage: storage,
+		ttl:     ttl,
+		log:     log,
+	}
+}
+
+// Begin reserves the key for the request. A completed record of the same
+// request is returned for replay, a nil record means the request has to run
+// and Finish or Release the key afterwards.
+func (r *idempotencyUsecase) Begin(ctx context.Context, accountID uint, key string, request string) (*entity.IdempotencyRecord, error) {
+	if key == "" || len(key) > _idempotencyKeyMaxLen {
+		return nil, ErrIdempotencyKeyInvalid
+	}
+
+	hash := sha256hash(request)
+	ret, err := r.storage.Reserve(ctx, entity.IdempotencyRecord{
+		AccountId:   accountID,
+		Key:         key,
+		RequestHash: hash,
+		ExpiresAt:   time.Now().Add(r.ttl),
+	})
+	if err != nil {
+		r.log.Error("IdempotencyUsecase - Begin - r.storage.Reserve: %v; accountID=%v, key=%v", err, accountID, key)
+		return nil, err
+	}
+	if ret == nil {
+		return nil, nil
+	}
+	if ret.RequestHash != hash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if !ret.Completed {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+	return ret, nil
+}
+
+// Finish stores the response to replay for the reserved key.
+func (r *idempotencyUsecase) Finish(ctx context.Context, accountID uint, key string, statusCode int, contentType string, body []byte) error {
+	err := r.storage.Complete(ctx, entity.IdempotencyRecord{
+		AccountId:   accountID,
+		Key:         key,
+		StatusCode:  statusCode,
+		ContentType: contentType,
+		Body:        body,
+	})
+	if err != nil {
+		r.log.Error("IdempotencyUsecase - Finish - r.storage.Complete: %v; accountID=%v, key=%v", err, accountID, key)
+		return err
+	}
+	return nil
+}
+
+// Release frees the reserved key when the request failed in a way a retry
+// may fix.
+func (r *idempotencyUsecase) Release(ctx context.Context, accountID uint, key string) error {
+	if err := r.storage.Release(ctx, accountID, key); err != nil {
+		r.log.Error("IdempotencyUsecase - Release - r.storage.Release: %v; accountID=%v, key=%v", err, accountID, key)
+		return err
+	}
+	return nil
+}
+
+// RunPurge drops expired records every interval until ctx is done.
+func (r *idempotencyUsecase) RunPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.storage.Purge(ctx, time.Now()); err != nil {
+				r.log.Error("IdempotencyUsecase - RunPurge - r.storage.Purge: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/logger"
+)
+
+type MemberStorage interface {
+	Create(ctx context.Context, dto entity.Member) (uint, error)
+	Get(ctx context.Context, memberID uint) (*entity.Member, error)
+	GetAllByResource(ctx context.Context, resourceType entity.MemberResource, resourceID uint) ([]entity.Member, error)
+	GetAllByAccount(ctx context.Context, accountID uint) ([]entity.Member, error)
+	GetRole(ctx context.Context, accountID uint, todoID uint, projectID uint) (entity.MemberRole, error)
+	Accept(ctx context.Context, memberID uint) error
+	Delete(ctx context.Context, memberID uint) error
+}
+
+type memberUsecase struct {
+	storage        MemberStorage
+	projectStorage ProjectStorage
+	notification   Notification
+	log            *logger.Logger
+}
+
+func NewMemberUsecase(log *logger.Logger, storage MemberStorage, projectStorage ProjectStorage, notification Notification) *memberUsecase {
+	return &memberUsecase{
+		storage:        storage,
+		projectStorage: projectStorage,
+		notification:   notification,
+		log:            log,
+	}
+}
+
+func (r *memberUsecase) InviteMember(ctx context.Context, dto entity.Member) (uint, error) {
+	dto.Accepted = false
+	id, err := r.storage.Create(ctx, dto)
+	if err != nil {
+		r.log.Error("MemberUsecase - InviteMember - r.storage.Create: %v; ResourceType=%v, ResourceId=%v, AccountId=%v, Role=%v",
+			err,
+			dto.ResourceType,
+			dto.ResourceId,
+			dto.AccountId,
+			dto.Role,
+		)
+		return 0, err
+	}
+	r.notification.Send(entity.MemberInvitedEvent{
+		MemberId:     id,
+		ResourceType: dto.ResourceType,
+		ResourceId:   dto.ResourceId,
+		AccountId:    dto.AccountId,
+		Role:         dto.Role,
+		InvitedBy:    dto.InvitedBy,
+	})
+	return id, nil
+}
+
+func (r *memberUsecase) AcceptInvite(ctx context.Context, memberID uint) error {
+	if err := r.storage.Accept(ctx, memberID); err != nil {
+		r.log.Error("MemberUsecase - AcceptInvite - r.storage.Accept: %v; memberID=%v", err, memberID)
+		return err
+	}
+	return nil
+}
+
+func (r *memberUsecase) GetMember(ctx context.Context, memberID uint) (*entity.Member, error) {
+	ret, err := r.storage.Get(ctx, memberID)
+	if err != nil {
+		r.log.Error("MemberUsecase - GetMember - r.storage.Get: %v; memberID=%v", err, memberID)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *memberUsecase) GetMemberAllByResource(ctx context.Context, resourceType entity.MemberResource, resourceID uint) ([]entity.Member, error) {
+	ret, err := r.storage.GetAllByResource(ctx, resourceType, resourceID)
+	if err != nil {
+		r.log.Error("MemberUsecase - GetMemberAllByResource - r.storage.GetAllByResource: %v; resourceType=%v, resourceID=%v",
+			err, resourceType, resourceID)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *memberUsecase) GetMemberAllByAccount(ctx context.Context, accountID uint) ([]entity.Member, error) {
+	ret, err := r.storage.GetAllByAccount(ctx, accountID)
+	if err != nil {
+		r.log.Error("MemberUsecase - GetMemberAllByAccount - r.storage.GetAllByAccount: %v; accountID=%v", err, accountID)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *memberUsecase) RemoveMember(ctx context.Context, memberID uint) error {
+	if err := r.storage.Delete(ctx, memberID); err != nil {
+		r.log.Error("MemberUsecase - RemoveMember - r.storage.Delete: %v; memberID=%v", err, memberID)
+		return err
+	}
+	return nil
+}
+
+// TodoRole resolves the effective role of the account on the todo. Admins,
+// todo owners and owners of the todo's project always get MemberRoleOwner,
+// assignees get at least MemberRoleViewer.
+func (r *memberUsecase) TodoRole(ctx context.Context, account entity.Account, todo entity.Todo) (entity.MemberRole, error) {
+	if account.IsAdmin() || account.Id == todo.OwnerId {
+		return entity.MemberRoleOwner, nil
+	}
+
+	if todo.ProjectId != 0 {
+		project, err := r.projectStorage.Get(ctx, todo.ProjectId)
+		if err != nil {
+			r.log.Error("MemberUsecase - TodoRole - r.projectStorage.Get: %v; projectID=%v", err, todo.ProjectId)
+			return entity.MemberRoleNone, err
+		}
+		if project != nil && project.OwnerId == account.Id {
+			return entity.MemberRoleOwner, nil
+		}
+	}
+
+	role, err := r.storage.GetRole(ctx, account.Id, todo.Id, todo.ProjectId)
+	if err != nil {
+		r.log.Error("MemberUsecase - TodoRole - r.storage.GetRole: %v; accountID=%v, todoID=%v", err, account.Id, todo.Id)
+		return entity.MemberRoleNone, err
+	}
+	if role == entity.MemberRoleNone && todo.HasAssignee(account.Id) {
+		role = entity.MemberRoleViewer
+	}
+	return role, nil
+}
+
+// ProjectRole resolves the effective role of the account on the project.
+func (r *memberUsecase) ProjectRole(ctx context.Context, account entity.Account, project entity.Project) (entity.MemberRole, error) {
+	if account.IsAdmin() || account.Id == project.OwnerId {
+		return entity.MemberRoleOwner, nil
+	}
+
+	role, err := r.storage.GetRole(ctx, account.Id, 0, project.Id)
+	if err != nil {
+		r.log.Error("MemberUsecase - ProjectRole - r.storage.GetRole: %v; accountID=%v, projectID=%v", err, account.Id, project.Id)
+		return entity.MemberRoleNone, err
+	}
+	return role, nil
+}
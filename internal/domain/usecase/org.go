@@ -0,0 +1,212 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/logger"
+)
+
+var (
+	ErrOrgRequired  = errors.New("account belongs to several orgs, org_id required")
+	ErrOrgNoAccess  = errors.New("account does not belong to org")
+	ErrOrgSelf      = errors.New("cannot remove yourself from the org")
+	ErrOrgLastAdmin = errors.New("cannot remove the last admin of the org")
+)
+
+type OrgStorage interface {
+	Create(ctx context.Context, dto entity.Org) (uint, error)
+	Get(ctx context.Context, orgID uint) (*entity.Org, error)
+	GetAll(ctx context.Context) ([]entity.Org, error)
+	GetAccountOrgs(ctx context.Context, accountID uint) ([]entity.OrgAccount, error)
+	GetOrgAccounts(ctx context.Context, orgID uint) ([]entity.OrgAccount, error)
+	AddAccount(ctx context.Context, dto entity.OrgAccount) error
+	RemoveAccount(ctx context.Context, orgID uint, accountID uint) error
+}
+
+type orgUsecase struct {
+	storage OrgStorage
+	auditor Auditor
+	log     *logger.Logger
+}
+
+func NewOrgUsecase(log *logger.Logger, storage OrgStorage, auditor Auditor) *orgUsecase {
+	return &orgUsecase{
+		storage: storage,
+		auditor: auditor,
+		log:     log,
+	}
+}
+
+func (r *orgUsecase) CreateOrg(ctx context.Context, dto entity.Org) (uint, error) {
+	id, err := r.storage.Create(ctx, dto)
+	if err != nil {
+		r.log.Error("OrgUsecase - CreateOrg - r.storage.Create: %v; Name=%v", err, dto.Name)
+		return 0, err
+	}
+	r.auditor.Audit(ctx, entity.AuditEntry{
+		Action:     entity.AuditOrgCreate,
+		TargetType: entity.AuditTargetOrg,
+		TargetId:   id,
+		Summary:    map[string]interface{}{"name": dto.Name},
+	})
+	return id, nil
+}
+
+func (r *orgUsecase) GetOrg(ctx context.Context, orgID uint) (*entity.Org, error) {
+	ret, err := r.storage.Get(ctx, orgID)
+	if err != nil {
+		r.log.Error("OrgUsecase - GetOrg - r.storage.Get: %v; orgID=%v", err, orgID)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *orgUsecase) GetOrgAll(ctx context.Context) ([]entity.Org, error) {
+	ret, err := r.storage.GetAll(ctx)
+	if err != nil {
+		r.log.Error("OrgUsecase - GetOrgAll - r.storage.GetAll: %v", err)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *orgUsecase) GetAccountOrgs(ctx context.Context, accountID uint) ([]entity.OrgAccount, error) {
+	ret, err := r.storage.GetAccountOrgs(ctx, accountID)
+	if err != nil {
+		r.log.Error("OrgUsecase - GetAccountOrgs - r.storage.GetAccountOrgs: %v; accountID=%v", err, accountID)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *orgUsecase) AddOrgAccount(ctx context.Context, dto entity.OrgAccount) error {
+	if err := r.storage.AddAccount(ctx, dto); err != nil {
+		r.log.Error("OrgUsecase - AddOrgAccount - r.storage.AddAccount: %v; OrgId=%v, AccountId=%v, Role=%v",
+			err,
+			dto.OrgId,
+			dto.AccountId,
+			dto.Role,
+		)
+		return err
+	}
+	r.auditor.Audit(ctx, entity.AuditEntry{
+		Action:     entity.AuditOrgAccountAdd,
+		TargetType: entity.AuditTargetOrg,
+		TargetId:   dto.OrgId,
+		Summary:    map[string]interface{}{"account_id": dto.AccountId, "role": dto.Role},
+	})
+	return nil
+}
+
+// RemoveOrgAccount moves the membership to the trash of the org. Guards keep
+// actor from removing itself or the last admin of the org.
+func (r *orgUsecase) RemoveOrgAccount(ctx context.Context, orgID uint, accountID uint, actorID uint) error {
+	if err := r.checkRemoval(ctx, orgID, accountID, actorID); err != nil {
+		if !errors.Is(err, ErrOrgSelf) && !errors.Is(err, ErrOrgLastAdmin) && !errors.Is(err, ErrOrgNoAccess) {
+			r.log.Error("OrgUsecase - RemoveOrgAccount - r.checkRemoval: %v; orgID=%v, accountID=%v", err, orgID, accountID)
+		}
+		return err
+	}
+	if err := r.storage.RemoveAccount(ctx, orgID, accountID); err != nil {
+		r.log.Error("OrgUsecase - RemoveOrgAccount - r.storage.RemoveAccount: %v; orgID=%v, accountID=%v", err, orgID, accountID)
+		return err
+	}
+	r.auditor.Audit(ctx, entity.AuditEntry{
+		Action:     entity.AuditOrgAccountDel,
+		TargetType: entity.AuditTargetOrg,
+		TargetId:   orgID,
+		Summary:    map[string]interface{}{"account_id": accountID},
+	})
+	return nil
+}
+
+// checkRemoval applies the self and last admin guards, like checkDeletion of
+// accounts.
+func (r *orgUsecase) checkRemoval(ctx context.Context, orgID uint, accountID uint, actorID uint) error {
+	if accountID == actorID {
+		return ErrOrgSelf
+	}
+	members, err := r.storage.GetOrgAccounts(ctx, orgID)
+	if err != nil {
+		return err
+	}
+
+	var (
+		target *entity.OrgAccount
+		admins int
+	)
+	for i := range members {
+		m := &members[i]
+		if m.Role == entity.AccountTypeAdmin {
+			admins++
+		}
+		if m.AccountId == accountID {
+			target = m
+		}
+	}
+	if target == nil {
+		return ErrOrgNoAccess
+	}
+	if target.Role == entity.AccountTypeAdmin && admins <= 1 {
+		return ErrOrgLastAdmin
+	}
+	return nil
+}
+
+// EnterOrg binds the account to the tenant it works in for the session. The
+// org role replaces the account type, superadmins may enter any org and keep
+// their type. Zero orgID picks the only org of the account, a superadmin
+// without orgID works in tenant zero, which holds no data. The outcome is the
+// login record of the audit log.
+func (r *orgUsecase) EnterOrg(ctx context.Context, account entity.Account, orgID uint) (entity.Account, error) {
+	ret, err := r.enterOrg(ctx, account, orgID)
+	if errors.Is(err, ErrOrgRequired) || errors.Is(err, ErrOrgNoAccess) {
+		r.auditor.Audit(entity.WithTenant(ctx, orgID), entity.AuditEntry{
+			ActorId:    account.Id,
+			Action:     entity.AuditLoginFailed,
+			TargetType: entity.AuditTargetAccount,
+			TargetId:   account.Id,
+			Summary:    map[string]interface{}{"name": account.Name, "reason": err.Error()},
+		})
+	}
+	if err != nil {
+		return ret, err
+	}
+	r.auditor.Audit(entity.WithTenant(ctx, ret.TenantId), entity.AuditEntry{
+		ActorId:    ret.Id,
+		Action:     entity.AuditLogin,
+		TargetType: entity.AuditTargetAccount,
+		TargetId:   ret.Id,
+	})
+	return ret, nil
+}
+
+func (r *orgUsecase) enterOrg(ctx context.Context, account entity.Account, orgID uint) (entity.Account, error) {
+	if account.AccountType == entity.AccountTypeSuperAdmin {
+		account.TenantId = orgID
+		return account, nil
+	}
+
+	orgs, err := r.storage.GetAccountOrgs(ctx, account.Id)
+	if err != nil {
+		r.log.Error("OrgUsecase - EnterOrg - r.storage.GetAccountOrgs: %v; accountID=%v", err, account.Id)
+		return account, err
+	}
+
+	if orgID == 0 {
+		if len(orgs) != 1 {
+			return account, ErrOrgRequired
+		}
+		orgID = orgs[0].OrgId
+	}
+	for _, o := range orgs {
+		if o.OrgId == orgID {
+			account.TenantId = o.OrgId
+			account.AccountType = o.Role
+			return account, nil
+		}
+	}
+	return account, ErrOrgNoAccess
+}
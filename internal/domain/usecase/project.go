@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/logger"
+)
+
+type ProjectStorage interface {
+	Create(ctx context.Context, dto entity.Project) (uint, error)
+	Get(ctx context.Context, projectID uint) (*entity.Project, error)
+	GetAll(ctx context.Context) ([]entity.Project, error)
+	GetAllVisible(ctx context.Context, accountID uint) ([]entity.Project, error)
+	Update(ctx context.Context, dto entity.Project) error
+	SetArchived(ctx context.Context, projectID uint, archived bool) error
+	Delete(ctx context.Context, projectID uint) error
+}
+
+type projectUsecase struct {
+	storage ProjectStorage
+	log     *logger.Logger
+}
+
+func NewProjectUsecase(log *logger.Logger, storage ProjectStorage) *projectUsecase {
+	return &projectUsecase{
+		storage: storage,
+		log:     log,
+	}
+}
+
+func (r *projectUsecase) CreateProject(ctx context.Context, dto entity.Project) (uint, error) {
+	id, err := r.storage.Create(ctx, dto)
+	if err != nil {
+		r.log.Error("ProjectUsecase - CreateProject - r.storage.Create: %v; OwnerId=%v, Name=%v, Desc=%v, Color=%v",
+			err,
+			dto.OwnerId,
+			dto.Name,
+			dto.Desc,
+			dto.Color,
+		)
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *projectUsecase) GetProject(ctx context.Context, projectID uint) (*entity.Project, error) {
+	ret, err := r.storage.Get(ctx, projectID)
+	if err != nil {
+		r.log.Error("ProjectUsecase - GetProject - r.storage.Get: %v; projectID=%v", err, projectID)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *projectUsecase) GetProjectAll(ctx context.Context) ([]entity.Project, error) {
+	ret, err := r.storage.GetAll(ctx)
+	if err != nil {
+		r.log.Error("ProjectUsecase - GetProjectAll - r.storage.GetAll: %v", err)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *projectUsecase) GetProjectAllVisible(ctx context.Context, accountID uint) ([]entity.Project, error) {
+	ret, err := r.storage.GetAllVisible(ctx, accountID)
+	if err != nil {
+		r.log.Error("ProjectUsecase - GetProjectAllVisible - r.storage.GetAllVisible: %v; accountID=%v", err, accountID)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *projectUsecase) UpdateProject(ctx context.Context, dto entity.Project) error {
+	if err := r.storage.Update(ctx, dto); err != nil {
+		r.log.Error("ProjectUsecase - UpdateProject - r.storage.Update: %v; ID=%v, Name=%v, Desc=%v, Color=%v",
+			err,
+			dto.Id,
+			dto.Name,
+			dto.Desc,
+			dto.Color,
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *projectUsecase) ArchiveProject(ctx context.Context, projectID uint, archived bool) error {
+	if err := r.storage.SetArchived(ctx, projectID, archived); err != nil {
+		r.log.Error("ProjectUsecase - ArchiveProject - r.storage.SetArchived: %v; projectID=%v, archived=%v", err, projectID, archived)
+		return err
+	}
+	return nil
+}
+
+// DeleteProject moves the todos of the project to the trash, their
+// attachments stay until they are purged.
+func (r *projectUsecase) DeleteProject(ctx context.Context, projectID uint) error {
+	if err := r.storage.Delete(ctx, projectID); err != nil {
+		r.log.Error("ProjectUsecase - DeleteProject - r.storage.Delete: %v; projectID=%v", err, projectID)
+		return err
+	}
+	return nil
+}
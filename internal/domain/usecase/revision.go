@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"testcode/test3/internal/domain/entity"
+)
+
+var (
+	ErrTodoNotFound        = errors.New("todo not found")
+	ErrTodoVersionConflict = errors.New("todo was changed by someone else")
+	ErrRevisionNotFound    = errors.New("revision not found")
+)
+
+type RevisionStorage interface {
+	Create(ctx context.Context, dto entity.TodoRevision) (uint, error)
+	Get(ctx context.Context, revisionID uint) (*entity.TodoRevision, error)
+	GetAllByTodo(ctx context.Context, todoID uint) ([]entity.TodoRevision, error)
+}
+
+// diffTodo lists the tracked fields that differ between old and new.
+func diffTodo(old, new entity.Todo) []entity.TodoFieldChange {
+	changes := make([]entity.TodoFieldChange, 0, 8)
+	if old.Name != new.Name {
+		changes = append(changes, entity.TodoFieldChange{Field: "name", Old: old.Name, New: new.Name})
+	}
+	if old.Desc != new.Desc {
+		changes = append(changes, entity.TodoFieldChange{Field: "desc", Old: old.Desc, New: new.Desc})
+	}
+	if old.Status != new.Status {
+		changes = append(changes, entity.TodoFieldChange{Field: "status", Old: old.Status, New: new.Status})
+	}
+	if !sameDue(old.Due, new.Due) {
+		changes = append(changes, entity.TodoFieldChange{Field: "due", Old: old.Due, New: new.Due})
+	}
+	if strings.Join(old.Labels, ",") != strings.Join(new.Labels, ",") {
+		changes = append(changes, entity.TodoFieldChange{Field: "labels", Old: old.Labels, New: new.Labels})
+	}
+	if old.Priority != new.Priority {
+		changes = append(changes, entity.TodoFieldChange{Field: "priority", Old: old.Priority, New: new.Priority})
+	}
+	if old.Recurrence != new.Recurrence {
+		changes = append(changes, entity.TodoFieldChange{Field: "recurrence", Old: old.Recurrence, New: new.Recurrence})
+	}
+	if old.ProjectId != new.ProjectId {
+		changes = append(changes, entity.TodoFieldChange{Field: "project_id", Old: old.ProjectId, New: new.ProjectId})
+	}
+	return changes
+}
+
+func sameDue(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// recordRevision stores new as a revision when it differs from old, restores
+// are recorded even when nothing changed, and publishes the change.
+func (r *todoUsecase) recordRevision(ctx context.Context, old, new entity.Todo, actorID uint, restoredFrom uint) error {
+	changes := diffTodo(old, new)
+	if len(changes) == 0 && restoredFrom == 0 {
+		return nil
+	}
+	_, err := r.revisionStorage.Create(ctx, entity.TodoRevision{
+		TodoId:       new.Id,
+		ActorId:      actorID,
+		Name:         new.Name,
+		Desc:         new.Desc,
+		Status:       new.Status,
+		Due:          new.Due,
+		Labels:       new.Labels,
+		Priority:     new.Priority,
+		Recurrence:   new.Recurrence,
+		ProjectId:    new.ProjectId,
+		RestoredFrom: restoredFrom,
+		Changes:      changes,
+	})
+	if err != nil {
+		return err
+	}
+
+	eventType := entity.TodoEventUpdated
+	if old.Id == 0 {
+		eventType = entity.TodoEventCreated
+	}
+	return r.publishTodo(ctx, eventType, new.Id, actorID)
+}
+
+func (r *todoUsecase) GetTodoRevision(ctx context.Context, revisionID uint) (*entity.TodoRevision, error) {
+	ret, err := r.revisionStorage.Get(ctx, revisionID)
+	if err != nil {
+		r.log.Error("TodoUsecase - GetTodoRevision - r.revisionStorage.Get: %v; revisionID=%v", err, revisionID)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *todoUsecase) GetTodoHistory(ctx context.Context, todoID uint) ([]entity.TodoRevision, error) {
+	ret, err := r.revisionStorage.GetAllByTodo(ctx, todoID)
+	if err != nil {
+		r.log.Error("TodoUsecase - GetTodoHistory - r.revisionStorage.GetAllByTodo: %v; todoID=%v", err, todoID)
+		return nil, err
+	}
+	return ret, nil
+}
+
+// restorePatch sets every tracked field of the todo, empty desc, no due date,
+// no labels and no recurrence included.
+func restorePatch(todo entity.Todo) entity.TodoPatch {
+	due := time.Time{}
+	if todo.Due != nil {
+		due = *todo.Due
+	}
+	labels := append([]string{}, todo.Labels...)
+	return entity.TodoPatch{
+		Name:       &todo.Name,
+		Desc:       &todo.Desc,
+		Status:     &todo.Status,
+		Due:        &due,
+		Labels:     &labels,
+		Priority:   &todo.Priority,
+		Recurrence: &todo.Recurrence,
+	}
+}
+
+// RestoreTodo brings the todo back to the state at the revision and records
+// that as a new revision.
+func (r *todoUsecase) RestoreTodo(ctx context.Context, todoID uint, revisionID uint, actorID uint) error {
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		rev, err := r.revisionStorage.Get(ctx, revisionID)
+		if err != nil {
+			return err
+		}
+		if rev == nil || rev.TodoId != todoID {
+			return ErrRevisionNotFound
+		}
+		old, err := r.storage.Get(ctx, todoID)
+		if err != nil {
+			return err
+		}
+		if old == nil {
+			return ErrTodoNotFound
+		}
+
+		restored := rev.Snapshot()
+		restored.OwnerId = old.OwnerId
+		ok, err := r.storage.Patch(ctx, todoID, restorePatch(restored))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrTodoNotFound
+		}
+		if restored.ProjectId != old.ProjectId {
+			if err = r.storage.SetProject(ctx, todoID, restored.ProjectId); err != nil {
+				return err
+			}
+		}
+		r.auditor.Audit(ctx, entity.AuditEntry{
+			ActorId:    actorID,
+			Action:     entity.AuditTodoRevert,
+			TargetType: entity.AuditTargetTodo,
+			TargetId:   todoID,
+			Summary:    map[string]interface{}{"revision_id": revisionID},
+		})
+		return r.recordRevision(ctx, *old, restored, actorID, revisionID)
+	})
+	if err != nil {
+		r.log.Error("TodoUsecase - RestoreTodo: %v; todoID=%v, revisionID=%v", err, todoID, revisionID)
+		return err
+	}
+	return nil
+}
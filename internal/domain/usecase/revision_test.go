@@ -0,0 +1,138 @@
+package usecase_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+	"testcode/test3/pkg/logger"
+)
+
+// todoStorage keeps one todo, Patch writes the fields the way the mysql
+// storage does.
+type todoStorage struct {
+	usecase.TodoStorage
+	todo entity.Todo
+}
+
+func (r *todoStorage) Get(_ context.Context, todoID uint) (*entity.Todo, error) {
+	if todoID != r.todo.Id {
+		return nil, nil
+	}
+	todo := r.todo
+	return &todo, nil
+}
+
+func (r *todoStorage) Patch(_ context.Context, todoID uint, patch entity.TodoPatch) (bool, error) {
+	if todoID != r.todo.Id {
+		return false, nil
+	}
+	if patch.Name != nil {
+		r.todo.Name = *patch.Name
+	}
+	if patch.Desc != nil {
+		r.todo.Desc = *patch.Desc
+	}
+	if patch.Status != nil {
+		r.todo.Status = *patch.Status
+	}
+	if patch.Due != nil {
+		r.todo.Due = nil
+		if !patch.Due.IsZero() {
+			due := *patch.Due
+			r.todo.Due = &due
+		}
+	}
+	if patch.Labels != nil {
+		r.todo.Labels = nil
+		if len(*patch.Labels) > 0 {
+			r.todo.Labels = append([]string(nil), *patch.Labels...)
+		}
+	}
+	if patch.Priority != nil {
+		r.todo.Priority = *patch.Priority
+	}
+	if patch.Recurrence != nil {
+		r.todo.Recurrence = *patch.Recurrence
+	}
+	r.todo.Version++
+	return true, nil
+}
+
+type revisionStorage struct {
+	usecase.RevisionStorage
+	revisions []entity.TodoRevision
+}
+
+func (r *revisionStorage) Create(_ context.Context, dto entity.TodoRevision) (uint, error) {
+	dto.Id = uint(len(r.revisions) + 1)
+	r.revisions = append(r.revisions, dto)
+	return dto.Id, nil
+}
+
+func (r *revisionStorage) Get(_ context.Context, revisionID uint) (*entity.TodoRevision, error) {
+	if revisionID == 0 || int(revisionID) > len(r.revisions) {
+		return nil, nil
+	}
+	rev := r.revisions[revisionID-1]
+	return &rev, nil
+}
+
+type transactor struct{}
+
+func (transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (transactor) AfterCommit(_ context.Context, fn func()) { fn() }
+
+type nopAuditor struct{}
+
+func (nopAuditor) Audit(context.Context, entity.AuditEntry) {}
+
+type nopEvents struct{ usecase.TodoEvents }
+
+func (nopEvents) Publish(entity.TodoEvent) {}
+
+func TestRestoreTodo(t *testing.T) {
+	due := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+	storage := &todoStorage{todo: entity.Todo{
+		Id:         1,
+		Name:       "changed",
+		Desc:       "some desc",
+		Status:     entity.TodoStatusDone,
+		Due:        &due,
+		Labels:     []string{"urgent"},
+		Priority:   1,
+		Recurrence: "FREQ=DAILY",
+	}}
+	revisions := &revisionStorage{revisions: []entity.TodoRevision{
+		// the todo as created, without desc, due date, labels or recurrence
+		{Id: 1, TodoId: 1, Name: "first", Status: entity.TodoStatusDefault},
+	}}
+	todos := usecase.NewTodoUsecase(logger.New("error"), storage, nil, revisions, transactor{}, nil, nopAuditor{}, nopEvents{})
+
+	if err := todos.RestoreTodo(context.Background(), 1, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	want := entity.Todo{Id: 1, Name: "first", Status: entity.TodoStatusDefault, Version: 1}
+	if !reflect.DeepEqual(storage.todo, want) {
+		t.Errorf("restored %+v, want %+v", storage.todo, want)
+	}
+	if len(revisions.revisions) != 2 || revisions.revisions[1].RestoredFrom != 1 {
+		t.Fatalf("revisions %+v, want a revision restored from 1", revisions.revisions)
+	}
+	fields := map[string]bool{}
+	for _, c := range revisions.revisions[1].Changes {
+		fields[c.Field] = true
+	}
+	for _, f := range []string{"name", "desc", "status", "due", "labels", "priority", "recurrence"} {
+		if !fields[f] {
+			t.Errorf("revision changes %v miss %s", revisions.revisions[1].Changes, f)
+		}
+	}
+}
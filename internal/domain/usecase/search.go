@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/logger"
+	"testcode/test3/pkg/search"
+)
+
+const (
+	_snippetWidth  = 160
+	_highlightPre  = "<mark>"
+	_highlightPost = "</mark>"
+)
+
+var ErrEmptySearchQuery = errors.New("search query has no words")
+
+type TodoSearcher interface {
+	Search(ctx context.Context, q search.Query, viewer entity.Account, limit uint64) ([]entity.TodoSearchHit, error)
+}
+
+type searchUsecase struct {
+	searcher TodoSearcher
+	log      *logger.Logger
+}
+
+func NewSearchUsecase(log *logger.Logger, searcher TodoSearcher) *searchUsecase {
+	return &searchUsecase{
+		searcher: searcher,
+		log:      log,
+	}
+}
+
+// SearchTodo returns todos visible to viewer matching the query ordered by
+// relevance, with matches highlighted in the name and the desc snippet.
+func (r *searchUsecase) SearchTodo(ctx context.Context, query string, viewer entity.Account, limit uint) ([]entity.TodoSearchHit, error) {
+	q := search.Parse(query)
+	if q.Empty() {
+		return nil, ErrEmptySearchQuery
+	}
+
+	hits, err := r.searcher.Search(ctx, q, viewer, uint64(limit))
+	if err != nil {
+		r.log.Error("SearchUsecase - SearchTodo - r.searcher.Search: %v; query=%v", err, query)
+		return nil, err
+	}
+
+	for i := range hits {
+		hits[i].Name = search.Highlight(hits[i].Todo.Name, q, _highlightPre, _highlightPost)
+		hits[i].Snippet = search.Snippet(hits[i].Todo.Desc, q, _snippetWidth, _highlightPre, _highlightPost)
+	}
+	return hits, nil
+}
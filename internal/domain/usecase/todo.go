@@ -2,50 +2,137 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/ical"
 	"testcode/test3/pkg/logger"
 )
 
+const (
+	// _todoNameMaxLen matches the todo.name column.
+	_todoNameMaxLen = 40
+	// _todoLabelMaxLen and _todoLabelsMax keep the joined labels within the
+	// todo.labels column.
+	_todoLabelMaxLen = 32
+	_todoLabelsMax   = 16
+	// _todoPriorityMax is the lowest RFC 5545 priority, _todoRecurrenceMaxLen
+	// matches the todo.rrule column.
+	_todoPriorityMax      = 9
+	_todoRecurrenceMaxLen = 255
+)
+
+var ErrTodoInvalid = errors.New("invalid todo")
+
 type TodoStorage interface {
-	Create(ctx context.Context, dto entity.Todo) error
+	Create(ctx context.Context, dto entity.Todo) (uint, error)
 	Get(ctx context.Context, todoID uint) (*entity.Todo, error)
 	GetAll(ctx context.Context) ([]entity.Todo, error)
-	Update(ctx context.Context, dto entity.Todo) error
+	GetAllVisible(ctx context.Context, accountID uint) ([]entity.Todo, error)
+	GetAllByAssignee(ctx context.Context, accountID uint) ([]entity.Todo, error)
+	GetAllByProject(ctx context.Context, projectID uint) ([]entity.Todo, error)
+	Update(ctx context.Context, dto entity.Todo) (bool, error)
+	Patch(ctx context.Context, todoID uint, patch entity.TodoPatch) (bool, error)
+	SetProject(ctx context.Context, todoID uint, projectID uint) error
 	Delete(ctx context.Context, todoID uint) error
 }
 
+type AssigneeStorage interface {
+	Add(ctx context.Context, todoID uint, accountID uint) error
+	Remove(ctx context.Context, todoID uint, accountID uint) error
+	GetByTodo(ctx context.Context, todoID uint) ([]uint, error)
+}
+
 type Notification interface {
 	Send(msg interface{})
 }
 
 type todoUsecase struct {
-	storage      TodoStorage
-	notification Notification
-	log          *logger.Logger
+	storage         TodoStorage
+	assigneeStorage AssigneeStorage
+	revisionStorage RevisionStorage
+	transactor      Transactor
+	notification    Notification
+	auditor         Auditor
+	events          TodoEvents
+	log             *logger.Logger
 }
 
-func NewTodoUsecase(log *logger.Logger, storage TodoStorage, notification Notification) *todoUsecase {
+func NewTodoUsecase(log *logger.Logger, storage TodoStorage, assigneeStorage AssigneeStorage, revisionStorage RevisionStorage,
+	transactor Transactor, notification Notification, auditor Auditor, events TodoEvents) *todoUsecase {
 	return &todoUsecase{
-		storage:      storage,
-		notification: notification,
-		log:          log,
+		storage:         storage,
+		assigneeStorage: assigneeStorage,
+		revisionStorage: revisionStorage,
+		transactor:      transactor,
+		notification:    notification,
+		auditor:         auditor,
+		events:          events,
+		log:             log,
 	}
 }
 
-func (r *todoUsecase) CreateTodo(ctx context.Context, dto entity.Todo) error {
-	if err := r.storage.Create(ctx, dto); err != nil {
-		r.log.Error("TodoUsecase - CreateTodo - r.storage.Create: %v; OwnerId=%v, Name=%v, Desc=%v, Status=%v",
-			err,
-			dto.OwnerId,
-			dto.Name,
-			dto.Desc,
-			dto.Status,
-		)
-		return err
+func (r *todoUsecase) CreateTodo(ctx context.Context, dto entity.Todo) (uint, error) {
+	if err := validateTodo(dto); err != nil {
+		return 0, err
 	}
-	r.notification.Send(dto)
-	return nil
+	var id uint
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		id, err = r.createTodo(ctx, dto)
+		if err != nil {
+			r.log.Error("TodoUsecase - CreateTodo - r.storage.Create: %v; OwnerId=%v, Name=%v, Desc=%v, Status=%v",
+				err,
+				dto.OwnerId,
+				dto.Name,
+				dto.Desc,
+				dto.Status,
+			)
+			return err
+		}
+		created := dto
+		created.Id = id
+		// the todo is only announced once it and its assignees are stored
+		r.transactor.AfterCommit(ctx, func() { r.notification.Send(created) })
+
+		for _, accountID := range dto.Assignees {
+			if err = r.AssignTodo(ctx, created, accountID, dto.OwnerId); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// createTodo stores the todo with its first revision, assignees and the
+// notification are up to the caller.
+func (r *todoUsecase) createTodo(ctx context.Context, dto entity.Todo) (id uint, err error) {
+	err = r.transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		if id, err = r.storage.Create(ctx, dto); err != nil {
+			return err
+		}
+		created := dto
+		created.Id = id
+		if created.Status == 0 {
+			created.Status = entity.TodoStatusDefault
+		}
+		r.auditor.Audit(ctx, entity.AuditEntry{
+			Action:     entity.AuditTodoCreate,
+			TargetType: entity.AuditTargetTodo,
+			TargetId:   id,
+			Summary:    map[string]interface{}{"name": dto.Name, "project_id": dto.ProjectId},
+		})
+		return r.recordRevision(ctx, entity.Todo{}, created, dto.OwnerId, 0)
+	})
+	return id, err
 }
 
 func (r *todoUsecase) GetTodo(ctx context.Context, todoID uint) (*entity.Todo, error) {
@@ -54,9 +141,111 @@ func (r *todoUsecase) GetTodo(ctx context.Context, todoID uint) (*entity.Todo, e
 		r.log.Error("TodoUsecase - GetTodo - r.storage.Get: %v; todoID=%v", err, todoID)
 		return nil, err
 	}
+	if ret == nil {
+		return nil, nil
+	}
+
+	ret.Assignees, err = r.assigneeStorage.GetByTodo(ctx, todoID)
+	if err != nil {
+		r.log.Error("TodoUsecase - GetTodo - r.assigneeStorage.GetByTodo: %v; todoID=%v", err, todoID)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *todoUsecase) GetTodoAllVisible(ctx context.Context, accountID uint) ([]entity.Todo, error) {
+	ret, err := r.storage.GetAllVisible(ctx, accountID)
+	if err != nil {
+		r.log.Error("TodoUsecase - GetTodoAllVisible - r.storage.GetAllVisible: %v; accountID=%v", err, accountID)
+		return nil, err
+	}
 	return ret, nil
 }
 
+func (r *todoUsecase) GetTodoAllByAssignee(ctx context.Context, accountID uint) ([]entity.Todo, error) {
+	ret, err := r.storage.GetAllByAssignee(ctx, accountID)
+	if err != nil {
+		r.log.Error("TodoUsecase - GetTodoAllByAssignee - r.storage.GetAllByAssignee: %v; accountID=%v", err, accountID)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *todoUsecase) GetTodoAllByProject(ctx context.Context, projectID uint) ([]entity.Todo, error) {
+	ret, err := r.storage.GetAllByProject(ctx, projectID)
+	if err != nil {
+		r.log.Error("TodoUsecase - GetTodoAllByProject - r.storage.GetAllByProject: %v; projectID=%v", err, projectID)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *todoUsecase) MoveTodo(ctx context.Context, todoID uint, projectID uint, actorID uint) error {
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		old, err := r.storage.Get(ctx, todoID)
+		if err != nil {
+			return err
+		}
+		if old == nil {
+			return ErrTodoNotFound
+		}
+		if err = r.storage.SetProject(ctx, todoID, projectID); err != nil {
+			return err
+		}
+		moved := *old
+		moved.ProjectId = projectID
+		r.auditor.Audit(ctx, entity.AuditEntry{
+			ActorId:    actorID,
+			Action:     entity.AuditTodoMove,
+			TargetType: entity.AuditTargetTodo,
+			TargetId:   todoID,
+			Summary:    map[string]interface{}{"from_project_id": old.ProjectId, "project_id": projectID},
+		})
+		return r.recordRevision(ctx, *old, moved, actorID, 0)
+	})
+	if err != nil {
+		r.log.Error("TodoUsecase - MoveTodo - r.storage.SetProject: %v; todoID=%v, projectID=%v", err, todoID, projectID)
+		return err
+	}
+	return nil
+}
+
+func (r *todoUsecase) AssignTodo(ctx context.Context, todo entity.Todo, accountID uint, assignedBy uint) error {
+	if err := r.assigneeStorage.Add(ctx, todo.Id, accountID); err != nil {
+		r.log.Error("TodoUsecase - AssignTodo - r.assigneeStorage.Add: %v; todoID=%v, accountID=%v", err, todo.Id, accountID)
+		return err
+	}
+	r.auditor.Audit(ctx, entity.AuditEntry{
+		ActorId:    assignedBy,
+		Action:     entity.AuditTodoAssign,
+		TargetType: entity.AuditTargetTodo,
+		TargetId:   todo.Id,
+		Summary:    map[string]interface{}{"account_id": accountID},
+	})
+	event := entity.TodoAssignedEvent{
+		TodoId:     todo.Id,
+		TodoName:   todo.Name,
+		AccountId:  accountID,
+		AssignedBy: assignedBy,
+	}
+	r.transactor.AfterCommit(ctx, func() { r.notification.Send(event) })
+	return nil
+}
+
+func (r *todoUsecase) UnassignTodo(ctx context.Context, todoID uint, accountID uint) error {
+	if err := r.assigneeStorage.Remove(ctx, todoID, accountID); err != nil {
+		r.log.Error("TodoUsecase - UnassignTodo - r.assigneeStorage.Remove: %v; todoID=%v, accountID=%v", err, todoID, accountID)
+		return err
+	}
+	r.auditor.Audit(ctx, entity.AuditEntry{
+		Action:     entity.AuditTodoUnassign,
+		TargetType: entity.AuditTargetTodo,
+		TargetId:   todoID,
+		Summary:    map[string]interface{}{"account_id": accountID},
+	})
+	return nil
+}
+
 func (r *todoUsecase) GetTodoAll(ctx context.Context) ([]entity.Todo, error) {
 	ret, err := r.storage.GetAll(ctx)
 	if err != nil {
@@ -66,8 +255,10 @@ func (r *todoUsecase) GetTodoAll(ctx context.Context) ([]entity.Todo, error) {
 	return ret, nil
 }
 
-func (r *todoUsecase) UpdateTodo(ctx context.Context, dto entity.Todo) error {
-	if err := r.storage.Update(ctx, dto); err != nil {
+// UpdateTodo applies non-empty fields of dto, non-zero dto.Version must match
+// the current version or ErrTodoVersionConflict is returned.
+func (r *todoUsecase) UpdateTodo(ctx context.Context, dto entity.Todo, actorID uint) error {
+	if err := r.updateTodo(ctx, dto, actorID); err != nil {
 		r.log.Error("TodoUsecase - UpdateTodo - r.storage.Update: %v; ID=%v, Name=%v, Desc=%v, Status=%v",
 			err,
 			dto.Id,
@@ -80,10 +271,270 @@ func (r *todoUsecase) UpdateTodo(ctx context.Context, dto entity.Todo) error {
 	return nil
 }
 
+func (r *todoUsecase) updateTodo(ctx context.Context, dto entity.Todo, actorID uint) error {
+	if err := validateTodoLabels(dto.Labels); err != nil {
+		return err
+	}
+	if err := validateTodoSchedule(dto.Priority, dto.Recurrence); err != nil {
+		return err
+	}
+	return r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		old, err := r.storage.Get(ctx, dto.Id)
+		if err != nil {
+			return err
+		}
+		if old == nil {
+			return ErrTodoNotFound
+		}
+		if dto.Version != 0 && dto.Version != old.Version {
+			return ErrTodoVersionConflict
+		}
+		ok, err := r.storage.Update(ctx, dto)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrTodoVersionConflict
+		}
+
+		updated := *old
+		if dto.Name != "" {
+			updated.Name = dto.Name
+		}
+		if dto.Desc != "" {
+			updated.Desc = dto.Desc
+		}
+		if dto.Status > 0 {
+			updated.Status = dto.Status
+		}
+		if dto.Due != nil {
+			updated.Due = dueOrNil(dto.Due)
+		}
+		if dto.Labels != nil {
+			updated.Labels = dto.Labels
+		}
+		if dto.Priority > 0 {
+			updated.Priority = dto.Priority
+		}
+		if dto.Recurrence != "" {
+			updated.Recurrence = dto.Recurrence
+		}
+		r.auditor.Audit(ctx, entity.AuditEntry{
+			ActorId:    actorID,
+			Action:     entity.AuditTodoUpdate,
+			TargetType: entity.AuditTargetTodo,
+			TargetId:   dto.Id,
+			Summary:    map[string]interface{}{"changes": diffTodo(*old, updated)},
+		})
+		return r.recordRevision(ctx, *old, updated, actorID, 0)
+	})
+}
+
+// dueOrNil maps the zero time, which clears the due date, to nil.
+func dueOrNil(due *time.Time) *time.Time {
+	if due == nil || due.IsZero() {
+		return nil
+	}
+	return due
+}
+
+func validateTodoName(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: name must not be empty", ErrTodoInvalid)
+	}
+	if utf8.RuneCountInString(name) > _todoNameMaxLen {
+		return fmt.Errorf("%w: name is longer than %d characters", ErrTodoInvalid, _todoNameMaxLen)
+	}
+	return nil
+}
+
+func validateTodoStatus(status entity.TodoStatus) error {
+	if status != entity.TodoStatusDefault && status != entity.TodoStatusDone {
+		return fmt.Errorf("%w: status must be %d or %d", ErrTodoInvalid, entity.TodoStatusDefault, entity.TodoStatusDone)
+	}
+	return nil
+}
+
+// validateTodoLabels checks the labels are distinct words, they are stored
+// comma separated.
+func validateTodoLabels(labels []string) error {
+	if len(labels) > _todoLabelsMax {
+		return fmt.Errorf("%w: more than %d labels", ErrTodoInvalid, _todoLabelsMax)
+	}
+	seen := make(map[string]struct{}, len(labels))
+	for _, label := range labels {
+		if label == "" || utf8.RuneCountInString(label) > _todoLabelMaxLen {
+			return fmt.Errorf("%w: labels must have 1 to %d characters", ErrTodoInvalid, _todoLabelMaxLen)
+		}
+		if strings.IndexFunc(label, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) >= 0 {
+			return fmt.Errorf("%w: label %q has a comma or space", ErrTodoInvalid, label)
+		}
+		key := strings.ToLower(label)
+		if _, ok := seen[key]; ok {
+			return fmt.Errorf("%w: label %q is repeated", ErrTodoInvalid, label)
+		}
+		seen[key] = struct{}{}
+	}
+	return nil
+}
+
+// validateTodoSchedule checks the priority and, when set, the recurrence
+// rule.
+func validateTodoSchedule(priority uint, recurrence string) error {
+	if priority > _todoPriorityMax {
+		return fmt.Errorf("%w: priority must be 0 to %d", ErrTodoInvalid, _todoPriorityMax)
+	}
+	if recurrence == "" {
+		return nil
+	}
+	if len(recurrence) > _todoRecurrenceMaxLen {
+		return fmt.Errorf("%w: recurrence longer than %d characters", ErrTodoInvalid, _todoRecurrenceMaxLen)
+	}
+	if err := ical.CheckRecur(recurrence); err != nil {
+		return fmt.Errorf("%w: %v", ErrTodoInvalid, err)
+	}
+	return nil
+}
+
+// validateTodo checks a new todo, zero status stands for the default.
+func validateTodo(todo entity.Todo) error {
+	if err := validateTodoName(todo.Name); err != nil {
+		return err
+	}
+	if err := validateTodoLabels(todo.Labels); err != nil {
+		return err
+	}
+	if err := validateTodoSchedule(todo.Priority, todo.Recurrence); err != nil {
+		return err
+	}
+	if todo.Status != 0 {
+		return validateTodoStatus(todo.Status)
+	}
+	return nil
+}
+
+// validateTodoPatch checks the fields the patch sets, the result has to be a
+// todo CreateTodo would accept.
+func validateTodoPatch(patch entity.TodoPatch) error {
+	if patch.Name != nil {
+		if err := validateTodoName(*patch.Name); err != nil {
+			return err
+		}
+	}
+	if patch.Labels != nil {
+		if err := validateTodoLabels(*patch.Labels); err != nil {
+			return err
+		}
+	}
+	if patch.Priority != nil {
+		if err := validateTodoSchedule(*patch.Priority, ""); err != nil {
+			return err
+		}
+	}
+	if patch.Recurrence != nil {
+		if err := validateTodoSchedule(0, *patch.Recurrence); err != nil {
+			return err
+		}
+	}
+	if patch.Status != nil {
+		return validateTodoStatus(*patch.Status)
+	}
+	return nil
+}
+
+// PatchTodo sets the fields of the patch, empty values included, and returns
+// the patched todo. Non-zero patch.Version must match the current version or
+// ErrTodoVersionConflict is returned, invalid fields give ErrTodoInvalid.
+func (r *todoUsecase) PatchTodo(ctx context.Context, todoID uint, patch entity.TodoPatch, actorID uint) (*entity.Todo, error) {
+	if err := validateTodoPatch(patch); err != nil {
+		return nil, err
+	}
+
+	var updated entity.Todo
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		old, err := r.storage.Get(ctx, todoID)
+		if err != nil {
+			return err
+		}
+		if old == nil {
+			return ErrTodoNotFound
+		}
+		if patch.Version != 0 && patch.Version != old.Version {
+			return ErrTodoVersionConflict
+		}
+		ok, err := r.storage.Patch(ctx, todoID, patch)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrTodoVersionConflict
+		}
+
+		updated = *old
+		updated.Version++
+		if patch.Name != nil {
+			updated.Name = *patch.Name
+		}
+		if patch.Desc != nil {
+			updated.Desc = *patch.Desc
+		}
+		if patch.Status != nil {
+			updated.Status = *patch.Status
+		}
+		if patch.Due != nil {
+			updated.Due = dueOrNil(patch.Due)
+		}
+		if patch.Labels != nil {
+			updated.Labels = *patch.Labels
+		}
+		if patch.Priority != nil {
+			updated.Priority = *patch.Priority
+		}
+		if patch.Recurrence != nil {
+			updated.Recurrence = *patch.Recurrence
+		}
+		r.auditor.Audit(ctx, entity.AuditEntry{
+			ActorId:    actorID,
+			Action:     entity.AuditTodoUpdate,
+			TargetType: entity.AuditTargetTodo,
+			TargetId:   todoID,
+			Summary:    map[string]interface{}{"changes": diffTodo(*old, updated)},
+		})
+		return r.recordRevision(ctx, *old, updated, actorID, 0)
+	})
+	if err != nil {
+		r.log.Error("TodoUsecase - PatchTodo - r.storage.Patch: %v; todoID=%v", err, todoID)
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteTodo moves the todo to the trash, attachments stay until it is
+// purged.
 func (r *todoUsecase) DeleteTodo(ctx context.Context, todoID uint) error {
-	if err := r.storage.Delete(ctx, todoID); err != nil {
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		return r.deleteTodo(ctx, todoID)
+	})
+	if err != nil {
 		r.log.Error("TodoUsecase - DeleteTodo - r.storage.Delete: %v", err)
 		return err
 	}
 	return nil
 }
+
+func (r *todoUsecase) deleteTodo(ctx context.Context, todoID uint) error {
+	// the state before the delete, a deleted todo cannot be read
+	err := r.publishTodo(ctx, entity.TodoEventDeleted, todoID, entity.AuditMetaFromContext(ctx).ActorId)
+	if err != nil {
+		return err
+	}
+	if err := r.storage.Delete(ctx, todoID); err != nil {
+		return err
+	}
+	r.auditor.Audit(ctx, entity.AuditEntry{
+		Action:     entity.AuditTodoDelete,
+		TargetType: entity.AuditTargetTodo,
+		TargetId:   todoID,
+	})
+	return nil
+}
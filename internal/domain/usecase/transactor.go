@@ -0,0 +1,8 @@
+package usecase
+
+import "context"
+
+type Transactor interface {
+	WithinTransaction(ctx context.Context, tFunc func(ctx context.Context) error) error
+	AfterCommit(ctx context.Context, fn func())
+}
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"testcode/test3/internal/domain/entity"
+)
+
+// TodoImportMaxRows bounds a single import transaction, readers can stop
+// after one more row.
+const TodoImportMaxRows = 1000
+
+var ErrImportTooLarge = fmt.Errorf("import is limited to %d rows", TodoImportMaxRows)
+
+// ImportTodos creates the todos of the rows for the owner in one transaction.
+// Any invalid row, or dryRun, leaves the todos unchanged and the report says
+// what would have happened.
+func (r *todoUsecase) ImportTodos(ctx context.Context, rows []entity.TodoImportRow, ownerID uint, dryRun bool) (*entity.TodoImportReport, error) {
+	if len(rows) > TodoImportMaxRows {
+		return nil, ErrImportTooLarge
+	}
+
+	report := &entity.TodoImportReport{Total: len(rows), DryRun: dryRun}
+	for _, row := range rows {
+		if row.Error == "" {
+			if err := validateTodo(row.Todo); err != nil {
+				row.Error = err.Error()
+			}
+		}
+		if row.Error != "" {
+			report.Errors = append(report.Errors, entity.TodoImportError{Row: row.Row, Error: row.Error})
+		}
+	}
+	if len(report.Errors) > 0 || dryRun {
+		return report, nil
+	}
+
+	ids := make([]uint, 0, len(rows))
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, row := range rows {
+			todo := row.Todo
+			todo.OwnerId = ownerID
+			id, err := r.createTodo(ctx, todo)
+			if err != nil {
+				return fmt.Errorf("row %d: %w", row.Row, err)
+			}
+			ids = append(ids, id)
+		}
+		return nil
+	})
+	if err != nil {
+		r.log.Error("TodoUsecase - ImportTodos - r.createTodo: %v; ownerID=%v", err, ownerID)
+		return nil, err
+	}
+
+	report.Imported = len(ids)
+	report.Ids = ids
+	if len(ids) > 0 {
+		r.notification.Send(entity.TodoBulkEvent{ActorId: ownerID, Created: ids})
+	}
+	return report, nil
+}
@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/pkg/logger"
+)
+
+var ErrNotInTrash = errors.New("not found in trash")
+
+type TrashTodoStorage interface {
+	GetDeleted(ctx context.Context, todoID uint) (*entity.Todo, error)
+	GetAllDeleted(ctx context.Context, ownerID uint) ([]entity.Todo, error)
+	Undelete(ctx context.Context, todoID uint) error
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+type TrashAccountStorage interface {
+	GetAllDeleted(ctx context.Context) ([]entity.Account, error)
+	Undelete(ctx context.Context, accountID uint) (bool, error)
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+type TrashAttachmentStorage interface {
+	GetAllPurgeable(ctx context.Context, before time.Time) ([]entity.Attachment, error)
+}
+
+type trashUsecase struct {
+	todoStorage       TrashTodoStorage
+	accountStorage    TrashAccountStorage
+	attachmentStorage TrashAttachmentStorage
+	blobStorage       BlobStorage
+	transactor        Transactor
+	auditor           Auditor
+	events            TodoEvents
+	retention         time.Duration
+	log               *logger.Logger
+}
+
+func NewTrashUsecase(log *logger.Logger, todoStorage TrashTodoStorage, accountStorage TrashAccountStorage,
+	attachmentStorage TrashAttachmentStorage, blobStorage BlobStorage, transactor Transactor, auditor Auditor,
+	events TodoEvents, retention time.Duration) *trashUsecase {
+	return &trashUsecase{
+		todoStorage:       todoStorage,
+		accountStorage:    accountStorage,
+		attachmentStorage: attachmentStorage,
+		blobStorage:       blobStorage,
+		transactor:        transactor,
+		auditor:           auditor,
+		events:            events,
+		retention:         retention,
+		log:               log,
+	}
+}
+
+func (r *trashUsecase) GetTrashedTodo(ctx context.Context, todoID uint) (*entity.Todo, error) {
+	ret, err := r.todoStorage.GetDeleted(ctx, todoID)
+	if err != nil {
+		r.log.Error("TrashUsecase - GetTrashedTodo - r.todoStorage.GetDeleted: %v; todoID=%v", err, todoID)
+		return nil, err
+	}
+	return ret, nil
+}
+
+// GetTrashedTodos returns deleted todos of the owner, zero ownerID returns
+// the whole trash.
+func (r *trashUsecase) GetTrashedTodos(ctx context.Context, ownerID uint) ([]entity.Todo, error) {
+	ret, err := r.todoStorage.GetAllDeleted(ctx, ownerID)
+	if err != nil {
+		r.log.Error("TrashUsecase - GetTrashedTodos - r.todoStorage.GetAllDeleted: %v; ownerID=%v", err, ownerID)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *trashUsecase) RestoreTodo(ctx context.Context, todoID uint) error {
+	todo, err := r.todoStorage.GetDeleted(ctx, todoID)
+	if err != nil {
+		r.log.Error("TrashUsecase - RestoreTodo - r.todoStorage.GetDeleted: %v; todoID=%v", err, todoID)
+		return err
+	}
+	if todo == nil {
+		return ErrNotInTrash
+	}
+	if err = r.todoStorage.Undelete(ctx, todoID); err != nil {
+		r.log.Error("TrashUsecase - RestoreTodo - r.todoStorage.Undelete: %v; todoID=%v", err, todoID)
+		return err
+	}
+	r.auditor.Audit(ctx, entity.AuditEntry{
+		Action:     entity.AuditTodoRestore,
+		TargetType: entity.AuditTargetTodo,
+		TargetId:   todoID,
+	})
+
+	todo.DeletedAt = nil
+	r.events.Publish(entity.TodoEvent{
+		Type:     entity.TodoEventCreated,
+		TenantId: entity.TenantFromContext(ctx),
+		ActorId:  entity.AuditMetaFromContext(ctx).ActorId,
+		Todo:     *todo,
+		At:       time.Now(),
+	})
+	return nil
+}
+
+func (r *trashUsecase) GetTrashedAccounts(ctx context.Context) ([]entity.Account, error) {
+	ret, err := r.accountStorage.GetAllDeleted(ctx)
+	if err != nil {
+		r.log.Error("TrashUsecase - GetTrashedAccounts - r.accountStorage.GetAllDeleted: %v", err)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (r *trashUsecase) RestoreAccount(ctx context.Context, accountID uint) error {
+	ok, err := r.accountStorage.Undelete(ctx, accountID)
+	if err != nil {
+		r.log.Error("TrashUsecase - RestoreAccount - r.accountStorage.Undelete: %v; accountID=%v", err, accountID)
+		return err
+	}
+	if !ok {
+		return ErrNotInTrash
+	}
+	r.auditor.Audit(ctx, entity.AuditEntry{
+		Action:     entity.AuditAccountRestore,
+		TargetType: entity.AuditTargetAccount,
+		TargetId:   accountID,
+	})
+	return nil
+}
+
+// Purge permanently drops everything deleted longer than the retention ago
+// together with attachment blobs of purged todos.
+func (r *trashUsecase) Purge(ctx context.Context) error {
+	before := time.Now().Add(-r.retention)
+
+	var (
+		attachments     []entity.Attachment
+		todos, accounts int64
+	)
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) (err error) {
+		if attachments, err = r.attachmentStorage.GetAllPurgeable(ctx, before); err != nil {
+			return err
+		}
+		if todos, err = r.todoStorage.Purge(ctx, before); err != nil {
+			return err
+		}
+		accounts, err = r.accountStorage.Purge(ctx, before)
+		return err
+	})
+	if err != nil {
+		r.log.Error("TrashUsecase - Purge: %v; before=%v", err, before)
+		return err
+	}
+	removeBlobs(ctx, r.log, r.blobStorage, attachments)
+
+	if todos > 0 || accounts > 0 {
+		r.log.Info("TrashUsecase - Purge: todos=%v, accounts=%v, attachments=%v", todos, accounts, len(attachments))
+	}
+	return nil
+}
+
+// RunPurge purges the trash every interval until ctx is done.
+func (r *trashUsecase) RunPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = r.Purge(ctx)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"testcode/test3/internal/domain/entity"
+)
+
+// ErrTodoEventsExpired means the events after the id to resume from are no
+// longer kept, the watcher has to reload.
+var ErrTodoEventsExpired = errors.New("events since the given id have expired")
+
+// TodoEvents delivers committed todo changes to the subscribers of this
+// process and keeps the last of them for resuming.
+type TodoEvents interface {
+	Publish(event entity.TodoEvent)
+	Subscribe(tenantID uint) (<-chan entity.TodoEvent, func())
+	SubscribeSince(tenantID uint, lastEventID uint64) ([]entity.TodoEvent, <-chan entity.TodoEvent, func(), bool)
+}
+
+// publishTodo sends the state of the todo as stored in ctx once the
+// transaction commits.
+func (r *todoUsecase) publishTodo(ctx context.Context, eventType entity.TodoEventType, todoID uint, actorID uint) error {
+	todo, err := r.storage.Get(ctx, todoID)
+	if err != nil {
+		return err
+	}
+	if todo == nil {
+		return ErrTodoNotFound
+	}
+	event := entity.TodoEvent{
+		Type:     eventType,
+		TenantId: entity.TenantFromContext(ctx),
+		ActorId:  actorID,
+		Todo:     *todo,
+		At:       time.Now(),
+	}
+	r.transactor.AfterCommit(ctx, func() { r.events.Publish(event) })
+	return nil
+}
+
+// WatchTodos returns the changes to todos of the tenant from ctx until stop
+// is called. The channel is closed early when the watcher falls behind.
+func (r *todoUsecase) WatchTodos(ctx context.Context) (<-chan entity.TodoEvent, func()) {
+	return r.events.Subscribe(entity.TenantFromContext(ctx))
+}
+
+// WatchTodosSince is WatchTodos resuming after lastEventID, the missed events
+// are returned first. With ErrTodoEventsExpired the watch still starts from
+// now, zero starts from now too.
+func (r *todoUsecase) WatchTodosSince(ctx context.Context, lastEventID uint64) ([]entity.TodoEvent, <-chan entity.TodoEvent, func(), error) {
+	tenantID := entity.TenantFromContext(ctx)
+	if lastEventID == 0 {
+		events, stop := r.events.Subscribe(tenantID)
+		return nil, events, stop, nil
+	}
+	backlog, events, stop, ok := r.events.SubscribeSince(tenantID, lastEventID)
+	if !ok {
+		return nil, events, stop, ErrTodoEventsExpired
+	}
+	return backlog, events, stop, nil
+}
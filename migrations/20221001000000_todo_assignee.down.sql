@@ -0,0 +1 @@
+DROP TABLE IF EXISTS todo_assignee;
\ No newline at end of file
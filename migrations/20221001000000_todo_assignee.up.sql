@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS todo_assignee(
+    todo_id INT NOT NULL,
+    account_id INT NOT NULL,
+    PRIMARY KEY(todo_id, account_id),
+    INDEX account_idx (account_id),
+    FOREIGN KEY(todo_id)
+        REFERENCES todo(id)
+        ON DELETE CASCADE,
+    FOREIGN KEY(account_id)
+        REFERENCES account(id)
+        ON DELETE CASCADE
+);
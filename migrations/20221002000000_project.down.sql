@@ -0,0 +1,6 @@
+ALTER TABLE todo
+    DROP FOREIGN KEY todo_project_fk,
+    DROP INDEX project_idx,
+    DROP COLUMN project_id;
+
+DROP TABLE IF EXISTS project;
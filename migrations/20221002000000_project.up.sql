@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS project(
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    owner_id INT NOT NULL,
+    name VARCHAR(40) NOT NULL,
+    `desc` TEXT NOT NULL,
+    color VARCHAR(7) NOT NULL DEFAULT '',
+    archived BOOL NOT NULL DEFAULT FALSE,
+    FOREIGN KEY(owner_id)
+        REFERENCES account(id)
+);
+
+ALTER TABLE todo
+    ADD COLUMN project_id INT NULL,
+    ADD INDEX project_idx (project_id),
+    ADD CONSTRAINT todo_project_fk FOREIGN KEY(project_id)
+        REFERENCES project(id)
+        ON DELETE CASCADE;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS member;
\ No newline at end of file
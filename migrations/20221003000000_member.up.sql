@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS member(
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    resource_type INT NOT NULL,
+    resource_id INT NOT NULL,
+    account_id INT NOT NULL,
+    role INT NOT NULL,
+    invited_by INT NOT NULL,
+    accepted BOOL NOT NULL DEFAULT FALSE,
+    UNIQUE INDEX resource_account_uniq (resource_type, resource_id, account_id),
+    INDEX account_idx (account_id),
+    FOREIGN KEY(account_id)
+        REFERENCES account(id)
+        ON DELETE CASCADE
+);
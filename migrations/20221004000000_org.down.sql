@@ -0,0 +1,10 @@
+ALTER TABLE todo_assignee DROP INDEX tenant_idx, DROP COLUMN tenant_id;
+ALTER TABLE member DROP INDEX tenant_idx, DROP COLUMN tenant_id;
+ALTER TABLE project DROP INDEX tenant_idx, DROP COLUMN tenant_id;
+ALTER TABLE todo DROP INDEX tenant_idx, DROP COLUMN tenant_id;
+
+UPDATE account a JOIN org_account o ON o.account_id = a.id AND o.org_id = 1 SET a.account_type = o.role;
+UPDATE account SET account_type = 1 WHERE account_type = 3;
+
+DROP TABLE IF EXISTS org_account;
+DROP TABLE IF EXISTS org;
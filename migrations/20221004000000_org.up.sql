@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS org(
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(40) NOT NULL,
+    UNIQUE INDEX name_uniq (name)
+);
+
+CREATE TABLE IF NOT EXISTS org_account(
+    org_id INT NOT NULL,
+    account_id INT NOT NULL,
+    role INT NOT NULL,
+    PRIMARY KEY(org_id, account_id),
+    INDEX account_idx (account_id),
+    FOREIGN KEY(org_id)
+        REFERENCES org(id)
+        ON DELETE CASCADE,
+    FOREIGN KEY(account_id)
+        REFERENCES account(id)
+        ON DELETE CASCADE
+);
+
+INSERT INTO org(id, name) VALUES(1, "default");
+INSERT INTO org_account(org_id, account_id, role) SELECT 1, id, account_type FROM account;
+UPDATE account SET account_type = 2 WHERE account_type = 1 AND name <> "admin";
+UPDATE account SET account_type = 3 WHERE name = "admin";
+
+ALTER TABLE todo ADD COLUMN tenant_id INT NOT NULL DEFAULT 1, ADD INDEX tenant_idx (tenant_id);
+ALTER TABLE project ADD COLUMN tenant_id INT NOT NULL DEFAULT 1, ADD INDEX tenant_idx (tenant_id);
+ALTER TABLE member ADD COLUMN tenant_id INT NOT NULL DEFAULT 1, ADD INDEX tenant_idx (tenant_id);
+ALTER TABLE todo_assignee ADD COLUMN tenant_id INT NOT NULL DEFAULT 1, ADD INDEX tenant_idx (tenant_id);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS comment(
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    tenant_id INT NOT NULL,
+    todo_id INT NOT NULL,
+    author_id INT NOT NULL,
+    body TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    edited_at DATETIME NULL,
+    deleted_at DATETIME NULL,
+    INDEX todo_idx (tenant_id, todo_id, created_at),
+    FOREIGN KEY(todo_id)
+        REFERENCES todo(id)
+        ON DELETE CASCADE,
+    FOREIGN KEY(author_id)
+        REFERENCES account(id)
+);
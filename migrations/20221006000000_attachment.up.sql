@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS attachment(
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    tenant_id INT NOT NULL,
+    todo_id INT NOT NULL,
+    uploader_id INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(127) NOT NULL,
+    size BIGINT NOT NULL,
+    blob_key VARCHAR(64) NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX todo_idx (tenant_id, todo_id),
+    UNIQUE INDEX blob_key_uniq (blob_key),
+    FOREIGN KEY(todo_id)
+        REFERENCES todo(id)
+        ON DELETE CASCADE,
+    FOREIGN KEY(uploader_id)
+        REFERENCES account(id)
+);
@@ -0,0 +1 @@
+ALTER TABLE todo DROP INDEX todo_fts;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE todo ADD FULLTEXT INDEX todo_fts (name, `desc`);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS saved_filter;
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS saved_filter(
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    tenant_id INT NOT NULL,
+    account_id INT NOT NULL,
+    name VARCHAR(40) NOT NULL,
+    query VARCHAR(1024) NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE INDEX account_name_uniq (tenant_id, account_id, name),
+    FOREIGN KEY(account_id)
+        REFERENCES account(id)
+        ON DELETE CASCADE
+);
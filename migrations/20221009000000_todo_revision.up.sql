@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS todo_revision(
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    tenant_id INT NOT NULL,
+    todo_id INT NOT NULL,
+    actor_id INT NOT NULL,
+    name VARCHAR(40) NOT NULL,
+    `desc` TEXT NOT NULL,
+    status INT NOT NULL,
+    project_id INT NULL,
+    restored_from INT NULL,
+    changes TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX todo_idx (tenant_id, todo_id, id),
+    FOREIGN KEY(todo_id)
+        REFERENCES todo(id)
+        ON DELETE CASCADE,
+    FOREIGN KEY(actor_id)
+        REFERENCES account(id)
+);
+
+INSERT INTO todo_revision(tenant_id, todo_id, actor_id, name, `desc`, status, project_id, changes)
+    SELECT tenant_id, id, owner_id, name, `desc`, COALESCE(status, 1), project_id, '[]' FROM todo;
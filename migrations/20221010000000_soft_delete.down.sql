@@ -0,0 +1,4 @@
+DELETE FROM todo WHERE deleted_at IS NOT NULL;
+DELETE FROM org_account WHERE deleted_at IS NOT NULL;
+ALTER TABLE todo DROP INDEX deleted_idx, DROP COLUMN deleted_at;
+ALTER TABLE org_account DROP INDEX deleted_idx, DROP COLUMN deleted_at;
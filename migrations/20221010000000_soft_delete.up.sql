@@ -0,0 +1,2 @@
+ALTER TABLE todo ADD COLUMN deleted_at DATETIME NULL, ADD INDEX deleted_idx (tenant_id, deleted_at);
+ALTER TABLE org_account ADD COLUMN deleted_at DATETIME NULL, ADD INDEX deleted_idx (org_id, deleted_at);
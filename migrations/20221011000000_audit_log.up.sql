@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS audit_log(
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    tenant_id INT NOT NULL,
+    actor_id INT NULL,
+    action VARCHAR(64) NOT NULL,
+    target_type VARCHAR(32) NOT NULL,
+    target_id INT NULL,
+    request_id VARCHAR(64) NOT NULL,
+    client_ip VARCHAR(45) NOT NULL,
+    summary TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX created_idx (tenant_id, created_at),
+    INDEX actor_idx (tenant_id, actor_id, created_at),
+    INDEX target_idx (tenant_id, target_type, target_id)
+);
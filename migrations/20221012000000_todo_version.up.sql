@@ -0,0 +1 @@
+ALTER TABLE todo ADD COLUMN version INT NOT NULL DEFAULT 1;
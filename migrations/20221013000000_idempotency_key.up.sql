@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS idempotency_key(
+    tenant_id INT NOT NULL,
+    account_id INT NOT NULL,
+    idem_key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    completed BOOLEAN NOT NULL DEFAULT FALSE,
+    status_code INT NOT NULL DEFAULT 0,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    body MEDIUMBLOB NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at DATETIME NOT NULL,
+    PRIMARY KEY (tenant_id, account_id, idem_key),
+    INDEX expires_idx (expires_at)
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS calendar_feed;
\ No newline at end of file
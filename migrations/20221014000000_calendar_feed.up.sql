@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS calendar_feed(
+    tenant_id INT NOT NULL,
+    account_id INT NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, account_id),
+    UNIQUE KEY token_idx (token_hash),
+    FOREIGN KEY(account_id)
+        REFERENCES account(id)
+        ON DELETE CASCADE
+);
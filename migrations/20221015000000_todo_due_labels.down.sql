@@ -0,0 +1,8 @@
+ALTER TABLE todo_revision
+    DROP COLUMN labels,
+    DROP COLUMN due;
+
+ALTER TABLE todo
+    DROP INDEX due_idx,
+    DROP COLUMN labels,
+    DROP COLUMN due;
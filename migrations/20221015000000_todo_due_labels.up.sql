@@ -0,0 +1,8 @@
+ALTER TABLE todo
+    ADD COLUMN due DATETIME NULL,
+    ADD COLUMN labels VARCHAR(1024) NOT NULL DEFAULT '',
+    ADD INDEX due_idx (tenant_id, due);
+
+ALTER TABLE todo_revision
+    ADD COLUMN due DATETIME NULL,
+    ADD COLUMN labels VARCHAR(1024) NOT NULL DEFAULT '';
@@ -0,0 +1,7 @@
+ALTER TABLE todo_revision
+    DROP COLUMN rrule,
+    DROP COLUMN priority;
+
+ALTER TABLE todo
+    DROP COLUMN rrule,
+    DROP COLUMN priority;
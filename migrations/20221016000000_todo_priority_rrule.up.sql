@@ -0,0 +1,7 @@
+ALTER TABLE todo
+    ADD COLUMN priority TINYINT UNSIGNED NOT NULL DEFAULT 0,
+    ADD COLUMN rrule VARCHAR(255) NOT NULL DEFAULT '';
+
+ALTER TABLE todo_revision
+    ADD COLUMN priority TINYINT UNSIGNED NOT NULL DEFAULT 0,
+    ADD COLUMN rrule VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS dav_resource(
+    tenant_id INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    todo_id INT NOT NULL,
+    uid VARCHAR(255) NOT NULL,
+    PRIMARY KEY (tenant_id, name),
+    UNIQUE KEY todo_idx (tenant_id, todo_id),
+    FOREIGN KEY(todo_id)
+        REFERENCES todo(id)
+        ON DELETE CASCADE
+);
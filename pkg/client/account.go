@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+func (c *Client) GetAccounts(ctx context.Context) ([]Account, error) {
+	var ret []Account
+	err := c.call(ctx, &request{method: http.MethodGet, path: "/v1/accounts", auth: true, retry: true}, &ret)
+	return ret, err
+}
+
+func (c *Client) CreateAccount(ctx context.Context, req CreateAccountRequest) error {
+	return c.call(ctx, &request{method: http.MethodPost, path: "/v1/account", body: req, auth: true}, nil)
+}
+
+func (c *Client) DeleteAccount(ctx context.Context, req DeleteAccountRequest) (*AccountDeletionReport, error) {
+	var ret AccountDeletionReport
+	if err := c.call(ctx, &request{method: http.MethodDelete, path: "/v1/account", body: req, auth: true}, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+func (c *Client) GetAudit(ctx context.Context, req GetAuditRequest) (*AuditPage, error) {
+	var ret AuditPage
+	if err := c.call(ctx, &request{method: http.MethodGet, path: "/v1/audit", query: req, auth: true, retry: true}, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// ExportAudit returns the export in req.Format for the caller to read and
+// close.
+func (c *Client) ExportAudit(ctx context.Context, req GetAuditRequest) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, &request{method: http.MethodGet, path: "/v1/audit/export", query: req, auth: true, retry: true}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (c *Client) GetOrgs(ctx context.Context) ([]Org, error) {
+	var ret []Org
+	err := c.call(ctx, &request{method: http.MethodGet, path: "/v1/orgs", auth: true, retry: true}, &ret)
+	return ret, err
+}
+
+func (c *Client) CreateOrg(ctx context.Context, req CreateOrgRequest) (uint, error) {
+	var ret idResponse
+	err := c.call(ctx, &request{method: http.MethodPost, path: "/v1/org", body: req, auth: true}, &ret)
+	return ret.Id, err
+}
+
+func (c *Client) AddOrgAccount(ctx context.Context, req AddOrgAccountRequest) error {
+	return c.call(ctx, &request{method: http.MethodPost, path: "/v1/org/account", body: req, auth: true}, nil)
+}
+
+func (c *Client) RemoveOrgAccount(ctx context.Context, req RemoveOrgAccountRequest) error {
+	return c.call(ctx, &request{method: http.MethodDelete, path: "/v1/org/account", body: req, auth: true}, nil)
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+// CreateCalendarToken replaces the token of the caller's calendar feed.
+func (c *Client) CreateCalendarToken(ctx context.Context) (*CalendarToken, error) {
+	var ret CalendarToken
+	if err := c.call(ctx, &request{method: http.MethodPost, path: "/v1/calendar/token", auth: true}, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+func (c *Client) RevokeCalendarToken(ctx context.Context) error {
+	return c.call(ctx, &request{method: http.MethodDelete, path: "/v1/calendar/token", auth: true}, nil)
+}
+
+// CalendarFeed returns the iCalendar feed of the token for the caller to read
+// and close, it needs no login.
+func (c *Client) CalendarFeed(ctx context.Context, token string) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, &request{method: http.MethodGet, path: "/v1/calendar/feed/" + url.PathEscape(token),
+		retry: true}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// ImportCalendar creates a todo of every VTODO of the .ics file read from r.
+// A failed call is not retried, it may have imported the todos.
+func (c *Client) ImportCalendar(ctx context.Context, req ImportCalendarRequest, r io.Reader) (*TodoImportReport, error) {
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var ret TodoImportReport
+	if err := c.call(ctx, &request{method: http.MethodPost, path: "/v1/calendar/import", query: req, body: body,
+		contentType: "text/calendar", auth: true, upload: true}, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
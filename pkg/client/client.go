@@ -0,0 +1,364 @@
+// Package client is a Go client of the /v1 REST API and the GraphQL
+// endpoint. It logs in on the first call and again when the token is
+// rejected, retries calls that are safe to repeat and maps error codes to
+// the Err values. POSTs carry an Idempotency-Key so they can be repeated,
+// DELETEs are not since a repeat fails once the first went through. CalDAV
+// and the WebSocket endpoints are not covered.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	_defaultRetries    = 3
+	_defaultBackoffMin = 100 * time.Millisecond
+	_defaultBackoffMax = 5 * time.Second
+	_defaultTimeout    = 30 * time.Second
+
+	_headerToken          = "token"
+	_headerIfMatch        = "If-Match"
+	_headerIdempotencyKey = "Idempotency-Key"
+	_headerRetryAfter     = "Retry-After"
+)
+
+// Client -.
+type Client struct {
+	baseURL string
+	http    *http.Client
+
+	name     string
+	password string
+	orgID    uint
+
+	retries    int
+	backoffMin time.Duration
+	backoffMax time.Duration
+
+	mu    sync.Mutex
+	token string
+	// login is held while logging in so concurrent calls wait for one login
+	login sync.Mutex
+}
+
+// New -.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		http:       &http.Client{Timeout: _defaultTimeout},
+		retries:    _defaultRetries,
+		backoffMin: _defaultBackoffMin,
+		backoffMax: _defaultBackoffMax,
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Token returns the current session token, empty before logging in.
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+func (c *Client) setToken(token string) {
+	c.mu.Lock()
+	c.token = token
+	c.mu.Unlock()
+}
+
+// Login logs in with the credentials, calls do it on their own when needed.
+func (c *Client) Login(ctx context.Context) error {
+	_, err := c.renew(ctx, c.Token())
+	return err
+}
+
+// Logout ends the session, a later call logs in again.
+func (c *Client) Logout(ctx context.Context) error {
+	token := c.Token()
+	if token == "" {
+		return nil
+	}
+	err := c.call(ctx, &request{method: http.MethodPost, path: "/v1/logout", token: token, retry: true}, nil)
+	c.setToken("")
+	if errors.Is(err, ErrUnauthenticated) {
+		// the session has ended already
+		return nil
+	}
+	return err
+}
+
+// renew logs in unless the token changed from old meanwhile, another call
+// has renewed it then.
+func (c *Client) renew(ctx context.Context, old string) (string, error) {
+	c.login.Lock()
+	defer c.login.Unlock()
+	if token := c.Token(); token != old && token != "" {
+		return token, nil
+	}
+	if c.name == "" {
+		return "", &Error{Method: http.MethodPost, Path: "/v1/login", Code: ErrCodeUnauthenticated,
+			Message: "no credentials"}
+	}
+
+	var resp struct {
+		Token string `json:"token"`
+	}
+	req := &request{
+		method: http.MethodPost,
+		path:   "/v1/login",
+		body:   LoginRequest{Name: c.name, Password: c.password, OrgId: c.orgID},
+		// a repeated login only creates another session
+		retry: true,
+	}
+	if err := c.call(ctx, req, &resp); err != nil {
+		return "", err
+	}
+	c.setToken(resp.Token)
+	return resp.Token, nil
+}
+
+type request struct {
+	method string
+	path   string
+	query  interface{}
+	// body is sent as json unless it is []byte, with contentType then
+	body        interface{}
+	contentType string
+	ifMatch     uint
+	// auth sends the token, logging in first when there is none
+	auth bool
+	// token is sent instead, it is not renewed
+	token string
+	// retry marks the request as safe to send again
+	retry bool
+	// upload is a POST the server does not replay for an Idempotency-Key,
+	// so it is not retried either
+	upload bool
+	// stream is read for longer than the timeout of the http client
+	stream bool
+}
+
+// call runs the request and decodes the data of the ResponseMessage into
+// out.
+func (c *Client) call(ctx context.Context, req *request, out interface{}) error {
+	resp, err := c.do(ctx, req, out)
+	if err != nil {
+		return err
+	}
+	if resp != nil {
+		resp.Body.Close()
+	}
+	return nil
+}
+
+// do runs the request. ResponseMessages are decoded, their data into out,
+// other successful responses are returned with the body to read and close.
+// A rejected token is renewed once, failures are retried with backoff when
+// the request is safe to repeat.
+func (c *Client) do(ctx context.Context, req *request, out interface{}) (*http.Response, error) {
+	body, contentType, err := req.encode()
+	if err != nil {
+		return nil, err
+	}
+	retry := req.retry
+	idempotencyKey := ""
+	if req.method == http.MethodPost && req.auth && !req.upload {
+		// the server replays the first response to a repeated key
+		idempotencyKey = newIdempotencyKey()
+		retry = true
+	}
+
+	httpClient := c.http
+	if req.stream {
+		hc := *c.http
+		hc.Timeout = 0
+		httpClient = &hc
+	}
+
+	renewed := false
+	for attempt := 0; ; attempt++ {
+		token := req.token
+		if req.auth {
+			if token = c.Token(); token == "" {
+				if token, err = c.renew(ctx, ""); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		hreq, err := http.NewRequestWithContext(ctx, req.method, c.baseURL+req.path, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		if req.query != nil {
+			hreq.URL.RawQuery = encodeQuery(req.query).Encode()
+		}
+		if contentType != "" {
+			hreq.Header.Set("Content-Type", contentType)
+		}
+		if token != "" {
+			hreq.Header.Set(_headerToken, token)
+		}
+		if idempotencyKey != "" {
+			hreq.Header.Set(_headerIdempotencyKey, idempotencyKey)
+		}
+		if req.ifMatch != 0 {
+			hreq.Header.Set(_headerIfMatch, `"`+strconv.FormatUint(uint64(req.ifMatch), 10)+`"`)
+		}
+
+		resp, err := httpClient.Do(hreq)
+		if err == nil {
+			resp, err = c.response(req, resp, out)
+			if err == nil {
+				return resp, nil
+			}
+		}
+
+		if req.auth && !renewed && errors.Is(err, ErrUnauthenticated) && c.name != "" {
+			// sessions end with a restart of the server
+			renewed = true
+			if _, err := c.renew(ctx, token); err != nil {
+				return nil, err
+			}
+			attempt--
+			continue
+		}
+		wait, ok := c.retryAfter(retry, attempt, resp, err)
+		if !ok || ctx.Err() != nil {
+			return nil, err
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, err
+		case <-t.C:
+		}
+	}
+}
+
+type responseMessage struct {
+	Code    *ErrCode        `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// response decodes a ResponseMessage. Json without Content-Disposition may
+// be one, files and GraphQL responses are not.
+func (c *Client) response(req *request, resp *http.Response, out interface{}) (*http.Response, error) {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "application/json" || resp.Header.Get("Content-Disposition") != "" {
+		if resp.StatusCode >= http.StatusBadRequest {
+			resp.Body.Close()
+			return resp, &Error{Method: req.method, Path: req.path, Status: resp.StatusCode,
+				Message: http.StatusText(resp.StatusCode)}
+		}
+		return resp, nil
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return resp, err
+	}
+	var msg responseMessage
+	if err := json.Unmarshal(data, &msg); err != nil || msg.Code == nil {
+		if resp.StatusCode >= http.StatusBadRequest {
+			return resp, &Error{Method: req.method, Path: req.path, Status: resp.StatusCode,
+				Message: http.StatusText(resp.StatusCode), Data: data}
+		}
+		resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+		return resp, nil
+	}
+
+	if *msg.Code != ErrCodeNone || resp.StatusCode >= http.StatusBadRequest {
+		e := &Error{Method: req.method, Path: req.path, Status: resp.StatusCode, Code: *msg.Code, Message: msg.Message}
+		if string(msg.Data) != "null" {
+			e.Data = msg.Data
+		}
+		return resp, e
+	}
+	if out != nil && len(msg.Data) > 0 {
+		if err := json.Unmarshal(msg.Data, out); err != nil {
+			return resp, err
+		}
+	}
+	return nil, nil
+}
+
+// retryAfter tells whether to retry the failed attempt and how long to wait.
+// Errors of the transport and statuses telling the server could not take
+// the request are retried, error codes are final.
+func (c *Client) retryAfter(retry bool, attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if !retry || attempt >= c.retries {
+		return 0, false
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		switch e.Status {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		default:
+			return 0, false
+		}
+		if resp != nil {
+			if s, err := strconv.Atoi(resp.Header.Get(_headerRetryAfter)); err == nil && s >= 0 {
+				return time.Duration(s) * time.Second, true
+			}
+		}
+	} else if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return 0, false
+	}
+
+	wait := c.backoffMin << uint(attempt)
+	if wait > c.backoffMax || wait <= 0 {
+		wait = c.backoffMax
+	}
+	// full jitter keeps clients failing together from retrying together
+	if n, err := rand.Int(rand.Reader, big.NewInt(int64(wait)+1)); err == nil {
+		wait = time.Duration(n.Int64())
+	}
+	return wait, true
+}
+
+func (req *request) encode() ([]byte, string, error) {
+	switch body := req.body.(type) {
+	case nil:
+		return nil, "", nil
+	case []byte:
+		return body, req.contentType, nil
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, "", err
+		}
+		return data, "application/json", nil
+	}
+}
+
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
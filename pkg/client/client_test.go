@@ -0,0 +1,420 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"testcode/test3/internal/adapters/db/session"
+	v1 "testcode/test3/internal/controller/http/v1"
+	"testcode/test3/internal/domain/entity"
+	"testcode/test3/internal/domain/usecase"
+	"testcode/test3/pkg/client"
+	"testcode/test3/pkg/logger"
+)
+
+type accountUsecase struct {
+	v1.AccountUsecase
+	accounts map[string]entity.Account
+}
+
+func (r accountUsecase) Authenticate(_ context.Context, name string, password string) (*entity.Account, error) {
+	a, ok := r.accounts[name]
+	if !ok || a.Password != password {
+		return nil, usecase.ErrAccountNotFound
+	}
+	return &a, nil
+}
+
+// orgUsecase enters every account into org 1.
+type orgUsecase struct{ v1.OrgUsecase }
+
+func (orgUsecase) EnterOrg(_ context.Context, account entity.Account, _ uint) (entity.Account, error) {
+	account.TenantId = 1
+	return account, nil
+}
+
+// todoUsecase keeps the todos in memory.
+type todoUsecase struct {
+	v1.TodoUsecase
+	mu    sync.Mutex
+	todos map[uint]entity.Todo
+}
+
+func (r *todoUsecase) CreateTodo(_ context.Context, dto entity.Todo) (uint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	dto.Id, dto.Version = uint(len(r.todos)+1), 1
+	r.todos[dto.Id] = dto
+	return dto.Id, nil
+}
+
+func (r *todoUsecase) GetTodo(_ context.Context, todoID uint) (*entity.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	todo, ok := r.todos[todoID]
+	if !ok {
+		return nil, nil
+	}
+	return &todo, nil
+}
+
+func (r *todoUsecase) GetTodoAllVisible(_ context.Context, accountID uint) ([]entity.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var ret []entity.Todo
+	for _, t := range r.todos {
+		if t.OwnerId == accountID {
+			ret = append(ret, t)
+		}
+	}
+	return ret, nil
+}
+
+func (r *todoUsecase) UpdateTodo(_ context.Context, dto entity.Todo, _ uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	todo := r.todos[dto.Id]
+	if dto.Version != 0 && dto.Version != todo.Version {
+		return usecase.ErrTodoVersionConflict
+	}
+	todo.Name = dto.Name
+	todo.Version++
+	r.todos[dto.Id] = todo
+	return nil
+}
+
+func (r *todoUsecase) PatchTodo(_ context.Context, todoID uint, patch entity.TodoPatch, _ uint) (*entity.Todo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	todo := r.todos[todoID]
+	if patch.Version != 0 && patch.Version != todo.Version {
+		return nil, usecase.ErrTodoVersionConflict
+	}
+	if patch.Name != nil {
+		todo.Name = *patch.Name
+	}
+	todo.Version++
+	r.todos[todoID] = todo
+	return &todo, nil
+}
+
+func (r *todoUsecase) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.todos)
+}
+
+// ownerRoles makes owners the only members of their todos.
+type ownerRoles struct{ v1.MemberUsecase }
+
+func (ownerRoles) TodoRole(_ context.Context, account entity.Account, todo entity.Todo) (entity.MemberRole, error) {
+	if todo.OwnerId == account.Id {
+		return entity.MemberRoleOwner, nil
+	}
+	return entity.MemberRoleNone, nil
+}
+
+// idempotencyStorage keeps the keys of the real usecase in memory.
+type idempotencyStorage struct {
+	usecase.IdempotencyStorage
+	mu      sync.Mutex
+	records map[string]entity.IdempotencyRecord
+}
+
+func (r *idempotencyStorage) Reserve(_ context.Context, dto entity.IdempotencyRecord) (*entity.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rec, ok := r.records[dto.Key]; ok {
+		return &rec, nil
+	}
+	r.records[dto.Key] = dto
+	return nil, nil
+}
+
+func (r *idempotencyStorage) Complete(_ context.Context, dto entity.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec := r.records[dto.Key]
+	rec.Completed, rec.StatusCode, rec.ContentType, rec.Body = true, dto.StatusCode, dto.ContentType, dto.Body
+	r.records[dto.Key] = rec
+	return nil
+}
+
+func (r *idempotencyStorage) Release(_ context.Context, _ uint, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.records, key)
+	return nil
+}
+
+type server struct {
+	*httptest.Server
+	engine   *gin.Engine
+	todos    *todoUsecase
+	sessions v1.SessionUsecase
+
+	mu       sync.Mutex
+	requests []*http.Request
+	// intercept answers the request in place of the router when it returns
+	// true, attempt counts the requests to the path so far
+	intercept func(w http.ResponseWriter, r *http.Request, attempt int) bool
+}
+
+// newServer serves alice and bob, users of org 1, and todo 1 of bob.
+func newServer(t *testing.T) *server {
+	gin.SetMode(gin.TestMode)
+	log := logger.New("error")
+	s := &server{
+		todos: &todoUsecase{todos: map[uint]entity.Todo{
+			1: {Id: 1, OwnerId: 3, Name: "of bob", Status: entity.TodoStatusDefault, Version: 1},
+		}},
+		sessions: usecase.NewSessionUsecase(session.NewSessionStorage()),
+	}
+	accounts := accountUsecase{accounts: map[string]entity.Account{
+		"alice": {Id: 2, Name: "alice", Password: "pw", AccountType: entity.AccountTypeUser},
+		"bob":   {Id: 3, Name: "bob", Password: "pw", AccountType: entity.AccountTypeUser},
+	}}
+	idempotency := usecase.NewIdempotencyUsecase(log, &idempotencyStorage{records: map[string]entity.IdempotencyRecord{}}, time.Hour)
+
+	e := gin.New()
+	s.engine = e
+	v1.NewRouter(e, log, accounts, s.todos, nil, ownerRoles{}, orgUsecase{}, nil, nil, nil, nil, nil, nil, s.sessions,
+		idempotency, nil)
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		attempt := 0
+		for _, req := range s.requests {
+			if req.URL.Path == r.URL.Path {
+				attempt++
+			}
+		}
+		s.requests = append(s.requests, r)
+		intercept := s.intercept
+		s.mu.Unlock()
+		if intercept != nil && intercept(w, r, attempt) {
+			return
+		}
+		e.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *server) setIntercept(intercept func(w http.ResponseWriter, r *http.Request, attempt int) bool) {
+	s.mu.Lock()
+	s.intercept = intercept
+	s.mu.Unlock()
+}
+
+// sent returns the requests to path.
+func (s *server) sent(path string) []*http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ret []*http.Request
+	for _, r := range s.requests {
+		if r.URL.Path == path {
+			ret = append(ret, r)
+		}
+	}
+	return ret
+}
+
+func TestClientLogin(t *testing.T) {
+	s := newServer(t)
+	c := client.New(s.URL, client.Credentials("alice", "pw"))
+	ctx := context.Background()
+
+	id, err := c.CreateTodo(ctx, client.CreateTodoRequest{Name: "first", Desc: "desc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := c.Token()
+	if token == "" || len(s.sent("/v1/login")) != 1 {
+		t.Fatalf("token %q after %d logins, want one login", token, len(s.sent("/v1/login")))
+	}
+
+	// the session ends, like with a restart of the server
+	s.sessions.Delete(token)
+	todo, err := c.GetTodo(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if todo.Name != "first" || c.Token() == token || len(s.sent("/v1/login")) != 2 {
+		t.Errorf("todo %q, token renewed %v, %d logins, want the todo after a second login", todo.Name,
+			c.Token() != token, len(s.sent("/v1/login")))
+	}
+
+	if _, err := client.New(s.URL).GetTodos(ctx); !errors.Is(err, client.ErrUnauthenticated) {
+		t.Errorf("without credentials: %v, want ErrUnauthenticated", err)
+	}
+	if err := client.New(s.URL, client.Credentials("alice", "wrong")).Login(ctx); err == nil {
+		t.Errorf("wrong password: logged in")
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	s := newServer(t)
+	c := client.New(s.URL, client.Credentials("bob", "pw"), client.Retries(0))
+	alice := client.New(s.URL, client.Credentials("alice", "pw"), client.Retries(0))
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{"invalid argument", func() error {
+			_, err := c.CreateTodo(ctx, client.CreateTodoRequest{Name: "no desc"})
+			return err
+		}, client.ErrInvalidArgument},
+		{"internal", func() error {
+			_, err := c.GetTodo(ctx, 99)
+			return err
+		}, client.ErrInternal},
+		{"no access", func() error {
+			_, err := alice.GetTodo(ctx, 1)
+			return err
+		}, client.ErrNoAccess},
+		{"conflict", func() error {
+			return c.UpdateTodo(ctx, client.UpdateTodoRequest{Id: 1, Name: "x", Version: 7})
+		}, client.ErrConflict},
+		{"precondition failed", func() error {
+			_, err := c.PatchTodo(ctx, 1, client.MergePatchType, map[string]string{"name": "x"}, 7)
+			return err
+		}, client.ErrPreconditionFailed},
+	}
+	for _, tt := range tests {
+		err := tt.call()
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	// a conflict carries the current todo
+	err := c.UpdateTodo(ctx, client.UpdateTodoRequest{Id: 1, Name: "x", Version: 7})
+	var e *client.Error
+	var todo client.Todo
+	if !errors.As(err, &e) || e.Decode(&todo) != nil || todo.Id != 1 || todo.Version != 1 {
+		t.Errorf("conflict: %v, data %s, want todo 1 at version 1", err, e.Data)
+	}
+}
+
+func TestClientRetry(t *testing.T) {
+	s := newServer(t)
+	c := client.New(s.URL, client.Credentials("alice", "pw"), client.Retries(2), client.Backoff(time.Millisecond, 2*time.Millisecond))
+	ctx := context.Background()
+
+	// the first create goes through but its response is lost
+	s.setIntercept(func(w http.ResponseWriter, r *http.Request, attempt int) bool {
+		if r.URL.Path != "/v1/todo" || attempt > 0 {
+			return false
+		}
+		s.engine.ServeHTTP(httptest.NewRecorder(), r)
+		w.WriteHeader(http.StatusBadGateway)
+		return true
+	})
+	id, err := c.CreateTodo(ctx, client.CreateTodoRequest{Name: "once", Desc: "desc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := s.sent("/v1/todo")
+	if len(sent) != 2 || s.todos.count() != 2 || id != 2 {
+		t.Fatalf("%d requests, %d todos, id %d, want a retry answered with todo 2", len(sent), s.todos.count(), id)
+	}
+	if key := sent[0].Header.Get(v1.HeaderIdempotencyKey); key == "" || sent[1].Header.Get(v1.HeaderIdempotencyKey) != key {
+		t.Errorf("Idempotency-Keys %q and %q, want the same key", key, sent[1].Header.Get(v1.HeaderIdempotencyKey))
+	}
+
+	// an unavailable server is tried once more per retry
+	s.setIntercept(func(w http.ResponseWriter, r *http.Request, _ int) bool {
+		if r.URL.Path != "/v1/todos" {
+			return false
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return true
+	})
+	_, err = c.GetTodos(ctx)
+	var e *client.Error
+	if !errors.As(err, &e) || e.Status != http.StatusServiceUnavailable || len(s.sent("/v1/todos")) != 3 {
+		t.Errorf("unavailable: %v after %d requests, want status %d after 3", err, len(s.sent("/v1/todos")),
+			http.StatusServiceUnavailable)
+	}
+
+	// error codes are final
+	if _, err := c.GetTodo(ctx, 1); !errors.Is(err, client.ErrNoAccess) || len(s.sent("/v1/todo")) != 3 {
+		t.Errorf("no access: %v after %d requests, want one request", err, len(s.sent("/v1/todo"))-2)
+	}
+
+	// the server does not replay uploads, they may have gone through
+	s.setIntercept(func(w http.ResponseWriter, _ *http.Request, _ int) bool {
+		w.WriteHeader(http.StatusBadGateway)
+		return true
+	})
+	uploads := map[string]func() error{
+		"/v1/todos/1/attachments": func() error {
+			_, err := c.UploadAttachment(ctx, 1, "notes.txt", strings.NewReader("notes"))
+			return err
+		},
+		"/v1/todos/import": func() error {
+			_, err := c.ImportTodos(ctx, client.ImportTodoRequest{Format: "csv"}, strings.NewReader("name,desc\nx,y\n"))
+			return err
+		},
+		"/v1/calendar/import": func() error {
+			_, err := c.ImportCalendar(ctx, client.ImportCalendarRequest{}, strings.NewReader("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"))
+			return err
+		},
+	}
+	for path, upload := range uploads {
+		err := upload()
+		if sent := s.sent(path); err == nil || len(sent) != 1 || sent[0].Header.Get(v1.HeaderIdempotencyKey) != "" {
+			t.Errorf("%s: %v after %d requests, want one request without an Idempotency-Key", path, err, len(sent))
+		}
+	}
+}
+
+func TestClientCancel(t *testing.T) {
+	s := newServer(t)
+	c := client.New(s.URL, client.Credentials("alice", "pw"), client.Backoff(time.Hour, time.Hour))
+	if err := c.Login(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// canceled while waiting to retry
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.setIntercept(func(w http.ResponseWriter, r *http.Request, _ int) bool {
+		if r.URL.Path != "/v1/todos" {
+			return false
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		time.AfterFunc(10*time.Millisecond, cancel)
+		return true
+	})
+	start := time.Now()
+	if _, err := c.GetTodos(ctx); err == nil || time.Since(start) > 5*time.Second || len(s.sent("/v1/todos")) != 1 {
+		t.Errorf("backoff: %v after %v and %d requests, want an error before the retry", err, time.Since(start),
+			len(s.sent("/v1/todos")))
+	}
+
+	// canceled while waiting for the response, which is not retried
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	s.setIntercept(func(w http.ResponseWriter, r *http.Request, _ int) bool {
+		if r.URL.Path != "/v1/todo" {
+			return false
+		}
+		<-r.Context().Done()
+		return true
+	})
+	if _, err := c.GetTodo(ctx, 1); !errors.Is(err, context.DeadlineExceeded) || len(s.sent("/v1/todo")) != 1 {
+		t.Errorf("slow server: %v after %d requests, want the deadline after one", err, len(s.sent("/v1/todo")))
+	}
+}
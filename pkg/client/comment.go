@@ -0,0 +1,97 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+)
+
+// Download is a file for the caller to read and close.
+type Download struct {
+	Body        io.ReadCloser
+	Name        string
+	ContentType string
+	Size        int64
+}
+
+func (c *Client) GetComments(ctx context.Context, todoID uint, req GetCommentsRequest) (*CommentPage, error) {
+	var ret CommentPage
+	if err := c.call(ctx, &request{method: http.MethodGet, path: todoPath(todoID) + "/comments", query: req,
+		auth: true, retry: true}, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+func (c *Client) CreateComment(ctx context.Context, todoID uint, body string) (uint, error) {
+	var ret idResponse
+	err := c.call(ctx, &request{method: http.MethodPost, path: todoPath(todoID) + "/comments",
+		body: CreateCommentRequest{Body: body}, auth: true}, &ret)
+	return ret.Id, err
+}
+
+func (c *Client) EditComment(ctx context.Context, todoID uint, commentID uint, body string) error {
+	return c.call(ctx, &request{method: http.MethodPut, path: commentPath(todoID, commentID),
+		body: EditCommentRequest{Body: body}, auth: true, retry: true}, nil)
+}
+
+func (c *Client) DeleteComment(ctx context.Context, todoID uint, commentID uint) error {
+	return c.call(ctx, &request{method: http.MethodDelete, path: commentPath(todoID, commentID), auth: true}, nil)
+}
+
+func commentPath(todoID uint, commentID uint) string {
+	return todoPath(todoID) + "/comments/" + strconv.FormatUint(uint64(commentID), 10)
+}
+
+func (c *Client) GetAttachments(ctx context.Context, todoID uint) ([]Attachment, error) {
+	var ret []Attachment
+	err := c.call(ctx, &request{method: http.MethodGet, path: todoPath(todoID) + "/attachments", auth: true, retry: true}, &ret)
+	return ret, err
+}
+
+// UploadAttachment attaches the file read from r as name. A failed call is
+// not retried, it may have attached the file.
+func (c *Client) UploadAttachment(ctx context.Context, todoID uint, name string, r io.Reader) (uint, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	part, err := w.CreateFormFile("file", name)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := io.Copy(part, r); err != nil {
+		return 0, err
+	}
+	if err := w.Close(); err != nil {
+		return 0, err
+	}
+
+	var ret idResponse
+	err = c.call(ctx, &request{method: http.MethodPost, path: todoPath(todoID) + "/attachments", body: buf.Bytes(),
+		contentType: w.FormDataContentType(), auth: true, upload: true}, &ret)
+	return ret.Id, err
+}
+
+func (c *Client) DownloadAttachment(ctx context.Context, todoID uint, attachmentID uint) (*Download, error) {
+	resp, err := c.do(ctx, &request{method: http.MethodGet, path: attachmentPath(todoID, attachmentID),
+		auth: true, retry: true}, nil)
+	if err != nil {
+		return nil, err
+	}
+	ret := &Download{Body: resp.Body, ContentType: resp.Header.Get("Content-Type"), Size: resp.ContentLength}
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		ret.Name = params["filename"]
+	}
+	return ret, nil
+}
+
+func (c *Client) DeleteAttachment(ctx context.Context, todoID uint, attachmentID uint) error {
+	return c.call(ctx, &request{method: http.MethodDelete, path: attachmentPath(todoID, attachmentID), auth: true}, nil)
+}
+
+func attachmentPath(todoID uint, attachmentID uint) string {
+	return todoPath(todoID) + "/attachments/" + strconv.FormatUint(uint64(attachmentID), 10)
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrCode is the code of a ResponseMessage.
+type ErrCode int
+
+const (
+	ErrCodeNone ErrCode = iota
+	ErrCodeInvalidArgument
+	ErrCodeInternal
+	ErrCodeUnauthenticated
+	ErrCodeNoAccess
+	ErrCodeConflict
+	ErrCodePreconditionFailed
+)
+
+// Errors of the codes, an *Error unwraps to the one of its code so callers
+// can use errors.Is.
+var (
+	ErrInvalidArgument    = errors.New("invalid argument")
+	ErrInternal           = errors.New("internal error")
+	ErrUnauthenticated    = errors.New("unauthenticated")
+	ErrNoAccess           = errors.New("no access")
+	ErrConflict           = errors.New("conflict")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrEventsReset means the events since the id to resume from expired,
+	// the caller has to reload.
+	ErrEventsReset = errors.New("events reset")
+)
+
+var _codeErrors = map[ErrCode]error{
+	ErrCodeInvalidArgument:    ErrInvalidArgument,
+	ErrCodeInternal:           ErrInternal,
+	ErrCodeUnauthenticated:    ErrUnauthenticated,
+	ErrCodeNoAccess:           ErrNoAccess,
+	ErrCodeConflict:           ErrConflict,
+	ErrCodePreconditionFailed: ErrPreconditionFailed,
+}
+
+// Error is a call answered with an error code or, without a ResponseMessage,
+// an error status.
+type Error struct {
+	Method  string
+	Path    string
+	Status  int
+	Code    ErrCode
+	Message string
+	// Data is the payload of the ResponseMessage, like the current todo of a
+	// conflict, or the body of another json response.
+	Data json.RawMessage
+}
+
+func (e *Error) Error() string {
+	if e.Code == ErrCodeNone {
+		return fmt.Sprintf("client - %s %s: status %d %s", e.Method, e.Path, e.Status, e.Message)
+	}
+	return fmt.Sprintf("client - %s %s: code %d %s", e.Method, e.Path, e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return _codeErrors[e.Code]
+}
+
+// Decode unmarshals Data into v.
+func (e *Error) Decode(v interface{}) error {
+	if len(e.Data) == 0 {
+		return errors.New("no data")
+	}
+	return json.Unmarshal(e.Data, v)
+}
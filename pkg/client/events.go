@@ -0,0 +1,100 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"testcode/test3/internal/controller/http/dto"
+)
+
+const _eventReset = "reset"
+
+// EventStream reads the events of Events until closed.
+type EventStream struct {
+	body        io.ReadCloser
+	r           *bufio.Reader
+	lastEventID uint64
+}
+
+// Events streams the changes to the todos the caller can view, resuming
+// after lastEventID unless it is zero. The server ends the stream of a
+// client falling behind, Next returns io.EOF then and the caller resumes
+// with LastEventId.
+func (c *Client) Events(ctx context.Context, lastEventID uint64) (*EventStream, error) {
+	resp, err := c.do(ctx, &request{method: http.MethodGet, path: "/v1/events",
+		query: dto.EventsRequest{LastEventId: lastEventID}, auth: true, retry: true, stream: true}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &EventStream{body: resp.Body, r: bufio.NewReader(resp.Body), lastEventID: lastEventID}, nil
+}
+
+// Next waits for the next event. ErrEventsReset means the events since the
+// id to resume from have expired, the caller has to reload and can go on
+// reading.
+func (s *EventStream) Next() (TodoEvent, error) {
+	var (
+		id, event string
+		data      []string
+	)
+	for {
+		line, err := s.r.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				err = io.EOF
+			}
+			return TodoEvent{}, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			if event == _eventReset {
+				return TodoEvent{}, ErrEventsReset
+			}
+			if data == nil {
+				// retry only
+				continue
+			}
+			var ret TodoEvent
+			if err := json.Unmarshal([]byte(strings.Join(data, "\n")), &ret); err != nil {
+				return TodoEvent{}, err
+			}
+			if n, err := strconv.ParseUint(id, 10, 64); err == nil {
+				s.lastEventID = n
+			}
+			return ret, nil
+		}
+		if strings.HasPrefix(line, ":") {
+			// heartbeat
+			continue
+		}
+
+		field, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "id":
+			id = value
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+}
+
+// LastEventId is the id to resume from.
+func (s *EventStream) LastEventId() uint64 {
+	return s.lastEventID
+}
+
+func (s *EventStream) Close() error {
+	return s.body.Close()
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// GraphQLError is an error of a GraphQL response.
+type GraphQLError struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+// GraphQLErrors are the errors of a GraphQL response.
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	if len(e) == 1 {
+		return "client - graphql: " + e[0].Message
+	}
+	return fmt.Sprintf("client - graphql: %s and %d more errors", e[0].Message, len(e)-1)
+}
+
+type graphqlRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors GraphQLErrors   `json:"errors"`
+}
+
+// GraphQL runs the query and decodes its data into out. Errors of the
+// response are returned as GraphQLErrors after decoding what data there is.
+func (c *Client) GraphQL(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	req := &request{method: http.MethodPost, path: "/graphql", body: graphqlRequest{Query: query, Variables: variables},
+		auth: true}
+	resp, err := c.do(ctx, req, nil)
+	var e *Error
+	if errors.As(err, &e) && e.Status == http.StatusBadRequest {
+		// queries rejected before running
+		var ret graphqlResponse
+		if json.Unmarshal(e.Data, &ret) == nil && len(ret.Errors) > 0 {
+			return ret.Errors
+		}
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var ret graphqlResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ret); err != nil {
+		return err
+	}
+	if out != nil && len(ret.Data) > 0 && string(ret.Data) != "null" {
+		if err := json.Unmarshal(ret.Data, out); err != nil {
+			return err
+		}
+	}
+	if len(ret.Errors) > 0 {
+		return ret.Errors
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"net/http"
+	"time"
+)
+
+// Option -.
+type Option func(*Client)
+
+// Credentials are used to log in before the first call and again when the
+// token is rejected.
+func Credentials(name string, password string) Option {
+	return func(c *Client) {
+		c.name = name
+		c.password = password
+	}
+}
+
+// OrgID is the org to log in to, required for accounts in more than one org.
+func OrgID(orgID uint) Option {
+	return func(c *Client) {
+		c.orgID = orgID
+	}
+}
+
+// Token is a session token to start with.
+func Token(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// HTTPClient -.
+func HTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.http = httpClient
+	}
+}
+
+// Retries is how often a failed call is retried, zero disables retrying.
+func Retries(retries int) Option {
+	return func(c *Client) {
+		c.retries = retries
+	}
+}
+
+// Backoff is the wait before the first retry, it doubles with every retry
+// up to max.
+func Backoff(min time.Duration, max time.Duration) Option {
+	return func(c *Client) {
+		c.backoffMin = min
+		c.backoffMax = max
+	}
+}
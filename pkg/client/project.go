@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"testcode/test3/internal/controller/http/dto"
+)
+
+func (c *Client) GetProjects(ctx context.Context) ([]Project, error) {
+	var ret []Project
+	err := c.call(ctx, &request{method: http.MethodGet, path: "/v1/projects", auth: true, retry: true}, &ret)
+	return ret, err
+}
+
+func (c *Client) GetProject(ctx context.Context, projectID uint) (*Project, error) {
+	var ret Project
+	req := &request{method: http.MethodGet, path: "/v1/project", query: dto.GetProjectRequest{Id: projectID},
+		auth: true, retry: true}
+	if err := c.call(ctx, req, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+func (c *Client) GetProjectTodos(ctx context.Context, projectID uint) ([]Todo, error) {
+	var ret []Todo
+	req := &request{method: http.MethodGet, path: "/v1/project/todos", query: dto.GetProjectRequest{Id: projectID},
+		auth: true, retry: true}
+	err := c.call(ctx, req, &ret)
+	return ret, err
+}
+
+func (c *Client) CreateProject(ctx context.Context, req CreateProjectRequest) (uint, error) {
+	var ret idResponse
+	err := c.call(ctx, &request{method: http.MethodPost, path: "/v1/project", body: req, auth: true}, &ret)
+	return ret.Id, err
+}
+
+func (c *Client) UpdateProject(ctx context.Context, req UpdateProjectRequest) error {
+	return c.call(ctx, &request{method: http.MethodPut, path: "/v1/project", body: req, auth: true, retry: true}, nil)
+}
+
+func (c *Client) ArchiveProject(ctx context.Context, req ArchiveProjectRequest) error {
+	return c.call(ctx, &request{method: http.MethodPut, path: "/v1/project/archive", body: req, auth: true, retry: true}, nil)
+}
+
+func (c *Client) DeleteProject(ctx context.Context, projectID uint) error {
+	return c.call(ctx, &request{method: http.MethodDelete, path: "/v1/project", body: dto.DeleteProjectRequest{Id: projectID},
+		auth: true}, nil)
+}
+
+func (c *Client) GetMembers(ctx context.Context, req GetMembersRequest) ([]Member, error) {
+	var ret []Member
+	err := c.call(ctx, &request{method: http.MethodGet, path: "/v1/members", query: req, auth: true, retry: true}, &ret)
+	return ret, err
+}
+
+// GetInvites returns the invites of the caller that wait to be accepted.
+func (c *Client) GetInvites(ctx context.Context) ([]Member, error) {
+	var ret []Member
+	err := c.call(ctx, &request{method: http.MethodGet, path: "/v1/invites", auth: true, retry: true}, &ret)
+	return ret, err
+}
+
+func (c *Client) InviteMember(ctx context.Context, req InviteMemberRequest) (uint, error) {
+	var ret idResponse
+	err := c.call(ctx, &request{method: http.MethodPost, path: "/v1/member", body: req, auth: true}, &ret)
+	return ret.Id, err
+}
+
+func (c *Client) AcceptInvite(ctx context.Context, memberID uint) error {
+	return c.call(ctx, &request{method: http.MethodPost, path: "/v1/member/accept", body: dto.AcceptInviteRequest{Id: memberID},
+		auth: true}, nil)
+}
+
+func (c *Client) RemoveMember(ctx context.Context, memberID uint) error {
+	return c.call(ctx, &request{method: http.MethodDelete, path: "/v1/member", body: dto.RemoveMemberRequest{Id: memberID},
+		auth: true}, nil)
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _timeType = reflect.TypeOf(time.Time{})
+
+// encodeQuery turns the form tagged fields of the request struct into query
+// parameters the way gin binds them, zero values are left out.
+func encodeQuery(req interface{}) url.Values {
+	ret := url.Values{}
+	if req == nil {
+		return ret
+	}
+	v := reflect.Indirect(reflect.ValueOf(req))
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("form"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		fv := v.Field(i)
+		if fv.IsZero() {
+			continue
+		}
+		switch {
+		case f.Type == _timeType:
+			layout := f.Tag.Get("time_format")
+			if layout == "" {
+				layout = time.RFC3339
+			}
+			ret.Set(name, fv.Interface().(time.Time).Format(layout))
+		case fv.Kind() == reflect.String:
+			ret.Set(name, fv.String())
+		case fv.Kind() == reflect.Bool:
+			ret.Set(name, strconv.FormatBool(fv.Bool()))
+		case fv.Kind() >= reflect.Int && fv.Kind() <= reflect.Int64:
+			ret.Set(name, strconv.FormatInt(fv.Int(), 10))
+		case fv.Kind() >= reflect.Uint && fv.Kind() <= reflect.Uint64:
+			ret.Set(name, strconv.FormatUint(fv.Uint(), 10))
+		}
+	}
+	return ret
+}
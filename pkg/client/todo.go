@@ -0,0 +1,207 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"testcode/test3/internal/controller/http/dto"
+	"testcode/test3/pkg/jsonpatch"
+)
+
+// Patch types of PatchTodo.
+const (
+	MergePatchType = jsonpatch.MergePatchType
+	JSONPatchType  = jsonpatch.JSONPatchType
+)
+
+var _importTypes = map[string]string{
+	"csv":      "text/csv",
+	"json":     "application/json",
+	"ndjson":   "application/x-ndjson",
+	"todotxt":  "text/plain",
+	"markdown": "text/markdown",
+}
+
+func todoPath(todoID uint) string {
+	return "/v1/todos/" + strconv.FormatUint(uint64(todoID), 10)
+}
+
+func (c *Client) GetTodos(ctx context.Context) ([]Todo, error) {
+	var ret []Todo
+	err := c.call(ctx, &request{method: http.MethodGet, path: "/v1/todos", auth: true, retry: true}, &ret)
+	return ret, err
+}
+
+// GetTodo returns ErrInternal for a todo that does not exist.
+func (c *Client) GetTodo(ctx context.Context, todoID uint) (*Todo, error) {
+	var ret Todo
+	req := &request{method: http.MethodGet, path: "/v1/todo", query: dto.GetTodoRequest{Id: todoID}, auth: true, retry: true}
+	if err := c.call(ctx, req, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+func (c *Client) CreateTodo(ctx context.Context, req CreateTodoRequest) (uint, error) {
+	var ret idResponse
+	err := c.call(ctx, &request{method: http.MethodPost, path: "/v1/todo", body: req, auth: true}, &ret)
+	return ret.Id, err
+}
+
+// UpdateTodo answers ErrConflict with the current todo as Data when
+// req.Version is set and outdated.
+func (c *Client) UpdateTodo(ctx context.Context, req UpdateTodoRequest) error {
+	return c.call(ctx, &request{method: http.MethodPut, path: "/v1/todo", body: req, auth: true, retry: true}, nil)
+}
+
+// PatchTodo applies a patch of patchType, a non zero version makes it
+// conditional and answers ErrPreconditionFailed when outdated.
+func (c *Client) PatchTodo(ctx context.Context, todoID uint, patchType string, patch interface{}, version uint) (*Todo, error) {
+	body, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	var ret Todo
+	req := &request{method: http.MethodPatch, path: todoPath(todoID), body: body, contentType: patchType,
+		ifMatch: version, auth: true}
+	if err := c.call(ctx, req, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// DeleteTodo deletes the todo, a non zero version makes it conditional.
+func (c *Client) DeleteTodo(ctx context.Context, todoID uint, version uint) error {
+	req := &request{method: http.MethodDelete, path: "/v1/todo", body: dto.DeleteTodoRequest{Id: todoID}, ifMatch: version, auth: true}
+	return c.call(ctx, req, nil)
+}
+
+// BulkTodos answers ErrConflict for a failed atomic request, Data holds the
+// result of the failed operation as {"results": [...]}.
+func (c *Client) BulkTodos(ctx context.Context, req BulkTodoRequest) ([]TodoBulkResult, error) {
+	var ret struct {
+		Results []TodoBulkResult `json:"results"`
+	}
+	err := c.call(ctx, &request{method: http.MethodPost, path: "/v1/todos/bulk", body: req, auth: true}, &ret)
+	return ret.Results, err
+}
+
+// ExportTodos returns the export in format, json by default, for the caller
+// to read and close.
+func (c *Client) ExportTodos(ctx context.Context, format string) (io.ReadCloser, error) {
+	req := &request{method: http.MethodGet, path: "/v1/todos/export", query: dto.ExportTodoRequest{Format: format}, auth: true, retry: true}
+	resp, err := c.do(ctx, req, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// ImportTodos imports the file in req.Format read from r. A failed call is
+// not retried, it may have imported the todos.
+func (c *Client) ImportTodos(ctx context.Context, req ImportTodoRequest, r io.Reader) (*TodoImportReport, error) {
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	contentType, ok := _importTypes[req.Format]
+	if !ok {
+		contentType = "application/octet-stream"
+	}
+	var ret TodoImportReport
+	if err := c.call(ctx, &request{method: http.MethodPost, path: "/v1/todos/import", query: req, body: body,
+		contentType: contentType, auth: true, upload: true}, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+func (c *Client) SearchTodos(ctx context.Context, req SearchTodoRequest) ([]TodoSearchHit, error) {
+	var ret []TodoSearchHit
+	err := c.call(ctx, &request{method: http.MethodGet, path: "/v1/todos/search", query: req, auth: true, retry: true}, &ret)
+	return ret, err
+}
+
+// FilterTodos answers ErrInvalidArgument with {"pos": n} as Data for a query
+// that does not parse.
+func (c *Client) FilterTodos(ctx context.Context, req FilterTodoRequest) ([]Todo, error) {
+	var ret []Todo
+	err := c.call(ctx, &request{method: http.MethodGet, path: "/v1/todos/filter", query: req, auth: true, retry: true}, &ret)
+	return ret, err
+}
+
+func (c *Client) GetSavedFilters(ctx context.Context) ([]SavedFilter, error) {
+	var ret []SavedFilter
+	err := c.call(ctx, &request{method: http.MethodGet, path: "/v1/filters", auth: true, retry: true}, &ret)
+	return ret, err
+}
+
+func (c *Client) SaveFilter(ctx context.Context, req SaveFilterRequest) (uint, error) {
+	var ret idResponse
+	err := c.call(ctx, &request{method: http.MethodPost, path: "/v1/filter", body: req, auth: true}, &ret)
+	return ret.Id, err
+}
+
+func (c *Client) DeleteSavedFilter(ctx context.Context, filterID uint) error {
+	return c.call(ctx, &request{method: http.MethodDelete, path: "/v1/filter", body: dto.DeleteSavedFilterRequest{Id: filterID}, auth: true}, nil)
+}
+
+func (c *Client) GetAssignedTodos(ctx context.Context) ([]Todo, error) {
+	var ret []Todo
+	err := c.call(ctx, &request{method: http.MethodGet, path: "/v1/todos/assigned", auth: true, retry: true}, &ret)
+	return ret, err
+}
+
+func (c *Client) AssignTodo(ctx context.Context, req AssignTodoRequest) error {
+	return c.call(ctx, &request{method: http.MethodPost, path: "/v1/todo/assignee", body: req, auth: true}, nil)
+}
+
+func (c *Client) UnassignTodo(ctx context.Context, req UnassignTodoRequest) error {
+	return c.call(ctx, &request{method: http.MethodDelete, path: "/v1/todo/assignee", body: req, auth: true}, nil)
+}
+
+// MoveTodo moves the todo to req.ProjectId, zero takes it out of its
+// project. A non zero version makes it conditional.
+func (c *Client) MoveTodo(ctx context.Context, req MoveTodoRequest, version uint) error {
+	return c.call(ctx, &request{method: http.MethodPut, path: "/v1/todo/project", body: req, ifMatch: version,
+		auth: true, retry: true}, nil)
+}
+
+func (c *Client) GetTodoHistory(ctx context.Context, todoID uint) ([]TodoRevision, error) {
+	var ret []TodoRevision
+	err := c.call(ctx, &request{method: http.MethodGet, path: todoPath(todoID) + "/history", auth: true, retry: true}, &ret)
+	return ret, err
+}
+
+// RestoreRevision sets the todo back to the revision, a non zero version
+// makes it conditional.
+func (c *Client) RestoreRevision(ctx context.Context, todoID uint, revisionID uint, version uint) error {
+	path := todoPath(todoID) + "/history/" + strconv.FormatUint(uint64(revisionID), 10) + "/restore"
+	return c.call(ctx, &request{method: http.MethodPost, path: path, ifMatch: version, auth: true}, nil)
+}
+
+func (c *Client) GetTrashedTodos(ctx context.Context) ([]Todo, error) {
+	var ret []Todo
+	err := c.call(ctx, &request{method: http.MethodGet, path: "/v1/trash/todos", auth: true, retry: true}, &ret)
+	return ret, err
+}
+
+func (c *Client) RestoreTrashedTodo(ctx context.Context, todoID uint) error {
+	return c.call(ctx, &request{method: http.MethodPost, path: "/v1/trash/todo/restore", body: dto.RestoreTodoRequest{Id: todoID},
+		auth: true}, nil)
+}
+
+func (c *Client) GetTrashedAccounts(ctx context.Context) ([]Account, error) {
+	var ret []Account
+	err := c.call(ctx, &request{method: http.MethodGet, path: "/v1/trash/accounts", auth: true, retry: true}, &ret)
+	return ret, err
+}
+
+func (c *Client) RestoreTrashedAccount(ctx context.Context, accountID uint) error {
+	return c.call(ctx, &request{method: http.MethodPost, path: "/v1/trash/account/restore", body: dto.RestoreAccountRequest{Id: accountID},
+		auth: true}, nil)
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"testcode/test3/internal/controller/http/dto"
+	"testcode/test3/internal/domain/entity"
+)
+
+// The types are the ones the server speaks, aliased so callers outside this
+// module can name them.
+type (
+	Account               = entity.Account
+	AccountType           = entity.AccountType
+	AccountDeletionReport = entity.AccountDeletionReport
+	Attachment            = entity.Attachment
+	AuditEntry            = entity.AuditEntry
+	Comment               = entity.Comment
+	Member                = entity.Member
+	MemberResource        = entity.MemberResource
+	MemberRole            = entity.MemberRole
+	Org                   = entity.Org
+	Project               = entity.Project
+	SavedFilter           = entity.SavedFilter
+	Todo                  = entity.Todo
+	TodoBulkResult        = entity.TodoBulkResult
+	TodoEvent             = entity.TodoEvent
+	TodoEventType         = entity.TodoEventType
+	TodoImportReport      = entity.TodoImportReport
+	TodoRevision          = entity.TodoRevision
+	TodoSearchHit         = entity.TodoSearchHit
+	TodoStatus            = entity.TodoStatus
+
+	LoginRequest            = dto.LoginRequest
+	CreateAccountRequest    = dto.CreateAccountRequest
+	DeleteAccountRequest    = dto.DeleteAccountRequest
+	GetAuditRequest         = dto.GetAuditRequest
+	CreateOrgRequest        = dto.CreateOrgRequest
+	AddOrgAccountRequest    = dto.AddOrgAccountRequest
+	RemoveOrgAccountRequest = dto.RemoveOrgAccountRequest
+	CreateTodoRequest       = dto.CreateTodoRequest
+	UpdateTodoRequest       = dto.UpdateTodoRequest
+	AssignTodoRequest       = dto.AssignTodoRequest
+	UnassignTodoRequest     = dto.UnassignTodoRequest
+	MoveTodoRequest         = dto.MoveTodoRequest
+	SearchTodoRequest       = dto.SearchTodoRequest
+	FilterTodoRequest       = dto.FilterTodoRequest
+	SaveFilterRequest       = dto.SaveFilterRequest
+	BulkTodoOperation       = dto.BulkTodoOperation
+	BulkTodoRequest         = dto.BulkTodoRequest
+	ImportTodoRequest       = dto.ImportTodoRequest
+	GetCommentsRequest      = dto.GetCommentsRequest
+	CreateCommentRequest    = dto.CreateCommentRequest
+	EditCommentRequest      = dto.EditCommentRequest
+	ImportCalendarRequest   = dto.ImportCalendarRequest
+	CreateProjectRequest    = dto.CreateProjectRequest
+	UpdateProjectRequest    = dto.UpdateProjectRequest
+	ArchiveProjectRequest   = dto.ArchiveProjectRequest
+	GetMembersRequest       = dto.GetMembersRequest
+	InviteMemberRequest     = dto.InviteMemberRequest
+)
+
+const (
+	AccountTypeAdmin      = entity.AccountTypeAdmin
+	AccountTypeUser       = entity.AccountTypeUser
+	AccountTypeSuperAdmin = entity.AccountTypeSuperAdmin
+
+	MemberRoleViewer = entity.MemberRoleViewer
+	MemberRoleEditor = entity.MemberRoleEditor
+	MemberRoleOwner  = entity.MemberRoleOwner
+
+	MemberResourceProject = entity.MemberResourceProject
+	MemberResourceTodo    = entity.MemberResourceTodo
+
+	TodoStatusDefault = entity.TodoStatusDefault
+	TodoStatusDone    = entity.TodoStatusDone
+
+	TodoEventCreated = entity.TodoEventCreated
+	TodoEventUpdated = entity.TodoEventUpdated
+	TodoEventDeleted = entity.TodoEventDeleted
+)
+
+// AuditPage is a page of GetAudit.
+type AuditPage struct {
+	Items []AuditEntry `json:"items"`
+	Total uint         `json:"total"`
+	Page  uint         `json:"page"`
+	Limit uint         `json:"limit"`
+}
+
+// CommentPage is a page of GetComments.
+type CommentPage struct {
+	Items []Comment `json:"items"`
+	Total uint      `json:"total"`
+	Page  uint      `json:"page"`
+	Limit uint      `json:"limit"`
+}
+
+// CalendarToken is the credential of the calendar feed at Url.
+type CalendarToken struct {
+	Token string `json:"token"`
+	Url   string `json:"url"`
+}
+
+type idResponse struct {
+	Id uint `json:"id"`
+}
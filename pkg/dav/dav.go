@@ -0,0 +1,185 @@
+// Package dav reads WebDAV and CalDAV request bodies and writes multistatus
+// responses, the resources behind them are up to the caller.
+package dav
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	NS          = "DAV:"
+	CalDAVNS    = "urn:ietf:params:xml:ns:caldav"
+	CalServerNS = "http://calendarserver.org/ns/"
+)
+
+// _prefixes are used in responses, other namespaces are declared inline.
+var _prefixes = map[string]string{NS: "d", CalDAVNS: "c", CalServerNS: "cs"}
+
+// PropNames are the names of the children of a prop element.
+type PropNames []xml.Name
+
+func (p *PropNames) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			*p = append(*p, t.Name)
+			if err = d.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// Propfind is the PROPFIND body, Prop is nil for allprop or an empty body.
+type Propfind struct {
+	XMLName xml.Name   `xml:"DAV: propfind"`
+	Prop    *PropNames `xml:"DAV: prop"`
+}
+
+// CompFilter is the CalDAV comp-filter, nested for subcomponents.
+type CompFilter struct {
+	Name        string       `xml:"name,attr"`
+	CompFilters []CompFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+// Report is the body of a calendar-query or calendar-multiget REPORT.
+type Report struct {
+	XMLName xml.Name
+	Prop    *PropNames `xml:"DAV: prop"`
+	Hrefs   []string   `xml:"DAV: href"`
+	Filter  *struct {
+		CompFilter CompFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	} `xml:"urn:ietf:params:xml:ns:caldav filter"`
+}
+
+// ReadPropfind parses the body, an empty body means allprop.
+func ReadPropfind(r io.Reader) (*Propfind, error) {
+	ret := &Propfind{}
+	if err := xml.NewDecoder(r).Decode(ret); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("dav - ReadPropfind: %w", err)
+	}
+	return ret, nil
+}
+
+func ReadReport(r io.Reader) (*Report, error) {
+	ret := &Report{}
+	if err := xml.NewDecoder(r).Decode(ret); err != nil {
+		return nil, fmt.Errorf("dav - ReadReport: %w", err)
+	}
+	return ret, nil
+}
+
+// Prop is a property with its already encoded XML content.
+type Prop struct {
+	Name  xml.Name
+	Inner string
+}
+
+// Response holds the found properties of href and the names of the missing
+// ones, Status replaces both for a missing resource.
+type Response struct {
+	Href    string
+	Props   []Prop
+	Missing []xml.Name
+	Status  int
+}
+
+// Text escapes character data.
+func Text(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// Element renders an empty element or one holding inner XML.
+func Element(name xml.Name, inner string) string {
+	open, close := tag(name)
+	if inner == "" {
+		return "<" + open + "/>"
+	}
+	return "<" + open + ">" + inner + "</" + close + ">"
+}
+
+// Href renders a DAV:href element.
+func Href(href string) string {
+	return Element(xml.Name{Space: NS, Local: "href"}, Text(href))
+}
+
+func tag(name xml.Name) (string, string) {
+	if p, ok := _prefixes[name.Space]; ok {
+		return p + ":" + name.Local, p + ":" + name.Local
+	}
+	return name.Local + ` xmlns="` + Text(name.Space) + `"`, name.Local
+}
+
+func statusLine(code int) string {
+	return Element(xml.Name{Space: NS, Local: "status"}, Text(fmt.Sprintf("HTTP/1.1 %d %s", code, http.StatusText(code))))
+}
+
+// WriteMultistatus writes the 207 body.
+func WriteMultistatus(w io.Writer, responses []Response) error {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<d:multistatus xmlns:d="DAV:" xmlns:c="` + CalDAVNS + `" xmlns:cs="` + CalServerNS + `">`)
+	for _, resp := range responses {
+		b.WriteString("<d:response>")
+		b.WriteString(Href(resp.Href))
+		if resp.Status != 0 {
+			b.WriteString(statusLine(resp.Status))
+			b.WriteString("</d:response>")
+			continue
+		}
+		if len(resp.Props) > 0 {
+			b.WriteString("<d:propstat><d:prop>")
+			for _, p := range resp.Props {
+				b.WriteString(Element(p.Name, p.Inner))
+			}
+			b.WriteString("</d:prop>" + statusLine(http.StatusOK) + "</d:propstat>")
+		}
+		if len(resp.Missing) > 0 {
+			b.WriteString("<d:propstat><d:prop>")
+			for _, name := range resp.Missing {
+				b.WriteString(Element(name, ""))
+			}
+			b.WriteString("</d:prop>" + statusLine(http.StatusNotFound) + "</d:propstat>")
+		}
+		b.WriteString("</d:response>")
+	}
+	b.WriteString("</d:multistatus>")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Select picks the requested properties from all known ones of a resource,
+// nil names selects all.
+func Select(href string, known []Prop, names PropNames) Response {
+	resp := Response{Href: href}
+	if names == nil {
+		resp.Props = known
+		return resp
+	}
+	for _, name := range names {
+		found := false
+		for _, p := range known {
+			if p.Name == name {
+				resp.Props = append(resp.Props, p)
+				found = true
+				break
+			}
+		}
+		if !found {
+			resp.Missing = append(resp.Missing, name)
+		}
+	}
+	return resp
+}
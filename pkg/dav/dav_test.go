@@ -0,0 +1,147 @@
+package dav_test
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+
+	"testcode/test3/pkg/dav"
+)
+
+func TestReadPropfind(t *testing.T) {
+	getetag := xml.Name{Space: dav.NS, Local: "getetag"}
+	ctag := xml.Name{Space: dav.CalServerNS, Local: "getctag"}
+	tests := []struct {
+		name string
+		body string
+		want *dav.PropNames // nil for allprop
+	}{
+		{"empty body", "", nil},
+		{"allprop", `<propfind xmlns="DAV:"><allprop/></propfind>`, nil},
+		{"prop", `<d:propfind xmlns:d="DAV:" xmlns:cs="http://calendarserver.org/ns/"><d:prop>` +
+			`<d:getetag/><cs:getctag><ignored/></cs:getctag></d:prop></d:propfind>`, &dav.PropNames{getetag, ctag}},
+	}
+	for _, tt := range tests {
+		got, err := dav.ReadPropfind(strings.NewReader(tt.body))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got.Prop, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got.Prop, tt.want)
+		}
+	}
+	if _, err := dav.ReadPropfind(strings.NewReader(`<propfind xmlns="DAV:"><prop>`)); err == nil {
+		t.Errorf("truncated body: no error")
+	}
+}
+
+func TestReadReport(t *testing.T) {
+	multiget := `<c:calendar-multiget xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">` +
+		`<d:prop><d:getetag/><c:calendar-data/></d:prop>` +
+		`<d:href>/dav/1.ics</d:href><d:href>/dav/2.ics</d:href></c:calendar-multiget>`
+	r, err := dav.ReadReport(strings.NewReader(multiget))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.XMLName.Local != "calendar-multiget" || !reflect.DeepEqual(r.Hrefs, []string{"/dav/1.ics", "/dav/2.ics"}) ||
+		r.Prop == nil || len(*r.Prop) != 2 || r.Filter != nil {
+		t.Errorf("multiget: got %+v", r)
+	}
+
+	query := `<c:calendar-query xmlns:c="urn:ietf:params:xml:ns:caldav"><c:filter>` +
+		`<c:comp-filter name="VCALENDAR"><c:comp-filter name="VTODO"/></c:comp-filter></c:filter></c:calendar-query>`
+	r, err = dav.ReadReport(strings.NewReader(query))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := dav.CompFilter{Name: "VCALENDAR", CompFilters: []dav.CompFilter{{Name: "VTODO"}}}
+	if r.Filter == nil || !reflect.DeepEqual(r.Filter.CompFilter, want) || r.Prop != nil {
+		t.Errorf("query: got %+v", r)
+	}
+
+	if _, err = dav.ReadReport(strings.NewReader("")); err == nil {
+		t.Errorf("empty body: no error")
+	}
+}
+
+func TestElement(t *testing.T) {
+	tests := []struct {
+		name  xml.Name
+		inner string
+		want  string
+	}{
+		{xml.Name{Space: dav.NS, Local: "getetag"}, `"1"`, `<d:getetag>"1"</d:getetag>`},
+		{xml.Name{Space: dav.CalDAVNS, Local: "calendar-data"}, "", `<c:calendar-data/>`},
+		{xml.Name{Space: "urn:x&y", Local: "color"}, "red", `<color xmlns="urn:x&amp;y">red</color>`},
+	}
+	for _, tt := range tests {
+		if got := dav.Element(tt.name, tt.inner); got != tt.want {
+			t.Errorf("%v: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+	if got := dav.Href("/a b&c"); got != `<d:href>/a b&amp;c</d:href>` {
+		t.Errorf("href: got %s", got)
+	}
+}
+
+// multistatus is the parsed form of a 207 body.
+type multistatus struct {
+	Responses []struct {
+		Href      string `xml:"DAV: href"`
+		Status    string `xml:"DAV: status"`
+		Propstats []struct {
+			Prop struct {
+				Any []struct {
+					XMLName xml.Name
+					Inner   string `xml:",innerxml"`
+				} `xml:",any"`
+			} `xml:"DAV: prop"`
+			Status string `xml:"DAV: status"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+func TestSelectAndWriteMultistatus(t *testing.T) {
+	getetag := xml.Name{Space: dav.NS, Local: "getetag"}
+	data := xml.Name{Space: dav.CalDAVNS, Local: "calendar-data"}
+	color := xml.Name{Space: "http://apple.com/ns/ical/", Local: "calendar-color"}
+	known := []dav.Prop{{Name: getetag, Inner: dav.Text(`"3"`)}, {Name: data, Inner: dav.Text("BEGIN:VCALENDAR")}}
+
+	if all := dav.Select("/dav/1.ics", known, nil); !reflect.DeepEqual(all.Props, known) || all.Missing != nil {
+		t.Errorf("allprop: got %+v", all)
+	}
+	picked := dav.Select("/dav/1.ics", known, dav.PropNames{data, color})
+	if len(picked.Props) != 1 || picked.Props[0].Name != data || !reflect.DeepEqual(picked.Missing, []xml.Name{color}) {
+		t.Errorf("prop: got %+v", picked)
+	}
+
+	var b strings.Builder
+	err := dav.WriteMultistatus(&b, []dav.Response{picked, {Href: "/dav/2.ics", Status: 404}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got multistatus
+	if err = xml.Unmarshal([]byte(b.String()), &got); err != nil {
+		t.Fatalf("%v: %s", err, b.String())
+	}
+	if len(got.Responses) != 2 {
+		t.Fatalf("responses: %s", b.String())
+	}
+	first, second := got.Responses[0], got.Responses[1]
+	if first.Href != "/dav/1.ics" || len(first.Propstats) != 2 {
+		t.Fatalf("first response: %+v", first)
+	}
+	found, missing := first.Propstats[0], first.Propstats[1]
+	if found.Status != "HTTP/1.1 200 OK" || len(found.Prop.Any) != 1 || found.Prop.Any[0].XMLName != data ||
+		found.Prop.Any[0].Inner != "BEGIN:VCALENDAR" {
+		t.Errorf("found: %+v", found)
+	}
+	if missing.Status != "HTTP/1.1 404 Not Found" || len(missing.Prop.Any) != 1 || missing.Prop.Any[0].XMLName != color {
+		t.Errorf("missing: %+v", missing)
+	}
+	if second.Href != "/dav/2.ics" || second.Status != "HTTP/1.1 404 Not Found" || len(second.Propstats) != 0 {
+		t.Errorf("second response: %+v", second)
+	}
+}
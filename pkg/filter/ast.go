@@ -0,0 +1,128 @@
+// Package filter parses the todo filter language into an AST:
+//
+//	status:open owner:alice -assignee:none (project:web OR project:api) id>=10 due<2026-11-01 "login bug"
+//
+// Terms next to each other are combined with AND, OR binds weaker than AND,
+// a leading - or NOT negates a term and parentheses group. A bare word or a
+// "quoted phrase" matches text. Compiling the AST to SQL is left to storage.
+package filter
+
+import (
+	"strings"
+)
+
+// Op -.
+type Op string
+
+const (
+	OpEq  Op = ":"
+	OpNe  Op = "!="
+	OpLt  Op = "<"
+	OpLte Op = "<="
+	OpGt  Op = ">"
+	OpGte Op = ">="
+)
+
+// Node -.
+type Node interface {
+	String() string
+}
+
+// And matches when every node matches.
+type And struct {
+	Nodes []Node
+}
+
+// Or matches when any node matches.
+type Or struct {
+	Nodes []Node
+}
+
+// Not negates the node.
+type Not struct {
+	Node Node
+}
+
+// Cmp compares field to value, field is lower-cased and known to the schema.
+type Cmp struct {
+	Field string
+	Op    Op
+	Value string
+}
+
+// Text matches the value anywhere in the text of the item.
+type Text struct {
+	Value string
+}
+
+func (n And) String() string {
+	return join(n.Nodes, " ", func(n Node) bool {
+		switch n.(type) {
+		case And, Or:
+			return true
+		}
+		return false
+	})
+}
+
+func (n Or) String() string {
+	return join(n.Nodes, " OR ", func(n Node) bool {
+		_, ok := n.(Or)
+		return ok
+	})
+}
+
+func (n Not) String() string {
+	switch n.Node.(type) {
+	case And, Or:
+		return "-(" + n.Node.String() + ")"
+	}
+	return "-" + n.Node.String()
+}
+
+func (n Cmp) String() string {
+	return n.Field + string(n.Op) + quote(n.Value)
+}
+
+func (n Text) String() string {
+	return quote(n.Value)
+}
+
+// join parenthesizes the nodes group tells would otherwise parse back with
+// another grouping.
+func join(nodes []Node, sep string, group func(Node) bool) string {
+	parts := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		s := n.String()
+		if group(n) {
+			s = "(" + s + ")"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, sep)
+}
+
+// quote quotes values that would not lex back as one word: empty ones,
+// keywords, and those with a leading - or a rune that is no word rune.
+func quote(s string) string {
+	plain := s != "" && s[0] != '-' && s != "AND" && s != "OR" && s != "NOT"
+	for _, r := range s {
+		if !isWordRune(r) {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return s
+	}
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		if r == '"' || r == '\\' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,63 @@
+package filter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"testcode/test3/pkg/filter"
+)
+
+var _schema = filter.Schema{
+	"id":     {Numeric: true},
+	"status": {Values: []string{"open", "done"}},
+	"label":  {},
+	"due":    {Date: true},
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	queries := []string{
+		`status:open label:x`,
+		`-(status:open label:x)`,
+		`NOT (label:a OR label:b)`,
+		`(label:a OR label:b) (label:c OR label:d)`,
+		`(label:a label:b) label:c`,
+		`(label:a OR label:b) OR label:c`,
+		`label:a label:b OR label:c`,
+		`-label:a OR -(id>=3 due<2026-11-01)`,
+		`due:none id!=7 "login bug"`,
+		`label:"foo:bar" "a<b" "OR" "AND" "NOT" "-x" ""`,
+		`label:"say \"hi\"" "back\\slash" "tab	here"`,
+	}
+	for _, q := range queries {
+		want, err := filter.Parse(q, _schema)
+		if err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+		s := want.String()
+		got, err := filter.Parse(s, _schema)
+		if err != nil {
+			t.Errorf("%s: String %s does not parse: %v", q, s, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: String %s parses as %#v, want %#v", q, s, got, want)
+		}
+	}
+}
+
+func TestStringQuotes(t *testing.T) {
+	values := []string{"foo:bar", "a<b", "x=y", "a!b", "OR", "AND", "NOT", "-x", "", "two words", "line\nbreak",
+		`quote"d`, `back\slash`, "(paren)", "plain", "a-b"}
+	for _, v := range values {
+		for _, n := range []filter.Node{filter.Text{Value: v}, filter.Cmp{Field: "label", Op: filter.OpEq, Value: v}} {
+			got, err := filter.Parse(n.String(), _schema)
+			if err != nil {
+				t.Errorf("%q: %s does not parse: %v", v, n.String(), err)
+				continue
+			}
+			if !reflect.DeepEqual(got, n) {
+				t.Errorf("%q: %s parses as %#v", v, n.String(), got)
+			}
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	_maxDepth = 32
+	_opChars  = ":=!<>"
+)
+
+// Error is a syntax or validation error, Pos is the 1-based rune offset of
+// the offending token in the query.
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("filter: %s at position %d", e.Msg, e.Pos)
+}
+
+// Field describes what a field accepts. Numeric fields allow every operator
+// with an unsigned integer value, Date fields every operator with a
+// YYYY-MM-DD date and : and != with none, others allow : and != only. Non
+// empty Values restricts the field to those values, compared
+// case-insensitively.
+type Field struct {
+	Numeric bool
+	Date    bool
+	Values  []string
+}
+
+const (
+	// DateLayout is the layout of values of Date fields.
+	DateLayout = "2006-01-02"
+	// None is the value of Date fields matching items without a date.
+	None = "none"
+)
+
+// Schema maps lower-cased field names to their description.
+type Schema map[string]Field
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOp
+	tokenMinus
+	tokenLParen
+	tokenRParen
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "end of query"
+	case tokenString:
+		return "string"
+	case tokenOp:
+		return "operator"
+	case tokenMinus:
+		return "-"
+	case tokenLParen:
+		return "("
+	case tokenRParen:
+		return ")"
+	}
+	return "word"
+}
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+func isWordRune(r rune) bool {
+	return !unicode.IsSpace(r) && !strings.ContainsRune(_opChars+`"()`, r)
+}
+
+func lex(q string) ([]token, error) {
+	var (
+		tokens []token
+		runes  = []rune(q)
+	)
+	for i := 0; i < len(runes); {
+		r, pos := runes[i], i+1
+		prevOp := len(tokens) > 0 && tokens[len(tokens)-1].kind == tokenOp
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{tokenLParen, "(", pos})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokenRParen, ")", pos})
+			i++
+		case r == '-' && !prevOp && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]):
+			tokens = append(tokens, token{tokenMinus, "-", pos})
+			i++
+		case r == '"':
+			var sb strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, &Error{pos, "unterminated string"}
+			}
+			tokens = append(tokens, token{tokenString, sb.String(), pos})
+			i++
+		case strings.ContainsRune(_opChars, r):
+			op := string(r)
+			if (r == '!' || r == '<' || r == '>') && i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+			}
+			i += len(op)
+			switch op {
+			case "=":
+				op = string(OpEq)
+			case "!":
+				return nil, &Error{pos, `unexpected "!"`}
+			}
+			tokens = append(tokens, token{tokenOp, op, pos})
+		default:
+			start := i
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{tokenWord, string(runes[start:i]), pos})
+		}
+	}
+	return append(tokens, token{tokenEOF, "", len(runes) + 1}), nil
+}
+
+type parser struct {
+	schema Schema
+	tokens []token
+	pos    int
+	depth  int
+}
+
+// Parse parses the query and validates fields against schema.
+func Parse(q string, schema Schema) (Node, error) {
+	tokens, err := lex(q)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{schema: schema, tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, &Error{1, "empty query"}
+	}
+	n, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, &Error{t.pos, fmt.Sprintf("unexpected %s", describe(t))}
+	}
+	return n, nil
+}
+
+func describe(t token) string {
+	if t.kind == tokenWord || t.kind == tokenOp {
+		return strconv.Quote(t.value)
+	}
+	return t.kind.String()
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func isKeyword(t token, kw string) bool {
+	return t.kind == tokenWord && t.value == kw
+}
+
+func (p *parser) or() (Node, error) {
+	n, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	nodes := []Node{n}
+	for isKeyword(p.peek(), "OR") {
+		p.next()
+		if n, err = p.and(); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return Or{nodes}, nil
+}
+
+func (p *parser) and() (Node, error) {
+	var nodes []Node
+	for {
+		t := p.peek()
+		if t.kind == tokenEOF || t.kind == tokenRParen || isKeyword(t, "OR") {
+			break
+		}
+		if isKeyword(t, "AND") {
+			p.next()
+			continue
+		}
+		n, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+	switch len(nodes) {
+	case 0:
+		t := p.peek()
+		return nil, &Error{t.pos, fmt.Sprintf("expected term, got %s", describe(t))}
+	case 1:
+		return nodes[0], nil
+	}
+	return And{nodes}, nil
+}
+
+func (p *parser) unary() (Node, error) {
+	t := p.peek()
+	if t.kind == tokenMinus || isKeyword(t, "NOT") {
+		p.next()
+		n, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if not, ok := n.(Not); ok {
+			return not.Node, nil
+		}
+		return Not{n}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (Node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenLParen:
+		if p.depth++; p.depth > _maxDepth {
+			return nil, &Error{t.pos, "too deeply nested"}
+		}
+		n, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if r := p.next(); r.kind != tokenRParen {
+			return nil, &Error{r.pos, fmt.Sprintf("expected ), got %s", describe(r))}
+		}
+		p.depth--
+		return n, nil
+	case tokenString:
+		return Text{t.value}, nil
+	case tokenWord:
+		if p.peek().kind != tokenOp {
+			return Text{t.value}, nil
+		}
+		return p.cmp(t)
+	}
+	return nil, &Error{t.pos, fmt.Sprintf("unexpected %s", describe(t))}
+}
+
+func (p *parser) cmp(name token) (Node, error) {
+	op := p.next()
+	field := strings.ToLower(name.value)
+	desc, ok := p.schema[field]
+	if !ok {
+		return nil, &Error{name.pos, fmt.Sprintf("unknown field %q", name.value)}
+	}
+	if !desc.Numeric && !desc.Date && op.value != string(OpEq) && op.value != string(OpNe) {
+		return nil, &Error{op.pos, fmt.Sprintf("operator %q is not supported for %s", op.value, field)}
+	}
+
+	v := p.next()
+	if v.kind != tokenWord && v.kind != tokenString {
+		return nil, &Error{v.pos, fmt.Sprintf("expected value after %s%s, got %s", field, op.value, describe(v))}
+	}
+	value := v.value
+	if desc.Numeric {
+		if _, err := strconv.ParseUint(value, 10, 64); err != nil {
+			return nil, &Error{v.pos, fmt.Sprintf("%s expects a number, got %q", field, value)}
+		}
+	}
+	if desc.Date {
+		if strings.EqualFold(value, None) {
+			if op.value != string(OpEq) && op.value != string(OpNe) {
+				return nil, &Error{op.pos, fmt.Sprintf("operator %q is not supported for %s:%s", op.value, field, None)}
+			}
+			value = None
+		} else if _, err := time.Parse(DateLayout, value); err != nil {
+			return nil, &Error{v.pos, fmt.Sprintf("%s expects a date like 2006-01-02 or none, got %q", field, value)}
+		}
+	}
+	if len(desc.Values) > 0 {
+		value = strings.ToLower(value)
+		if !contains(desc.Values, value) {
+			return nil, &Error{v.pos, fmt.Sprintf("%s expects one of %s, got %q", field, strings.Join(desc.Values, ", "), v.value)}
+		}
+	}
+	return Cmp{field, Op(op.value), value}, nil
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,293 @@
+// Package gqlcost estimates the cost of a GraphQL operation before it runs.
+// Every field costs 1, the selections under a list field are counted as
+// many times as the list is expected to hold. Only the syntax is read, the
+// schema validates the query afterwards.
+package gqlcost
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Estimate returns the cost of the operation, the only one of the document
+// when name is empty. lists maps field names returning lists to the items
+// they are expected to hold.
+func Estimate(query string, name string, lists map[string]int) (int, error) {
+	p := &parser{lex: lexer{src: query}}
+	p.next()
+	doc, err := p.document()
+	if err != nil {
+		return 0, err
+	}
+
+	var op selectionSet
+	switch {
+	case name != "":
+		s, ok := doc.operations[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown operation %q", name)
+		}
+		op = s
+	case doc.count == 1:
+		for _, s := range doc.operations {
+			op = s
+		}
+	default:
+		return 0, errors.New("operation name required")
+	}
+
+	e := estimator{doc: doc, lists: lists, visiting: make(map[string]bool)}
+	return e.cost(op)
+}
+
+type selection struct {
+	name      string
+	fragment  string // name of a fragment spread
+	selection selectionSet
+}
+
+type selectionSet []selection
+
+type document struct {
+	operations map[string]selectionSet
+	count      int
+	fragments  map[string]selectionSet
+}
+
+type estimator struct {
+	doc      *document
+	lists    map[string]int
+	visiting map[string]bool
+}
+
+func (e *estimator) cost(set selectionSet) (int, error) {
+	total := 0
+	for _, s := range set {
+		if s.fragment != "" {
+			if e.visiting[s.fragment] {
+				return 0, fmt.Errorf("fragment %q spreads itself", s.fragment)
+			}
+			f, ok := e.doc.fragments[s.fragment]
+			if !ok {
+				return 0, fmt.Errorf("unknown fragment %q", s.fragment)
+			}
+			e.visiting[s.fragment] = true
+			c, err := e.cost(f)
+			delete(e.visiting, s.fragment)
+			if err != nil {
+				return 0, err
+			}
+			total += c
+			continue
+		}
+
+		c, err := e.cost(s.selection)
+		if err != nil {
+			return 0, err
+		}
+		if n, ok := e.lists[s.name]; ok {
+			c *= n
+		}
+		// inline fragments have no name and cost nothing themselves
+		if s.name != "" {
+			c++
+		}
+		total += c
+	}
+	return total, nil
+}
+
+type parser struct {
+	lex lexer
+	tok token
+}
+
+func (p *parser) next() {
+	p.tok = p.lex.next()
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("gqlcost: offset %d: %s", p.tok.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) unexpected() error {
+	switch p.tok.kind {
+	case tokEOF:
+		return p.errorf("unexpected end of query")
+	case tokError:
+		return p.errorf("%s", p.tok.text)
+	}
+	return p.errorf("unexpected %q", p.tok.text)
+}
+
+func (p *parser) expect(kind tokenKind, text string) error {
+	if p.tok.kind != kind || (text != "" && p.tok.text != text) {
+		return p.unexpected()
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) punct(text string) bool {
+	return p.tok.kind == tokPunct && p.tok.text == text
+}
+
+func (p *parser) document() (*document, error) {
+	doc := &document{operations: make(map[string]selectionSet), fragments: make(map[string]selectionSet)}
+	for p.tok.kind != tokEOF {
+		switch {
+		case p.punct("{"):
+			set, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations[""] = set
+			doc.count++
+		case p.tok.kind == tokName && p.tok.text == "fragment":
+			p.next()
+			name := p.tok.text
+			if err := p.expect(tokName, ""); err != nil {
+				return nil, err
+			}
+			if err := p.expect(tokName, "on"); err != nil {
+				return nil, err
+			}
+			if err := p.expect(tokName, ""); err != nil {
+				return nil, err
+			}
+			set, err := p.directivesAndSelectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.fragments[name] = set
+		case p.tok.kind == tokName && (p.tok.text == "query" || p.tok.text == "mutation" || p.tok.text == "subscription"):
+			p.next()
+			var name string
+			if p.tok.kind == tokName {
+				name = p.tok.text
+				p.next()
+			}
+			if p.punct("(") {
+				if err := p.skipBalanced("(", ")"); err != nil {
+					return nil, err
+				}
+			}
+			set, err := p.directivesAndSelectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations[name] = set
+			doc.count++
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	return doc, nil
+}
+
+func (p *parser) directivesAndSelectionSet() (selectionSet, error) {
+	if err := p.directives(); err != nil {
+		return nil, err
+	}
+	return p.selectionSet()
+}
+
+func (p *parser) directives() error {
+	for p.punct("@") {
+		p.next()
+		if err := p.expect(tokName, ""); err != nil {
+			return err
+		}
+		if p.punct("(") {
+			if err := p.skipBalanced("(", ")"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *parser) selectionSet() (selectionSet, error) {
+	if err := p.expect(tokPunct, "{"); err != nil {
+		return nil, err
+	}
+	var set selectionSet
+	for !p.punct("}") {
+		s, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, s)
+	}
+	p.next()
+	return set, nil
+}
+
+func (p *parser) selection() (selection, error) {
+	if p.punct("...") {
+		p.next()
+		if p.tok.kind == tokName && p.tok.text != "on" {
+			s := selection{fragment: p.tok.text}
+			p.next()
+			return s, p.directives()
+		}
+		if p.tok.kind == tokName {
+			p.next()
+			if err := p.expect(tokName, ""); err != nil {
+				return selection{}, err
+			}
+		}
+		set, err := p.directivesAndSelectionSet()
+		return selection{selection: set}, err
+	}
+
+	name := p.tok.text
+	if err := p.expect(tokName, ""); err != nil {
+		return selection{}, err
+	}
+	// alias: name
+	if p.punct(":") {
+		p.next()
+		name = p.tok.text
+		if err := p.expect(tokName, ""); err != nil {
+			return selection{}, err
+		}
+	}
+	if p.punct("(") {
+		if err := p.skipBalanced("(", ")"); err != nil {
+			return selection{}, err
+		}
+	}
+	if err := p.directives(); err != nil {
+		return selection{}, err
+	}
+	s := selection{name: name}
+	if p.punct("{") {
+		set, err := p.selectionSet()
+		if err != nil {
+			return selection{}, err
+		}
+		s.selection = set
+	}
+	return s, nil
+}
+
+// skipBalanced skips arguments and variable definitions, their values do not
+// change the cost.
+func (p *parser) skipBalanced(open, close string) error {
+	depth := 0
+	for {
+		switch {
+		case p.tok.kind == tokEOF || p.tok.kind == tokError:
+			return p.unexpected()
+		case p.punct(open):
+			depth++
+		case p.punct(close):
+			depth--
+		}
+		p.next()
+		if depth == 0 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package gqlcost_test
+
+import (
+	"testing"
+
+	"testcode/test3/pkg/gqlcost"
+)
+
+func TestEstimate(t *testing.T) {
+	lists := map[string]int{"todos": 10, "assignees": 5}
+	two := `query A { me { id } } query B { todos { id name } }`
+	tests := []struct {
+		name  string
+		query string
+		op    string
+		want  int // -1 when the query is refused
+	}{
+		{"fields", `{ me { id name } }`, "", 3},
+		{"list", `{ todos { id name } }`, "", 21},
+		{"nested lists", `{ todos { id assignees { id } } }`, "", 71},
+		{"aliases count per field", `{ a: todos { id } b: todos { id } mine: me { id } }`, "", 24},
+		{"alias takes the list of its field", `{ todos: me { id } }`, "", 2},
+		{"named fragment", `query Q { todos { ...F } } fragment F on Todo { id name }`, "", 21},
+		{"fragment before the query", `fragment F on Todo { id } { todos { ...F ...F } }`, "", 21},
+		{"nested fragments", `{ todos { ...F } } fragment F on Todo { ...G assignees { ...G } } fragment G on Todo { id }`, "", 71},
+		{"inline fragment", `{ todos { ... on Todo { id } ... @include(if: true) { name } } }`, "", 21},
+		{"arguments and directives", `query Q($f: String = "}{") { todos(filter: $f, first: 10) @include(if: true) { id } }`, "", 11},
+		{"comments and commas", "# { todos { id } }\n{ me, { id, name } }", "", 3},
+		{"block string", `{ todos(filter: """a "}" b""") { id } }`, "", 11},
+		{"operation by name", two, "B", 21},
+		{"other operation by name", two, "A", 2},
+		{"operation name required", two, "", -1},
+		{"unknown operation", two, "C", -1},
+		{"unknown fragment", `{ todos { ...F } }`, "", -1},
+		{"fragment spreads itself", `{ todos { ...F } } fragment F on Todo { assignees { ...F } }`, "", -1},
+		{"unterminated string", `{ todos(filter: "x) { id } }`, "", -1},
+		{"unexpected end", `{ todos { id `, "", -1},
+		{"unexpected character", `{ todos { id % } }`, "", -1},
+	}
+	for _, tt := range tests {
+		got, err := gqlcost.Estimate(tt.query, tt.op, lists)
+		if tt.want < 0 {
+			if err == nil {
+				t.Errorf("%s: got %d, want an error", tt.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
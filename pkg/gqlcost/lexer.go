@@ -0,0 +1,111 @@
+package gqlcost
+
+import "strings"
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokError
+	tokName
+	tokPunct
+	tokValue // numbers and strings
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isName(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
+
+// next skips white space, commas, byte order marks and comments, which the
+// grammar ignores.
+func (l *lexer) next() token {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == '#' {
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' && l.src[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		if strings.HasPrefix(l.src[l.pos:], "\ufeff") {
+			l.pos += len("\ufeff")
+			continue
+		}
+		if c != ' ' && c != '\t' && c != '\n' && c != '\r' && c != ',' {
+			break
+		}
+		l.pos++
+	}
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, pos: l.pos}
+	}
+
+	start := l.pos
+	c := l.src[l.pos]
+	switch {
+	case isNameStart(c):
+		for l.pos < len(l.src) && isName(l.src[l.pos]) {
+			l.pos++
+		}
+		return token{kind: tokName, text: l.src[start:l.pos], pos: start}
+	case c == '-' || (c >= '0' && c <= '9'):
+		l.pos++
+		for l.pos < len(l.src) && (isName(l.src[l.pos]) || l.src[l.pos] == '.' ||
+			((l.src[l.pos] == '+' || l.src[l.pos] == '-') && (l.src[l.pos-1] == 'e' || l.src[l.pos-1] == 'E'))) {
+			l.pos++
+		}
+		return token{kind: tokValue, text: l.src[start:l.pos], pos: start}
+	case strings.HasPrefix(l.src[l.pos:], `"""`):
+		l.pos += 3
+		for l.pos < len(l.src) {
+			if strings.HasPrefix(l.src[l.pos:], `\"""`) {
+				l.pos += 4
+				continue
+			}
+			if strings.HasPrefix(l.src[l.pos:], `"""`) {
+				l.pos += 3
+				return token{kind: tokValue, text: l.src[start:l.pos], pos: start}
+			}
+			l.pos++
+		}
+		return token{kind: tokError, text: "unterminated string", pos: start}
+	case c == '"':
+		l.pos++
+		for l.pos < len(l.src) {
+			switch l.src[l.pos] {
+			case '\\':
+				l.pos += 2
+				continue
+			case '"':
+				l.pos++
+				return token{kind: tokValue, text: l.src[start:l.pos], pos: start}
+			case '\n', '\r':
+				return token{kind: tokError, text: "unterminated string", pos: start}
+			}
+			l.pos++
+		}
+		return token{kind: tokError, text: "unterminated string", pos: start}
+	case strings.HasPrefix(l.src[l.pos:], "..."):
+		l.pos += 3
+		return token{kind: tokPunct, text: "...", pos: start}
+	case strings.IndexByte("!$&()[]{}:=@|", c) >= 0:
+		l.pos++
+		return token{kind: tokPunct, text: string(c), pos: start}
+	}
+	return token{kind: tokError, text: "unexpected character " + string(c), pos: start}
+}
@@ -0,0 +1,326 @@
+// Package gqlws serves GraphQL operations over WebSocket with the
+// graphql-transport-ws protocol:
+// https://github.com/enisdenjo/graphql-ws/blob/master/PROTOCOL.md
+package gqlws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Protocol is the WebSocket subprotocol clients ask for.
+const Protocol = "graphql-transport-ws"
+
+const (
+	_defaultInitTimeout  = 10 * time.Second
+	_defaultPingInterval = 30 * time.Second
+	_defaultWriteTimeout = 10 * time.Second
+)
+
+// Close codes of the protocol.
+const (
+	CloseBadRequest      = 4400
+	CloseUnauthorized    = 4401
+	CloseForbidden       = 4403
+	CloseInitTimeout     = 4408
+	CloseDuplicateID     = 4409
+	CloseTooManyRequests = 4429
+)
+
+const (
+	_msgConnectionInit = "connection_init"
+	_msgConnectionAck  = "connection_ack"
+	_msgPing           = "ping"
+	_msgPong           = "pong"
+	_msgSubscribe      = "subscribe"
+	_msgNext           = "next"
+	_msgError          = "error"
+	_msgComplete       = "complete"
+)
+
+type message struct {
+	Id      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Payload is the operation of a subscribe message.
+type Payload struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Errors returned by Subscribe are sent as the payload of the error message,
+// other errors as a single error with their text.
+type Errors []interface{}
+
+func (e Errors) Error() string {
+	return fmt.Sprintf("%d graphql errors", len(e))
+}
+
+// Server upgrades requests to the protocol, operations run until their
+// results channel is closed, the client completes them or the connection
+// ends.
+type Server struct {
+	// Init accepts the connection, the returned context is the one of its
+	// operations. An error closes the connection as forbidden.
+	Init func(ctx context.Context, payload json.RawMessage) (context.Context, error)
+	// Subscribe starts the operation, the results are sent as next messages.
+	Subscribe func(ctx context.Context, payload Payload) (<-chan interface{}, error)
+
+	InitTimeout  time.Duration
+	PingInterval time.Duration
+	WriteTimeout time.Duration
+	Upgrader     websocket.Upgrader
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	up := s.Upgrader
+	up.Subprotocols = []string{Protocol}
+	ws, err := up.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader has answered already
+		return
+	}
+	if ws.Subprotocol() != Protocol {
+		ws.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseProtocolError, "subprotocol "+Protocol+" required"))
+		ws.Close()
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	c := &conn{
+		server: s,
+		ws:     ws,
+		ctx:    ctx,
+		ops:    make(map[string]context.CancelFunc),
+	}
+	c.serve()
+	cancel()
+	ws.Close()
+}
+
+func (s *Server) initTimeout() time.Duration {
+	if s.InitTimeout > 0 {
+		return s.InitTimeout
+	}
+	return _defaultInitTimeout
+}
+
+func (s *Server) pingInterval() time.Duration {
+	if s.PingInterval > 0 {
+		return s.PingInterval
+	}
+	return _defaultPingInterval
+}
+
+func (s *Server) writeTimeout() time.Duration {
+	if s.WriteTimeout > 0 {
+		return s.WriteTimeout
+	}
+	return _defaultWriteTimeout
+}
+
+type conn struct {
+	server *Server
+	ws     *websocket.Conn
+	ctx    context.Context
+
+	writeMu sync.Mutex
+
+	mu    sync.Mutex
+	acked bool
+	ops   map[string]context.CancelFunc
+}
+
+// write sends the message, a client not reading within the write timeout
+// fails it and ends the connection.
+func (c *conn) write(m message) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.ws.SetWriteDeadline(time.Now().Add(c.server.writeTimeout()))
+	if err := c.ws.WriteJSON(m); err != nil {
+		c.ws.Close()
+		return err
+	}
+	return nil
+}
+
+func (c *conn) close(code int, text string) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text),
+		time.Now().Add(c.server.writeTimeout()))
+}
+
+func (c *conn) serve() {
+	initTimer := time.AfterFunc(c.server.initTimeout(), func() {
+		c.mu.Lock()
+		acked := c.acked
+		c.mu.Unlock()
+		if !acked {
+			c.close(CloseInitTimeout, "connection initialisation timeout")
+			c.ws.Close()
+		}
+	})
+	defer initTimer.Stop()
+
+	stopPing := make(chan struct{})
+	defer close(stopPing)
+	go c.ping(stopPing)
+
+	defer c.cancelAll()
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		var m message
+		if err = json.Unmarshal(data, &m); err != nil {
+			c.close(CloseBadRequest, "invalid message")
+			return
+		}
+		if !c.handle(m) {
+			return
+		}
+	}
+}
+
+// ping keeps proxies from closing an idle connection.
+func (c *conn) ping(stop <-chan struct{}) {
+	t := time.NewTicker(c.server.pingInterval())
+	defer t.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+			if c.write(message{Type: _msgPing}) != nil {
+				return
+			}
+		}
+	}
+}
+
+// handle processes a client message, false ends the connection.
+func (c *conn) handle(m message) bool {
+	switch m.Type {
+	case _msgConnectionInit:
+		c.mu.Lock()
+		acked := c.acked
+		c.mu.Unlock()
+		if acked {
+			c.close(CloseTooManyRequests, "too many initialisation requests")
+			return false
+		}
+		if c.server.Init != nil {
+			ctx, err := c.server.Init(c.ctx, m.Payload)
+			if err != nil {
+				c.close(CloseForbidden, err.Error())
+				return false
+			}
+			c.ctx = ctx
+		}
+		c.mu.Lock()
+		c.acked = true
+		c.mu.Unlock()
+		return c.write(message{Type: _msgConnectionAck}) == nil
+	case _msgPing:
+		return c.write(message{Type: _msgPong}) == nil
+	case _msgPong:
+		return true
+	case _msgSubscribe:
+		return c.subscribe(m)
+	case _msgComplete:
+		c.mu.Lock()
+		if cancel, ok := c.ops[m.Id]; ok {
+			cancel()
+			delete(c.ops, m.Id)
+		}
+		c.mu.Unlock()
+		return true
+	}
+	c.close(CloseBadRequest, fmt.Sprintf("invalid message type %q", m.Type))
+	return false
+}
+
+func (c *conn) subscribe(m message) bool {
+	var p Payload
+	if m.Id == "" || json.Unmarshal(m.Payload, &p) != nil {
+		c.close(CloseBadRequest, "invalid subscribe message")
+		return false
+	}
+
+	c.mu.Lock()
+	if !c.acked {
+		c.mu.Unlock()
+		c.close(CloseUnauthorized, "unauthorized")
+		return false
+	}
+	if _, ok := c.ops[m.Id]; ok {
+		c.mu.Unlock()
+		c.close(CloseDuplicateID, "subscriber for "+m.Id+" already exists")
+		return false
+	}
+	ctx, cancel := context.WithCancel(c.ctx)
+	c.ops[m.Id] = cancel
+	c.mu.Unlock()
+
+	results, err := c.server.Subscribe(ctx, p)
+	if err != nil {
+		c.done(m.Id)
+		errs, ok := err.(Errors)
+		if !ok {
+			errs = Errors{map[string]string{"message": err.Error()}}
+		}
+		payload, _ := json.Marshal(errs)
+		return c.write(message{Id: m.Id, Type: _msgError, Payload: payload}) == nil
+	}
+
+	go func() {
+		for r := range results {
+			payload, err := json.Marshal(r)
+			if err != nil || c.write(message{Id: m.Id, Type: _msgNext, Payload: payload}) != nil {
+				cancel()
+				// the operation stops sending once it sees ctx is done
+				for range results {
+				}
+				break
+			}
+		}
+		// completed by the client already when the operation is gone
+		if c.done(m.Id) {
+			c.write(message{Id: m.Id, Type: _msgComplete})
+		}
+	}()
+	return true
+}
+
+// done forgets the operation, false when it was gone already.
+func (c *conn) done(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cancel, ok := c.ops[id]
+	if ok {
+		cancel()
+		delete(c.ops, id)
+	}
+	return ok
+}
+
+func (c *conn) cancelAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, cancel := range c.ops {
+		cancel()
+		delete(c.ops, id)
+	}
+}
@@ -0,0 +1,209 @@
+package gqlws_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"testcode/test3/pkg/gqlws"
+)
+
+type message struct {
+	Id      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// newServer accepts the init payload {"token":"ok"}. The query "count" sends
+// two results, "forever" runs until it is cancelled and then reports on
+// stopped, "fail" and "broken" fail to start.
+func newServer(t *testing.T, stopped chan<- string) *httptest.Server {
+	t.Helper()
+	s := &gqlws.Server{
+		Init: func(ctx context.Context, payload json.RawMessage) (context.Context, error) {
+			var p struct{ Token string }
+			if json.Unmarshal(payload, &p) != nil || p.Token != "ok" {
+				return nil, errors.New("bad token")
+			}
+			return ctx, nil
+		},
+		Subscribe: func(ctx context.Context, p gqlws.Payload) (<-chan interface{}, error) {
+			results := make(chan interface{})
+			switch p.Query {
+			case "count":
+				go func() {
+					defer close(results)
+					for i := 1; i <= 2; i++ {
+						select {
+						case results <- map[string]int{"n": i}:
+						case <-ctx.Done():
+							return
+						}
+					}
+				}()
+			case "forever":
+				go func() {
+					<-ctx.Done()
+					close(results)
+					stopped <- p.OperationName
+				}()
+			case "fail":
+				return nil, gqlws.Errors{map[string]string{"message": "no such field"}}
+			default:
+				return nil, errors.New("broken")
+			}
+			return results, nil
+		},
+		InitTimeout: 100 * time.Millisecond,
+	}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func dial(t *testing.T, srv *httptest.Server, protocols ...string) *websocket.Conn {
+	t.Helper()
+	d := websocket.Dialer{Subprotocols: protocols}
+	ws, _, err := d.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ws.Close() })
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return ws
+}
+
+func send(t *testing.T, ws *websocket.Conn, m string) {
+	t.Helper()
+	if err := ws.WriteMessage(websocket.TextMessage, []byte(m)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func read(t *testing.T, ws *websocket.Conn) message {
+	t.Helper()
+	var m message
+	if err := ws.ReadJSON(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// closeCode reads until the server closes the connection.
+func closeCode(ws *websocket.Conn) int {
+	for {
+		_, _, err := ws.ReadMessage()
+		var ce *websocket.CloseError
+		if errors.As(err, &ce) {
+			return ce.Code
+		}
+		if err != nil {
+			return 0
+		}
+	}
+}
+
+func TestClose(t *testing.T) {
+	srv := newServer(t, nil)
+	const init = `{"type":"connection_init","payload":{"token":"ok"}}`
+	tests := []struct {
+		name     string
+		messages []string
+		want     int
+	}{
+		{"subscribe before init", []string{`{"id":"1","type":"subscribe","payload":{"query":"count"}}`},
+			gqlws.CloseUnauthorized},
+		{"rejected init", []string{`{"type":"connection_init","payload":{"token":"bad"}}`}, gqlws.CloseForbidden},
+		{"second init", []string{init, init}, gqlws.CloseTooManyRequests},
+		{"no init", nil, gqlws.CloseInitTimeout},
+		{"unknown type", []string{init, `{"type":"query"}`}, gqlws.CloseBadRequest},
+		{"invalid json", []string{`{"type":`}, gqlws.CloseBadRequest},
+		{"type not a string", []string{`{"type":1}`}, gqlws.CloseBadRequest},
+		{"subscribe without id", []string{init, `{"type":"subscribe","payload":{"query":"count"}}`},
+			gqlws.CloseBadRequest},
+	}
+	for _, tt := range tests {
+		ws := dial(t, srv, gqlws.Protocol)
+		for _, m := range tt.messages {
+			send(t, ws, m)
+		}
+		if got := closeCode(ws); got != tt.want {
+			t.Errorf("%s: closed with %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	ws := dial(t, srv)
+	if got := closeCode(ws); got != websocket.CloseProtocolError {
+		t.Errorf("no subprotocol: closed with %d, want %d", got, websocket.CloseProtocolError)
+	}
+}
+
+func TestOperations(t *testing.T) {
+	stopped := make(chan string, 1)
+	ws := dial(t, newServer(t, stopped), gqlws.Protocol)
+	send(t, ws, `{"type":"connection_init","payload":{"token":"ok"}}`)
+	if m := read(t, ws); m.Type != "connection_ack" {
+		t.Fatalf("init: got %+v", m)
+	}
+	send(t, ws, `{"type":"ping"}`)
+	if m := read(t, ws); m.Type != "pong" {
+		t.Errorf("ping: got %+v", m)
+	}
+
+	// results are sent in order and the operation completes
+	send(t, ws, `{"id":"a","type":"subscribe","payload":{"query":"count"}}`)
+	for _, want := range []message{
+		{Id: "a", Type: "next", Payload: json.RawMessage(`{"n":1}`)},
+		{Id: "a", Type: "next", Payload: json.RawMessage(`{"n":2}`)},
+		{Id: "a", Type: "complete"},
+	} {
+		if m := read(t, ws); m.Id != want.Id || m.Type != want.Type || string(m.Payload) != string(want.Payload) {
+			t.Errorf("count: got %+v %s, want %+v %s", m, m.Payload, want, want.Payload)
+		}
+	}
+
+	// failing to start answers with the errors
+	for _, tt := range []struct{ query, want string }{
+		{"fail", `[{"message":"no such field"}]`},
+		{"broken", `[{"message":"broken"}]`},
+	} {
+		send(t, ws, `{"id":"b","type":"subscribe","payload":{"query":"`+tt.query+`"}}`)
+		if m := read(t, ws); m.Id != "b" || m.Type != "error" || string(m.Payload) != tt.want {
+			t.Errorf("%s: got %+v %s, want error %s", tt.query, m, m.Payload, tt.want)
+		}
+	}
+
+	// completing from the client cancels the operation, a ping after it
+	// shows no complete message was sent back
+	send(t, ws, `{"id":"c","type":"subscribe","payload":{"query":"forever","operationName":"c"}}`)
+	send(t, ws, `{"id":"c","type":"complete"}`)
+	select {
+	case name := <-stopped:
+		if name != "c" {
+			t.Errorf("complete: stopped %q", name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("complete: operation still running")
+	}
+	send(t, ws, `{"type":"ping"}`)
+	if m := read(t, ws); m.Type != "pong" {
+		t.Errorf("complete: got %+v, want pong", m)
+	}
+
+	// an id still running cannot be reused, the connection end stops it
+	send(t, ws, `{"id":"d","type":"subscribe","payload":{"query":"forever","operationName":"d"}}`)
+	send(t, ws, `{"id":"d","type":"subscribe","payload":{"query":"count"}}`)
+	if got := closeCode(ws); got != gqlws.CloseDuplicateID {
+		t.Errorf("duplicate id: closed with %d, want %d", got, gqlws.CloseDuplicateID)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Error("duplicate id: operation still running after the connection ended")
+	}
+}
@@ -0,0 +1,327 @@
+// Package ical reads and writes RFC 5545 iCalendar objects as a tree of
+// components and properties, the meaning of properties is up to the caller.
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// _lineLen is the content line length limit in octets before folding.
+const _lineLen = 75
+
+// DateTimeFormat is the UTC DATE-TIME form, DateFormat the DATE form.
+const (
+	DateTimeFormat = "20060102T150405Z"
+	DateFormat     = "20060102"
+)
+
+// _localDateTimeFormat is the DATE-TIME form of floating and TZID times.
+const _localDateTimeFormat = "20060102T150405"
+
+var _recurFreqs = map[string]bool{
+	"SECONDLY": true, "MINUTELY": true, "HOURLY": true, "DAILY": true, "WEEKLY": true, "MONTHLY": true, "YEARLY": true,
+}
+
+// Property -.
+type Property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// Component -.
+type Component struct {
+	Name       string
+	Props      []Property
+	Components []Component
+}
+
+// Prop returns the first property of the name, nil when there is none.
+func (c *Component) Prop(name string) *Property {
+	for i := range c.Props {
+		if c.Props[i].Name == name {
+			return &c.Props[i]
+		}
+	}
+	return nil
+}
+
+// Text returns the unescaped TEXT value of the property, empty when absent.
+func (c *Component) Text(name string) string {
+	if p := c.Prop(name); p != nil {
+		return UnescapeText(p.Value)
+	}
+	return ""
+}
+
+// Time returns the DATE-TIME or DATE value of the property, nil when absent.
+// Floating times are taken as UTC.
+func (c *Component) Time(name string) (*time.Time, error) {
+	p := c.Prop(name)
+	if p == nil {
+		return nil, nil
+	}
+	loc := time.UTC
+	if tzid := p.Params["TZID"]; tzid != "" {
+		var err error
+		if loc, err = time.LoadLocation(tzid); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	for _, layout := range []string{DateTimeFormat, _localDateTimeFormat, DateFormat} {
+		if t, err := time.ParseInLocation(layout, p.Value, loc); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: invalid date %q", name, p.Value)
+}
+
+// CheckRecur checks the RECUR value is a list of NAME=VALUE parts with one
+// known FREQ, the other parts are not interpreted.
+func CheckRecur(value string) error {
+	freq := ""
+	for _, part := range strings.Split(value, ";") {
+		eq := strings.IndexByte(part, '=')
+		if eq <= 0 || eq == len(part)-1 {
+			return fmt.Errorf("invalid recurrence part %q", part)
+		}
+		if strings.EqualFold(part[:eq], "FREQ") {
+			if freq != "" {
+				return errors.New("recurrence has FREQ twice")
+			}
+			freq = strings.ToUpper(part[eq+1:])
+		}
+	}
+	if !_recurFreqs[freq] {
+		return fmt.Errorf("invalid recurrence frequency %q", freq)
+	}
+	return nil
+}
+
+// Add appends a property with a raw value.
+func (c *Component) Add(name string, value string) {
+	c.Props = append(c.Props, Property{Name: name, Value: value})
+}
+
+// AddText appends a property with an escaped TEXT value.
+func (c *Component) AddText(name string, value string) {
+	c.Add(name, EscapeText(value))
+}
+
+// AddTime appends a UTC DATE-TIME property.
+func (c *Component) AddTime(name string, t time.Time) {
+	c.Add(name, t.UTC().Format(DateTimeFormat))
+}
+
+// EscapeText escapes a TEXT value.
+func EscapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// UnescapeText reverses EscapeText.
+func UnescapeText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// Encode writes the component with CRLF line endings and folded lines.
+func Encode(w io.Writer, c Component) error {
+	bw := bufio.NewWriter(w)
+	if err := encode(bw, c); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func encode(w *bufio.Writer, c Component) error {
+	if err := writeLine(w, "BEGIN:"+c.Name); err != nil {
+		return err
+	}
+	for _, p := range c.Props {
+		var line strings.Builder
+		line.WriteString(p.Name)
+		for k, v := range p.Params {
+			line.WriteString(";" + k + "=")
+			if strings.ContainsAny(v, ":;,") {
+				v = `"` + v + `"`
+			}
+			line.WriteString(v)
+		}
+		line.WriteString(":" + p.Value)
+		if err := writeLine(w, line.String()); err != nil {
+			return err
+		}
+	}
+	for _, sub := range c.Components {
+		if err := encode(w, sub); err != nil {
+			return err
+		}
+	}
+	return writeLine(w, "END:"+c.Name)
+}
+
+// writeLine folds the line at _lineLen octets without splitting characters.
+func writeLine(w *bufio.Writer, line string) error {
+	limit := _lineLen
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		if _, err := w.WriteString(line[:cut] + "\r\n "); err != nil {
+			return err
+		}
+		line = line[cut:]
+		// the leading space of continuation lines counts
+		limit = _lineLen - 1
+	}
+	_, err := w.WriteString(line + "\r\n")
+	return err
+}
+
+// Error -.
+type Error struct {
+	Line int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("ical: line %d: %s", e.Line, e.Msg)
+}
+
+// Decode reads the components at the top level of the stream.
+func Decode(r io.Reader) ([]Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ret   []Component
+		stack []Component
+	)
+	for _, l := range lines {
+		p, err := parseLine(l.text)
+		if err != nil {
+			return nil, &Error{Line: l.n, Msg: err.Error()}
+		}
+		switch p.Name {
+		case "BEGIN":
+			stack = append(stack, Component{Name: strings.ToUpper(p.Value)})
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(p.Value) {
+				return nil, &Error{Line: l.n, Msg: fmt.Sprintf("unexpected END:%s", p.Value)}
+			}
+			done := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				ret = append(ret, done)
+			} else {
+				parent := &stack[len(stack)-1]
+				parent.Components = append(parent.Components, done)
+			}
+		default:
+			if len(stack) == 0 {
+				return nil, &Error{Line: l.n, Msg: fmt.Sprintf("property %s outside of a component", p.Name)}
+			}
+			top := &stack[len(stack)-1]
+			top.Props = append(top.Props, p)
+		}
+	}
+	if len(stack) > 0 {
+		return nil, &Error{Line: len(lines), Msg: fmt.Sprintf("missing END:%s", stack[len(stack)-1].Name)}
+	}
+	return ret, nil
+}
+
+type contentLine struct {
+	n    int
+	text string
+}
+
+// unfold joins continuation lines, n is the line the content line starts at.
+func unfold(r io.Reader) ([]contentLine, error) {
+	var ret []contentLine
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		text := strings.TrimRight(sc.Text(), "\r")
+		if text == "" {
+			continue
+		}
+		if (text[0] == ' ' || text[0] == '\t') && len(ret) > 0 {
+			ret[len(ret)-1].text += text[1:]
+			continue
+		}
+		ret = append(ret, contentLine{n, text})
+	}
+	return ret, sc.Err()
+}
+
+// parseLine splits name *(";" param) ":" value, quoted param values may hold
+// the separators.
+func parseLine(s string) (Property, error) {
+	p := Property{}
+	i := strings.IndexAny(s, ";:")
+	if i <= 0 {
+		return p, fmt.Errorf("invalid content line %q", s)
+	}
+	p.Name = strings.ToUpper(s[:i])
+	for s[i] == ';' {
+		s = s[i+1:]
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return p, fmt.Errorf("invalid parameter in %s", p.Name)
+		}
+		key := strings.ToUpper(s[:eq])
+		s = s[eq+1:]
+		var val string
+		if strings.HasPrefix(s, `"`) {
+			end := strings.IndexByte(s[1:], '"')
+			if end < 0 {
+				return p, fmt.Errorf("unterminated quote in %s", p.Name)
+			}
+			val = s[1 : end+1]
+			s = s[end+2:]
+		} else {
+			end := strings.IndexAny(s, ";:")
+			if end < 0 {
+				return p, fmt.Errorf("missing value of %s", p.Name)
+			}
+			val = s[:end]
+			s = s[end:]
+		}
+		if p.Params == nil {
+			p.Params = make(map[string]string)
+		}
+		p.Params[key] = val
+		if s == "" {
+			return p, fmt.Errorf("missing value of %s", p.Name)
+		}
+		i = 0
+	}
+	if s[i] != ':' {
+		return p, fmt.Errorf("invalid content line of %s", p.Name)
+	}
+	p.Value = s[i+1:]
+	return p, nil
+}
@@ -0,0 +1,151 @@
+package ical
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestFoldRoundTrip(t *testing.T) {
+	summary := strings.Repeat("Grüße, ünïcödé; ", 12) + "\\end"
+	c := Component{Name: "VCALENDAR", Components: []Component{{Name: "VTODO"}}}
+	c.Components[0].AddText("SUMMARY", summary)
+	c.Components[0].Props = append(c.Components[0].Props,
+		Property{Name: "DTSTART", Params: map[string]string{"TZID": "Europe/Berlin"}, Value: "20221001T090000"})
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, c); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+	folded := 0
+	for i, line := range lines {
+		if len(line) > _lineLen {
+			t.Errorf("line %d has %d octets", i+1, len(line))
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line %d splits a character: %q", i+1, line)
+		}
+		if strings.HasPrefix(line, " ") {
+			folded++
+		}
+	}
+	if folded == 0 {
+		t.Errorf("no line folded:\n%s", buf.String())
+	}
+
+	got, err := Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || len(got[0].Components) != 1 {
+		t.Fatalf("components %+v", got)
+	}
+	todo := got[0].Components[0]
+	if s := todo.Text("SUMMARY"); s != summary {
+		t.Errorf("SUMMARY %q, want %q", s, summary)
+	}
+	if p := todo.Prop("DTSTART"); p == nil || p.Params["TZID"] != "Europe/Berlin" || p.Value != "20221001T090000" {
+		t.Errorf("DTSTART %+v", p)
+	}
+}
+
+func TestUnfold(t *testing.T) {
+	text := "BEGIN:VTODO\r\nSUMMARY:fol\r\n ded\r\n\tand tabbed\r\n\r\nUID:1\nEND:VTODO\r\n"
+	lines, err := unfold(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []contentLine{{1, "BEGIN:VTODO"}, {2, "SUMMARY:folded" + "and tabbed"}, {6, "UID:1"}, {7, "END:VTODO"}}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("lines %+v, want %+v", lines, want)
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want Property // zero Name when the line is invalid
+	}{
+		{"summary:buy milk", Property{Name: "SUMMARY", Value: "buy milk"}},
+		{"DESCRIPTION:a:b;c", Property{Name: "DESCRIPTION", Value: "a:b;c"}},
+		{"DTSTART;TZID=Europe/Berlin:20221001T090000",
+			Property{Name: "DTSTART", Params: map[string]string{"TZID": "Europe/Berlin"}, Value: "20221001T090000"}},
+		{`ATTENDEE;CN="Doe, Jane";role=REQ-PARTICIPANT:mailto:jane@example.com`,
+			Property{Name: "ATTENDEE", Params: map[string]string{"CN": "Doe, Jane", "ROLE": "REQ-PARTICIPANT"},
+				Value: "mailto:jane@example.com"}},
+		{`X-NOTE;LABEL="a:b;c":v`, Property{Name: "X-NOTE", Params: map[string]string{"LABEL": "a:b;c"}, Value: "v"}},
+		{"SUMMARY:", Property{Name: "SUMMARY"}},
+		{":no name", Property{}},
+		{"NO VALUE", Property{}},
+		{"X;=b:v", Property{}},
+		{`X;A="open:v`, Property{}},
+		{"X;A=b", Property{}},
+		{`X;A="b"`, Property{}},
+		{`X;A="b"c:v`, Property{}},
+	}
+	for _, tt := range tests {
+		got, err := parseLine(tt.line)
+		if tt.want.Name == "" {
+			if err == nil {
+				t.Errorf("%q: got %+v, want an error", tt.line, got)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %+v, %v, want %+v", tt.line, got, err, tt.want)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		text string
+		line int
+	}{
+		{"BEGIN:VTODO\r\nEND:VEVENT\r\n", 2},
+		{"BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nEND:VTODO\r\n", 3},
+		{"SUMMARY:outside\r\n", 1},
+		{"BEGIN:VTODO\r\nX;A=\"open:v\r\nEND:VTODO\r\n", 2},
+	}
+	for _, tt := range tests {
+		_, err := Decode(strings.NewReader(tt.text))
+		var e *Error
+		if !errors.As(err, &e) || e.Line != tt.line {
+			t.Errorf("%q: %v, want an error at line %d", tt.text, err, tt.line)
+		}
+	}
+}
+
+func TestCheckRecur(t *testing.T) {
+	valid := []string{"FREQ=WEEKLY", "FREQ=WEEKLY;BYDAY=MO,WE", "freq=daily;COUNT=3", "INTERVAL=2;FREQ=MONTHLY"}
+	for _, v := range valid {
+		if err := CheckRecur(v); err != nil {
+			t.Errorf("%q: %v", v, err)
+		}
+	}
+	invalid := []string{"", "BYDAY=MO", "FREQ=WEEKLY;FREQ=DAILY", "FREQ=FORTNIGHTLY", "FREQ=", "FREQ=DAILY;COUNT",
+		"FREQ=DAILY;", "=DAILY"}
+	for _, v := range invalid {
+		if err := CheckRecur(v); err == nil {
+			t.Errorf("%q: no error", v)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	for _, s := range []string{"plain", `a\b`, "a;b,c", "two\nlines", `trailing\`} {
+		if got := UnescapeText(EscapeText(s)); got != s {
+			t.Errorf("%q: round trip gives %q", s, got)
+		}
+	}
+	if got := EscapeText("crlf\r\nline"); got != `crlf\nline` {
+		t.Errorf("crlf: %q", got)
+	}
+	if got := UnescapeText(`A\NB\,C`); got != "A\nB,C" {
+		t.Errorf("capital N: %q", got)
+	}
+}
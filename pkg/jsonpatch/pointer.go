@@ -0,0 +1,105 @@
+package jsonpatch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parsePointer splits the RFC 6901 JSON pointer into unescaped tokens.
+func parsePointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if ptr[0] != '/' {
+		return nil, fmt.Errorf("pointer %q must start with /", ptr)
+	}
+	tokens := strings.Split(ptr[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+	}
+	return tokens, nil
+}
+
+// arrayIndex parses the token as index into an array of length n, "-" and n
+// are allowed when appending.
+func arrayIndex(token string, n int, appending bool) (int, error) {
+	if appending && token == "-" {
+		return n, nil
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if i > n || (i == n && !appending) {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+// get returns the value the tokens point to.
+func get(doc interface{}, tokens []string) (interface{}, error) {
+	cur := doc
+	for _, t := range tokens {
+		switch node := cur.(type) {
+		case map[string]interface{}:
+			v, ok := node[t]
+			if !ok {
+				return nil, fmt.Errorf("member %q not found", t)
+			}
+			cur = v
+		case []interface{}:
+			i, err := arrayIndex(t, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			cur = node[i]
+		default:
+			return nil, fmt.Errorf("cannot traverse %q of a scalar", t)
+		}
+	}
+	return cur, nil
+}
+
+// update replaces the parent container of the last token with what fn
+// returns and gives back the new document.
+func update(doc interface{}, tokens []string, fn func(parent interface{}, last string) (interface{}, error)) (interface{}, error) {
+	if len(tokens) == 0 {
+		return fn(nil, "")
+	}
+	var walk func(cur interface{}, rest []string) (interface{}, error)
+	walk = func(cur interface{}, rest []string) (interface{}, error) {
+		if len(rest) == 1 {
+			return fn(cur, rest[0])
+		}
+		switch node := cur.(type) {
+		case map[string]interface{}:
+			child, ok := node[rest[0]]
+			if !ok {
+				return nil, fmt.Errorf("member %q not found", rest[0])
+			}
+			v, err := walk(child, rest[1:])
+			if err != nil {
+				return nil, err
+			}
+			node[rest[0]] = v
+			return node, nil
+		case []interface{}:
+			i, err := arrayIndex(rest[0], len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			v, err := walk(node[i], rest[1:])
+			if err != nil {
+				return nil, err
+			}
+			node[i] = v
+			return node, nil
+		}
+		return nil, fmt.Errorf("cannot traverse %q of a scalar", rest[0])
+	}
+	return walk(doc, tokens)
+}
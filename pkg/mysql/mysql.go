@@ -7,7 +7,7 @@ import (
 	"time"
 
 	"github.com/Masterminds/squirrel"
-	_ "github.com/go-sql-driver/mysql"
+	driver "github.com/go-sql-driver/mysql"
 )
 
 const (
@@ -39,7 +39,15 @@ func New(url string, opts ...Option) (*Mysql, error) {
 		opt(my)
 	}
 	my.Builder = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question)
-	db, err := sql.Open("mysql", url)
+
+	// Scan DATETIME columns into time.Time
+	cfg, err := driver.ParseDSN(url)
+	if err != nil {
+		return nil, fmt.Errorf("mysql - New - driver.ParseDSN: %w", err)
+	}
+	cfg.ParseTime = true
+
+	db, err := sql.Open("mysql", cfg.FormatDSN())
 	if err != nil {
 		return nil, fmt.Errorf("mysql - New - sql.Open: %w", err)
 	}
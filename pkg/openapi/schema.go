@@ -0,0 +1,223 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	_timeType = reflect.TypeOf(time.Time{})
+	_rawType  = reflect.TypeOf(json.RawMessage{})
+)
+
+// Generator turns Go types into schemas, named structs go to Schemas and are
+// referred to.
+type Generator struct {
+	Schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+// NewGenerator -.
+func NewGenerator() *Generator {
+	return &Generator{Schemas: make(map[string]*Schema), names: make(map[reflect.Type]string)}
+}
+
+// Schema returns the schema of the type of v, nil for a nil v.
+func (g *Generator) Schema(v interface{}) *Schema {
+	if v == nil {
+		return nil
+	}
+	return g.schema(reflect.TypeOf(v))
+}
+
+func (g *Generator) schema(t reflect.Type) *Schema {
+	switch {
+	case t == _timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == _rawType:
+		return &Schema{}
+	case t.Kind() == reflect.Ptr:
+		s := g.schema(t.Elem())
+		if s.Ref != "" {
+			return &Schema{AllOf: []*Schema{s}, Nullable: true}
+		}
+		s.Nullable = true
+		return s
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		return Ref(g.component(t))
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32", Minimum: float(0)}
+	case reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64", Minimum: float(0)}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		return g.object(t)
+	}
+	// interface{} holds anything
+	return &Schema{}
+}
+
+// component registers the named struct once, a name taken by another type
+// is qualified with the package name.
+func (g *Generator) component(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := strings.Title(t.Name())
+	if _, taken := g.Schemas[name]; taken {
+		pkg := t.PkgPath()
+		name = strings.Title(pkg[strings.LastIndexByte(pkg, '/')+1:]) + name
+	}
+	g.names[t] = name
+	// registered before the fields so recursive types refer to themselves
+	g.Schemas[name] = &Schema{}
+	*g.Schemas[name] = *g.object(t)
+	return name
+}
+
+func (g *Generator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, f := range fields(t, "json") {
+		fs := g.schema(f.field.Type)
+		if f.required {
+			s.Required = append(s.Required, f.name)
+		}
+		applyBinding(fs, f.field)
+		s.Properties[f.name] = fs
+	}
+	return s
+}
+
+// Parameters returns the fields of the struct of v with the tag, form for
+// query and uri for path parameters, as parameters in where.
+func (g *Generator) Parameters(v interface{}, tag string, in string) []Parameter {
+	var ret []Parameter
+	for _, f := range fields(reflect.TypeOf(v), tag) {
+		s := g.schema(f.field.Type)
+		applyBinding(s, f.field)
+		ret = append(ret, Parameter{Name: f.name, In: in, Required: f.required || in == "path", Schema: s})
+	}
+	return ret
+}
+
+type field struct {
+	name     string
+	field    reflect.StructField
+	required bool
+}
+
+// fields lists the exported fields named by the tag, embedded structs are
+// flattened like encoding/json does.
+func fields(t reflect.Type, tag string) []field {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var ret []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get(tag), ",")[0]
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			ret = append(ret, fields(f.Type, tag)...)
+			continue
+		}
+		if f.PkgPath != "" || name == "-" {
+			continue
+		}
+		if name == "" {
+			if tag != "json" {
+				continue
+			}
+			name = f.Name
+		}
+		ret = append(ret, field{name: name, field: f, required: hasRule(f, "required")})
+	}
+	return ret
+}
+
+func bindingRules(f reflect.StructField) []string {
+	b := f.Tag.Get("binding")
+	if b == "" {
+		return nil
+	}
+	// rules after dive apply to the elements
+	if i := strings.Index(b, "dive"); i >= 0 {
+		b = b[:i]
+	}
+	return strings.Split(b, ",")
+}
+
+func hasRule(f reflect.StructField, rule string) bool {
+	for _, r := range bindingRules(f) {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// applyBinding describes the validator rules the schema can hold, conditional
+// ones like required_if are left to the description of the operation.
+func applyBinding(s *Schema, f reflect.StructField) {
+	for _, r := range bindingRules(f) {
+		kv := strings.SplitN(r, "=", 2)
+		switch kv[0] {
+		case "oneof":
+			for _, v := range strings.Fields(kv[1]) {
+				if n, err := strconv.ParseInt(v, 10, 64); err == nil && s.Type == "integer" {
+					s.Enum = append(s.Enum, n)
+					continue
+				}
+				s.Enum = append(s.Enum, v)
+			}
+		case "min", "max":
+			n, err := strconv.ParseUint(kv[1], 10, 64)
+			if err != nil {
+				continue
+			}
+			switch {
+			case s.Type == "string" && kv[0] == "min":
+				s.MinLength = &n
+			case s.Type == "string":
+				s.MaxLength = &n
+			case s.Type == "array" && kv[0] == "min":
+				s.MinItems = &n
+			case s.Type == "array":
+				s.MaxItems = &n
+			case kv[0] == "min":
+				s.Minimum = float(float64(n))
+			default:
+				s.Maximum = float(float64(n))
+			}
+		case "hexcolor":
+			s.Pattern = "^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$"
+		}
+	}
+}
+
+func float(v float64) *float64 {
+	return &v
+}
@@ -0,0 +1,168 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+const (
+	_bm25K1 = 1.2
+	_bm25B  = 0.75
+	// _fieldGap separates positions of fields so phrases never span them
+	_fieldGap = 1000
+)
+
+// Result -.
+type Result struct {
+	Id    uint
+	Score float64
+}
+
+// Index is a positional inverted index safe for concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	postings map[string]map[uint][]int
+	docLen   map[uint]int
+	totalLen int
+}
+
+// NewIndex -.
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[uint][]int),
+		docLen:   make(map[uint]int),
+	}
+}
+
+// Add indexes the document fields, re-adding an id replaces it.
+func (x *Index) Add(id uint, fields ...string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.remove(id)
+	length := 0
+	for f, text := range fields {
+		for pos, word := range Tokenize(text) {
+			docs, ok := x.postings[word]
+			if !ok {
+				docs = make(map[uint][]int)
+				x.postings[word] = docs
+			}
+			docs[id] = append(docs[id], f*_fieldGap+pos)
+			length++
+		}
+	}
+	x.docLen[id] = length
+	x.totalLen += length
+}
+
+// Remove -.
+func (x *Index) Remove(id uint) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.remove(id)
+}
+
+func (x *Index) remove(id uint) {
+	length, ok := x.docLen[id]
+	if !ok {
+		return
+	}
+	for word, docs := range x.postings {
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(x.postings, word)
+		}
+	}
+	delete(x.docLen, id)
+	x.totalLen -= length
+}
+
+// Search returns documents matching every term and phrase of q ordered by
+// BM25 relevance.
+func (x *Index) Search(q Query) []Result {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	words := q.Words()
+	if len(words) == 0 || len(x.docLen) == 0 {
+		return nil
+	}
+
+	// candidates contain every word
+	var candidates map[uint]struct{}
+	for _, word := range words {
+		docs := x.postings[word]
+		next := make(map[uint]struct{}, len(docs))
+		for id := range docs {
+			if _, ok := candidates[id]; candidates == nil || ok {
+				next[id] = struct{}{}
+			}
+		}
+		candidates = next
+		if len(candidates) == 0 {
+			return nil
+		}
+	}
+
+	n := float64(len(x.docLen))
+	avgLen := float64(x.totalLen) / n
+	results := make([]Result, 0, len(candidates))
+	for id := range candidates {
+		if !x.hasPhrases(id, q.Phrases) {
+			continue
+		}
+		score := 0.0
+		docLen := float64(x.docLen[id])
+		for _, word := range words {
+			docs := x.postings[word]
+			df := float64(len(docs))
+			tf := float64(len(docs[id]))
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			score += idf * tf * (_bm25K1 + 1) / (tf + _bm25K1*(1-_bm25B+_bm25B*docLen/avgLen))
+		}
+		results = append(results, Result{id, score})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Id < results[j].Id
+	})
+	return results
+}
+
+func (x *Index) hasPhrases(id uint, phrases [][]string) bool {
+	for _, phrase := range phrases {
+		if !x.hasPhrase(id, phrase) {
+			return false
+		}
+	}
+	return true
+}
+
+func (x *Index) hasPhrase(id uint, phrase []string) bool {
+	next := make([]map[int]struct{}, len(phrase))
+	for i, word := range phrase {
+		next[i] = make(map[int]struct{})
+		for _, pos := range x.postings[word][id] {
+			next[i][pos] = struct{}{}
+		}
+	}
+	for _, start := range x.postings[phrase[0]][id] {
+		found := true
+		for i := 1; i < len(phrase); i++ {
+			if _, ok := next[i][start+i]; !ok {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
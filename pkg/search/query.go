@@ -0,0 +1,86 @@
+// Package search implements a small in-memory full-text index with phrase
+// queries, BM25 ranking and highlighted snippets.
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Query -.
+type Query struct {
+	Terms   []string
+	Phrases [][]string
+}
+
+// Parse splits q into lower-cased terms and "quoted phrases", all of them
+// must match. A phrase of a single word is treated as a term.
+func Parse(q string) Query {
+	var query Query
+	parts := strings.Split(q, `"`)
+	for i, part := range parts {
+		words := Tokenize(part)
+		if i%2 == 1 && len(words) > 1 {
+			query.Phrases = append(query.Phrases, words)
+			continue
+		}
+		query.Terms = append(query.Terms, words...)
+	}
+	return query
+}
+
+// Empty -.
+func (q Query) Empty() bool {
+	return len(q.Terms) == 0 && len(q.Phrases) == 0
+}
+
+// Words returns every word of the query, phrases included.
+func (q Query) Words() []string {
+	words := append([]string{}, q.Terms...)
+	for _, p := range q.Phrases {
+		words = append(words, p...)
+	}
+	return words
+}
+
+type span struct {
+	start, end int
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// spans returns byte offsets of words in text
+func spans(text string) []span {
+	var (
+		ret   []span
+		start = -1
+	)
+	for i, r := range text {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			ret = append(ret, span{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		ret = append(ret, span{start, len(text)})
+	}
+	return ret
+}
+
+// Tokenize splits text into lower-cased words of letters and digits.
+func Tokenize(text string) []string {
+	s := spans(text)
+	words := make([]string, 0, len(s))
+	for _, sp := range s {
+		words = append(words, strings.ToLower(text[sp.start:sp.end]))
+	}
+	return words
+}
@@ -0,0 +1,98 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+const _ellipsis = "…"
+
+// Highlight returns text as HTML with every word matching q wrapped in pre
+// and post. The text is escaped, pre and post are markup and written as is.
+func Highlight(text string, q Query, pre, post string) string {
+	return highlight(text, 0, len(text), q, pre, post)
+}
+
+// Snippet cuts a fragment of about width runes around the first match of q
+// and highlights matches in it like Highlight. Text without matches yields
+// its beginning.
+func Snippet(text string, q Query, width int, pre, post string) string {
+	if utf8.RuneCountInString(text) <= width {
+		return Highlight(text, q, pre, post)
+	}
+
+	words := make(map[string]struct{})
+	for _, w := range q.Words() {
+		words[w] = struct{}{}
+	}
+
+	// byte offset of the first match
+	first := 0
+	for _, sp := range spans(text) {
+		if _, ok := words[strings.ToLower(text[sp.start:sp.end])]; ok {
+			first = sp.start
+			break
+		}
+	}
+
+	// start a third of the width before the match, on a rune boundary
+	start := first
+	for back := width / 3; back > 0 && start > 0; back-- {
+		_, size := utf8.DecodeLastRuneInString(text[:start])
+		start -= size
+	}
+	end := start
+	for n := 0; n < width && end < len(text); n++ {
+		_, size := utf8.DecodeRuneInString(text[end:])
+		end += size
+	}
+
+	// do not cut words in half
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(text[:start])
+		if !isWordRune(r) {
+			break
+		}
+		start -= size
+	}
+	for end < len(text) {
+		r, size := utf8.DecodeRuneInString(text[end:])
+		if !isWordRune(r) {
+			break
+		}
+		end += size
+	}
+
+	ret := highlight(text, start, end, q, pre, post)
+	if start > 0 {
+		ret = _ellipsis + ret
+	}
+	if end < len(text) {
+		ret += _ellipsis
+	}
+	return ret
+}
+
+func highlight(text string, start, end int, q Query, pre, post string) string {
+	words := make(map[string]struct{})
+	for _, w := range q.Words() {
+		words[w] = struct{}{}
+	}
+
+	var b strings.Builder
+	last := start
+	for _, sp := range spans(text[start:end]) {
+		s, e := start+sp.start, start+sp.end
+		if _, ok := words[strings.ToLower(text[s:e])]; !ok {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[last:s]))
+		b.WriteString(pre)
+		b.WriteString(html.EscapeString(text[s:e]))
+		b.WriteString(post)
+		last = e
+	}
+	b.WriteString(html.EscapeString(text[last:end]))
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package search
+
+import "testing"
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		text  string
+		query string
+		want  string
+	}{
+		{"fix login bug", "login", "fix <mark>login</mark> bug"},
+		{"Login page", "login", "<mark>Login</mark> page"},
+		{"no match", "login", "no match"},
+		{`<script>alert("login")</script>`, "login",
+			`&lt;script&gt;alert(&#34;<mark>login</mark>&#34;)&lt;/script&gt;`},
+		{"<b>bold</b> & login", "bold", "&lt;b&gt;<mark>bold</mark>&lt;/b&gt; &amp; login"},
+	}
+	for _, tt := range tests {
+		if got := Highlight(tt.text, Parse(tt.query), "<mark>", "</mark>"); got != tt.want {
+			t.Errorf("Highlight(%q, %q) = %q, want %q", tt.text, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	tests := []struct {
+		text  string
+		query string
+		width int
+		want  string
+	}{
+		{"short login text", "login", 40, "short <mark>login</mark> text"},
+		{"a long text with the <img src=x onerror=alert(1)> login bug somewhere in the middle of it", "login", 20,
+			"…alert(1)&gt; <mark>login</mark> bug somewhere…"},
+		{"<i>start</i> of a long text without any match at all", "login", 10, "&lt;i&gt;start&lt;/i…"},
+	}
+	for _, tt := range tests {
+		if got := Snippet(tt.text, Parse(tt.query), tt.width, "<mark>", "</mark>"); got != tt.want {
+			t.Errorf("Snippet(%q, %q, %d) = %q, want %q", tt.text, tt.query, tt.width, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Package sse streams Server-Sent Events:
+// https://html.spec.whatwg.org/multipage/server-sent-events.html
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const _defaultWriteTimeout = 10 * time.Second
+
+// Event is a message of the stream, empty fields are left out.
+type Event struct {
+	Id    string
+	Event string
+	Data  []byte
+}
+
+// Stream writes events to a client until it goes away or Close is called.
+type Stream struct {
+	w       *bufio.Writer
+	conn    net.Conn
+	flusher http.Flusher
+	timeout time.Duration
+	done    <-chan struct{}
+}
+
+// Open answers the request with an event stream. HTTP/1 connections are
+// hijacked so every write gets writeTimeout as deadline instead of the
+// server's write timeout ending the stream, the connection is closed with
+// the stream. Other connections are flushed after every write and keep the
+// server's timeouts.
+func Open(w http.ResponseWriter, r *http.Request, writeTimeout time.Duration) (*Stream, error) {
+	if writeTimeout <= 0 {
+		writeTimeout = _defaultWriteTimeout
+	}
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	// nginx buffers responses otherwise
+	h.Set("X-Accel-Buffering", "no")
+
+	if hj, ok := w.(http.Hijacker); ok && r.ProtoMajor == 1 {
+		conn, rw, err := hj.Hijack()
+		if err != nil {
+			return nil, err
+		}
+		// the server's read deadline still applies to the hijacked connection
+		conn.SetDeadline(time.Time{})
+		h.Set("Connection", "close")
+
+		s := &Stream{w: rw.Writer, conn: conn, timeout: writeTimeout}
+		s.conn.SetWriteDeadline(time.Now().Add(s.timeout))
+		s.w.WriteString("HTTP/1.1 200 OK\r\n")
+		h.Write(s.w)
+		s.w.WriteString("\r\n")
+		if err := s.flush(); err != nil {
+			return nil, err
+		}
+
+		// the client sends nothing more, reading ends when it goes away
+		done := make(chan struct{})
+		go func() {
+			io.Copy(ioutil.Discard, rw.Reader)
+			close(done)
+		}()
+		s.done = done
+		return s, nil
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, http.ErrNotSupported
+	}
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return &Stream{w: bufio.NewWriter(w), flusher: flusher, timeout: writeTimeout, done: r.Context().Done()}, nil
+}
+
+// Done is closed when the client has gone away.
+func (s *Stream) Done() <-chan struct{} {
+	return s.done
+}
+
+// Send writes the event, each line of Data as a data field.
+func (s *Stream) Send(e Event) error {
+	if e.Id != "" {
+		s.field("id", e.Id)
+	}
+	if e.Event != "" {
+		s.field("event", e.Event)
+	}
+	for _, line := range bytes.Split(e.Data, []byte("\n")) {
+		s.w.WriteString("data: ")
+		s.w.Write(bytes.TrimSuffix(line, []byte("\r")))
+		s.w.WriteByte('\n')
+	}
+	s.w.WriteByte('\n')
+	return s.flush()
+}
+
+// Retry tells the client how long to wait before reconnecting.
+func (s *Stream) Retry(d time.Duration) error {
+	s.field("retry", strconv.FormatInt(int64(d/time.Millisecond), 10))
+	s.w.WriteByte('\n')
+	return s.flush()
+}
+
+// Comment writes a line clients ignore, it keeps proxies from closing an
+// idle stream.
+func (s *Stream) Comment(text string) error {
+	s.w.WriteString(": ")
+	s.w.WriteString(clean(text))
+	s.w.WriteString("\n\n")
+	return s.flush()
+}
+
+// Close ends the stream.
+func (s *Stream) Close() error {
+	if s.conn != nil {
+		return s.conn.Close()
+	}
+	return nil
+}
+
+func (s *Stream) field(name string, value string) {
+	s.w.WriteString(name)
+	s.w.WriteString(": ")
+	s.w.WriteString(clean(value))
+	s.w.WriteByte('\n')
+}
+
+// flush sends what was written, a client not reading within the write
+// timeout fails it and the connection is closed.
+func (s *Stream) flush() error {
+	if s.conn == nil {
+		err := s.w.Flush()
+		if err == nil {
+			s.flusher.Flush()
+		}
+		return err
+	}
+	s.conn.SetWriteDeadline(time.Now().Add(s.timeout))
+	if err := s.w.Flush(); err != nil {
+		s.conn.Close()
+		return err
+	}
+	return nil
+}
+
+// clean keeps a value on its line.
+func clean(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
@@ -0,0 +1,168 @@
+// Package todotxt reads and writes the todo.txt format, one task per line
+// with optional completion mark, priority and dates ahead of the text. The
+// text keeps its +projects, @contexts and key:value tags as written.
+package todotxt
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+// DateFormat -.
+const DateFormat = "2006-01-02"
+
+// Task -.
+type Task struct {
+	Done bool
+	// Priority is 'A' to 'Z', 0 when there is none.
+	Priority byte
+	// Completed is only written for a done task.
+	Completed time.Time
+	Created   time.Time
+	Text      string
+}
+
+// Parse reads a line, any line is a task and anything not recognized ahead
+// of the text is text.
+func Parse(line string) Task {
+	var t Task
+	s := strings.TrimRight(line, "\r\n")
+	if strings.HasPrefix(s, "x ") {
+		t.Done = true
+		s = s[2:]
+	}
+	if !t.Done && len(s) >= 4 && s[0] == '(' && s[1] >= 'A' && s[1] <= 'Z' && s[2] == ')' && s[3] == ' ' {
+		t.Priority = s[1]
+		s = s[4:]
+	}
+	// a done task has the completion date first
+	if d, rest, ok := cutDate(s); ok {
+		s = rest
+		if !t.Done {
+			t.Created = d
+		} else {
+			t.Completed = d
+			if d2, rest2, ok := cutDate(s); ok {
+				t.Created, s = d2, rest2
+			}
+		}
+	}
+	t.Text = s
+	return t
+}
+
+func cutDate(s string) (time.Time, string, bool) {
+	if len(s) < len(DateFormat)+1 || s[len(DateFormat)] != ' ' {
+		return time.Time{}, s, false
+	}
+	d, err := time.Parse(DateFormat, s[:len(DateFormat)])
+	if err != nil {
+		return time.Time{}, s, false
+	}
+	return d, s[len(DateFormat)+1:], true
+}
+
+// String formats the task as a line without the line ending. A completed
+// task keeps its priority as a pri: tag, like most todo.txt clients.
+func (t Task) String() string {
+	var b strings.Builder
+	if t.Done {
+		b.WriteString("x ")
+		if !t.Completed.IsZero() {
+			b.WriteString(t.Completed.Format(DateFormat))
+			b.WriteByte(' ')
+		}
+	} else if t.Priority != 0 {
+		b.WriteByte('(')
+		b.WriteByte(t.Priority)
+		b.WriteString(") ")
+	}
+	// without a completion date the creation date would read as one
+	if !t.Created.IsZero() && (!t.Done || !t.Completed.IsZero()) {
+		b.WriteString(t.Created.Format(DateFormat))
+		b.WriteByte(' ')
+	}
+	b.WriteString(t.Text)
+	if t.Done && t.Priority != 0 && t.Tags()["pri"] == "" {
+		b.WriteString(" pri:")
+		b.WriteByte(t.Priority)
+	}
+	return b.String()
+}
+
+func (t Task) words(prefix byte) []string {
+	var ret []string
+	for _, w := range strings.Fields(t.Text) {
+		if len(w) > 1 && w[0] == prefix {
+			ret = append(ret, w[1:])
+		}
+	}
+	return ret
+}
+
+// Projects returns the +project words of the text.
+func (t Task) Projects() []string {
+	return t.words('+')
+}
+
+// Contexts returns the @context words of the text.
+func (t Task) Contexts() []string {
+	return t.words('@')
+}
+
+// Tags returns the key:value words of the text, like due:2022-10-01. The
+// first of repeated keys wins.
+func (t Task) Tags() map[string]string {
+	ret := make(map[string]string)
+	for _, w := range strings.Fields(t.Text) {
+		k, v, ok := Tag(w)
+		if !ok {
+			continue
+		}
+		if _, dup := ret[k]; !dup {
+			ret[k] = v
+		}
+	}
+	return ret
+}
+
+// Tag splits a key:value word, neither side may be empty or hold spaces
+// and a URL like http://host is not a tag.
+func Tag(word string) (string, string, bool) {
+	i := strings.IndexByte(word, ':')
+	if i <= 0 || i == len(word)-1 || strings.ContainsAny(word, " \t") {
+		return "", "", false
+	}
+	if strings.HasPrefix(word[i+1:], "//") {
+		return "", "", false
+	}
+	return word[:i], word[i+1:], true
+}
+
+// Read reads the tasks of the stream, blank lines are skipped.
+func Read(r io.Reader) ([]Task, error) {
+	var ret []Task
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		ret = append(ret, Parse(line))
+	}
+	return ret, sc.Err()
+}
+
+// Write writes the tasks a line each.
+func Write(w io.Writer, tasks []Task) error {
+	bw := bufio.NewWriter(w)
+	for _, t := range tasks {
+		if _, err := bw.WriteString(t.String() + "\n"); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}